@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/export"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+)
+
+// exportFormats maps export formats to request formats
+var exportFormats = map[export.Format]pb.ExportFormat{
+	export.FormatCSV:     pb.ExportFormat_EXPORT_FORMAT_CSV,
+	export.FormatJSONL:   pb.ExportFormat_EXPORT_FORMAT_JSONL,
+	export.FormatParquet: pb.ExportFormat_EXPORT_FORMAT_PARQUET,
+}
+
+// runConvert implements "etcproc convert": it runs a statement through the pipeline without saving it,
+// writes the normalized records and exits with the number of records left out
+func runConvert(args []string, stdout, stderr io.Writer) int {
+	flags := newFlagSet("convert", "[flags] file.csv", stderr)
+	format := flags.String("format", "csv", "Output format: csv, jsonl or parquet")
+	output := flags.String("o", "", "Output file (default: standard output)")
+	accountID := flags.String("account", "", "Account ID written to the records")
+	configPath := flags.String("config", "", "Server config file (master data, interchanges, card mask policy, ...)")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	exportFormat, err := export.ParseFormat(*format)
+	if err != nil {
+		fmt.Fprintf(stderr, "etcproc: %v\n", err)
+		return exitUsage
+	}
+	service, err := newService(*configPath, nil)
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	path := flags.Arg(0)
+	return writeExport(service, &pb.ExportRecordsRequest{
+		Format:      exportFormats[exportFormat],
+		AccountId:   *accountID,
+		CsvFilePath: &path,
+	}, *output, stdout, stderr)
+}
+
+// runExport implements "etcproc export": records come from the record store written by the server
+// (or by "etcproc import -store"), or from a CSV file given as argument like "etcproc convert"
+func runExport(args []string, stdout, stderr io.Writer) int {
+	flags := newFlagSet("export", "[flags] [file.csv]", stderr)
+	format := flags.String("format", "csv", "Output format: csv, jsonl or parquet")
+	output := flags.String("o", "", "Output file (default: standard output)")
+	storePath := flags.String("store", os.Getenv("RECORD_STORE_FILE"), "Record store file written by the server")
+	jobID := flags.String("job", "", "Export the records of one import job")
+	sourceFile := flags.String("source-file", "", "Export the records read from this file")
+	accountID := flags.String("account", "", "Export the records of one account")
+	from := flags.String("from", "", "First usage date (YYYY-MM-DD)")
+	to := flags.String("to", "", "Last usage date (YYYY-MM-DD)")
+	configPath := flags.String("config", "", "Server config file (master data, interchanges, card mask policy, ...)")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	exportFormat, err := export.ParseFormat(*format)
+	if err != nil {
+		fmt.Fprintf(stderr, "etcproc: %v\n", err)
+		return exitUsage
+	}
+
+	service, err := newService(*configPath, nil)
+	if err != nil {
+		return fail(stderr, err)
+	}
+	req := &pb.ExportRecordsRequest{
+		Format:      exportFormats[exportFormat],
+		ImportJobId: *jobID,
+		SourceFile:  *sourceFile,
+		AccountId:   *accountID,
+		FromDate:    *from,
+		ToDate:      *to,
+	}
+	if flags.NArg() == 1 {
+		path := flags.Arg(0)
+		req.CsvFilePath = &path
+	} else {
+		if *storePath == "" {
+			fmt.Fprintln(stderr, "etcproc: either -store (or RECORD_STORE_FILE) or a CSV file is required")
+			return exitUsage
+		}
+		store, err := export.OpenFileStore(*storePath)
+		if err != nil {
+			return fail(stderr, err)
+		}
+		service.SetRecordStore(store)
+	}
+	return writeExport(service, req, *output, stdout, stderr)
+}
+
+// writeExport runs ExportRecords and writes the content to output (standard output when empty)
+func writeExport(service *handler.DataProcessorService, req *pb.ExportRecordsRequest, output string, stdout, stderr io.Writer) int {
+	resp, err := service.ExportRecords(context.Background(), req)
+	if err != nil {
+		return fail(stderr, err)
+	}
+	printRecordErrors(stderr, resp.RecordErrors)
+
+	if output == "" {
+		_, err = stdout.Write(resp.Content)
+	} else {
+		err = os.WriteFile(output, resp.Content, 0644)
+	}
+	if err != nil {
+		return fail(stderr, fmt.Errorf("failed to write output: %w", err))
+	}
+	if output != "" {
+		fmt.Fprintf(stderr, "Exported %d records (%s) to %s\n", resp.RecordCount, resp.SchemaVersion, output)
+	}
+	return errorExitCode(rejectedRecords(resp.RecordErrors))
+}
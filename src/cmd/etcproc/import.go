@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/db"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/export"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+)
+
+// runImport implements "etcproc import": it runs ProcessCSVFile on a file or a directory of CSV files
+// and exits with the number of records that could not be saved
+func runImport(args []string, stdout, stderr io.Writer) int {
+	flags := newFlagSet("import", "[flags] file.csv|directory", stderr)
+	jsonOutput := flags.Bool("json", false, "Print the response as JSON")
+	dbAddr := flags.String("db", os.Getenv("ETC_PROCESSOR_DB_ADDR"), "db_service address; without it records are only processed locally")
+	accountID := flags.String("account", "", "Account ID to import the statements for")
+	dryRun := flags.Bool("dry-run", false, "Report what would be saved without saving")
+	stitchTrips := flags.Bool("stitch", false, "Group consecutive records into trips")
+	storePath := flags.String("store", os.Getenv("RECORD_STORE_FILE"), "Record store file to keep the imported records for export")
+	configPath := flags.String("config", "", "Server config file (master data, interchanges, card mask policy, ...)")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	var dbClient handler.DBClient
+	if *dbAddr != "" && !*dryRun {
+		client, err := db.NewETCMeisaiClient(*dbAddr)
+		if err != nil {
+			return fail(stderr, fmt.Errorf("failed to connect to db_service: %w", err))
+		}
+		defer client.Close()
+		dbClient = client
+	} else if !*dryRun {
+		fmt.Fprintln(stderr, "etcproc: no db_service address (-db or ETC_PROCESSOR_DB_ADDR); records are not saved to the database")
+	}
+
+	service, err := newService(*configPath, dbClient)
+	if err != nil {
+		return fail(stderr, err)
+	}
+	if *storePath != "" {
+		store, err := export.OpenFileStore(*storePath)
+		if err != nil {
+			return fail(stderr, err)
+		}
+		service.SetRecordStore(store)
+	}
+
+	path := flags.Arg(0)
+	resp, err := service.ProcessCSVFile(context.Background(), &pb.ProcessCSVFileRequest{
+		CsvFilePath: &path,
+		AccountId:   accountID,
+		DryRun:      dryRun,
+		StitchTrips: stitchTrips,
+	})
+	if err != nil {
+		return fail(stderr, err)
+	}
+	// A file that cannot be parsed at all fails the import
+	if resp.Stats.GetTotalRecords() == 0 && !resp.Success && len(resp.Errors) > 0 {
+		return fail(stderr, fmt.Errorf("%s", resp.Message))
+	}
+
+	if *jsonOutput {
+		result, err := protoJSON(resp)
+		if err != nil {
+			return fail(stderr, err)
+		}
+		if err := writeJSON(stdout, result); err != nil {
+			return fail(stderr, err)
+		}
+		return errorExitCode(int(resp.Stats.GetErrorRecords()))
+	}
+
+	fmt.Fprintln(stdout, resp.Message)
+	if resp.ImportJobId != "" && !resp.DryRun {
+		fmt.Fprintf(stdout, "Import job: %s\n", resp.ImportJobId)
+	}
+	for _, file := range resp.FileResults {
+		stats := file.Stats
+		fmt.Fprintf(stdout, "%s (%s, %s): %d saved, %d skipped, %d errors\n",
+			file.FilePath, file.Format, file.Encoding, stats.GetSavedRecords(), stats.GetSkippedRecords(), stats.GetErrorRecords())
+		printRecordErrors(stdout, file.RecordErrors)
+	}
+	return errorExitCode(int(resp.Stats.GetErrorRecords()))
+}
@@ -0,0 +1,251 @@
+// Command etcproc runs the processing pipeline locally, without the gRPC server or db_service.
+//
+// Usage:
+//
+//	etcproc validate [flags] file.csv...
+//	etcproc preview [flags] file.csv
+//	etcproc convert [flags] file.csv
+//	etcproc import [flags] file.csv|directory
+//	etcproc export [flags] [file.csv]
+//
+// validate, preview, convert and import exit with the number of rejected records (at most 100),
+// so 0 means the statement is clean.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/anomaly"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/card"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/discount"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/holiday"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/interchange"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/logging"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/masterdata"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/tax"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/internal/config"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Exit codes; 1 to maxErrorExit is the number of rejected records
+const (
+	exitOK       = 0
+	maxErrorExit = 100
+	exitError    = 101 // the command could not run (unreadable file, unreachable db_service, ...)
+	exitUsage    = 102
+)
+
+// command is one etcproc subcommand
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+// commands lists the subcommands in the order of the usage message
+var commands = []command{
+	{"validate", "check statements and report invalid records", runValidate},
+	{"preview", "show the first records of a statement as parsed and converted", runPreview},
+	{"convert", "convert a statement to normalized CSV, JSON Lines or Parquet", runConvert},
+	{"import", "process statements and save them to db_service", runImport},
+	{"export", "export records from the record store", runExport},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run dispatches to a subcommand and returns the exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	// The commands print their own results; pipeline logs go to stderr, errors only unless LOG_LEVEL is set
+	level, err := logging.ParseLevel(os.Getenv("LOG_LEVEL"))
+	if err != nil || os.Getenv("LOG_LEVEL") == "" {
+		level = slog.LevelError
+	}
+	slog.SetDefault(logging.New(stderr, level))
+
+	if len(args) == 0 {
+		usage(stderr)
+		return exitUsage
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdout, stderr)
+		}
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stdout)
+		return exitOK
+	}
+	fmt.Fprintf(stderr, "etcproc: unknown command %q\n", args[0])
+	usage(stderr)
+	return exitUsage
+}
+
+// usage prints the list of subcommands
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: etcproc <command> [flags] [args]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w, "\nRun \"etcproc <command> -h\" for the flags of a command.")
+}
+
+// newFlagSet creates the flag set of a subcommand with its usage line
+func newFlagSet(name, arguments string, stderr io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: etcproc %s %s\n", name, arguments)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseFlags parses the arguments of a subcommand; when it returns false the command exits with code
+func parseFlags(flags *flag.FlagSet, args []string) (code int, ok bool) {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+	return exitOK, true
+}
+
+// newService creates the processing service with the settings of a server config file (optional).
+// Settings that only concern the server (port, db_service address) are ignored.
+func newService(configPath string, dbClient handler.DBClient) (*handler.DataProcessorService, error) {
+	service := handler.NewDataProcessorService(dbClient)
+	if configPath == "" {
+		return service, nil
+	}
+
+	cfg, err := config.LoadFromFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	maskPolicy, err := card.ParseMaskPolicy(cfg.CardMaskPolicy)
+	if err != nil {
+		return nil, err
+	}
+	service.SetCardMaskPolicy(maskPolicy)
+
+	rounding, err := tax.ParseRounding(cfg.TaxRounding)
+	if err != nil {
+		return nil, err
+	}
+	service.SetTaxCalculator(tax.NewCalculator(rounding))
+
+	if cfg.MasterDataFile != "" {
+		registry, err := masterdata.LoadFile(cfg.MasterDataFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load master data: %w", err)
+		}
+		service.SetMasterData(registry)
+	}
+
+	if cfg.InterchangeDictionaryFile != "" {
+		dictionary, err := interchange.LoadDictionary(cfg.InterchangeDictionaryFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load interchange dictionary: %w", err)
+		}
+		service.SetInterchangeDictionary(dictionary)
+	}
+
+	if cfg.HolidayFile != "" {
+		calendar, err := holiday.LoadFile(cfg.HolidayFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load holiday file: %w", err)
+		}
+		service.SetHolidayCalendar(calendar)
+	}
+
+	if cfg.DiscountRulesFile != "" {
+		engine, err := discount.LoadFile(cfg.DiscountRulesFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load discount rules: %w", err)
+		}
+		service.SetDiscountEngine(engine)
+	} else if cfg.VerifyDiscounts {
+		service.SetDiscountEngine(discount.NewEngine(discount.DefaultRules(), nil))
+	}
+
+	if cfg.AnomalyRulesFile != "" {
+		detector, err := anomaly.LoadFile(cfg.AnomalyRulesFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load anomaly rules: %w", err)
+		}
+		service.SetAnomalyDetector(detector)
+	} else if cfg.DetectAnomalies {
+		service.SetAnomalyDetector(anomaly.NewDetector(anomaly.DefaultRules()...))
+	}
+	return service, nil
+}
+
+// errorExitCode maps a number of rejected records to an exit code
+func errorExitCode(count int) int {
+	if count > maxErrorExit {
+		return maxErrorExit
+	}
+	return count
+}
+
+// rejectedRecords counts the record errors that kept a record out (validation and conversion failures);
+// warnings such as duplicates, unknown cards or anomalies are not counted
+func rejectedRecords(recordErrors []*pb.RecordError) int {
+	count := 0
+	for _, e := range recordErrors {
+		switch e.Code {
+		case pb.ErrorCode_ERROR_CODE_PARSE, pb.ErrorCode_ERROR_CODE_VALIDATION, pb.ErrorCode_ERROR_CODE_CONVERSION, pb.ErrorCode_ERROR_CODE_PERSISTENCE:
+			count++
+		}
+	}
+	return count
+}
+
+// printRecordErrors prints structured errors one per line
+func printRecordErrors(w io.Writer, recordErrors []*pb.RecordError) {
+	for _, e := range recordErrors {
+		code := strings.TrimPrefix(e.Code.String(), "ERROR_CODE_")
+		switch {
+		case e.LineNumber > 0:
+			fmt.Fprintf(w, "  line %d: [%s] %s\n", e.LineNumber, code, e.Message)
+		case e.FilePath != "":
+			fmt.Fprintf(w, "  %s: [%s] %s\n", e.FilePath, code, e.Message)
+		default:
+			fmt.Fprintf(w, "  [%s] %s\n", code, e.Message)
+		}
+	}
+}
+
+// protoJSON converts a response to JSON with the field names of the proto definition
+func protoJSON(message proto.Message) (json.RawMessage, error) {
+	return protojson.MarshalOptions{UseProtoNames: true}.Marshal(message)
+}
+
+// writeJSON prints a value as indented JSON
+func writeJSON(w io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// fail prints an error and returns exitError
+func fail(stderr io.Writer, err error) int {
+	fmt.Fprintf(stderr, "etcproc: %v\n", err)
+	return exitError
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/card"
+)
+
+// runPreview implements "etcproc preview": it prints the first records of a statement as
+// PreviewCSV returns them and exits with the number of previewed records that failed conversion
+func runPreview(args []string, stdout, stderr io.Writer) int {
+	flags := newFlagSet("preview", "[flags] file.csv", stderr)
+	jsonOutput := flags.Bool("json", false, "Print the preview as JSON (including field mappings)")
+	limit := flags.Int("n", 10, "Number of records to show (at most 100)")
+	stitchTrips := flags.Bool("stitch", false, "Group the previewed records into trips")
+	configPath := flags.String("config", "", "Server config file (master data, interchanges, card mask policy, ...)")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	service, err := newService(*configPath, nil)
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	path := flags.Arg(0)
+	n := int32(*limit)
+	resp, err := service.PreviewCSV(context.Background(), &pb.PreviewCSVRequest{
+		CsvFilePath: &path,
+		Limit:       &n,
+		StitchTrips: stitchTrips,
+	})
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	failed := 0
+	for _, record := range resp.Records {
+		if record.ConversionError != "" {
+			failed++
+		}
+	}
+
+	if *jsonOutput {
+		result, err := protoJSON(resp)
+		if err != nil {
+			return fail(stderr, err)
+		}
+		if err := writeJSON(stdout, result); err != nil {
+			return fail(stderr, err)
+		}
+		return errorExitCode(failed)
+	}
+
+	fmt.Fprintf(stdout, "%s: %s format, %s, %d records\n", path, resp.Format, resp.Encoding, resp.TotalRecords)
+	table := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "LINE\tDATE\tENTRY\tEXIT\tCLASS\tAMOUNT\tCARD\tTRIP\t")
+	for _, record := range resp.Records {
+		parsed := record.Parsed
+		if record.ConversionError != "" {
+			fmt.Fprintf(table, "%d\t%s\t%s\t%s\t\t\t%s\t\t\n", record.LineNumber, parsed.GetExitDate(),
+				parsed.GetEntryIc(), parsed.GetExitIc(), card.MaskLast4.Mask(parsed.GetCardNumber()))
+			continue
+		}
+		converted := record.Converted
+		fmt.Fprintf(table, "%d\t%s\t%s\t%s\t%d\t%d\t%s\t%s\t\n", record.LineNumber, converted.Date,
+			converted.EntryIc, converted.ExitIc, converted.VehicleType, converted.Amount,
+			card.MaskLast4.Mask(converted.CardNumber), record.TripId)
+	}
+	table.Flush()
+
+	for _, record := range resp.Records {
+		if record.ConversionError != "" {
+			fmt.Fprintf(stdout, "  line %d: conversion failed: %s\n", record.LineNumber, record.ConversionError)
+		}
+	}
+	return errorExitCode(failed)
+}
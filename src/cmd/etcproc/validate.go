@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+)
+
+// validateResult is the JSON output of one validated file
+type validateResult struct {
+	File     string          `json:"file"`
+	Encoding string          `json:"encoding"`
+	Result   json.RawMessage `json:"result"`
+}
+
+// runValidate implements "etcproc validate": it runs ValidateCSVData on each file and
+// exits with the total number of invalid records
+func runValidate(args []string, stdout, stderr io.Writer) int {
+	flags := newFlagSet("validate", "[flags] file.csv...", stderr)
+	jsonOutput := flags.Bool("json", false, "Print the results as JSON")
+	accountID := flags.String("account", "", "Account ID to validate the statements for")
+	configPath := flags.String("config", "", "Server config file (master data, interchanges, card mask policy, ...)")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	service, err := newService(*configPath, nil)
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	etcParser := parser.NewETCCSVParser()
+	var results []validateResult
+	errorCount := 0
+	for _, path := range flags.Args() {
+		data, info, err := etcParser.ReadFile(path)
+		if err != nil {
+			return fail(stderr, err)
+		}
+		resp, err := service.ValidateCSVData(context.Background(), &pb.ValidateCSVDataRequest{CsvData: data, AccountId: accountID})
+		if err != nil {
+			return fail(stderr, fmt.Errorf("%s: %w", path, err))
+		}
+		errorCount += len(resp.Errors)
+
+		if *jsonOutput {
+			result, err := protoJSON(resp)
+			if err != nil {
+				return fail(stderr, err)
+			}
+			results = append(results, validateResult{File: path, Encoding: info.Encoding, Result: result})
+			continue
+		}
+		fmt.Fprintf(stdout, "%s: %d records (%s), %d errors, %d duplicates\n",
+			path, resp.TotalRecords, info.Encoding, len(resp.Errors), resp.DuplicateCount)
+		for _, e := range resp.Errors {
+			if e.Field != "" {
+				fmt.Fprintf(stdout, "  line %d: %s: %s\n", e.LineNumber, e.Field, e.Message)
+			} else {
+				fmt.Fprintf(stdout, "  line %d: %s\n", e.LineNumber, e.Message)
+			}
+		}
+	}
+
+	if *jsonOutput {
+		if err := writeJSON(stdout, map[string]interface{}{"files": results, "error_count": errorCount}); err != nil {
+			return fail(stderr, err)
+		}
+	}
+	return errorExitCode(errorCount)
+}
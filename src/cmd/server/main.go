@@ -1,18 +1,38 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
 	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/anomaly"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/card"
 	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/db"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/discount"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/export"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/holiday"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/idempotency"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/interchange"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/journal"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/logging"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/masterdata"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/metrics"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/tax"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/tracing"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/usage"
 	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/internal/config"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 )
@@ -29,9 +49,17 @@ func main() {
 	// Load configuration
 	cfg, err := loadConfig(*configFile)
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		fatal("Failed to load config", err)
 	}
 
+	// Structured JSON logs; the standard log package (and grpc) write through the same handler
+	level, err := logging.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		fatal("Invalid config", err)
+	}
+	logger := logging.New(os.Stdout, level)
+	slog.SetDefault(logger)
+
 	// Override with command line flags if provided
 	if *port != 50051 {
 		cfg.Port = *port
@@ -43,31 +71,182 @@ func main() {
 	// Create listener
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Port))
 	if err != nil {
-		log.Fatalf("Failed to listen: %v", err)
+		fatal("Failed to listen", err)
+	}
+
+	// Prometheus metrics on a separate HTTP listener; a nil collector records nothing
+	var collector *metrics.Metrics
+	var metricsServer *http.Server
+	if cfg.MetricsAddr != "" {
+		collector = metrics.New()
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", collector.Handler())
+		metricsServer = &http.Server{Addr: cfg.MetricsAddr, Handler: mux}
+		go func() {
+			slog.Info("Serving metrics", "address", cfg.MetricsAddr, "path", "/metrics")
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				fatal("Failed to serve metrics", err)
+			}
+		}()
+	}
+
+	// OpenTelemetry tracing; incoming trace context is passed on to db_service even when nothing is exported
+	exporter, err := tracing.ParseExporter(cfg.TraceExporter)
+	if err != nil {
+		fatal("Invalid config", err)
+	}
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter: exporter,
+		Endpoint: cfg.TraceEndpoint,
+		File:     cfg.TraceFile,
+	})
+	if err != nil {
+		fatal("Failed to set up tracing", err)
+	}
+	if exporter != tracing.ExporterNone {
+		slog.Info("Tracing enabled", "exporter", string(exporter))
 	}
 
 	// Create gRPC server
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(
+			logging.UnaryServerInterceptor(logger),
+			collector.UnaryServerInterceptor(),
+		),
+	)
 
 	// Create DB client
 	var dbClient handler.DBClient
 	if cfg.DBServiceAddr != "" {
-		log.Printf("Connecting to db_service at: %s", cfg.DBServiceAddr)
+		slog.Info("Connecting to db_service", "address", cfg.DBServiceAddr)
 		client, err := db.NewETCMeisaiClient(cfg.DBServiceAddr)
 		if err != nil {
-			log.Printf("Warning: Failed to connect to db_service: %v", err)
-			log.Printf("Continuing without database integration...")
+			slog.Warn("Failed to connect to db_service; continuing without database integration", "error", err)
 		} else {
+			client.SetMetrics(collector)
 			dbClient = client
-			log.Printf("Successfully connected to db_service")
+			slog.Info("Successfully connected to db_service")
 			defer client.Close()
 		}
 	} else {
-		log.Printf("No db_service address configured - running without database integration")
+		slog.Info("No db_service address configured - running without database integration")
 	}
 
 	// Register service
 	service := handler.NewDataProcessorService(dbClient)
+	service.SetLogger(logger)
+	service.SetMetrics(collector)
+	service.SetIdempotencyStore(idempotency.NewMemoryStore(time.Duration(cfg.IdempotencyTTLSeconds) * time.Second))
+	service.SetTripStitchOptions(parser.StitchOptions{
+		MaxGap:        time.Duration(cfg.TripMaxGapMinutes) * time.Minute,
+		IgnoreICChain: cfg.TripIgnoreICChain,
+	})
+
+	maskPolicy, err := card.ParseMaskPolicy(cfg.CardMaskPolicy)
+	if err != nil {
+		fatal("Invalid config", err)
+	}
+	service.SetCardMaskPolicy(maskPolicy)
+
+	rounding, err := tax.ParseRounding(cfg.TaxRounding)
+	if err != nil {
+		fatal("Invalid config", err)
+	}
+	service.SetTaxCalculator(tax.NewCalculator(rounding))
+
+	if cfg.MasterDataFile != "" {
+		registry, err := masterdata.LoadFile(cfg.MasterDataFile)
+		if err != nil {
+			fatal("Failed to load master data", err)
+		}
+		service.SetMasterData(registry)
+		slog.Info("Loaded card assignments", "count", registry.Len(), "file", cfg.MasterDataFile)
+	}
+
+	if cfg.InterchangeDictionaryFile != "" {
+		dictionary, err := interchange.LoadDictionary(cfg.InterchangeDictionaryFile)
+		if err != nil {
+			fatal("Failed to load interchange dictionary", err)
+		}
+		service.SetInterchangeDictionary(dictionary)
+		slog.Info("Loaded interchange names", "count", dictionary.Len(), "file", cfg.InterchangeDictionaryFile)
+	}
+
+	if cfg.HolidayFile != "" {
+		calendar, err := holiday.LoadFile(cfg.HolidayFile)
+		if err != nil {
+			fatal("Failed to load holiday file", err)
+		}
+		service.SetHolidayCalendar(calendar)
+		slog.Info("Loaded extra holidays", "file", cfg.HolidayFile)
+	}
+
+	if cfg.DiscountRulesFile != "" {
+		engine, err := discount.LoadFile(cfg.DiscountRulesFile)
+		if err != nil {
+			fatal("Failed to load discount rules", err)
+		}
+		service.SetDiscountEngine(engine)
+		slog.Info("Discount verification enabled", "rules_file", cfg.DiscountRulesFile)
+	} else if cfg.VerifyDiscounts {
+		service.SetDiscountEngine(discount.NewEngine(discount.DefaultRules(), nil))
+		slog.Info("Discount verification enabled with default rules")
+	}
+
+	if cfg.JournalSettingsFile != "" {
+		settings, err := journal.LoadSettings(cfg.JournalSettingsFile)
+		if err != nil {
+			fatal("Failed to load journal settings", err)
+		}
+		service.SetJournalSettings(settings)
+		slog.Info("Loaded journal settings", "file", cfg.JournalSettingsFile)
+	}
+
+	if cfg.RecordStoreFile != "" {
+		store, err := export.OpenFileStore(cfg.RecordStoreFile)
+		if err != nil {
+			fatal("Failed to open record store", err)
+		}
+		service.SetRecordStore(store)
+		slog.Info("Keeping imported records for export", "file", cfg.RecordStoreFile)
+	}
+
+	if cfg.UsageStoreFile != "" {
+		store, err := usage.OpenFileStore(cfg.UsageStoreFile, cfg.UsageRetentionDays)
+		if err != nil {
+			fatal("Failed to open usage store", err)
+		}
+		service.SetUsageStore(store)
+		slog.Info("Keeping usage in a file", "file", cfg.UsageStoreFile, "retention_days", cfg.UsageRetentionDays)
+	} else {
+		store := usage.NewMemoryStore()
+		store.SetRetention(cfg.UsageRetentionDays)
+		service.SetUsageStore(store)
+		slog.Info("Keeping usage in memory", "retention_days", cfg.UsageRetentionDays)
+	}
+
+	// The usage store is set first so the anomaly rules learn the amount history of earlier imports
+	if cfg.AnomalyRulesFile != "" {
+		detector, err := anomaly.LoadFile(cfg.AnomalyRulesFile)
+		if err != nil {
+			fatal("Failed to load anomaly rules", err)
+		}
+		service.SetAnomalyDetector(detector)
+		slog.Info("Anomaly detection enabled", "rules_file", cfg.AnomalyRulesFile)
+	} else if cfg.DetectAnomalies {
+		service.SetAnomalyDetector(anomaly.NewDetector(anomaly.DefaultRules()...))
+		slog.Info("Anomaly detection enabled with default rules")
+	}
+
+	if cfg.AnomalyStoreFile != "" {
+		store, err := anomaly.OpenFileStore(cfg.AnomalyStoreFile)
+		if err != nil {
+			fatal("Failed to open anomaly store", err)
+		}
+		service.SetAnomalyStore(store)
+		slog.Info("Keeping anomaly findings in a file", "file", cfg.AnomalyStoreFile)
+	}
 	pb.RegisterDataProcessorServiceServer(grpcServer, service)
 
 	// Register reflection service for grpcurl
@@ -75,9 +254,9 @@ func main() {
 
 	// Start server in goroutine
 	go func() {
-		log.Printf("Starting gRPC server on port %d...", cfg.Port)
+		slog.Info("Starting gRPC server", "port", cfg.Port, "log_level", level.String())
 		if err := grpcServer.Serve(lis); err != nil {
-			log.Fatalf("Failed to serve: %v", err)
+			fatal("Failed to serve", err)
 		}
 	}()
 
@@ -86,9 +265,23 @@ func main() {
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 	<-sigCh
 
-	log.Println("Shutting down server...")
+	slog.Info("Shutting down server")
 	grpcServer.GracefulStop()
-	log.Println("Server stopped")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if metricsServer != nil {
+		metricsServer.Shutdown(ctx)
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Warn("Failed to flush traces", "error", err)
+	}
+	slog.Info("Server stopped")
+}
+
+// fatal logs an error that prevents the server from running and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
 
 func loadConfig(configFile string) (*config.Config, error) {
@@ -129,5 +322,105 @@ func loadConfig(configFile string) (*config.Config, error) {
 		cfg.DBServiceAddr = dbAddr
 	}
 
+	if path := os.Getenv("MASTER_DATA_FILE"); path != "" {
+		cfg.MasterDataFile = path
+	}
+
+	if path := os.Getenv("INTERCHANGE_DICTIONARY_FILE"); path != "" {
+		cfg.InterchangeDictionaryFile = path
+	}
+
+	if verify := os.Getenv("VERIFY_DISCOUNTS"); verify == "true" || verify == "1" {
+		cfg.VerifyDiscounts = true
+	}
+
+	if path := os.Getenv("DISCOUNT_RULES_FILE"); path != "" {
+		cfg.DiscountRulesFile = path
+	}
+
+	if detect := os.Getenv("DETECT_ANOMALIES"); detect == "true" || detect == "1" {
+		cfg.DetectAnomalies = true
+	}
+
+	if path := os.Getenv("ANOMALY_RULES_FILE"); path != "" {
+		cfg.AnomalyRulesFile = path
+	}
+
+	if path := os.Getenv("ANOMALY_STORE_FILE"); path != "" {
+		cfg.AnomalyStoreFile = path
+	}
+
+	if path := os.Getenv("HOLIDAY_FILE"); path != "" {
+		cfg.HolidayFile = path
+	}
+
+	if path := os.Getenv("JOURNAL_SETTINGS_FILE"); path != "" {
+		cfg.JournalSettingsFile = path
+	}
+
+	if path := os.Getenv("RECORD_STORE_FILE"); path != "" {
+		cfg.RecordStoreFile = path
+	}
+
+	if path := os.Getenv("USAGE_STORE_FILE"); path != "" {
+		cfg.UsageStoreFile = path
+	}
+
+	if retention := os.Getenv("USAGE_RETENTION_DAYS"); retention != "" {
+		var days int
+		fmt.Sscanf(retention, "%d", &days)
+		if days > 0 {
+			cfg.UsageRetentionDays = days
+		}
+	}
+
+	if addr := os.Getenv("METRICS_ADDR"); addr != "" {
+		cfg.MetricsAddr = addr
+	}
+
+	if exporter := os.Getenv("TRACE_EXPORTER"); exporter != "" {
+		cfg.TraceExporter = exporter
+	}
+
+	if endpoint := os.Getenv("TRACE_ENDPOINT"); endpoint != "" {
+		cfg.TraceEndpoint = endpoint
+	}
+
+	if path := os.Getenv("TRACE_FILE"); path != "" {
+		cfg.TraceFile = path
+	}
+
+	if level := os.Getenv("LOG_LEVEL"); level != "" {
+		cfg.LogLevel = level
+	}
+
+	if rounding := os.Getenv("TAX_ROUNDING"); rounding != "" {
+		cfg.TaxRounding = rounding
+	}
+
+	if policy := os.Getenv("CARD_MASK_POLICY"); policy != "" {
+		cfg.CardMaskPolicy = policy
+	}
+
+	if ttl := os.Getenv("IDEMPOTENCY_TTL_SECONDS"); ttl != "" {
+		var seconds int
+		fmt.Sscanf(ttl, "%d", &seconds)
+		if seconds > 0 {
+			cfg.IdempotencyTTLSeconds = seconds
+		}
+	}
+
+	if gap := os.Getenv("TRIP_MAX_GAP_MINUTES"); gap != "" {
+		var minutes int
+		fmt.Sscanf(gap, "%d", &minutes)
+		if minutes > 0 {
+			cfg.TripMaxGapMinutes = minutes
+		}
+	}
+
+	if ignore := os.Getenv("TRIP_IGNORE_IC_CHAIN"); ignore == "true" || ignore == "1" {
+		cfg.TripIgnoreICChain = true
+	}
+
 	return cfg, nil
 }
\ No newline at end of file
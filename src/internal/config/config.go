@@ -5,16 +5,42 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/card"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/logging"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/tax"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/tracing"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/usage"
 	"gopkg.in/yaml.v3"
 )
 
 // Config holds the application configuration
 type Config struct {
-	Port          int    `json:"port" yaml:"port"`
-	DBServiceAddr string `json:"db_service_addr" yaml:"db_service_addr"`
-	MaxBatchSize  int    `json:"max_batch_size" yaml:"max_batch_size"`
-	ValidateData  bool   `json:"validate_data" yaml:"validate_data"`
-	LogLevel      string `json:"log_level" yaml:"log_level"`
+	Port                      int    `json:"port" yaml:"port"`
+	DBServiceAddr             string `json:"db_service_addr" yaml:"db_service_addr"`
+	MaxBatchSize              int    `json:"max_batch_size" yaml:"max_batch_size"`
+	ValidateData              bool   `json:"validate_data" yaml:"validate_data"`
+	LogLevel                  string `json:"log_level" yaml:"log_level"`
+	IdempotencyTTLSeconds     int    `json:"idempotency_ttl_seconds" yaml:"idempotency_ttl_seconds"`
+	CardMaskPolicy            string `json:"card_mask_policy" yaml:"card_mask_policy"`
+	MasterDataFile            string `json:"master_data_file" yaml:"master_data_file"`
+	InterchangeDictionaryFile string `json:"interchange_dictionary_file" yaml:"interchange_dictionary_file"`
+	TripMaxGapMinutes         int    `json:"trip_max_gap_minutes" yaml:"trip_max_gap_minutes"`
+	TripIgnoreICChain         bool   `json:"trip_ignore_ic_chain" yaml:"trip_ignore_ic_chain"`
+	VerifyDiscounts           bool   `json:"verify_discounts" yaml:"verify_discounts"`
+	DiscountRulesFile         string `json:"discount_rules_file" yaml:"discount_rules_file"`
+	DetectAnomalies           bool   `json:"detect_anomalies" yaml:"detect_anomalies"`
+	AnomalyRulesFile          string `json:"anomaly_rules_file" yaml:"anomaly_rules_file"`
+	AnomalyStoreFile          string `json:"anomaly_store_file" yaml:"anomaly_store_file"`
+	HolidayFile               string `json:"holiday_file" yaml:"holiday_file"`
+	JournalSettingsFile       string `json:"journal_settings_file" yaml:"journal_settings_file"`
+	TaxRounding               string `json:"tax_rounding" yaml:"tax_rounding"`
+	RecordStoreFile           string `json:"record_store_file" yaml:"record_store_file"`
+	UsageStoreFile            string `json:"usage_store_file" yaml:"usage_store_file"`
+	UsageRetentionDays        int    `json:"usage_retention_days" yaml:"usage_retention_days"`
+	MetricsAddr               string `json:"metrics_addr" yaml:"metrics_addr"`
+	TraceExporter             string `json:"trace_exporter" yaml:"trace_exporter"`
+	TraceEndpoint             string `json:"trace_endpoint" yaml:"trace_endpoint"`
+	TraceFile                 string `json:"trace_file" yaml:"trace_file"`
 }
 
 // LoadFromFile loads configuration from a file
@@ -49,6 +75,38 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid max_batch_size: %d", c.MaxBatchSize)
 	}
 
+	if c.IdempotencyTTLSeconds < 0 {
+		return fmt.Errorf("invalid idempotency_ttl_seconds: %d", c.IdempotencyTTLSeconds)
+	}
+
+	if c.TripMaxGapMinutes < 0 {
+		return fmt.Errorf("invalid trip_max_gap_minutes: %d", c.TripMaxGapMinutes)
+	}
+
+	if c.UsageRetentionDays < 0 {
+		return fmt.Errorf("invalid usage_retention_days: %d", c.UsageRetentionDays)
+	}
+
+	if _, err := card.ParseMaskPolicy(c.CardMaskPolicy); err != nil {
+		return err
+	}
+
+	if _, err := tax.ParseRounding(c.TaxRounding); err != nil {
+		return err
+	}
+
+	if _, err := logging.ParseLevel(c.LogLevel); err != nil {
+		return err
+	}
+
+	exporter, err := tracing.ParseExporter(c.TraceExporter)
+	if err != nil {
+		return err
+	}
+	if exporter == tracing.ExporterFile && c.TraceFile == "" {
+		return fmt.Errorf("trace_file is required when trace_exporter is file")
+	}
+
 	return nil
 }
 
@@ -65,4 +123,24 @@ func (c *Config) SetDefaults() {
 	if c.LogLevel == "" {
 		c.LogLevel = "info"
 	}
+
+	if c.IdempotencyTTLSeconds == 0 {
+		c.IdempotencyTTLSeconds = 86400
+	}
+
+	if c.TripMaxGapMinutes == 0 {
+		c.TripMaxGapMinutes = 30
+	}
+
+	if c.CardMaskPolicy == "" {
+		c.CardMaskPolicy = string(card.MaskLast4)
+	}
+
+	if c.TaxRounding == "" {
+		c.TaxRounding = string(tax.DefaultRound)
+	}
+
+	if c.UsageRetentionDays == 0 {
+		c.UsageRetentionDays = usage.DefaultRetentionDays
+	}
 }
\ No newline at end of file
@@ -0,0 +1,132 @@
+// Package anomaly flags imported trips that point to card misuse or data errors, such as trips at
+// impossible times, one card in two places at once or amounts far above the usual toll.
+package anomaly
+
+import (
+	"sort"
+	"time"
+
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+)
+
+// Trip is the part of a statement row the anomaly rules look at
+type Trip struct {
+	Index           int                 `json:"index"`       // 0-based position of the row in its batch
+	LineNumber      int                 `json:"line_number"` // CSV line of the row
+	Row             string              `json:"row"`         // row identity independent of account and amount (parser.TripKey)
+	Date            time.Time           `json:"date"`
+	CardNumber      string              `json:"card_number"`
+	VehicleNumber   string              `json:"vehicle_number"`
+	VehicleID       string              `json:"vehicle_id"`       // vehicle ID from the master data
+	VehicleClass    parser.VehicleClass `json:"vehicle_class"`    // 車種 on the statement
+	RegisteredClass parser.VehicleClass `json:"registered_class"` // class of the registered vehicle; unknown skips the class check
+	Entry           time.Time           `json:"entry"`            // zero when the row has no valid entry time
+	Exit            time.Time           `json:"exit"`             // zero when the row has no valid exit time
+	EntryIC         string              `json:"entry_ic"`
+	ExitIC          string              `json:"exit_ic"`
+	Amount          int                 `json:"amount"` // charged amount, negative for reversals
+	Reversal        bool                `json:"reversal"`
+}
+
+// NewTrip reads the trip of a parsed statement row and its converted record.
+// Times that cannot be parsed are left zero and skipped by the time-based rules.
+func NewTrip(index int, record parser.ActualETCRecord, simpleRecord parser.ETCRecord) Trip {
+	trip := Trip{
+		Index:         index,
+		LineNumber:    record.LineNumber,
+		Row:           parser.TripKey(record),
+		Date:          simpleRecord.Date,
+		CardNumber:    simpleRecord.CardNumber,
+		VehicleNumber: record.VehicleNumber,
+		VehicleClass:  record.VehicleClass,
+		EntryIC:       simpleRecord.EntryIC,
+		ExitIC:        simpleRecord.ExitIC,
+		Amount:        simpleRecord.Amount,
+		Reversal:      simpleRecord.IsReversal(),
+	}
+	if entry, err := parser.ParseDateTime(record.EntryDate, record.EntryTime); err == nil {
+		trip.Entry = entry
+	}
+	if exit, err := parser.ParseDateTime(record.ExitDate, record.ExitTime); err == nil {
+		trip.Exit = exit
+	}
+	return trip
+}
+
+// timed reports whether both entry and exit times are known
+func (t Trip) timed() bool {
+	return !t.Entry.IsZero() && !t.Exit.IsZero()
+}
+
+// Finding is an anomaly a rule found in a trip
+type Finding struct {
+	Rule        string `json:"rule"`
+	Trip        Trip   `json:"trip"`
+	Message     string `json:"message"`
+	RelatedLine int    `json:"related_line"` // CSV line of the other trip involved, if any
+	AccountID   string `json:"account_id"`   // account the trip was imported for; set by the caller
+	FilePath    string `json:"file_path"`    // file the trip was read from; set by the caller
+}
+
+// Rule inspects a batch of trips and reports anomalies.
+// Rules see the whole batch so they can compare trips with each other.
+type Rule interface {
+	// Name identifies the rule in findings
+	Name() string
+	// Check returns the anomalies found in trips
+	Check(trips []Trip) []Finding
+}
+
+// Learner is implemented by rules that build up history from imported trips
+type Learner interface {
+	// Learn records trips that were imported
+	Learn(trips []Trip)
+}
+
+// Detector runs a set of rules over imported trips
+type Detector struct {
+	rules []Rule
+}
+
+// NewDetector creates a detector running the given rules
+func NewDetector(rules ...Rule) *Detector {
+	return &Detector{rules: rules}
+}
+
+// Add adds a rule to the detector
+func (d *Detector) Add(rule Rule) {
+	d.rules = append(d.rules, rule)
+}
+
+// Rules returns the names of the detector's rules
+func (d *Detector) Rules() []string {
+	names := make([]string, len(d.rules))
+	for i, rule := range d.rules {
+		names[i] = rule.Name()
+	}
+	return names
+}
+
+// Check runs every rule over trips and returns the findings ordered by row and rule
+func (d *Detector) Check(trips []Trip) []Finding {
+	var findings []Finding
+	for _, rule := range d.rules {
+		findings = append(findings, rule.Check(trips)...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Trip.Index != findings[j].Trip.Index {
+			return findings[i].Trip.Index < findings[j].Trip.Index
+		}
+		return findings[i].Rule < findings[j].Rule
+	})
+	return findings
+}
+
+// Learn passes imported trips to the rules that keep history
+func (d *Detector) Learn(trips []Trip) {
+	for _, rule := range d.rules {
+		if learner, ok := rule.(Learner); ok {
+			learner.Learn(trips)
+		}
+	}
+}
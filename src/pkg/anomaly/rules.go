@@ -0,0 +1,325 @@
+package anomaly
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/card"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/interchange"
+	"gopkg.in/yaml.v3"
+)
+
+// Built-in rule names
+const (
+	RuleImpossibleTime = "impossible_time"        // exit before entry, implausibly long or in the future
+	RuleCardOverlap    = "card_overlap"           // the same card on two trips at overlapping times
+	RuleVehicleClass   = "vehicle_class_mismatch" // 車種 differs from the registered vehicle
+	RuleAmountOutlier  = "amount_outlier"         // amount far above the average for the IC pair
+)
+
+// Defaults of the built-in rules
+const (
+	DefaultMaxTripDuration = 24 * time.Hour
+	DefaultAmountFactor    = 3.0
+	DefaultMinSamples      = 5
+)
+
+// jst is the time zone of statement times; a fixed offset avoids depending on tzdata
+var jst = time.FixedZone("JST", 9*60*60)
+
+// statementClock returns t as a statement time: the JST wall clock in UTC, the way
+// parser.ParseDateTime reads the CSV
+func statementClock(t time.Time) time.Time {
+	local := t.In(jst)
+	return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), time.UTC)
+}
+
+// ImpossibleTime flags trips whose exit is before their entry, that last longer than
+// MaxDuration, or that end in the future
+type ImpossibleTime struct {
+	MaxDuration time.Duration    // zero means DefaultMaxTripDuration
+	Now         func() time.Time // nil means time.Now; compared on the JST wall clock
+}
+
+// Name implements Rule
+func (r ImpossibleTime) Name() string {
+	return RuleImpossibleTime
+}
+
+// Check implements Rule
+func (r ImpossibleTime) Check(trips []Trip) []Finding {
+	maxDuration := r.MaxDuration
+	if maxDuration <= 0 {
+		maxDuration = DefaultMaxTripDuration
+	}
+	now := time.Now()
+	if r.Now != nil {
+		now = r.Now()
+	}
+	now = statementClock(now)
+
+	var findings []Finding
+	for _, trip := range trips {
+		message := ""
+		switch {
+		case trip.timed() && trip.Exit.Before(trip.Entry):
+			message = fmt.Sprintf("exit %s is before entry %s", trip.Exit.Format("2006-01-02 15:04"), trip.Entry.Format("2006-01-02 15:04"))
+		case trip.timed() && trip.Exit.Sub(trip.Entry) > maxDuration:
+			message = fmt.Sprintf("trip took %s, longer than %s", trip.Exit.Sub(trip.Entry), maxDuration)
+		case trip.Exit.After(now):
+			message = fmt.Sprintf("exit %s is in the future", trip.Exit.Format("2006-01-02 15:04"))
+		}
+		if message != "" {
+			findings = append(findings, Finding{Rule: RuleImpossibleTime, Trip: trip, Message: message})
+		}
+	}
+	return findings
+}
+
+// CardOverlap flags a trip that starts before an earlier trip of the same card has ended.
+// Identical rows are left to duplicate detection, and reversals are ignored.
+type CardOverlap struct{}
+
+// Name implements Rule
+func (CardOverlap) Name() string {
+	return RuleCardOverlap
+}
+
+// Check implements Rule
+func (CardOverlap) Check(trips []Trip) []Finding {
+	byCard := make(map[string][]Trip)
+	var cards []string
+	for _, trip := range trips {
+		if trip.Reversal || !trip.timed() || trip.Exit.Before(trip.Entry) {
+			continue
+		}
+		number := card.Normalize(trip.CardNumber)
+		if _, ok := byCard[number]; !ok {
+			cards = append(cards, number)
+		}
+		byCard[number] = append(byCard[number], trip)
+	}
+
+	var findings []Finding
+	for _, number := range cards {
+		cardTrips := byCard[number]
+		sort.SliceStable(cardTrips, func(i, j int) bool {
+			return cardTrips[i].Entry.Before(cardTrips[j].Entry)
+		})
+
+		// latest is the trip seen so far that ends last
+		latest := cardTrips[0]
+		for _, trip := range cardTrips[1:] {
+			if trip.Entry.Before(latest.Exit) && !sameJourney(trip, latest) {
+				findings = append(findings, Finding{
+					Rule: RuleCardOverlap,
+					Trip: trip,
+					Message: fmt.Sprintf("%s→%s from %s overlaps %s→%s until %s", trip.EntryIC, trip.ExitIC,
+						trip.Entry.Format("15:04"), latest.EntryIC, latest.ExitIC, latest.Exit.Format("15:04")),
+					RelatedLine: latest.LineNumber,
+				})
+			}
+			if trip.Exit.After(latest.Exit) {
+				latest = trip
+			}
+		}
+	}
+	return findings
+}
+
+// sameJourney reports whether two rows describe the same journey
+func sameJourney(a, b Trip) bool {
+	return a.Entry.Equal(b.Entry) && a.Exit.Equal(b.Exit) &&
+		interchange.Key(a.EntryIC) == interchange.Key(b.EntryIC) && interchange.Key(a.ExitIC) == interchange.Key(b.ExitIC)
+}
+
+// VehicleClassMismatch flags trips charged at a different 車種 than the registered vehicle's
+type VehicleClassMismatch struct{}
+
+// Name implements Rule
+func (VehicleClassMismatch) Name() string {
+	return RuleVehicleClass
+}
+
+// Check implements Rule
+func (VehicleClassMismatch) Check(trips []Trip) []Finding {
+	var findings []Finding
+	for _, trip := range trips {
+		if trip.Reversal || !trip.RegisteredClass.IsValid() || !trip.VehicleClass.IsValid() || trip.VehicleClass == trip.RegisteredClass {
+			continue
+		}
+		vehicle := trip.VehicleID
+		if vehicle == "" {
+			vehicle = trip.VehicleNumber
+		}
+		findings = append(findings, Finding{
+			Rule: RuleVehicleClass,
+			Trip: trip,
+			Message: fmt.Sprintf("charged as %s but vehicle %s is registered as %s",
+				trip.VehicleClass, vehicle, trip.RegisteredClass),
+		})
+	}
+	return findings
+}
+
+// AmountOutlier flags trips whose amount is more than Factor times the average of earlier
+// trips between the same ICs in the same vehicle class. The averages come from Learn, so a
+// route is only checked once it has MinSamples imported trips.
+type AmountOutlier struct {
+	Factor     float64
+	MinSamples int
+
+	mu     sync.RWMutex
+	routes map[string]*routeAmounts
+}
+
+// routeAmounts is the amount history of one IC pair and vehicle class
+type routeAmounts struct {
+	count int
+	total int64
+}
+
+// NewAmountOutlier creates the rule with an empty history; non-positive values use the defaults
+func NewAmountOutlier(factor float64, minSamples int) *AmountOutlier {
+	if factor <= 0 {
+		factor = DefaultAmountFactor
+	}
+	if minSamples <= 0 {
+		minSamples = DefaultMinSamples
+	}
+	return &AmountOutlier{Factor: factor, MinSamples: minSamples, routes: make(map[string]*routeAmounts)}
+}
+
+// Name implements Rule
+func (r *AmountOutlier) Name() string {
+	return RuleAmountOutlier
+}
+
+// Check implements Rule
+func (r *AmountOutlier) Check(trips []Trip) []Finding {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var findings []Finding
+	for _, trip := range trips {
+		key, ok := routeKey(trip)
+		if !ok {
+			continue
+		}
+		history := r.routes[key]
+		if history == nil || history.count < r.MinSamples {
+			continue
+		}
+		average := float64(history.total) / float64(history.count)
+		if float64(trip.Amount) > r.Factor*average {
+			findings = append(findings, Finding{
+				Rule: RuleAmountOutlier,
+				Trip: trip,
+				Message: fmt.Sprintf("%d yen is %.1f times the average %.0f yen for %s→%s (%d trips)",
+					trip.Amount, float64(trip.Amount)/average, average, trip.EntryIC, trip.ExitIC, history.count),
+			})
+		}
+	}
+	return findings
+}
+
+// Learn implements Learner
+func (r *AmountOutlier) Learn(trips []Trip) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, trip := range trips {
+		key, ok := routeKey(trip)
+		if !ok {
+			continue
+		}
+		history := r.routes[key]
+		if history == nil {
+			history = &routeAmounts{}
+			r.routes[key] = history
+		}
+		history.count++
+		history.total += int64(trip.Amount)
+	}
+}
+
+// routeKey identifies the IC pair and vehicle class of a regular trip
+func routeKey(trip Trip) (string, bool) {
+	if trip.Reversal || trip.Amount <= 0 || trip.EntryIC == "" || trip.ExitIC == "" {
+		return "", false
+	}
+	return fmt.Sprintf("%s\x00%s\x00%d", interchange.Key(trip.EntryIC), interchange.Key(trip.ExitIC), trip.VehicleClass), true
+}
+
+// DefaultRules are the built-in rules with their default settings
+func DefaultRules() []Rule {
+	return []Rule{
+		ImpossibleTime{},
+		CardOverlap{},
+		VehicleClassMismatch{},
+		NewAmountOutlier(DefaultAmountFactor, DefaultMinSamples),
+	}
+}
+
+// Config is the layout of anomaly rule files
+type Config struct {
+	Rules        []string `yaml:"rules"`          // built-in rules to run; empty runs all of them
+	MaxTripHours int      `yaml:"max_trip_hours"` // zero means DefaultMaxTripDuration
+	AmountFactor float64  `yaml:"amount_factor"`  // zero means DefaultAmountFactor
+	MinSamples   int      `yaml:"min_samples"`    // zero means DefaultMinSamples
+}
+
+// LoadFile loads a detector from a YAML rule file
+func LoadFile(path string) (*Detector, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read anomaly rules: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse anomaly rules: %w", err)
+	}
+	return NewDetectorFromConfig(cfg)
+}
+
+// NewDetectorFromConfig validates a rule configuration and creates a detector
+func NewDetectorFromConfig(cfg Config) (*Detector, error) {
+	if cfg.MaxTripHours < 0 {
+		return nil, fmt.Errorf("invalid max_trip_hours: %d", cfg.MaxTripHours)
+	}
+	if cfg.AmountFactor < 0 || (cfg.AmountFactor > 0 && cfg.AmountFactor <= 1) {
+		return nil, fmt.Errorf("amount_factor must be greater than 1, got %v", cfg.AmountFactor)
+	}
+	if cfg.MinSamples < 0 {
+		return nil, fmt.Errorf("invalid min_samples: %d", cfg.MinSamples)
+	}
+
+	builtin := map[string]Rule{
+		RuleImpossibleTime: ImpossibleTime{MaxDuration: time.Duration(cfg.MaxTripHours) * time.Hour},
+		RuleCardOverlap:    CardOverlap{},
+		RuleVehicleClass:   VehicleClassMismatch{},
+		RuleAmountOutlier:  NewAmountOutlier(cfg.AmountFactor, cfg.MinSamples),
+	}
+	names := cfg.Rules
+	if len(names) == 0 {
+		names = []string{RuleImpossibleTime, RuleCardOverlap, RuleVehicleClass, RuleAmountOutlier}
+	}
+
+	detector := NewDetector()
+	seen := make(map[string]bool)
+	for _, name := range names {
+		rule, ok := builtin[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown anomaly rule %q", name)
+		}
+		if !seen[name] {
+			seen[name] = true
+			detector.Add(rule)
+		}
+	}
+	return detector, nil
+}
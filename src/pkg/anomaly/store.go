@@ -0,0 +1,206 @@
+package anomaly
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/card"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/masterdata"
+)
+
+// Filter selects the findings to list
+type Filter struct {
+	From       time.Time // first trip day, inclusive; zero means unbounded
+	To         time.Time // last trip day, inclusive; zero means unbounded
+	AccountID  string    // empty means all accounts
+	CardNumber string    // empty means all cards; masked numbers match by suffix
+	Rule       string    // empty means all rules
+}
+
+// matches reports whether a finding passes the filter
+func (f Filter) matches(finding Finding) bool {
+	date := finding.Trip.Date
+	if !f.From.IsZero() && date.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && date.After(f.To) {
+		return false
+	}
+	if f.AccountID != "" && finding.AccountID != f.AccountID {
+		return false
+	}
+	if f.Rule != "" && finding.Rule != f.Rule {
+		return false
+	}
+	return f.CardNumber == "" || masterdata.CardMatches(card.Normalize(f.CardNumber), card.Normalize(finding.Trip.CardNumber))
+}
+
+// Store keeps findings of imported trips so they can be reviewed later
+type Store interface {
+	// Add records findings, replacing an earlier finding of the same rule for the same row and account
+	Add(findings ...Finding) error
+	// List returns the findings passing filter, ordered by trip date, row and rule
+	List(filter Filter) []Finding
+}
+
+// MemoryStore is an in-process Store. Its findings are lost when the process exits;
+// use a FileStore to keep them across restarts.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	findings map[string]Finding
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{findings: make(map[string]Finding)}
+}
+
+// Add implements Store
+func (s *MemoryStore) Add(findings ...Finding) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, finding := range findings {
+		s.findings[finding.AccountID+"\x00"+finding.Rule+"\x00"+finding.Trip.Row] = finding
+	}
+	return nil
+}
+
+// Len returns the number of stored findings
+func (s *MemoryStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.findings)
+}
+
+// List implements Store
+func (s *MemoryStore) List(filter Filter) []Finding {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var findings []Finding
+	for _, finding := range s.findings {
+		if filter.matches(finding) {
+			findings = append(findings, finding)
+		}
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if !a.Trip.Date.Equal(b.Trip.Date) {
+			return a.Trip.Date.Before(b.Trip.Date)
+		}
+		if a.Trip.Row != b.Trip.Row {
+			return a.Trip.Row < b.Trip.Row
+		}
+		return a.Rule < b.Rule
+	})
+	return findings
+}
+
+// FileStore is a Store persisted as JSON Lines, so findings can be reviewed after a restart.
+// Each finding is appended as a line; when the file is loaded, later lines replace earlier findings
+// of the same rule, row and account, and the file is rewritten without the replaced lines.
+type FileStore struct {
+	*MemoryStore
+	mu   sync.Mutex // serializes appends
+	path string
+}
+
+// OpenFileStore loads a findings file; a missing file yields an empty store that is created on the first finding
+func OpenFileStore(path string) (*FileStore, error) {
+	s := &FileStore{MemoryStore: NewMemoryStore(), path: path}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open anomaly store: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line, lines := 0, 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var finding Finding
+		if err := json.Unmarshal(scanner.Bytes(), &finding); err != nil {
+			return nil, fmt.Errorf("anomaly store line %d: %w", line, err)
+		}
+		s.MemoryStore.Add(finding)
+		lines++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read anomaly store: %w", err)
+	}
+
+	if lines > s.Len() {
+		if err := s.compact(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Add implements Store by appending the findings to the file before keeping them in memory
+func (s *FileStore) Add(findings ...Finding) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open anomaly store: %w", err)
+	}
+	if err := writeFindings(file, findings); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write anomaly store: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write anomaly store: %w", err)
+	}
+	return s.MemoryStore.Add(findings...)
+}
+
+// compact rewrites the file with one line per kept finding
+func (s *FileStore) compact() error {
+	tmp := s.path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to compact anomaly store: %w", err)
+	}
+	if err := writeFindings(file, s.List(Filter{})); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to compact anomaly store: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to compact anomaly store: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to compact anomaly store: %w", err)
+	}
+	return nil
+}
+
+// writeFindings writes findings as JSON Lines
+func writeFindings(file *os.File, findings []Finding) error {
+	encoder := json.NewEncoder(file)
+	encoder.SetEscapeHTML(false)
+	for _, finding := range findings {
+		if err := encoder.Encode(finding); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+// Package card normalizes, validates and masks ETC card numbers.
+package card
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Length is the number of characters in an ETC card number, including masked digits
+const Length = 16
+
+// minVisibleDigits is how many trailing digits a masked statement number must show
+const minVisibleDigits = 4
+
+// maskChar replaces hidden digits, as in statements ("********12345678")
+const maskChar = '*'
+
+// ErrInvalidNumber is returned for card numbers that are not 16 digits (optionally with leading * masking)
+var ErrInvalidNumber = errors.New("invalid card number")
+
+// Normalize strips spaces and hyphens and converts full-width digits and asterisks to ASCII
+func Normalize(number string) string {
+	var b strings.Builder
+	b.Grow(len(number))
+	for _, r := range number {
+		switch {
+		case r >= '０' && r <= '９':
+			b.WriteRune('0' + (r - '０'))
+		case r == '＊':
+			b.WriteRune(maskChar)
+		case r == ' ' || r == '　' || r == '\t' || r == '-' || r == '－' || r == '‐' || r == 'ー':
+			// Separators are dropped
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Validate checks the format of a normalized card number.
+// Statement numbers may hide leading digits with '*' but must show at least the last 4 digits.
+// Fully visible numbers must also pass the Luhn check digit.
+func Validate(number string) error {
+	if len(number) != Length {
+		return fmt.Errorf("%w: must be %d digits", ErrInvalidNumber, Length)
+	}
+
+	masked := strings.LastIndexByte(number, maskChar) + 1
+	if strings.Count(number[:masked], string(maskChar)) != masked {
+		return fmt.Errorf("%w: masking must only cover leading digits", ErrInvalidNumber)
+	}
+	if Length-masked < minVisibleDigits {
+		return fmt.Errorf("%w: at least the last %d digits must be visible", ErrInvalidNumber, minVisibleDigits)
+	}
+	for _, r := range number[masked:] {
+		if r < '0' || r > '9' {
+			return fmt.Errorf("%w: must contain only digits", ErrInvalidNumber)
+		}
+	}
+
+	if masked == 0 && !luhn(number) {
+		return fmt.Errorf("%w: check digit mismatch", ErrInvalidNumber)
+	}
+	return nil
+}
+
+// luhn reports whether a string of digits has a valid Luhn check digit
+func luhn(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// MaskPolicy controls how much of a card number appears in logs and error messages
+type MaskPolicy string
+
+// Masking policies
+const (
+	MaskLast4 MaskPolicy = "last4" // keep only the last 4 digits (default)
+	MaskAll   MaskPolicy = "all"   // hide every digit
+	MaskNone  MaskPolicy = "none"  // show the number as-is
+)
+
+// ParseMaskPolicy parses a policy name; an empty name is MaskLast4
+func ParseMaskPolicy(name string) (MaskPolicy, error) {
+	switch policy := MaskPolicy(strings.ToLower(strings.TrimSpace(name))); policy {
+	case "":
+		return MaskLast4, nil
+	case MaskLast4, MaskAll, MaskNone:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown card mask policy: %q", name)
+}
+
+// Mask hides the card number according to the policy
+func (p MaskPolicy) Mask(number string) string {
+	switch p {
+	case MaskNone:
+		return number
+	case MaskAll:
+		return strings.Repeat(string(maskChar), len([]rune(number)))
+	}
+
+	// MaskLast4 (and unknown policies) keep at most the last 4 characters
+	runes := []rune(number)
+	visible := minVisibleDigits
+	if len(runes) <= visible {
+		visible = 0
+	}
+	return strings.Repeat(string(maskChar), len(runes)-visible) + string(runes[len(runes)-visible:])
+}
@@ -9,14 +9,17 @@ import (
 	"time"
 
 	pb "github.com/yhonda-ohishi-pub-dev/db_service/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/metrics"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
 // ETCMeisaiClient wraps db_service gRPC client for ETC data operations
 type ETCMeisaiClient struct {
-	conn   *grpc.ClientConn
-	client pb.Db_ETCMeisaiServiceClient
+	conn    *grpc.ClientConn
+	client  pb.Db_ETCMeisaiServiceClient
+	metrics *metrics.Metrics
 }
 
 // NewETCMeisaiClient creates a new gRPC client connecting to db_service
@@ -27,6 +30,8 @@ func NewETCMeisaiClient(address string) (*ETCMeisaiClient, error) {
 	conn, err := grpc.DialContext(ctx, address,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithBlock(),
+		// Propagate the trace context so db_service spans join the import's trace
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to db_service at %s: %w", address, err)
@@ -38,9 +43,20 @@ func NewETCMeisaiClient(address string) (*ETCMeisaiClient, error) {
 	}, nil
 }
 
+// SetMetrics enables latency and error metrics for db_service calls; nil disables them
+func (c *ETCMeisaiClient) SetMetrics(m *metrics.Metrics) {
+	c.metrics = m
+}
+
 // SaveETCData implements handler.DBClient interface
 // Converts map data to ETCMeisai proto and saves to database
 func (c *ETCMeisaiClient) SaveETCData(data interface{}) error {
+	return c.SaveETCDataContext(context.Background(), data)
+}
+
+// SaveETCDataContext implements handler.ContextDBClient interface
+// Like SaveETCData, but the call carries the deadline and trace context of ctx
+func (c *ETCMeisaiClient) SaveETCDataContext(ctx context.Context, data interface{}) error {
 	etcData, ok := data.(map[string]interface{})
 	if !ok {
 		return fmt.Errorf("invalid data type, expected map[string]interface{}, got %T", data)
@@ -58,10 +74,12 @@ func (c *ETCMeisaiClient) SaveETCData(data interface{}) error {
 	}
 
 	// Call gRPC service
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
+	start := time.Now()
 	_, err = c.client.Create(ctx, req)
+	c.metrics.ObserveDBCall("Create", time.Since(start), err)
 	if err != nil {
 		return fmt.Errorf("failed to save ETC meisai to db_service: %w", err)
 	}
@@ -125,6 +143,9 @@ func convertToETCMeisai(data map[string]interface{}) (*pb.Db_ETCMeisai, error) {
 	}
 
 	// Build ETCMeisai proto message
+	// db_service's ETCMeisai has no fields for the fare breakdown, so only the charged amount (price)
+	// is stored there; normal_amount, discount_amount, etc_amount, post_payment_amount, mileage and
+	// redemption_base_amount are dropped here until db_service adds them (they remain available via ExportRecords)
 	etcMeisai := &pb.Db_ETCMeisai{
 		DateTo:     dateToRFC3339, // RFC3339 format for db_service
 		DateToDate: dateStr,       // Keep original date format for date_to_date
@@ -0,0 +1,10 @@
+package discount
+
+import (
+	"time"
+)
+
+// Calendar decides which days count as holidays (土日祝) for holiday discounts; *holiday.Calendar implements it
+type Calendar interface {
+	IsDayOff(date time.Time) bool
+}
@@ -0,0 +1,172 @@
+package discount
+
+import (
+	"math"
+	"time"
+
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/card"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/holiday"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/masterdata"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+)
+
+// Trip is the part of a statement row the discount rules look at
+type Trip struct {
+	Entry        time.Time
+	Exit         time.Time
+	VehicleClass parser.VehicleClass
+	CardNumber   string
+	NormalAmount int // 割引前料金
+	Discount     int // discount on the statement, as a positive amount
+}
+
+// TripFromRecord reads the trip of a parsed statement row
+func TripFromRecord(record parser.ActualETCRecord) (Trip, error) {
+	entry, err := parser.ParseDateTime(record.EntryDate, record.EntryTime)
+	if err != nil {
+		return Trip{}, err
+	}
+	exit, err := parser.ParseDateTime(record.ExitDate, record.ExitTime)
+	if err != nil {
+		return Trip{}, err
+	}
+
+	// ＥＴＣ割引額 is negative on statements; some files print it as a positive number
+	discount := record.DiscountApplied
+	if discount < 0 {
+		discount = -discount
+	}
+	return Trip{
+		Entry:        entry,
+		Exit:         exit,
+		VehicleClass: record.VehicleClass,
+		CardNumber:   record.CardNumber,
+		NormalAmount: record.NormalAmount,
+		Discount:     discount,
+	}, nil
+}
+
+// Result is the outcome of verifying one trip
+type Result struct {
+	Expected   int      // expected discount in yen
+	Actual     int      // discount on the statement
+	Difference int      // Expected - Actual; positive means the discount was not fully applied
+	Rules      []string // names of the rules that apply
+	Mismatch   bool     // the difference exceeds the tolerance
+}
+
+// Engine recomputes expected discounts from rules and a holiday calendar
+type Engine struct {
+	rules         []Rule
+	calendar      Calendar
+	extraHolidays map[string]bool // "YYYY-MM-DD" days off from the rule file, kept when the calendar is replaced
+	tolerance     int
+}
+
+// NewEngine creates an engine; a nil calendar uses the built-in Japanese holidays
+func NewEngine(rules []Rule, calendar Calendar) *Engine {
+	if calendar == nil {
+		calendar = holiday.New()
+	}
+	return &Engine{rules: rules, calendar: calendar, tolerance: DefaultTolerance}
+}
+
+// SetCalendar replaces the holiday calendar; extra holidays from the rule file still apply
+func (e *Engine) SetCalendar(calendar Calendar) {
+	e.calendar = calendar
+}
+
+// Verify computes the expected discount of a trip and compares it with the statement
+func (e *Engine) Verify(trip Trip) Result {
+	result := Result{Actual: trip.Discount}
+
+	// The best competing discount applies first
+	var best *Rule
+	for i := range e.rules {
+		rule := &e.rules[i]
+		if !rule.Stack && e.applies(*rule, trip) && (best == nil || rule.Rate > best.Rate) {
+			best = rule
+		}
+	}
+	if best != nil {
+		result.Expected = yen(float64(trip.NormalAmount) * best.Rate)
+		result.Rules = append(result.Rules, best.Name)
+	}
+
+	// Stacking discounts apply to what is left
+	remaining := trip.NormalAmount - result.Expected
+	for _, rule := range e.rules {
+		if rule.Stack && e.applies(rule, trip) {
+			result.Expected += yen(float64(remaining) * rule.Rate)
+			result.Rules = append(result.Rules, rule.Name)
+		}
+	}
+
+	result.Difference = result.Expected - result.Actual
+	result.Mismatch = result.Difference > e.tolerance || result.Difference < -e.tolerance
+	return result
+}
+
+// applies reports whether a rule covers the trip
+func (e *Engine) applies(rule Rule, trip Trip) bool {
+	if len(rule.VehicleClasses) > 0 && !containsClass(rule.VehicleClasses, trip.VehicleClass) {
+		return false
+	}
+	if len(rule.Cards) > 0 && !containsCard(rule.Cards, trip.CardNumber) {
+		return false
+	}
+
+	switch rule.Kind {
+	case KindHoliday:
+		return e.isDayOff(trip.Entry) || e.isDayOff(trip.Exit)
+	case KindLateNight:
+		return overlapsWindow(trip.Entry, trip.Exit, rule.StartHour, rule.EndHour)
+	case KindContract:
+		return true
+	}
+	return false
+}
+
+// isDayOff reports whether date is a holiday for holiday discounts
+func (e *Engine) isDayOff(date time.Time) bool {
+	return e.extraHolidays[date.Format(dateLayout)] || e.calendar.IsDayOff(date)
+}
+
+// overlapsWindow reports whether [entry, exit] overlaps the daily window [startHour, endHour)
+func overlapsWindow(entry, exit time.Time, startHour, endHour int) bool {
+	day := time.Date(entry.Year(), entry.Month(), entry.Day(), 0, 0, 0, 0, entry.Location())
+	for ; !day.After(exit); day = day.AddDate(0, 0, 1) {
+		start := day.Add(time.Duration(startHour) * time.Hour)
+		end := day.Add(time.Duration(endHour) * time.Hour)
+		if entry.Before(end) && !exit.Before(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsClass reports whether classes includes class
+func containsClass(classes []parser.VehicleClass, class parser.VehicleClass) bool {
+	for _, c := range classes {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// containsCard reports whether any listed card matches the trip's card
+func containsCard(cards []string, number string) bool {
+	number = card.Normalize(number)
+	for _, c := range cards {
+		if masterdata.CardMatches(card.Normalize(c), number) {
+			return true
+		}
+	}
+	return false
+}
+
+// yen rounds a discount to whole yen
+func yen(amount float64) int {
+	return int(math.Round(amount))
+}
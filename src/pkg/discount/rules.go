@@ -0,0 +1,126 @@
+// Package discount recomputes the expected ETC discount of a trip and flags mismatches with the statement.
+package discount
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// dateLayout is the format of holiday dates
+const dateLayout = "2006-01-02"
+
+// DefaultTolerance is the difference in yen accepted as rounding
+const DefaultTolerance = 10
+
+// Kind selects when a rule applies
+type Kind string
+
+// Rule kinds
+const (
+	KindHoliday   Kind = "holiday"    // the trip starts or ends on a weekend or holiday
+	KindLateNight Kind = "late_night" // the trip overlaps the late-night window
+	KindContract  Kind = "contract"   // always, for the listed cards (大口・多頻度割引 and similar contracts)
+)
+
+// Rule is one discount. Rules that do not stack compete and only the highest rate applies;
+// stacking rules are applied afterwards to the amount left after that discount.
+type Rule struct {
+	Name           string                `yaml:"name"`
+	Kind           Kind                  `yaml:"kind"`
+	Rate           float64               `yaml:"rate"`            // fraction of 割引前料金, e.g. 0.3
+	VehicleClasses []parser.VehicleClass `yaml:"vehicle_classes"` // empty applies to all classes
+	Cards          []string              `yaml:"cards"`           // empty applies to all cards; masked numbers match by suffix
+	StartHour      int                   `yaml:"start_hour"`      // late-night window start (inclusive)
+	EndHour        int                   `yaml:"end_hour"`        // late-night window end (exclusive)
+	Stack          bool                  `yaml:"stack"`
+}
+
+// DefaultRules are the NEXCO holiday (普通車・軽自動車等, 30%) and late-night (0-4時, 30%) discounts
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			Name:           "休日割引",
+			Kind:           KindHoliday,
+			Rate:           0.3,
+			VehicleClasses: []parser.VehicleClass{parser.VehicleClassStandard, parser.VehicleClassLight},
+		},
+		{
+			Name:      "深夜割引",
+			Kind:      KindLateNight,
+			Rate:      0.3,
+			StartHour: 0,
+			EndHour:   4,
+		},
+	}
+}
+
+// Config is the layout of discount rule files
+type Config struct {
+	Tolerance int      `yaml:"tolerance"` // zero means DefaultTolerance
+	Holidays  []string `yaml:"holidays"`  // extra holidays ("YYYY-MM-DD") on top of weekends and national holidays
+	Rules     []Rule   `yaml:"rules"`     // empty means DefaultRules
+}
+
+// LoadFile loads an engine from a YAML rule file
+func LoadFile(path string) (*Engine, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read discount rules: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse discount rules: %w", err)
+	}
+	return NewEngineFromConfig(cfg)
+}
+
+// NewEngineFromConfig validates a rule configuration and creates an engine using the Japanese holiday calendar
+func NewEngineFromConfig(cfg Config) (*Engine, error) {
+	extra := make(map[string]bool)
+	for _, date := range cfg.Holidays {
+		day, err := time.Parse(dateLayout, date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid holiday %q: must be YYYY-MM-DD", date)
+		}
+		extra[day.Format(dateLayout)] = true
+	}
+
+	rules := cfg.Rules
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+	for i, rule := range rules {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("discount rule %d: %w", i+1, err)
+		}
+	}
+
+	engine := NewEngine(rules, nil)
+	engine.extraHolidays = extra
+	if cfg.Tolerance > 0 {
+		engine.tolerance = cfg.Tolerance
+	}
+	return engine, nil
+}
+
+// validate checks a rule's kind, rate and late-night window
+func (r Rule) validate() error {
+	switch r.Kind {
+	case KindHoliday, KindContract:
+	case KindLateNight:
+		if r.StartHour < 0 || r.StartHour > 23 || r.EndHour <= r.StartHour || r.EndHour > 24 {
+			return fmt.Errorf("invalid late-night window %d-%d", r.StartHour, r.EndHour)
+		}
+	default:
+		return fmt.Errorf("unknown kind %q", r.Kind)
+	}
+	if r.Rate <= 0 || r.Rate >= 1 {
+		return fmt.Errorf("rate must be between 0 and 1, got %v", r.Rate)
+	}
+	return nil
+}
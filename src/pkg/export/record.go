@@ -0,0 +1,140 @@
+// Package export writes normalized ETC records to CSV, JSON Lines and Parquet files for
+// analytics and archives, and keeps imported records so they can be exported later.
+package export
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaVersion tags every exported record. Columns are only ever added at the end;
+// renaming or removing a column requires a new version.
+const SchemaVersion = "etc_record.v1"
+
+// TimeLayout is the format of entry, exit and import times (local time as on the statement)
+const TimeLayout = "2006-01-02T15:04:05"
+
+// DateLayout is the format of usage dates
+const DateLayout = "2006-01-02"
+
+// Record is one normalized statement row with its full amount breakdown
+type Record struct {
+	SchemaVersion      string `json:"schema_version"`
+	ImportJobID        string `json:"import_job_id"`
+	ImportedAt         string `json:"imported_at"`
+	AccountID          string `json:"account_id"`
+	SourceFile         string `json:"source_file"`
+	LineNumber         int32  `json:"line_number"`
+	Date               string `json:"date"`
+	EntryTime          string `json:"entry_time"`
+	ExitTime           string `json:"exit_time"`
+	EntryIC            string `json:"entry_ic"`
+	ExitIC             string `json:"exit_ic"`
+	EntryICCode        string `json:"entry_ic_code"`
+	ExitICCode         string `json:"exit_ic_code"`
+	Route              string `json:"route"`
+	VehicleClass       int32  `json:"vehicle_class"`
+	VehicleNumber      string `json:"vehicle_number"`
+	CardNumber         string `json:"card_number"`
+	NormalAmount       int64  `json:"normal_amount"`
+	DiscountAmount     int64  `json:"discount_amount"`
+	ETCAmount          int64  `json:"etc_amount"`
+	PostPaymentAmount  int64  `json:"post_payment_amount"`
+	Amount             int64  `json:"amount"`
+	TaxExclusiveAmount int64  `json:"tax_exclusive_amount"`
+	TaxAmount          int64  `json:"tax_amount"`
+	Mileage            int64  `json:"mileage"`
+	Reversal           bool   `json:"reversal"`
+	CorrectionReason   string `json:"correction_reason"`
+	TripID             string `json:"trip_id"`
+	VehicleID          string `json:"vehicle_id"`
+	DriverID           string `json:"driver_id"`
+	DayType            string `json:"day_type"`
+
+	RedemptionBaseAmount int64 `json:"redemption_base_amount"`
+}
+
+// columnKind is the type of a schema column
+type columnKind int
+
+// Column kinds
+const (
+	kindString columnKind = iota
+	kindInt32
+	kindInt64
+	kindBool
+)
+
+// column is one field of the schema with its accessor
+type column struct {
+	name  string
+	kind  columnKind
+	value func(r Record) interface{} // string, int32, int64 or bool according to kind
+}
+
+// schema lists the columns of SchemaVersion in order
+var schema = []column{
+	{"schema_version", kindString, func(r Record) interface{} { return r.SchemaVersion }},
+	{"import_job_id", kindString, func(r Record) interface{} { return r.ImportJobID }},
+	{"imported_at", kindString, func(r Record) interface{} { return r.ImportedAt }},
+	{"account_id", kindString, func(r Record) interface{} { return r.AccountID }},
+	{"source_file", kindString, func(r Record) interface{} { return r.SourceFile }},
+	{"line_number", kindInt32, func(r Record) interface{} { return r.LineNumber }},
+	{"date", kindString, func(r Record) interface{} { return r.Date }},
+	{"entry_time", kindString, func(r Record) interface{} { return r.EntryTime }},
+	{"exit_time", kindString, func(r Record) interface{} { return r.ExitTime }},
+	{"entry_ic", kindString, func(r Record) interface{} { return r.EntryIC }},
+	{"exit_ic", kindString, func(r Record) interface{} { return r.ExitIC }},
+	{"entry_ic_code", kindString, func(r Record) interface{} { return r.EntryICCode }},
+	{"exit_ic_code", kindString, func(r Record) interface{} { return r.ExitICCode }},
+	{"route", kindString, func(r Record) interface{} { return r.Route }},
+	{"vehicle_class", kindInt32, func(r Record) interface{} { return r.VehicleClass }},
+	{"vehicle_number", kindString, func(r Record) interface{} { return r.VehicleNumber }},
+	{"card_number", kindString, func(r Record) interface{} { return r.CardNumber }},
+	{"normal_amount", kindInt64, func(r Record) interface{} { return r.NormalAmount }},
+	{"discount_amount", kindInt64, func(r Record) interface{} { return r.DiscountAmount }},
+	{"etc_amount", kindInt64, func(r Record) interface{} { return r.ETCAmount }},
+	{"post_payment_amount", kindInt64, func(r Record) interface{} { return r.PostPaymentAmount }},
+	{"amount", kindInt64, func(r Record) interface{} { return r.Amount }},
+	{"tax_exclusive_amount", kindInt64, func(r Record) interface{} { return r.TaxExclusiveAmount }},
+	{"tax_amount", kindInt64, func(r Record) interface{} { return r.TaxAmount }},
+	{"mileage", kindInt64, func(r Record) interface{} { return r.Mileage }},
+	{"reversal", kindBool, func(r Record) interface{} { return r.Reversal }},
+	{"correction_reason", kindString, func(r Record) interface{} { return r.CorrectionReason }},
+	{"trip_id", kindString, func(r Record) interface{} { return r.TripID }},
+	{"vehicle_id", kindString, func(r Record) interface{} { return r.VehicleID }},
+	{"driver_id", kindString, func(r Record) interface{} { return r.DriverID }},
+	{"day_type", kindString, func(r Record) interface{} { return r.DayType }},
+	{"redemption_base_amount", kindInt64, func(r Record) interface{} { return r.RedemptionBaseAmount }},
+}
+
+// Columns returns the column names of the schema in order
+func Columns() []string {
+	names := make([]string, len(schema))
+	for i, c := range schema {
+		names[i] = c.name
+	}
+	return names
+}
+
+// key identifies the statement row of a record within an account, so re-importing it replaces it
+func (r Record) key() string {
+	return strings.Join([]string{
+		r.AccountID, r.EntryTime, r.ExitTime, r.EntryIC, r.ExitIC, r.CardNumber,
+		itoa(r.Amount), strconv.FormatBool(r.Reversal),
+	}, "\x00")
+}
+
+// FormatTime formats an entry or exit time; the zero time is empty
+func FormatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(TimeLayout)
+}
+
+// itoa formats an integer
+func itoa(value int64) string {
+	return strconv.FormatInt(value, 10)
+}
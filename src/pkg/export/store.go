@@ -0,0 +1,197 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Filter selects the records to export; every set field must match
+type Filter struct {
+	ImportJobID string    // records of one import job
+	SourceFile  string    // records read from this file
+	AccountID   string    // records of one account
+	From        time.Time // first usage day, inclusive; zero means unbounded
+	To          time.Time // last usage day, inclusive; zero means unbounded
+}
+
+// matches reports whether a record passes the filter
+func (f Filter) matches(r Record) bool {
+	if f.ImportJobID != "" && r.ImportJobID != f.ImportJobID {
+		return false
+	}
+	if f.SourceFile != "" && r.SourceFile != f.SourceFile {
+		return false
+	}
+	if f.AccountID != "" && r.AccountID != f.AccountID {
+		return false
+	}
+	if !f.From.IsZero() && r.Date < f.From.Format(DateLayout) {
+		return false
+	}
+	return f.To.IsZero() || r.Date <= f.To.Format(DateLayout)
+}
+
+// Store keeps imported records for export
+type Store interface {
+	// Add records imported records, replacing earlier imports of the same statement rows
+	Add(records ...Record) error
+	// List returns the records passing filter, ordered by date, entry time and line
+	List(filter Filter) ([]Record, error)
+}
+
+// MemoryStore is an in-process Store
+type MemoryStore struct {
+	mu      sync.RWMutex
+	records map[string]Record
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]Record)}
+}
+
+// Add implements Store
+func (s *MemoryStore) Add(records ...Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, record := range records {
+		s.records[record.key()] = record
+	}
+	return nil
+}
+
+// Len returns the number of stored records
+func (s *MemoryStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.records)
+}
+
+// List implements Store
+func (s *MemoryStore) List(filter Filter) ([]Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var records []Record
+	for _, record := range s.records {
+		if filter.matches(record) {
+			records = append(records, record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.EntryTime != b.EntryTime {
+			return a.EntryTime < b.EntryTime
+		}
+		if a.SourceFile != b.SourceFile {
+			return a.SourceFile < b.SourceFile
+		}
+		return a.LineNumber < b.LineNumber
+	})
+	return records, nil
+}
+
+// FileStore is a Store persisted as JSON Lines, so records survive restarts and can be
+// exported by the etcproc command. New records are appended; when the file is loaded,
+// later lines replace earlier imports of the same statement row, and the file is rewritten
+// without the replaced lines.
+type FileStore struct {
+	*MemoryStore
+	mu   sync.Mutex // serializes appends
+	path string
+}
+
+// OpenFileStore loads a record store file; a missing file yields an empty store that is created on the first import
+func OpenFileStore(path string) (*FileStore, error) {
+	s := &FileStore{MemoryStore: NewMemoryStore(), path: path}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open record store: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line, lines := 0, 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("record store line %d: %w", line, err)
+		}
+		s.MemoryStore.Add(record)
+		lines++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read record store: %w", err)
+	}
+
+	if lines > s.Len() {
+		if err := s.compact(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// compact rewrites the file with one line per kept record
+func (s *FileStore) compact() error {
+	records, err := s.List(Filter{})
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to compact record store: %w", err)
+	}
+	if err := writeJSONL(file, records); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to compact record store: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to compact record store: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to compact record store: %w", err)
+	}
+	return nil
+}
+
+// Add implements Store by appending the records to the file before keeping them in memory
+func (s *FileStore) Add(records ...Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open record store: %w", err)
+	}
+	if err := writeJSONL(file, records); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write record store: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write record store: %w", err)
+	}
+	return s.MemoryStore.Add(records...)
+}
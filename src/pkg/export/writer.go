@@ -0,0 +1,118 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Format is an export file format
+type Format string
+
+// Supported export formats
+const (
+	FormatCSV     Format = "csv"     // UTF-8 CSV with a header row
+	FormatJSONL   Format = "jsonl"   // one JSON object per line
+	FormatParquet Format = "parquet" // Snappy-compressed Parquet
+)
+
+// ErrUnknownFormat is returned for format names that are not supported
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// ParseFormat parses a format name ("csv", "jsonl" or "json", "parquet"), ignoring case
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "csv":
+		return FormatCSV, nil
+	case "jsonl", "json":
+		return FormatJSONL, nil
+	case "parquet":
+		return FormatParquet, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownFormat, name)
+}
+
+// ContentType returns the MIME type of files in this format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSONL:
+		return "application/x-ndjson"
+	case FormatParquet:
+		return "application/vnd.apache.parquet"
+	}
+	return "text/csv; charset=UTF-8"
+}
+
+// Filename returns the name of an export file in this format
+func (f Format) Filename() string {
+	return fmt.Sprintf("etc_records.%s", f)
+}
+
+// Write writes records in the given format. Records without a schema version are tagged with SchemaVersion.
+func Write(w io.Writer, format Format, records []Record) error {
+	for i := range records {
+		if records[i].SchemaVersion == "" {
+			records[i].SchemaVersion = SchemaVersion
+		}
+	}
+
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, records)
+	case FormatJSONL:
+		return writeJSONL(w, records)
+	case FormatParquet:
+		return writeParquet(w, records)
+	}
+	return fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+}
+
+// writeCSV writes a header row followed by one row per record
+func writeCSV(w io.Writer, records []Record) error {
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write(Columns()); err != nil {
+		return err
+	}
+	row := make([]string, len(schema))
+	for _, record := range records {
+		for i, c := range schema {
+			row[i] = formatValue(c.value(record))
+		}
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// writeJSONL writes one JSON object per line
+func writeJSONL(w io.Writer, records []Record) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatValue formats a column value for CSV
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int32:
+		return itoa(int64(v))
+	case int64:
+		return itoa(v)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/anomaly"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/card"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/usage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SetAnomalyDetector enables anomaly detection on imported records with the given detector; nil disables it.
+// Rules that learn from imported trips (such as amount outliers) are seeded with the records in the usage store,
+// so their history survives restarts when the usage store is kept in a file; set the usage store first.
+func (s *DataProcessorService) SetAnomalyDetector(detector *anomaly.Detector) {
+	s.anomalies = detector
+	if detector != nil && s.usage != nil {
+		detector.Learn(usageTrips(s.usage.Entries(usage.Filter{})))
+	}
+}
+
+// usageTrips rebuilds the trips of usage store entries for the rules that learn from imported trips
+func usageTrips(entries []usage.Entry) []anomaly.Trip {
+	trips := make([]anomaly.Trip, len(entries))
+	for i, entry := range entries {
+		trips[i] = anomaly.Trip{
+			Index:         i,
+			Row:           entry.Row,
+			Date:          entry.Date,
+			CardNumber:    entry.CardNumber,
+			VehicleNumber: entry.VehicleNumber,
+			VehicleID:     entry.VehicleID,
+			VehicleClass:  parser.VehicleClass(entry.VehicleClass),
+			EntryIC:       entry.EntryIC,
+			ExitIC:        entry.ExitIC,
+			Amount:        entry.Amount,
+			Reversal:      entry.Reversal,
+		}
+	}
+	return trips
+}
+
+// SetAnomalyStore replaces the store findings are listed from; nil disables ListAnomalies
+func (s *DataProcessorService) SetAnomalyStore(store anomaly.Store) {
+	s.findings = store
+}
+
+// ListAnomalies returns the anomaly findings of imported records
+func (s *DataProcessorService) ListAnomalies(ctx context.Context, req *pb.ListAnomaliesRequest) (*pb.ListAnomaliesResponse, error) {
+	if s.findings == nil {
+		return nil, status.Error(codes.Unimplemented, "anomaly findings are not kept")
+	}
+
+	filter := anomaly.Filter{AccountID: req.GetAccountId(), CardNumber: req.GetCardNumber(), Rule: req.GetRule()}
+	var err error
+	if filter.From, err = parseUsageDate(req.GetFromDate()); err != nil {
+		return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "from_date", err.Error())
+	}
+	if filter.To, err = parseUsageDate(req.GetToDate()); err != nil {
+		return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "to_date", err.Error())
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "to_date", "to_date must not be before from_date")
+	}
+
+	resp := &pb.ListAnomaliesResponse{}
+	for _, finding := range s.findings.List(filter) {
+		resp.Findings = append(resp.Findings, s.toAnomalyFindingProto(finding))
+	}
+	return resp, nil
+}
+
+// batchAnomalies holds the anomaly findings and the checked trip of each record index of one batch
+type batchAnomalies struct {
+	findings map[int][]anomaly.Finding
+	trips    map[int]anomaly.Trip
+}
+
+// detectAnomalies runs the anomaly rules once over the parsed records of all batches before they are processed,
+// so rules comparing trips with each other also see trips in other files of the request.
+// It returns one result per batch, indexed by the record's position in its batch; the results are empty when detection is disabled.
+func (s *DataProcessorService) detectAnomalies(batches ...[]parser.ActualETCRecord) []batchAnomalies {
+	results := make([]batchAnomalies, len(batches))
+	if s.anomalies == nil {
+		return results
+	}
+
+	// Trips are numbered across batches for the rules and mapped back to their batch afterwards
+	var trips []anomaly.Trip
+	type position struct{ batch, index int }
+	var positions []position
+	for b, records := range batches {
+		results[b].trips = make(map[int]anomaly.Trip, len(records))
+		for i, record := range records {
+			if card.Validate(record.CardNumber) != nil {
+				continue
+			}
+			simpleRecord, err := s.parser.ConvertToSimpleRecord(record)
+			if err != nil {
+				continue
+			}
+			trip := anomaly.NewTrip(i, record, simpleRecord)
+			if entry, ok := s.interchanges.Lookup(trip.EntryIC); ok {
+				trip.EntryIC = entry.Name
+			}
+			if entry, ok := s.interchanges.Lookup(trip.ExitIC); ok {
+				trip.ExitIC = entry.Name
+			}
+			if s.masterData.Len() > 0 {
+				if assignment, ok := s.masterData.Lookup(simpleRecord.CardNumber, record.VehicleNumber, simpleRecord.Date); ok {
+					trip.VehicleID = assignment.VehicleID
+					trip.RegisteredClass = assignment.VehicleClass
+				}
+			}
+			results[b].trips[i] = trip
+			trip.Index = len(trips)
+			trips = append(trips, trip)
+			positions = append(positions, position{batch: b, index: i})
+		}
+	}
+
+	for _, finding := range s.anomalies.Check(trips) {
+		at := positions[finding.Trip.Index]
+		finding.Trip.Index = at.index
+		if results[at.batch].findings == nil {
+			results[at.batch].findings = make(map[int][]anomaly.Finding)
+		}
+		results[at.batch].findings[at.index] = append(results[at.batch].findings[at.index], finding)
+	}
+	return results
+}
+
+// anomalyRulesPayload lists the rules that flagged a record for the DB payload
+func anomalyRulesPayload(findings []anomaly.Finding) []interface{} {
+	rules := make([]interface{}, len(findings))
+	for i, finding := range findings {
+		rules[i] = finding.Rule
+	}
+	return rules
+}
+
+// toAnomalyFindingProto converts a finding to its proto representation with the card number masked
+func (s *DataProcessorService) toAnomalyFindingProto(finding anomaly.Finding) *pb.AnomalyFinding {
+	date := ""
+	if !finding.Trip.Date.IsZero() {
+		date = finding.Trip.Date.Format("2006-01-02")
+	}
+	return &pb.AnomalyFinding{
+		Rule:              finding.Rule,
+		RecordIndex:       int32(finding.Trip.Index + 1),
+		LineNumber:        int32(finding.Trip.LineNumber),
+		FilePath:          finding.FilePath,
+		AccountId:         finding.AccountID,
+		CardNumber:        s.cardMask.Mask(finding.Trip.CardNumber),
+		Date:              date,
+		EntryIc:           finding.Trip.EntryIC,
+		ExitIc:            finding.Trip.ExitIC,
+		Amount:            int32(finding.Trip.Amount),
+		Message:           finding.Message,
+		RelatedLineNumber: int32(finding.RelatedLine),
+	}
+}
+
+// anomalyMessage formats a finding for record errors
+func anomalyMessage(index int, finding anomaly.Finding) string {
+	return fmt.Sprintf("Record %d: anomaly (%s): %s", index+1, finding.Rule, finding.Message)
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/discount"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+)
+
+// SetDiscountEngine enables discount verification with the given engine; nil disables it.
+// The engine uses the service's holiday calendar.
+func (s *DataProcessorService) SetDiscountEngine(engine *discount.Engine) {
+	if engine != nil {
+		engine.SetCalendar(s.calendar)
+	}
+	s.discounts = engine
+}
+
+// verifyDiscount recomputes the expected discount of a record and adds the outcome to the payload.
+// It returns nil when verification is disabled or does not apply (reversals, rows without 割引前料金 or times).
+func (s *DataProcessorService) verifyDiscount(record parser.ActualETCRecord, simpleRecord parser.ETCRecord, payload map[string]interface{}) *discount.Result {
+	if s.discounts == nil || simpleRecord.IsReversal() || record.NormalAmount <= 0 {
+		return nil
+	}
+	trip, err := discount.TripFromRecord(record)
+	if err != nil {
+		return nil
+	}
+
+	result := s.discounts.Verify(trip)
+	rules := make([]interface{}, len(result.Rules))
+	for i, rule := range result.Rules {
+		rules[i] = rule
+	}
+	payload["expected_discount"] = result.Expected
+	payload["discount_difference"] = result.Difference
+	payload["discount_rules"] = rules
+	payload["discount_mismatch"] = result.Mismatch
+	return &result
+}
+
+// discountRulesLabel lists the applied rules for error messages
+func discountRulesLabel(result *discount.Result) string {
+	if len(result.Rules) == 0 {
+		return "no discount"
+	}
+	return strings.Join(result.Rules, ", ")
+}
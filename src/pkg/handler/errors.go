@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"strings"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// errorDomain is the google.rpc.ErrorInfo domain for errors raised by this service
+const errorDomain = "etcdataprocessor.v1"
+
+// newRecordError creates a structured error for the record at the given 0-based index.
+// The message is the same human-readable text returned in the legacy errors list.
+func newRecordError(code pb.ErrorCode, index int, record parser.ActualETCRecord, field, message string) *pb.RecordError {
+	return &pb.RecordError{
+		Code:        code,
+		RecordIndex: int32(index + 1),
+		LineNumber:  int32(record.LineNumber),
+		Field:       field,
+		Message:     message,
+	}
+}
+
+// newFileError creates a structured error that applies to a whole file rather than a single record
+func newFileError(code pb.ErrorCode, filePath, message string) *pb.RecordError {
+	return &pb.RecordError{
+		Code:     code,
+		FilePath: filePath,
+		Message:  message,
+	}
+}
+
+// errorMessages returns the messages of structured errors for the legacy errors field
+func errorMessages(recordErrors []*pb.RecordError) []string {
+	var messages []string
+	for _, e := range recordErrors {
+		messages = append(messages, e.Message)
+	}
+	return messages
+}
+
+// errorReason returns the google.rpc.ErrorInfo reason for an error code (e.g. "PARSE")
+func errorReason(code pb.ErrorCode) string {
+	return strings.TrimPrefix(code.String(), "ERROR_CODE_")
+}
+
+// statusError creates a gRPC status error with google.rpc error details attached.
+// ErrorInfo always carries the error code; BadRequest is added when the error concerns a request field.
+func statusError(c codes.Code, code pb.ErrorCode, field, message string) error {
+	st := status.New(c, message)
+
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{
+			Reason: errorReason(code),
+			Domain: errorDomain,
+		},
+	}
+	if field != "" {
+		details = append(details, &errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: field, Description: message},
+			},
+		})
+	}
+
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
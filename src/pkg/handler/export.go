@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/export"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/interchange"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+	"google.golang.org/grpc/codes"
+)
+
+// exportFormats maps request formats to export formats; unspecified means CSV
+var exportFormats = map[pb.ExportFormat]export.Format{
+	pb.ExportFormat_EXPORT_FORMAT_UNSPECIFIED: export.FormatCSV,
+	pb.ExportFormat_EXPORT_FORMAT_CSV:         export.FormatCSV,
+	pb.ExportFormat_EXPORT_FORMAT_JSONL:       export.FormatJSONL,
+	pb.ExportFormat_EXPORT_FORMAT_PARQUET:     export.FormatParquet,
+}
+
+// SetRecordStore sets the store that keeps imported records for ExportRecords; nil (the default) stops keeping them
+func (s *DataProcessorService) SetRecordStore(store export.Store) {
+	s.archive = store
+}
+
+// ExportRecords writes normalized records as CSV, JSON Lines or Parquet.
+// Records come from the record store, selected by import job, source file, account and date range,
+// or from a CSV given in the request, which is processed as a dry run.
+func (s *DataProcessorService) ExportRecords(ctx context.Context, req *pb.ExportRecordsRequest) (*pb.ExportRecordsResponse, error) {
+	// Validate request using validator
+	if err := ValidateExportRecordsRequest(req, s.validator); err != nil {
+		return nil, err
+	}
+
+	format, ok := exportFormats[req.GetFormat()]
+	if !ok {
+		return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "format", "format must be CSV, JSONL or PARQUET")
+	}
+
+	resp := &pb.ExportRecordsResponse{}
+	var records []export.Record
+	if req.GetCsvData() != "" || req.GetCsvFilePath() != "" {
+		result, err := s.exportCSV(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		records = result.exported
+		resp.RecordErrors = result.errors
+	} else {
+		if s.archive == nil {
+			return nil, statusError(codes.Unimplemented, pb.ErrorCode_ERROR_CODE_UNSPECIFIED, "", "record store is not configured")
+		}
+		from, err := parseUsageDate(req.GetFromDate())
+		if err != nil {
+			return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "from_date", err.Error())
+		}
+		to, err := parseUsageDate(req.GetToDate())
+		if err != nil {
+			return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "to_date", err.Error())
+		}
+		if !from.IsZero() && !to.IsZero() && to.Before(from) {
+			return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "to_date", "to_date must not be before from_date")
+		}
+
+		records, err = s.archive.List(export.Filter{
+			ImportJobID: req.GetImportJobId(),
+			SourceFile:  req.GetSourceFile(),
+			AccountID:   req.GetAccountId(),
+			From:        from,
+			To:          to,
+		})
+		if err != nil {
+			return nil, statusError(codes.Internal, pb.ErrorCode_ERROR_CODE_PERSISTENCE, "", fmt.Sprintf("failed to read record store: %v", err))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := export.Write(&buf, format, records); err != nil {
+		return nil, statusError(codes.Internal, pb.ErrorCode_ERROR_CODE_UNSPECIFIED, "", fmt.Sprintf("failed to write export: %v", err))
+	}
+	resp.Content = buf.Bytes()
+	resp.Filename = format.Filename()
+	resp.ContentType = format.ContentType()
+	resp.RecordCount = int32(len(records))
+	resp.SchemaVersion = export.SchemaVersion
+	return resp, nil
+}
+
+// exportCSV runs the CSV of an export request through the pipeline as a dry run and collects its records
+func (s *DataProcessorService) exportCSV(ctx context.Context, req *pb.ExportRecordsRequest) (*processResult, error) {
+	var records []parser.ActualETCRecord
+	var err error
+	var path string
+	field := "csv_data"
+	if req.GetCsvFilePath() != "" {
+		field = "csv_file_path"
+		if path, err = resolveCSVFile(req.GetCsvFilePath()); err != nil {
+			return nil, err
+		}
+		records, err = s.parser.ParseFile(path)
+	} else {
+		records, err = s.parser.Parse(strings.NewReader(req.GetCsvData()))
+	}
+	if err != nil {
+		return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_PARSE, field, fmt.Sprintf("invalid CSV format: %v", err))
+	}
+
+	result := s.processRecords(ctx, records, processOptions{
+		accountID:      req.GetAccountId(),
+		skipDuplicates: getSkipDuplicatesDefault(),
+		dryRun:         true,
+		stitchTrips:    true,
+		processedKeys:  make(map[string]bool),
+		trips:          newTripLedger(),
+		unmatchedICs:   interchange.NewUnmatched(),
+		filePath:       path,
+		importedAt:     time.Now(),
+		export:         true,
+	})
+	for _, recordError := range result.errors {
+		recordError.FilePath = path
+	}
+	return result, nil
+}
+
+// exportRecord converts a processed record to its export representation;
+// the caller fills in the values computed by the pipeline (IC codes, tax, trip and assignment)
+func (s *DataProcessorService) exportRecord(record parser.ActualETCRecord, simpleRecord parser.ETCRecord, opts processOptions) export.Record {
+	entry, _ := parser.ParseDateTime(record.EntryDate, record.EntryTime)
+	exit, _ := parser.ParseDateTime(record.ExitDate, record.ExitTime)
+	return export.Record{
+		ImportJobID:       opts.jobID,
+		ImportedAt:        export.FormatTime(opts.importedAt),
+		AccountID:         opts.accountID,
+		SourceFile:        opts.filePath,
+		LineNumber:        int32(record.LineNumber),
+		Date:              simpleRecord.Date.Format(export.DateLayout),
+		EntryTime:         export.FormatTime(entry),
+		ExitTime:          export.FormatTime(exit),
+		EntryIC:           simpleRecord.EntryIC,
+		ExitIC:            simpleRecord.ExitIC,
+		Route:             simpleRecord.Route,
+		VehicleClass:      int32(simpleRecord.VehicleType),
+		VehicleNumber:     record.VehicleNumber,
+		CardNumber:        simpleRecord.CardNumber,
+		NormalAmount:      int64(simpleRecord.NormalAmount),
+		DiscountAmount:    int64(simpleRecord.DiscountAmount),
+		ETCAmount:         int64(simpleRecord.ETCAmount),
+		PostPaymentAmount: int64(simpleRecord.PostPaymentAmount),
+		Amount:            int64(simpleRecord.Amount),
+		Mileage:           int64(simpleRecord.Mileage),
+		Reversal:          simpleRecord.IsReversal(),
+		CorrectionReason:  string(simpleRecord.Correction),
+		DayType:           string(s.calendar.DayType(simpleRecord.Date)),
+
+		RedemptionBaseAmount: int64(simpleRecord.RedemptionBaseAmount),
+	}
+}
+
+// newImportJobID generates the ID that tags the records of one import call
+func newImportJobID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("job-%x", time.Now().UnixNano())
+	}
+	return "job-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/idempotency"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxIdempotencyKeyLength limits the size of client supplied idempotency keys
+const maxIdempotencyKeyLength = 255
+
+// idempotentRequest is a process request that may carry an idempotency key
+type idempotentRequest interface {
+	proto.Message
+	GetIdempotencyKey() string
+	GetAccountId() string
+}
+
+// savedRecords tracks the records saved under an idempotency key, so a retry of an interrupted run does not save them again.
+// A nil *savedRecords (no idempotency key) never reports a record as saved.
+type savedRecords struct {
+	store idempotency.Store
+	key   string
+}
+
+// has reports whether an earlier attempt with the same idempotency key saved the record
+func (r *savedRecords) has(recordKey string) bool {
+	return r != nil && r.store.Saved(r.key, recordKey)
+}
+
+// add marks a record as saved under the idempotency key
+func (r *savedRecords) add(recordKey string) {
+	if r != nil {
+		r.store.MarkSaved(r.key, recordKey)
+	}
+}
+
+// runIdempotent runs a process RPC at most once per idempotency key.
+// Requests without a key always run. replayed is true when the stored response of an earlier attempt is returned.
+// A run cut short by cancellation or its deadline is not stored; the records it saved are remembered,
+// so a retry with the same key skips them and processes the rest.
+func runIdempotent[Resp proto.Message](ctx context.Context, store idempotency.Store, method string, req idempotentRequest, run func(saved *savedRecords) (Resp, error)) (resp Resp, replayed bool, err error) {
+	key := req.GetIdempotencyKey()
+	if key == "" || store == nil {
+		resp, err = run(nil)
+		return resp, false, err
+	}
+
+	if len(key) > maxIdempotencyKeyLength {
+		return resp, false, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "idempotency_key",
+			fmt.Sprintf("idempotency_key must be at most %d characters", maxIdempotencyKeyLength))
+	}
+
+	// The key itself is not part of the fingerprint, everything else in the request is
+	unkeyed := proto.Clone(req)
+	message := unkeyed.ProtoReflect()
+	message.Clear(message.Descriptor().Fields().ByName("idempotency_key"))
+
+	fingerprint, err := idempotency.Fingerprint(method, unkeyed)
+	if err != nil {
+		return resp, false, statusError(codes.Internal, pb.ErrorCode_ERROR_CODE_IDEMPOTENCY_CONFLICT, "", err.Error())
+	}
+
+	// Keys are scoped per account so tenants cannot collide
+	storeKey := req.GetAccountId() + "/" + key
+
+	stored, err := store.Begin(storeKey, fingerprint)
+	switch {
+	case errors.Is(err, idempotency.ErrConflict):
+		return resp, false, statusError(codes.AlreadyExists, pb.ErrorCode_ERROR_CODE_IDEMPOTENCY_CONFLICT, "idempotency_key", err.Error())
+	case errors.Is(err, idempotency.ErrInProgress):
+		return resp, false, statusError(codes.Aborted, pb.ErrorCode_ERROR_CODE_IDEMPOTENCY_CONFLICT, "idempotency_key", err.Error())
+	case err != nil:
+		return resp, false, statusError(codes.Internal, pb.ErrorCode_ERROR_CODE_IDEMPOTENCY_CONFLICT, "", err.Error())
+	}
+
+	if stored != nil {
+		if storedResp, ok := stored.(Resp); ok {
+			return storedResp, true, nil
+		}
+		return resp, false, statusError(codes.AlreadyExists, pb.ErrorCode_ERROR_CODE_IDEMPOTENCY_CONFLICT, "idempotency_key", idempotency.ErrConflict.Error())
+	}
+
+	completed := false
+	defer func() {
+		// Release the key on errors (and panics) so the client can retry; saved records stay marked
+		if !completed {
+			store.Abort(storeKey)
+		}
+	}()
+
+	resp, err = run(&savedRecords{store: store, key: storeKey})
+	if err != nil || ctx.Err() != nil {
+		return resp, false, err
+	}
+
+	store.Complete(storeKey, resp)
+	completed = true
+	return resp, false, nil
+}
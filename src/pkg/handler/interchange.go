@@ -0,0 +1,39 @@
+package handler
+
+import (
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/interchange"
+)
+
+// SetInterchangeDictionary replaces the dictionary used to canonicalize IC names; nil resets it to an empty dictionary
+func (s *DataProcessorService) SetInterchangeDictionary(dictionary *interchange.Dictionary) {
+	if dictionary == nil {
+		dictionary = interchange.NewDictionary()
+	}
+	s.interchanges = dictionary
+}
+
+// resolveIC returns the canonical name and code of an IC, recording names missing from the dictionary
+func (s *DataProcessorService) resolveIC(name string, unmatched *interchange.Unmatched) (string, string) {
+	if name == "" {
+		return name, ""
+	}
+	if entry, ok := s.interchanges.Lookup(name); ok {
+		return entry.Name, entry.Code
+	}
+	unmatched.Add(name)
+	return name, ""
+}
+
+// unmatchedICsToProto converts the collected unmatched IC names for a response
+func unmatchedICsToProto(unmatched *interchange.Unmatched) []*pb.UnmatchedIC {
+	var result []*pb.UnmatchedIC
+	for _, name := range unmatched.List() {
+		result = append(result, &pb.UnmatchedIC{
+			Name:     name.Name,
+			Count:    int32(name.Count),
+			RawNames: name.RawNames,
+		})
+	}
+	return result
+}
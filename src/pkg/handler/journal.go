@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/interchange"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/journal"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+	"google.golang.org/grpc/codes"
+)
+
+// journalFormats maps request formats to journal formats
+var journalFormats = map[pb.JournalFormat]journal.Format{
+	pb.JournalFormat_JOURNAL_FORMAT_FREEE:         journal.FormatFreee,
+	pb.JournalFormat_JOURNAL_FORMAT_MONEY_FORWARD: journal.FormatMoneyForward,
+	pb.JournalFormat_JOURNAL_FORMAT_YAYOI:         journal.FormatYayoi,
+}
+
+// SetJournalSettings sets the account titles, tax category and departments used for journal exports
+func (s *DataProcessorService) SetJournalSettings(settings journal.Settings) {
+	s.journal = settings
+}
+
+// ExportJournal converts the records of a CSV into journal entries and returns them as an import file for accounting software
+func (s *DataProcessorService) ExportJournal(ctx context.Context, req *pb.ExportJournalRequest) (*pb.ExportJournalResponse, error) {
+	// Validate request using validator
+	if err := ValidateExportJournalRequest(req, s.validator); err != nil {
+		return nil, err
+	}
+
+	format, ok := journalFormats[req.GetFormat()]
+	if !ok {
+		return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "format", "format must be FREEE, MONEY_FORWARD or YAYOI")
+	}
+	outputEncoding := req.GetEncoding()
+	if outputEncoding == "" {
+		outputEncoding = parser.EncodingShiftJIS
+	}
+	if outputEncoding != parser.EncodingShiftJIS && outputEncoding != parser.EncodingUTF8 {
+		return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "encoding",
+			fmt.Sprintf("encoding must be %s or %s", parser.EncodingShiftJIS, parser.EncodingUTF8))
+	}
+
+	result, err := s.journalCSV(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := journal.Write(&buf, format, outputEncoding, result.entries); err != nil {
+		return nil, statusError(codes.Internal, pb.ErrorCode_ERROR_CODE_CONVERSION, "", fmt.Sprintf("failed to write journal: %v", err))
+	}
+
+	resp := &pb.ExportJournalResponse{RecordErrors: result.errors}
+	resp.Content = buf.Bytes()
+	resp.Filename = format.Filename()
+	resp.ContentType = "text/csv; charset=" + outputEncoding
+	resp.EntryCount = int32(len(result.entries))
+	resp.TaxAmount = result.stats.TaxAmount
+	resp.RecordTaxAmount = result.stats.RecordTaxAmount
+	return resp, nil
+}
+
+// journalCSV runs the CSV of a journal request through the pipeline as a dry run and collects the entries
+// of the records an import would save, so invalid and duplicate rows are left out as they would be on import
+func (s *DataProcessorService) journalCSV(ctx context.Context, req *pb.ExportJournalRequest) (*processResult, error) {
+	var records []parser.ActualETCRecord
+	var err error
+	var path string
+	field := "csv_data"
+	if req.GetCsvFilePath() != "" {
+		field = "csv_file_path"
+		if path, err = resolveCSVFile(req.GetCsvFilePath()); err != nil {
+			return nil, err
+		}
+		records, err = s.parser.ParseFile(path)
+	} else {
+		records, err = s.parser.Parse(strings.NewReader(req.GetCsvData()))
+	}
+	if err != nil {
+		return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_PARSE, field, fmt.Sprintf("invalid CSV format: %v", err))
+	}
+
+	result := s.processRecords(ctx, records, processOptions{
+		skipDuplicates: getSkipDuplicatesDefault(),
+		dryRun:         true,
+		processedKeys:  make(map[string]bool),
+		trips:          newTripLedger(),
+		unmatchedICs:   interchange.NewUnmatched(),
+		filePath:       path,
+		journal:        true,
+	})
+	for _, recordError := range result.errors {
+		recordError.FilePath = path
+	}
+	return result, nil
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/masterdata"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+	"google.golang.org/grpc/codes"
+)
+
+// CreateCardAssignment adds a card-to-vehicle/driver assignment to the master data
+func (s *DataProcessorService) CreateCardAssignment(ctx context.Context, req *pb.CreateCardAssignmentRequest) (*pb.CardAssignment, error) {
+	if req == nil || req.Assignment == nil {
+		return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "assignment", "assignment is required")
+	}
+
+	assignment, err := fromCardAssignmentProto(req.Assignment)
+	if err != nil {
+		return nil, masterDataError(err)
+	}
+	created, err := s.masterData.Create(assignment)
+	if err != nil {
+		return nil, masterDataError(err)
+	}
+	return toCardAssignmentProto(created), nil
+}
+
+// GetCardAssignment returns one assignment by ID
+func (s *DataProcessorService) GetCardAssignment(ctx context.Context, req *pb.GetCardAssignmentRequest) (*pb.CardAssignment, error) {
+	if req.GetId() == "" {
+		return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "id", "id is required")
+	}
+
+	assignment, err := s.masterData.Get(req.GetId())
+	if err != nil {
+		return nil, masterDataError(err)
+	}
+	return toCardAssignmentProto(assignment), nil
+}
+
+// ListCardAssignments lists assignments, optionally filtered by card or vehicle number
+func (s *DataProcessorService) ListCardAssignments(ctx context.Context, req *pb.ListCardAssignmentsRequest) (*pb.ListCardAssignmentsResponse, error) {
+	resp := &pb.ListCardAssignmentsResponse{}
+	for _, assignment := range s.masterData.List(req.GetCardNumber(), req.GetVehicleNumber()) {
+		resp.Assignments = append(resp.Assignments, toCardAssignmentProto(assignment))
+	}
+	return resp, nil
+}
+
+// UpdateCardAssignment replaces an existing assignment
+func (s *DataProcessorService) UpdateCardAssignment(ctx context.Context, req *pb.UpdateCardAssignmentRequest) (*pb.CardAssignment, error) {
+	if req.GetAssignment().GetId() == "" {
+		return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "assignment.id", "assignment.id is required")
+	}
+
+	assignment, err := fromCardAssignmentProto(req.Assignment)
+	if err != nil {
+		return nil, masterDataError(err)
+	}
+	updated, err := s.masterData.Update(assignment)
+	if err != nil {
+		return nil, masterDataError(err)
+	}
+	return toCardAssignmentProto(updated), nil
+}
+
+// DeleteCardAssignment removes an assignment
+func (s *DataProcessorService) DeleteCardAssignment(ctx context.Context, req *pb.DeleteCardAssignmentRequest) (*pb.DeleteCardAssignmentResponse, error) {
+	if req.GetId() == "" {
+		return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "id", "id is required")
+	}
+
+	if err := s.masterData.Delete(req.GetId()); err != nil {
+		return nil, masterDataError(err)
+	}
+	return &pb.DeleteCardAssignmentResponse{}, nil
+}
+
+// masterDataError maps registry errors to gRPC status errors
+func masterDataError(err error) error {
+	switch {
+	case errors.Is(err, masterdata.ErrNotFound):
+		return statusError(codes.NotFound, pb.ErrorCode_ERROR_CODE_VALIDATION, "id", err.Error())
+	case errors.Is(err, masterdata.ErrOverlap):
+		return statusError(codes.FailedPrecondition, pb.ErrorCode_ERROR_CODE_VALIDATION, "assignment", err.Error())
+	case errors.Is(err, masterdata.ErrInvalid):
+		return statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "assignment", err.Error())
+	}
+	return statusError(codes.Internal, pb.ErrorCode_ERROR_CODE_PERSISTENCE, "", err.Error())
+}
+
+// fromCardAssignmentProto converts a proto assignment, parsing its validity dates
+func fromCardAssignmentProto(a *pb.CardAssignment) (masterdata.Assignment, error) {
+	validFrom, err := masterdata.ParseDate(a.GetValidFrom())
+	if err != nil {
+		return masterdata.Assignment{}, err
+	}
+	validTo, err := masterdata.ParseDate(a.GetValidTo())
+	if err != nil {
+		return masterdata.Assignment{}, err
+	}
+	return masterdata.Assignment{
+		ID:            a.GetId(),
+		CardNumber:    a.GetCardNumber(),
+		VehicleNumber: a.GetVehicleNumber(),
+		VehicleID:     a.GetVehicleId(),
+		DriverID:      a.GetDriverId(),
+		VehicleClass:  parser.VehicleClass(a.GetVehicleClass()),
+		ValidFrom:     validFrom,
+		ValidTo:       validTo,
+	}, nil
+}
+
+// toCardAssignmentProto converts an assignment to its proto representation
+func toCardAssignmentProto(a masterdata.Assignment) *pb.CardAssignment {
+	return &pb.CardAssignment{
+		Id:            a.ID,
+		CardNumber:    a.CardNumber,
+		VehicleNumber: a.VehicleNumber,
+		VehicleId:     a.VehicleID,
+		DriverId:      a.DriverID,
+		VehicleClass:  pb.VehicleClass(a.VehicleClass),
+		ValidFrom:     masterdata.FormatDate(a.ValidFrom),
+		ValidTo:       masterdata.FormatDate(a.ValidTo),
+	}
+}
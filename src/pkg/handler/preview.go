@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultPreviewLimit = 10
+	maxPreviewLimit     = 100
+)
+
+// PreviewParser is implemented by parsers that can report how raw columns were mapped to record fields
+type PreviewParser interface {
+	Preview(reader io.Reader, limit int) (*parser.PreviewResult, error)
+	PreviewFile(filePath string, limit int) (*parser.PreviewResult, error)
+}
+
+// PreviewCSV returns the first records of a CSV as normalized records, with their column mappings
+func (s *DataProcessorService) PreviewCSV(ctx context.Context, req *pb.PreviewCSVRequest) (*pb.PreviewCSVResponse, error) {
+	// Validate request using validator
+	if err := ValidatePreviewCSVRequest(req, s.validator); err != nil {
+		return nil, err
+	}
+
+	previewParser, ok := s.parser.(PreviewParser)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "configured parser does not support preview")
+	}
+
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = defaultPreviewLimit
+	}
+	if limit > maxPreviewLimit {
+		limit = maxPreviewLimit
+	}
+
+	var result *parser.PreviewResult
+	var err error
+	field := "csv_data"
+	if req.GetCsvFilePath() != "" {
+		field = "csv_file_path"
+		var path string
+		if path, err = resolveCSVFile(req.GetCsvFilePath()); err != nil {
+			return nil, err
+		}
+		result, err = previewParser.PreviewFile(path, limit)
+	} else {
+		result, err = previewParser.Preview(strings.NewReader(req.GetCsvData()), limit)
+	}
+	if err != nil {
+		return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_PARSE, field, fmt.Sprintf("invalid CSV format: %v", err))
+	}
+
+	resp := &pb.PreviewCSVResponse{
+		Format:       result.Info.Format,
+		Encoding:     result.Info.Encoding,
+		TotalRecords: int32(result.TotalRecords),
+	}
+
+	// Trips are stitched over the previewed records only
+	var tripIDs map[int]string
+	if req.GetStitchTrips() {
+		records := make([]parser.ActualETCRecord, len(result.Records))
+		for i, preview := range result.Records {
+			records[i] = preview.Record
+		}
+		resp.Trips, tripIDs = s.stitchTrips(records, nil)
+	}
+
+	for i, preview := range result.Records {
+		record := &pb.PreviewRecord{
+			LineNumber: int32(preview.Record.LineNumber),
+			RawColumns: preview.Raw,
+			Parsed:     toParsedRecordProto(preview.Record),
+			TripId:     tripIDs[i],
+		}
+
+		converted, err := s.parser.ConvertToSimpleRecord(preview.Record)
+		if err != nil {
+			record.ConversionError = err.Error()
+		} else {
+			record.Converted = toConvertedRecordProto(converted)
+			record.Converted.DayType = string(s.calendar.DayType(converted.Date))
+			breakdown := s.tax.Split(converted.Amount)
+			record.Converted.TaxExclusiveAmount = int32(breakdown.Exclusive)
+			record.Converted.TaxAmount = int32(breakdown.Tax)
+		}
+
+		for _, mapping := range preview.Mappings {
+			record.Mappings = append(record.Mappings, &pb.FieldMapping{
+				Field:    mapping.Field,
+				Header:   mapping.Header,
+				Column:   int32(mapping.Column),
+				RawValue: mapping.RawValue,
+				Value:    mapping.Value,
+				Coerced:  mapping.Coerced,
+				Note:     mapping.Note,
+			})
+		}
+
+		resp.Records = append(resp.Records, record)
+	}
+
+	return resp, nil
+}
+
+// toParsedRecordProto converts a parsed CSV record to its proto representation
+func toParsedRecordProto(record parser.ActualETCRecord) *pb.ParsedRecord {
+	return &pb.ParsedRecord{
+		EntryDate:       record.EntryDate,
+		EntryTime:       record.EntryTime,
+		ExitDate:        record.ExitDate,
+		ExitTime:        record.ExitTime,
+		EntryIc:         record.EntryIC,
+		ExitIc:          record.ExitIC,
+		RouteInfo:       record.RouteInfo,
+		EtcAmount:       int32(record.ETCAmount),
+		NormalAmount:    int32(record.NormalAmount),
+		DiscountApplied: int32(record.DiscountApplied),
+		Mileage:         int32(record.Mileage),
+		VehicleClass:    pb.VehicleClass(record.VehicleClass),
+		VehicleNumber:   record.VehicleNumber,
+		CardNumber:      record.CardNumber,
+		Notes:           record.Notes,
+
+		PostPaymentAmount:    int32(record.PostPaymentAmount),
+		RedemptionBaseAmount: int32(record.RedemptionBaseAmount),
+	}
+}
+
+// toConvertedRecordProto converts a simplified record to its proto representation
+func toConvertedRecordProto(record parser.ETCRecord) *pb.ConvertedRecord {
+	return &pb.ConvertedRecord{
+		Date:        record.Date.Format("2006-01-02"),
+		EntryIc:     record.EntryIC,
+		ExitIc:      record.ExitIC,
+		Route:       record.Route,
+		VehicleType: pb.VehicleClass(record.VehicleType),
+		Amount:      int32(record.Amount),
+		CardNumber:  record.CardNumber,
+
+		NormalAmount:      int32(record.NormalAmount),
+		DiscountAmount:    int32(record.DiscountAmount),
+		EtcAmount:         int32(record.ETCAmount),
+		PostPaymentAmount: int32(record.PostPaymentAmount),
+		Mileage:           int32(record.Mileage),
+
+		RedemptionBaseAmount: int32(record.RedemptionBaseAmount),
+
+		Reversal:         record.IsReversal(),
+		CorrectionReason: string(record.Correction),
+		RouteSegments:    toRouteSegmentsProto(record.Segments),
+	}
+}
+
+// toRouteSegmentsProto converts parsed route segments to their proto representation
+func toRouteSegmentsProto(segments []parser.RouteSegment) []*pb.RouteSegment {
+	var result []*pb.RouteSegment
+	for _, segment := range segments {
+		result = append(result, &pb.RouteSegment{
+			Road: segment.Road,
+			From: segment.From,
+			To:   segment.To,
+			Via:  segment.Via,
+		})
+	}
+	return result
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/card"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/interchange"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/masterdata"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/reconcile"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/usage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// issueKinds maps reconciliation issue kinds to their proto values
+var issueKinds = map[reconcile.IssueKind]pb.ReconciliationIssueKind{
+	reconcile.IssueMissing:   pb.ReconciliationIssueKind_RECONCILIATION_ISSUE_KIND_MISSING,
+	reconcile.IssueExtra:     pb.ReconciliationIssueKind_RECONCILIATION_ISSUE_KIND_EXTRA,
+	reconcile.IssueDuplicate: pb.ReconciliationIssueKind_RECONCILIATION_ISSUE_KIND_DUPLICATE,
+}
+
+// ReconcileStatement compares imported records with the issuer's invoice totals per card and month
+func (s *DataProcessorService) ReconcileStatement(ctx context.Context, req *pb.ReconcileStatementRequest) (*pb.ReconcileStatementResponse, error) {
+	if s.usage == nil {
+		return nil, status.Error(codes.Unimplemented, "usage reporting is disabled")
+	}
+	if len(req.GetExpected()) == 0 {
+		return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "expected", "at least one expected total is required")
+	}
+	if req.GetCsvData() != "" && req.GetCsvFilePath() != "" {
+		return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "csv_data", "at most one of csv_data or csv_file_path is allowed")
+	}
+
+	expected := make([]reconcile.Expected, len(req.GetExpected()))
+	months := make([]time.Time, len(req.GetExpected()))
+	for i, total := range req.GetExpected() {
+		field := fmt.Sprintf("expected[%d]", i)
+		cardNumber := card.Normalize(total.GetCardNumber())
+		if cardNumber == "" {
+			return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, field+".card_number", "card_number is required")
+		}
+		month, err := time.Parse(reconcile.MonthLayout, total.GetMonth())
+		if err != nil {
+			return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, field+".month",
+				fmt.Sprintf("month %q must be YYYY-MM", total.GetMonth()))
+		}
+		if total.GetRecordCount() < 0 {
+			return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, field+".record_count", "record_count must not be negative")
+		}
+		expected[i] = reconcile.Expected{
+			CardNumber: cardNumber,
+			Month:      total.GetMonth(),
+			Amount:     total.GetAmount(),
+			Records:    int(total.GetRecordCount()),
+		}
+		months[i] = month
+	}
+
+	var statement []statementRow
+	if req.GetCsvData() != "" || req.GetCsvFilePath() != "" {
+		var err error
+		if statement, err = s.statementRows(req); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &pb.ReconcileStatementResponse{Matched: true}
+	for i, total := range expected {
+		filter := usage.Filter{From: months[i], To: months[i].AddDate(0, 1, -1), AccountID: req.GetAccountId()}
+		var imported []reconcile.Row
+		for _, entry := range s.usage.Entries(filter) {
+			if !invoiceCardMatches(total.CardNumber, card.Normalize(entry.CardNumber)) {
+				continue
+			}
+			// A row saved by several imports is in db_service once per import
+			for n := 0; n < max(entry.Saves, 1); n++ {
+				imported = append(imported, reconcile.Row{
+					Key:      entry.Row,
+					Date:     entry.Date,
+					EntryIC:  entry.EntryIC,
+					ExitIC:   entry.ExitIC,
+					Amount:   entry.Amount,
+					Reversal: entry.Reversal,
+				})
+			}
+		}
+
+		var statementRows []reconcile.Row
+		if statement != nil {
+			statementRows = []reconcile.Row{}
+			for _, row := range statement {
+				if row.Date.Format(reconcile.MonthLayout) == total.Month && invoiceCardMatches(total.CardNumber, row.cardNumber) {
+					statementRows = append(statementRows, row.Row)
+				}
+			}
+		}
+
+		result := reconcile.Reconcile(total, imported, statementRows)
+		resp.Matched = resp.Matched && result.Matched
+		resp.Results = append(resp.Results, toCardReconciliationProto(result))
+	}
+	return resp, nil
+}
+
+// statementRow is a row of the issuer's statement with its card number
+type statementRow struct {
+	reconcile.Row
+	cardNumber string
+}
+
+// statementRows parses the statement CSV of a reconciliation request.
+// IC names are canonicalized like imported records so the rows can be matched.
+func (s *DataProcessorService) statementRows(req *pb.ReconcileStatementRequest) ([]statementRow, error) {
+	var records []parser.ActualETCRecord
+	var err error
+	field := "csv_data"
+	if req.GetCsvFilePath() != "" {
+		field = "csv_file_path"
+		var path string
+		if path, err = resolveCSVFile(req.GetCsvFilePath()); err != nil {
+			return nil, err
+		}
+		if err := s.validator.CheckFileExists(path); err != nil {
+			return nil, err
+		}
+		records, err = s.parser.ParseFile(path)
+	} else {
+		if err := s.validator.ValidateCSVData(req.GetCsvData()); err != nil {
+			return nil, err
+		}
+		records, err = s.parser.Parse(strings.NewReader(req.GetCsvData()))
+	}
+	if err != nil {
+		return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_PARSE, field, fmt.Sprintf("invalid CSV format: %v", err))
+	}
+
+	rows := []statementRow{}
+	unmatched := interchange.NewUnmatched()
+	for _, record := range records {
+		if s.interchanges.Len() > 0 {
+			record.EntryIC, _ = s.resolveIC(record.EntryIC, unmatched)
+			record.ExitIC, _ = s.resolveIC(record.ExitIC, unmatched)
+		}
+		simpleRecord, err := s.parser.ConvertToSimpleRecord(record)
+		if err != nil {
+			continue
+		}
+		rows = append(rows, statementRow{
+			Row: reconcile.Row{
+				Key:        parser.TripKey(record),
+				Date:       simpleRecord.Date,
+				EntryIC:    simpleRecord.EntryIC,
+				ExitIC:     simpleRecord.ExitIC,
+				Amount:     simpleRecord.Amount,
+				Reversal:   simpleRecord.IsReversal(),
+				LineNumber: record.LineNumber,
+			},
+			cardNumber: card.Normalize(record.CardNumber),
+		})
+	}
+	return rows, nil
+}
+
+// invoiceCardMatches compares an invoice card number with a statement one. Invoices often list
+// only the trailing digits, so the shorter number is treated as masked in front.
+func invoiceCardMatches(invoice, number string) bool {
+	if len(invoice) < len(number) {
+		invoice = strings.Repeat("*", len(number)-len(invoice)) + invoice
+	} else if len(number) < len(invoice) {
+		number = strings.Repeat("*", len(invoice)-len(number)) + number
+	}
+	return masterdata.CardMatches(invoice, number)
+}
+
+// toCardReconciliationProto converts a reconciliation result to its proto representation
+func toCardReconciliationProto(result reconcile.Result) *pb.CardReconciliation {
+	reconciliation := &pb.CardReconciliation{
+		CardNumber:          result.CardNumber,
+		Month:               result.Month,
+		ExpectedAmount:      result.Amount,
+		ImportedAmount:      result.ImportedAmount,
+		Difference:          result.Difference,
+		ExpectedRecordCount: int32(result.Records),
+		ImportedRecordCount: int32(result.ImportedRecords),
+		Matched:             result.Matched,
+	}
+	for _, issue := range result.Issues {
+		date := ""
+		if !issue.Row.Date.IsZero() {
+			date = issue.Row.Date.Format("2006-01-02")
+		}
+		reconciliation.Issues = append(reconciliation.Issues, &pb.ReconciliationIssue{
+			Kind:       issueKinds[issue.Kind],
+			Date:       date,
+			EntryIc:    issue.Row.EntryIC,
+			ExitIc:     issue.Row.ExitIC,
+			Amount:     int64(issue.Row.Amount),
+			LineNumber: int32(issue.Row.LineNumber),
+			Note:       issue.Note,
+		})
+	}
+	return reconciliation
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+)
+
+// charge is a trip saved earlier in the request that a later correction row may reverse
+type charge struct {
+	lineNumber int
+	record     parser.ETCRecord
+	reversed   bool
+}
+
+// tripLedger remembers saved charges by trip so correction rows can be linked to the record they reverse
+type tripLedger struct {
+	charges map[string][]*charge
+}
+
+// newTripLedger creates an empty ledger
+func newTripLedger() *tripLedger {
+	return &tripLedger{charges: make(map[string][]*charge)}
+}
+
+// find returns the first charge for the trip that has not been reversed yet, preferring one with the given amount
+func (l *tripLedger) find(tripKey string, amount int) *charge {
+	var fallback *charge
+	for _, c := range l.charges[tripKey] {
+		if c.reversed {
+			continue
+		}
+		if c.record.Amount == amount {
+			return c
+		}
+		if fallback == nil {
+			fallback = c
+		}
+	}
+	return fallback
+}
+
+// offset returns the earlier charge of the same trip that a row cancels without being marked as a correction:
+// the row repeats the charged fare and its ＥＴＣ割引額 returns exactly the discount the charge was given.
+// Rows with a positive discount that does not offset an earlier charge stay regular charges.
+func (l *tripLedger) offset(tripKey string, record parser.ActualETCRecord) *charge {
+	if record.DiscountApplied <= 0 || parser.DetectCorrection(record) != parser.CorrectionNone {
+		return nil
+	}
+	for _, c := range l.charges[tripKey] {
+		if !c.reversed && c.record.ETCAmount == record.ETCAmount && c.record.DiscountAmount == -record.DiscountApplied {
+			return c
+		}
+	}
+	return nil
+}
+
+// link returns the earlier charge of the same trip that a correction row reverses.
+// earlier is the charge found by offset, if any; the row is turned into a reversal of it.
+// Otherwise only rows detected as corrections (parser.DetectCorrection) are linked and other rows return nil.
+func (l *tripLedger) link(tripKey string, simpleRecord *parser.ETCRecord, earlier *charge) *charge {
+	if earlier != nil {
+		simpleRecord.Correction = parser.CorrectionEarlierTrip
+		simpleRecord.Amount = -simpleRecord.Amount
+		return earlier
+	}
+	if !simpleRecord.IsReversal() {
+		return nil
+	}
+	return l.find(tripKey, -simpleRecord.Amount)
+}
+
+// record adds a saved row to the ledger, marking the charge it reverses
+func (l *tripLedger) record(tripKey string, lineNumber int, simpleRecord parser.ETCRecord, original *charge) {
+	if original != nil {
+		original.reversed = true
+	}
+	if simpleRecord.IsReversal() {
+		return
+	}
+	l.charges[tripKey] = append(l.charges[tripKey], &charge{lineNumber: lineNumber, record: simpleRecord})
+}
+
+// reversalPayload identifies the original charge in the DB payload of a reversal
+func (c *charge) reversalPayload() map[string]interface{} {
+	return map[string]interface{}{
+		"line_number": c.lineNumber,
+		"date":        c.record.Date.Format("2006-01-02"),
+		"entry_ic":    c.record.EntryIC,
+		"exit_ic":     c.record.ExitIC,
+		"amount":      c.record.Amount,
+		"card_number": c.record.CardNumber,
+	}
+}
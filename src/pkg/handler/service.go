@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sort"
@@ -11,9 +12,24 @@ import (
 	"time"
 
 	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/anomaly"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/card"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/discount"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/export"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/holiday"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/idempotency"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/interchange"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/journal"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/logging"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/masterdata"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/metrics"
 	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/tax"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/tracing"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/usage"
+	"go.opentelemetry.io/otel/attribute"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 const (
@@ -71,11 +87,38 @@ func resolveCSVFilePath(providedPath string) (string, error) {
 	return latestDirPath, nil
 }
 
+// resolveCSVFile resolves the csv_file_path of an RPC that reads a single file the same way
+// ProcessCSVFile does. A path that resolves to a directory (such as the latest folder under
+// CSV_BASE_PATH) must contain exactly one CSV file.
+func resolveCSVFile(providedPath string) (string, error) {
+	resolvedPath, err := resolveCSVFilePath(providedPath)
+	if err != nil {
+		return "", statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "csv_file_path",
+			fmt.Sprintf("failed to resolve CSV file path: %v", err))
+	}
+
+	if fileInfo, err := os.Stat(resolvedPath); err == nil && fileInfo.IsDir() {
+		csvFiles, _ := filepath.Glob(filepath.Join(resolvedPath, "*.csv"))
+		if len(csvFiles) != 1 {
+			return "", statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "csv_file_path",
+				fmt.Sprintf("%s contains %d CSV files, exactly one is required", resolvedPath, len(csvFiles)))
+		}
+		resolvedPath = csvFiles[0]
+	}
+	return resolvedPath, nil
+}
+
 // DBClient interface for database operations
 type DBClient interface {
 	SaveETCData(data interface{}) error
 }
 
+// ContextDBClient is implemented by database clients that pass the request context
+// (deadline and trace context) on to the database
+type ContextDBClient interface {
+	SaveETCDataContext(ctx context.Context, data interface{}) error
+}
+
 // Parser interface for CSV parsing operations
 type Parser interface {
 	ParseFile(filePath string) ([]parser.ActualETCRecord, error)
@@ -84,51 +127,141 @@ type Parser interface {
 	ConvertToSimpleRecord(record parser.ActualETCRecord) (parser.ETCRecord, error)
 }
 
+// FileInfoParser is implemented by parsers that can report the detected format and encoding of a file
+type FileInfoParser interface {
+	ParseFileWithInfo(filePath string) ([]parser.ActualETCRecord, parser.FileInfo, error)
+}
+
 // DataProcessorService implements the gRPC service
 type DataProcessorService struct {
 	pb.UnimplementedDataProcessorServiceServer
-	dbClient  DBClient
-	parser    Parser
-	validator Validator
+	dbClient     DBClient
+	parser       Parser
+	validator    Validator
+	idempotency  idempotency.Store
+	cardMask     card.MaskPolicy
+	masterData   *masterdata.Registry
+	interchanges *interchange.Dictionary
+	tripOptions  parser.StitchOptions
+	discounts    *discount.Engine
+	calendar     *holiday.Calendar
+	usage        usage.Store
+	journal      journal.Settings
+	tax          tax.Calculator
+	anomalies    *anomaly.Detector
+	findings     anomaly.Store
+	archive      export.Store
+	logger       *slog.Logger
+	metrics      *metrics.Metrics
 }
 
 // NewDataProcessorService creates a new service instance
 func NewDataProcessorService(dbClient DBClient) *DataProcessorService {
-	return &DataProcessorService{
-		dbClient:  dbClient,
-		parser:    parser.NewETCCSVParser(),
-		validator: NewDefaultValidator(),
-	}
+	return newService(dbClient, parser.NewETCCSVParser(), NewDefaultValidator())
 }
 
 // NewDataProcessorServiceWithValidator creates a service with custom validator
 func NewDataProcessorServiceWithValidator(dbClient DBClient, validator Validator) *DataProcessorService {
-	return &DataProcessorService{
-		dbClient:  dbClient,
-		parser:    parser.NewETCCSVParser(),
-		validator: validator,
-	}
+	return newService(dbClient, parser.NewETCCSVParser(), validator)
 }
 
 // NewDataProcessorServiceWithDependencies creates a service with custom dependencies
 func NewDataProcessorServiceWithDependencies(dbClient DBClient, csvParser Parser, validator Validator) *DataProcessorService {
+	return newService(dbClient, csvParser, validator)
+}
+
+// newService creates a service with the default settings shared by all constructors
+func newService(dbClient DBClient, csvParser Parser, validator Validator) *DataProcessorService {
 	return &DataProcessorService{
-		dbClient:  dbClient,
-		parser:    csvParser,
-		validator: validator,
+		dbClient:     dbClient,
+		parser:       csvParser,
+		validator:    validator,
+		idempotency:  idempotency.NewMemoryStore(idempotency.DefaultTTL),
+		cardMask:     card.MaskLast4,
+		masterData:   masterdata.NewRegistry(),
+		interchanges: interchange.NewDictionary(),
+		calendar:     holiday.New(),
+		usage:        usage.NewMemoryStore(),
+		tax:          tax.NewCalculator(tax.DefaultRound),
+		findings:     anomaly.NewMemoryStore(),
+		logger:       slog.Default(),
+	}
+}
+
+// SetMasterData replaces the card-to-vehicle/driver registry used to enrich records; nil resets it to an empty registry
+func (s *DataProcessorService) SetMasterData(registry *masterdata.Registry) {
+	if registry == nil {
+		registry = masterdata.NewRegistry()
 	}
+	s.masterData = registry
+}
+
+// SetHolidayCalendar replaces the calendar used to classify days and for holiday discounts; nil resets it to the built-in national holidays
+func (s *DataProcessorService) SetHolidayCalendar(calendar *holiday.Calendar) {
+	if calendar == nil {
+		calendar = holiday.New()
+	}
+	s.calendar = calendar
+	if s.discounts != nil {
+		s.discounts.SetCalendar(calendar)
+	}
+}
+
+// SetTaxCalculator sets the consumption tax rate and rounding used to split record amounts
+func (s *DataProcessorService) SetTaxCalculator(calculator tax.Calculator) {
+	s.tax = calculator
+}
+
+// SetCardMaskPolicy sets how card numbers appear in error messages and validation record data
+func (s *DataProcessorService) SetCardMaskPolicy(policy card.MaskPolicy) {
+	s.cardMask = policy
+}
+
+// SetLogger sets the logger for parse and save events; nil resets it to slog.Default().
+// Request and account IDs come from the context (see the logging package).
+func (s *DataProcessorService) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	s.logger = logger
+}
+
+// SetMetrics enables import throughput metrics; nil disables them
+func (s *DataProcessorService) SetMetrics(m *metrics.Metrics) {
+	s.metrics = m
+}
+
+// SetIdempotencyStore replaces the store used for idempotency keys; nil disables idempotency handling
+func (s *DataProcessorService) SetIdempotencyStore(store idempotency.Store) {
+	s.idempotency = store
 }
 
 // ProcessCSVFile processes a CSV file from filesystem
 func (s *DataProcessorService) ProcessCSVFile(ctx context.Context, req *pb.ProcessCSVFileRequest) (*pb.ProcessCSVFileResponse, error) {
+	resp, replayed, err := runIdempotent(ctx, s.idempotency, "ProcessCSVFile", req, func(saved *savedRecords) (*pb.ProcessCSVFileResponse, error) {
+		return s.processCSVFile(ctx, req, saved)
+	})
+	if replayed {
+		s.logger.InfoContext(ctx, "replayed stored response for idempotency key")
+		resp.Replayed = true
+	}
+	return resp, err
+}
+
+// processCSVFile implements ProcessCSVFile once idempotency has been checked
+func (s *DataProcessorService) processCSVFile(ctx context.Context, req *pb.ProcessCSVFileRequest, saved *savedRecords) (*pb.ProcessCSVFileResponse, error) {
+	ctx = withLogAccount(ctx, req.GetAccountId())
+
 	// Validate request using validator
 	if err := ValidateProcessCSVFileRequest(req, s.validator); err != nil {
 		return nil, err
 	}
 
 	// Resolve CSV file path (may use CSV_BASE_PATH to find latest folder)
+	_, resolveSpan := tracing.Start(ctx, "resolveCSVFilePath", attribute.String("csv_file_path", req.GetCsvFilePath()))
 	resolvedPath, err := resolveCSVFilePath(req.GetCsvFilePath())
 	if err != nil {
+		tracing.End(resolveSpan, err)
 		return &pb.ProcessCSVFileResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to resolve CSV file path: %v", err),
@@ -139,16 +272,15 @@ func (s *DataProcessorService) ProcessCSVFile(ctx context.Context, req *pb.Proce
 		}, nil
 	}
 
-	var allRecords []parser.ActualETCRecord
-	var parseErrors []string
-	var fileCount int
+	var csvFiles []string
 
 	// Check if resolved path is a directory (only if it exists)
 	fileInfo, err := os.Stat(resolvedPath)
 	if err == nil && fileInfo.IsDir() {
 		// Process all CSV files in directory
-		csvFiles, err := filepath.Glob(filepath.Join(resolvedPath, "*.csv"))
+		csvFiles, err = filepath.Glob(filepath.Join(resolvedPath, "*.csv"))
 		if err != nil {
+			tracing.End(resolveSpan, err)
 			return &pb.ProcessCSVFileResponse{
 				Success: false,
 				Message: fmt.Sprintf("Failed to search for CSV files: %v", err),
@@ -160,6 +292,8 @@ func (s *DataProcessorService) ProcessCSVFile(ctx context.Context, req *pb.Proce
 		}
 
 		if len(csvFiles) == 0 {
+			resolveSpan.SetAttributes(attribute.String("resolved_path", resolvedPath), attribute.Int("files", 0))
+			tracing.End(resolveSpan, nil)
 			return &pb.ProcessCSVFileResponse{
 				Success: false,
 				Message: "No CSV files found in directory",
@@ -169,80 +303,269 @@ func (s *DataProcessorService) ProcessCSVFile(ctx context.Context, req *pb.Proce
 				Errors: []string{"no CSV files found in " + resolvedPath},
 			}, nil
 		}
-
-		fileCount = len(csvFiles)
-
-		// Parse each CSV file
-		for _, csvFile := range csvFiles {
-			records, err := s.parser.ParseFile(csvFile)
-			if err != nil {
-				parseErrors = append(parseErrors, fmt.Sprintf("Failed to parse %s: %v", filepath.Base(csvFile), err))
-				continue
-			}
-			allRecords = append(allRecords, records...)
-		}
 	} else {
 		// Single file processing (or error will be caught by parser)
-		fileCount = 1
-		records, err := s.parser.ParseFile(resolvedPath)
-		if err != nil {
+		csvFiles = []string{resolvedPath}
+	}
+	resolveSpan.SetAttributes(attribute.String("resolved_path", resolvedPath), attribute.Int("files", len(csvFiles)))
+	tracing.End(resolveSpan, nil)
+
+	// Duplicate keys are shared across files so the same trip in two statements is only saved once
+	opts := processOptions{
+		accountID:      req.GetAccountId(),
+		skipDuplicates: getSkipDuplicatesDefault(),
+		dryRun:         req.GetDryRun(),
+		stitchTrips:    req.GetStitchTrips(),
+		processedKeys:  make(map[string]bool),
+		saved:          saved,
+		skipImported:   req.GetDryRun(),
+		trips:          newTripLedger(),
+		unmatchedICs:   interchange.NewUnmatched(),
+		jobID:          newImportJobID(),
+		importedAt:     time.Now(),
+	}
+
+	stats := &pb.ProcessingStats{}
+	var allErrors []string
+	var recordErrors []*pb.RecordError
+	var fileResults []*pb.FileResult
+	var dryRunRecords []*pb.DryRunRecord
+	var trips []*pb.Trip
+	var anomalies []*pb.AnomalyFinding
+
+	// Parse every file first so the anomaly rules see the trips of all files together
+	files := make([]*parsedFile, len(csvFiles))
+	batches := make([][]parser.ActualETCRecord, len(csvFiles))
+	for i, csvFile := range csvFiles {
+		files[i] = s.parseFile(ctx, csvFile)
+		batches[i] = files[i].records
+	}
+	detected := s.detectAnomalies(batches...)
+
+	for i, file := range files {
+		fileOpts := opts
+		fileOpts.anomalies = &detected[i]
+		result, err := s.processFile(ctx, file, fileOpts)
+		if err != nil && len(csvFiles) == 1 {
 			return &pb.ProcessCSVFileResponse{
 				Success: false,
 				Message: fmt.Sprintf("Failed to parse CSV file: %v", err),
 				Stats: &pb.ProcessingStats{
 					TotalRecords: 0,
 				},
-				Errors: []string{err.Error()},
+				Errors:       []string{err.Error()},
+				FileResults:  []*pb.FileResult{result},
+				RecordErrors: result.RecordErrors,
+				DryRun:       opts.dryRun,
 			}, nil
 		}
-		allRecords = records
-	}
-
-	// Get skip_duplicates setting from environment or default
-	skipDuplicates := getSkipDuplicatesDefault()
-
-	// Process records
-	stats, errors := s.processRecords(ctx, allRecords, req.GetAccountId(), skipDuplicates)
+		if err != nil {
+			allErrors = append(allErrors, fmt.Sprintf("Failed to parse %s: %v", filepath.Base(file.path), err))
+		} else {
+			allErrors = append(allErrors, result.Errors...)
+		}
+		recordErrors = append(recordErrors, result.RecordErrors...)
+		dryRunRecords = append(dryRunRecords, result.DryRunRecords...)
+		trips = append(trips, result.Trips...)
+		anomalies = append(anomalies, result.Anomalies...)
 
-	// Combine parse errors with processing errors
-	allErrors := append(parseErrors, errors...)
+		addStats(stats, result.Stats)
+		fileResults = append(fileResults, result)
+	}
 
 	return &pb.ProcessCSVFileResponse{
 		Success: stats.SavedRecords > 0,
-		Message: fmt.Sprintf("Processed %d records from %d file(s): %d saved, %d skipped, %d errors",
-			stats.TotalRecords, fileCount, stats.SavedRecords, stats.SkippedRecords, stats.ErrorRecords),
-		Stats:   stats,
-		Errors:  allErrors,
+		Message: dryRunPrefix(opts.dryRun) + fmt.Sprintf("Processed %d records from %d file(s): %d saved, %d skipped, %d errors",
+			stats.TotalRecords, len(csvFiles), stats.SavedRecords, stats.SkippedRecords, stats.ErrorRecords),
+		Stats:         stats,
+		Errors:        allErrors,
+		FileResults:   fileResults,
+		RecordErrors:  recordErrors,
+		DryRun:        opts.dryRun,
+		DryRunRecords: dryRunRecords,
+		UnmatchedIcs:  unmatchedICsToProto(opts.unmatchedICs),
+		Trips:         trips,
+		Anomalies:     anomalies,
+		ImportJobId:   opts.jobID,
 	}, nil
 }
 
+// parsedFile is a CSV file of a request, parsed before any file is processed
+type parsedFile struct {
+	path     string
+	records  []parser.ActualETCRecord
+	info     parser.FileInfo
+	duration time.Duration
+	err      error
+}
+
+// parseFile parses a single CSV file; a parse failure is kept in the result
+func (s *DataProcessorService) parseFile(ctx context.Context, path string) *parsedFile {
+	start := time.Now()
+	file := &parsedFile{path: path}
+
+	_, parseSpan := tracing.Start(ctx, "parseFile", attribute.String("file", path))
+	if infoParser, ok := s.parser.(FileInfoParser); ok {
+		file.records, file.info, file.err = infoParser.ParseFileWithInfo(path)
+	} else {
+		file.records, file.err = s.parser.ParseFile(path)
+	}
+	parseSpan.SetAttributes(attribute.String("format", file.info.Format), attribute.String("encoding", file.info.Encoding),
+		attribute.Int("records", len(file.records)))
+	tracing.End(parseSpan, file.err)
+	file.duration = time.Since(start)
+
+	if file.err != nil {
+		s.logger.ErrorContext(ctx, "failed to parse file", "file", path, "error", file.err)
+		return file
+	}
+	s.logger.InfoContext(ctx, "parsed file", "file", path, "format", file.info.Format, "encoding", file.info.Encoding,
+		"records", len(file.records), "duration_ms", file.duration.Milliseconds())
+	return file
+}
+
+// processFile processes the records of a parsed CSV file, returning its per-file result.
+// A parse failure is returned as an error alongside a result describing the failed file.
+func (s *DataProcessorService) processFile(ctx context.Context, file *parsedFile, opts processOptions) (result *pb.FileResult, err error) {
+	path := file.path
+	ctx, span := tracing.Start(ctx, "processFile", attribute.String("file", path))
+	defer func() {
+		span.SetAttributes(
+			attribute.Int("records.total", int(result.Stats.TotalRecords)),
+			attribute.Int("records.saved", int(result.Stats.SavedRecords)),
+			attribute.Int("records.skipped", int(result.Stats.SkippedRecords)),
+			attribute.Int("records.errored", int(result.Stats.ErrorRecords)),
+		)
+		tracing.End(span, err)
+	}()
+
+	start := time.Now()
+	result = &pb.FileResult{
+		FilePath: path,
+		Format:   file.info.Format,
+		Encoding: file.info.Encoding,
+		Stats:    &pb.ProcessingStats{},
+	}
+
+	records, err := file.records, file.err
+	if err != nil {
+		result.Errors = []string{err.Error()}
+		result.RecordErrors = []*pb.RecordError{newFileError(pb.ErrorCode_ERROR_CODE_PARSE, path, err.Error())}
+		result.DurationMs = (file.duration + time.Since(start)).Milliseconds()
+		return result, err
+	}
+
+	opts.filePath = path
+	opts.format = result.Format
+	processed := s.processRecords(ctx, records, opts)
+	for _, recordError := range processed.errors {
+		recordError.FilePath = path
+	}
+	for _, dryRunRecord := range processed.dryRunRecords {
+		dryRunRecord.FilePath = path
+	}
+	for _, trip := range processed.trips {
+		trip.FilePath = path
+	}
+	result.Stats = processed.stats
+	result.RecordErrors = processed.errors
+	result.Errors = errorMessages(processed.errors)
+	result.DryRunRecords = processed.dryRunRecords
+	result.Trips = processed.trips
+	result.Anomalies = processed.anomalies
+	result.DurationMs = (file.duration + time.Since(start)).Milliseconds()
+	return result, nil
+}
+
+// dryRunPrefix marks response messages of dry runs, where "saved" means "would be saved"
+func dryRunPrefix(dryRun bool) string {
+	if dryRun {
+		return "[dry run] "
+	}
+	return ""
+}
+
+// addStats accumulates per-file statistics into a running total
+func addStats(total, stats *pb.ProcessingStats) {
+	total.TotalRecords += stats.TotalRecords
+	total.SavedRecords += stats.SavedRecords
+	total.SkippedRecords += stats.SkippedRecords
+	total.ErrorRecords += stats.ErrorRecords
+	total.ReversalRecords += stats.ReversalRecords
+	total.NetAmount += stats.NetAmount
+	total.UnknownCardRecords += stats.UnknownCardRecords
+	total.DiscountMismatchRecords += stats.DiscountMismatchRecords
+	total.ClaimableDiscount += stats.ClaimableDiscount
+	total.TaxExclusiveAmount += stats.TaxExclusiveAmount
+	total.TaxAmount += stats.TaxAmount
+	total.RecordTaxAmount += stats.RecordTaxAmount
+	total.AnomalyRecords += stats.AnomalyRecords
+}
+
 // ProcessCSVData processes CSV data directly
 func (s *DataProcessorService) ProcessCSVData(ctx context.Context, req *pb.ProcessCSVDataRequest) (*pb.ProcessCSVDataResponse, error) {
+	resp, replayed, err := runIdempotent(ctx, s.idempotency, "ProcessCSVData", req, func(saved *savedRecords) (*pb.ProcessCSVDataResponse, error) {
+		return s.processCSVData(ctx, req, saved)
+	})
+	if replayed {
+		s.logger.InfoContext(ctx, "replayed stored response for idempotency key")
+		resp.Replayed = true
+	}
+	return resp, err
+}
+
+// processCSVData implements ProcessCSVData once idempotency has been checked
+func (s *DataProcessorService) processCSVData(ctx context.Context, req *pb.ProcessCSVDataRequest, saved *savedRecords) (*pb.ProcessCSVDataResponse, error) {
+	ctx = withLogAccount(ctx, req.GetAccountId())
+
 	// Validate request using validator
 	if err := ValidateProcessCSVDataRequest(req, s.validator); err != nil {
 		return nil, err
 	}
 
 	// Parse CSV data
+	_, parseSpan := tracing.Start(ctx, "parseCSVData", attribute.Int("bytes", len(req.CsvData)))
 	reader := strings.NewReader(req.CsvData)
 	records, err := s.parser.Parse(reader)
+	parseSpan.SetAttributes(attribute.Int("records", len(records)))
+	tracing.End(parseSpan, err)
 	if err != nil {
+		s.logger.WarnContext(ctx, "failed to parse CSV data", "error", err)
 		// All parsing errors should be treated as invalid format for API
-		return nil, status.Errorf(codes.InvalidArgument, "invalid CSV format: %v", err)
+		return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_PARSE, "csv_data", fmt.Sprintf("invalid CSV format: %v", err))
 	}
 
-	// Get skip_duplicates setting from environment or default
-	skipDuplicates := getSkipDuplicatesDefault()
-
 	// Process records
-	stats, errors := s.processRecords(ctx, records, req.GetAccountId(), skipDuplicates)
+	opts := processOptions{
+		accountID:      req.GetAccountId(),
+		skipDuplicates: getSkipDuplicatesDefault(),
+		dryRun:         req.GetDryRun(),
+		stitchTrips:    req.GetStitchTrips(),
+		processedKeys:  make(map[string]bool),
+		saved:          saved,
+		skipImported:   req.GetDryRun(),
+		trips:          newTripLedger(),
+		unmatchedICs:   interchange.NewUnmatched(),
+		jobID:          newImportJobID(),
+		importedAt:     time.Now(),
+	}
+	s.logger.InfoContext(ctx, "parsed CSV data", "records", len(records))
+	result := s.processRecords(ctx, records, opts)
+	stats := result.stats
 
 	return &pb.ProcessCSVDataResponse{
 		Success: stats.SavedRecords > 0,
-		Message: fmt.Sprintf("Processed %d records: %d saved, %d skipped, %d errors",
+		Message: dryRunPrefix(req.GetDryRun()) + fmt.Sprintf("Processed %d records: %d saved, %d skipped, %d errors",
 			stats.TotalRecords, stats.SavedRecords, stats.SkippedRecords, stats.ErrorRecords),
-		Stats:   stats,
-		Errors:  errors,
+		Stats:         stats,
+		Errors:        errorMessages(result.errors),
+		RecordErrors:  result.errors,
+		DryRun:        req.GetDryRun(),
+		DryRunRecords: result.dryRunRecords,
+		UnmatchedIcs:  unmatchedICsToProto(opts.unmatchedICs),
+		Trips:         result.trips,
+		Anomalies:     result.anomalies,
+		ImportJobId:   opts.jobID,
 	}, nil
 }
 
@@ -298,7 +621,14 @@ func (s *DataProcessorService) ValidateCSVData(ctx context.Context, req *pb.Vali
 				LineNumber:  int32(i + 2), // +2 for header and 1-based indexing
 				Field:       "",
 				Message:     err.Error(),
-				RecordData:  fmt.Sprintf("%v", record),
+				RecordData:  s.recordData(record),
+			})
+		} else if err := card.Validate(record.CardNumber); err != nil {
+			validationErrors = append(validationErrors, &pb.ValidationError{
+				LineNumber: int32(i + 2),
+				Field:      "card_number",
+				Message:    err.Error(),
+				RecordData: s.recordData(record),
 			})
 		}
 	}
@@ -324,72 +654,503 @@ func (s *DataProcessorService) HealthCheck(ctx context.Context, req *pb.HealthCh
 	}, nil
 }
 
+// processOptions controls how processRecords handles a batch of parsed records
+type processOptions struct {
+	accountID      string
+	skipDuplicates bool
+	// dryRun runs the full pipeline but reports the planned outcome instead of saving
+	dryRun bool
+	// stitchTrips groups consecutive rows of the same card into trips and tags saved records with their trip ID
+	stitchTrips bool
+	// processedKeys tracks records already saved in this request and is updated in place
+	processedKeys map[string]bool
+	// skipImported also treats records saved by earlier imports (per the usage store) as duplicates,
+	// so a dry run reports what a re-import would add
+	skipImported bool
+	// saved holds the records an interrupted attempt with the same idempotency key already saved; nil without a key
+	saved *savedRecords
+	// trips links refund and correction rows to the charges they reverse and is updated in place
+	trips *tripLedger
+	// unmatchedICs collects IC names missing from the interchange dictionary and is updated in place
+	unmatchedICs *interchange.Unmatched
+	// filePath is the file the records were read from; empty for CSV data
+	filePath string
+	// jobID and importedAt tag the records kept for export
+	jobID      string
+	importedAt time.Time
+	// export collects export records even in dry-run mode, for exports that bypass the record store
+	export bool
+	// journal collects a journal entry for every saved record with a non-zero amount, for ExportJournal
+	journal bool
+	// format is the detected file format, used as a metrics label; empty for CSV data
+	format string
+	// anomalies holds the findings the caller detected over all files of the request; nil detects them over the records
+	anomalies *batchAnomalies
+}
+
+// processResult is the outcome of processRecords
+type processResult struct {
+	stats         *pb.ProcessingStats
+	errors        []*pb.RecordError
+	dryRun        bool
+	dryRunRecords []*pb.DryRunRecord
+	trips         []*pb.Trip
+	anomalies     []*pb.AnomalyFinding
+	exported      []export.Record
+	entries       []journal.Entry
+}
+
+// acceptedRecord is a record that passed validation, deduplication and conversion
+type acceptedRecord struct {
+	index        int
+	record       parser.ActualETCRecord // with canonical IC names
+	simpleRecord parser.ETCRecord
+	key          string  // duplicate key
+	original     *charge // charge the record reverses, if any
+	entryICCode  string
+	exitICCode   string
+}
+
+// plan records the planned outcome of a record; it is a no-op unless running in dry-run mode
+func (r *processResult) plan(action pb.DryRunAction, reason pb.ErrorCode, index int, record parser.ActualETCRecord, payload map[string]interface{}) {
+	if !r.dryRun {
+		return
+	}
+	dryRunRecord := &pb.DryRunRecord{
+		RecordIndex: int32(index + 1),
+		LineNumber:  int32(record.LineNumber),
+		Action:      action,
+		Reason:      reason,
+	}
+	if payload != nil {
+		// The payload only holds JSON-compatible values, so conversion cannot fail
+		dryRunRecord.Payload, _ = structpb.NewStruct(payload)
+	}
+	r.dryRunRecords = append(r.dryRunRecords, dryRunRecord)
+}
+
 // processRecords processes parsed records and saves to database
-func (s *DataProcessorService) processRecords(ctx context.Context, records []parser.ActualETCRecord, accountID string, skipDuplicates bool) (*pb.ProcessingStats, []string) {
-	stats := &pb.ProcessingStats{
-		TotalRecords:   int32(len(records)),
-		SavedRecords:   0,
-		SkippedRecords: 0,
-		ErrorRecords:   0,
+func (s *DataProcessorService) processRecords(ctx context.Context, records []parser.ActualETCRecord, opts processOptions) *processResult {
+	result := &processResult{
+		stats: &pb.ProcessingStats{
+			TotalRecords:   int32(len(records)),
+			SavedRecords:   0,
+			SkippedRecords: 0,
+			ErrorRecords:   0,
+		},
+		dryRun: opts.dryRun,
+	}
+	stats := result.stats
+
+	ctx = withLogAccount(ctx, opts.accountID)
+
+	// Only imports count towards throughput metrics; dry runs and exports are previews
+	observe := !opts.dryRun
+	if observe {
+		s.metrics.AddPending(len(records))
+		defer s.metrics.AddPending(-len(records))
+	}
+
+	// The records of one call form one statement, whose tax is computed on its total
+	statement := s.tax.NewStatement()
+
+	// Anomaly rules see the whole batch (or request, when the caller detected them); findings are reported for the records that get saved
+	detected := opts.anomalies
+	if detected == nil {
+		detected = &s.detectAnomalies(records)[0]
 	}
+	anomalies, anomalyTrips := detected.findings, detected.trips
+	var savedTrips []anomaly.Trip
+	var savedFindings []anomaly.Finding
 
-	var errors []string
-	processedKeys := make(map[string]bool)
+	collect := opts.export || (s.archive != nil && !opts.dryRun)
 
+	// Screen every record first: validation, deduplication and conversion decide which records are accepted,
+	// so trips are stitched from the accepted records only
+	var accepted []acceptedRecord
 	for i, record := range records {
 		// Check context cancellation
 		if ctx.Err() != nil {
-			errors = append(errors, fmt.Sprintf("Processing cancelled at record %d", i))
-			stats.ErrorRecords = int32(len(records) - i)
+			s.logger.WarnContext(ctx, "processing cancelled", "file", opts.filePath, "record", i+1, "error", ctx.Err())
+			result.errors = append(result.errors, newRecordError(pb.ErrorCode_ERROR_CODE_CANCELLED, i, record, "",
+				fmt.Sprintf("Processing cancelled at record %d", i)))
+			stats.ErrorRecords += int32(len(records) - i + len(accepted))
+			accepted = nil
 			break
 		}
 
+		// Reject malformed card numbers before they reach the database
+		if err := card.Validate(record.CardNumber); err != nil {
+			s.logger.WarnContext(ctx, "record rejected", s.recordAttrs(opts, record, "error", err)...)
+			result.errors = append(result.errors, newRecordError(pb.ErrorCode_ERROR_CODE_VALIDATION, i, record, "card_number",
+				fmt.Sprintf("Record %d: %v (card: %s)", i+1, err, s.cardMask.Mask(record.CardNumber))))
+			result.plan(pb.DryRunAction_DRY_RUN_ACTION_REJECT, pb.ErrorCode_ERROR_CODE_VALIDATION, i, record, nil)
+			stats.ErrorRecords++
+			continue
+		}
+
+		// An unrecognised 車種 label parses to 0, which is not a class db_service can store
+		if err := record.VehicleClass.Validate(); err != nil {
+			s.logger.WarnContext(ctx, "record rejected", s.recordAttrs(opts, record, "error", err)...)
+			result.errors = append(result.errors, newRecordError(pb.ErrorCode_ERROR_CODE_VALIDATION, i, record, "vehicle_class",
+				fmt.Sprintf("Record %d: %v", i+1, err)))
+			result.plan(pb.DryRunAction_DRY_RUN_ACTION_REJECT, pb.ErrorCode_ERROR_CODE_VALIDATION, i, record, nil)
+			stats.ErrorRecords++
+			continue
+		}
+
+		// Use canonical IC names so the same interchange hashes the same across statements
+		entryICCode, exitICCode := "", ""
+		if s.interchanges.Len() > 0 {
+			record.EntryIC, entryICCode = s.resolveIC(record.EntryIC, opts.unmatchedICs)
+			record.ExitIC, exitICCode = s.resolveIC(record.ExitIC, opts.unmatchedICs)
+		}
+
+		// A row returning the discount of an earlier charge of the same trip cancels that charge
+		tripKey := parser.TripKey(record)
+		earlier := opts.trips.offset(tripKey, record)
+
 		// Create unique key for duplicate detection
 		key := fmt.Sprintf("%s_%s_%s_%s_%d_%s",
 			record.EntryDate, record.EntryTime,
 			record.ExitDate, record.ExitTime,
 			record.ETCAmount, record.CardNumber)
+		if parser.DetectCorrection(record) != parser.CorrectionNone || earlier != nil {
+			// A correction row must not be mistaken for a duplicate of the charge it reverses
+			key += "_correction"
+		}
+
+		// A retry after cancellation must not save the records the interrupted attempt already saved
+		if !opts.dryRun && opts.saved.has(key) {
+			s.logger.DebugContext(ctx, "record saved by an earlier attempt skipped", s.recordAttrs(opts, record)...)
+			stats.SkippedRecords++
+			opts.processedKeys[key] = true
+			result.errors = append(result.errors, newRecordError(pb.ErrorCode_ERROR_CODE_DUPLICATE, i, record, "",
+				fmt.Sprintf("Record %d: skipped (already saved by an earlier attempt with this idempotency key)", i+1)))
+			continue
+		}
 
 		// Skip duplicates if requested
-		if skipDuplicates && processedKeys[key] {
+		duplicate := opts.skipDuplicates && (opts.processedKeys[key] || opts.skipImported && s.imported(opts.accountID, key))
+		if opts.skipDuplicates && observe {
+			s.metrics.ObserveDedup(duplicate)
+		}
+		if duplicate {
+			s.logger.DebugContext(ctx, "duplicate record skipped", s.recordAttrs(opts, record)...)
 			stats.SkippedRecords++
-			errors = append(errors, fmt.Sprintf("Record %d: skipped (duplicate): %s %s -> %s %s, amount: %d",
-				i+1, record.EntryDate, record.EntryTime, record.ExitDate, record.ExitTime, record.ETCAmount))
+			result.errors = append(result.errors, newRecordError(pb.ErrorCode_ERROR_CODE_DUPLICATE, i, record, "",
+				fmt.Sprintf("Record %d: skipped (duplicate): %s %s -> %s %s, amount: %d, card: %s",
+					i+1, record.EntryDate, record.EntryTime, record.ExitDate, record.ExitTime, record.ETCAmount,
+					s.cardMask.Mask(record.CardNumber))))
+			result.plan(pb.DryRunAction_DRY_RUN_ACTION_SKIP, pb.ErrorCode_ERROR_CODE_DUPLICATE, i, record, nil)
 			continue
 		}
 
 		// Convert to simple format for saving
+		_, convertSpan := tracing.Start(ctx, "convertRecord", attribute.Int("line", record.LineNumber))
 		simpleRecord, err := s.parser.ConvertToSimpleRecord(record)
 		if err != nil {
-			errors = append(errors, fmt.Sprintf("Record %d: conversion failed: %v", i+1, err))
+			tracing.End(convertSpan, err)
+			s.logger.WarnContext(ctx, "record conversion failed", s.recordAttrs(opts, record, "error", err)...)
+			result.errors = append(result.errors, newRecordError(pb.ErrorCode_ERROR_CODE_CONVERSION, i, record, "",
+				fmt.Sprintf("Record %d: conversion failed: %v", i+1, err)))
+			result.plan(pb.DryRunAction_DRY_RUN_ACTION_REJECT, pb.ErrorCode_ERROR_CODE_CONVERSION, i, record, nil)
 			stats.ErrorRecords++
 			continue
 		}
+		tracing.End(convertSpan, nil)
 
-		// Add account ID
-		dataToSave := map[string]interface{}{
-			"account_id":   accountID,
-			"date":        simpleRecord.Date.Format("2006-01-02"),
-			"entry_ic":    simpleRecord.EntryIC,
-			"exit_ic":     simpleRecord.ExitIC,
-			"route":       simpleRecord.Route,
-			"vehicle_type": simpleRecord.VehicleType,
-			"amount":      simpleRecord.Amount,
-			"card_number": simpleRecord.CardNumber,
+		// Accepted records count as duplicates for later rows and can be reversed by later corrections
+		original := opts.trips.link(tripKey, &simpleRecord, earlier)
+		opts.processedKeys[key] = true
+		opts.trips.record(tripKey, record.LineNumber, simpleRecord, original)
+		accepted = append(accepted, acceptedRecord{
+			index:        i,
+			record:       record,
+			simpleRecord: simpleRecord,
+			key:          key,
+			original:     original,
+			entryICCode:  entryICCode,
+			exitICCode:   exitICCode,
+		})
+	}
+
+	// Rejected and duplicate records neither join nor split a trip
+	var tripIDs map[int]string
+	if opts.stitchTrips && len(accepted) > 0 {
+		rows := make([]parser.ActualETCRecord, len(accepted))
+		indexes := make([]int, len(accepted))
+		for k, a := range accepted {
+			rows[k], indexes[k] = a.record, a.index
+		}
+		result.trips, tripIDs = s.stitchTrips(rows, indexes)
+	}
+
+	for k, a := range accepted {
+		i, record, simpleRecord, original := a.index, a.record, a.simpleRecord, a.original
+		entryICCode, exitICCode := a.entryICCode, a.exitICCode
+
+		// Check context cancellation
+		if ctx.Err() != nil {
+			s.logger.WarnContext(ctx, "processing cancelled", "file", opts.filePath, "record", i+1, "error", ctx.Err())
+			result.errors = append(result.errors, newRecordError(pb.ErrorCode_ERROR_CODE_CANCELLED, i, record, "",
+				fmt.Sprintf("Processing cancelled at record %d", i)))
+			stats.ErrorRecords += int32(len(accepted) - k)
+			break
 		}
 
-		// Save to database
-		if s.dbClient != nil {
-			if err := s.dbClient.SaveETCData(dataToSave); err != nil {
-				errors = append(errors, fmt.Sprintf("Record %d: save failed: %v", i+1, err))
+		unknownCard := false
+		vehicleID, driverID := "", ""
+
+		dataToSave := buildDBPayload(opts.accountID, simpleRecord)
+		if original != nil {
+			dataToSave["reversal_of"] = original.reversalPayload()
+		}
+		breakdown := s.tax.Split(simpleRecord.Amount)
+		dataToSave["tax_exclusive_amount"] = breakdown.Exclusive
+		dataToSave["tax_amount"] = breakdown.Tax
+		dataToSave["day_type"] = string(s.calendar.DayType(simpleRecord.Date))
+		if name, ok := s.calendar.HolidayName(simpleRecord.Date); ok {
+			dataToSave["holiday_name"] = name
+		}
+		if tripID, ok := tripIDs[i]; ok {
+			dataToSave["trip_id"] = tripID
+		}
+		if s.interchanges.Len() > 0 {
+			dataToSave["entry_ic_code"] = entryICCode
+			dataToSave["exit_ic_code"] = exitICCode
+		}
+
+		// Enrich with the vehicle and driver the card was assigned to; unknown cards are flagged but still saved
+		if s.masterData.Len() > 0 {
+			if assignment, ok := s.masterData.Lookup(simpleRecord.CardNumber, record.VehicleNumber, simpleRecord.Date); ok {
+				dataToSave["assignment_id"] = assignment.ID
+				dataToSave["vehicle_id"] = assignment.VehicleID
+				dataToSave["driver_id"] = assignment.DriverID
+				vehicleID = assignment.VehicleID
+				driverID = assignment.DriverID
+			} else {
+				dataToSave["unknown_card"] = true
+				result.errors = append(result.errors, newRecordError(pb.ErrorCode_ERROR_CODE_UNKNOWN_CARD, i, record, "card_number",
+					fmt.Sprintf("Record %d: card %s is not assigned to a vehicle or driver on %s",
+						i+1, s.cardMask.Mask(simpleRecord.CardNumber), simpleRecord.Date.Format("2006-01-02"))))
+				unknownCard = true
+			}
+		}
+
+		// Flag discounts that differ from what the rules expect; the record is still saved
+		verified := s.verifyDiscount(record, simpleRecord, dataToSave)
+		if verified != nil && verified.Mismatch {
+			result.errors = append(result.errors, newRecordError(pb.ErrorCode_ERROR_CODE_DISCOUNT_MISMATCH, i, record, "discount_applied",
+				fmt.Sprintf("Record %d: expected discount %d yen (%s), statement shows %d yen (difference %d)",
+					i+1, verified.Expected, discountRulesLabel(verified), verified.Actual, verified.Difference)))
+		}
+
+		// Flag suspicious trips; the record is still saved
+		findings := anomalies[i]
+		for j := range findings {
+			findings[j].AccountID = opts.accountID
+			findings[j].FilePath = opts.filePath
+			s.logger.WarnContext(ctx, "anomaly detected", s.recordAttrs(opts, record, "rule", findings[j].Rule)...)
+			result.errors = append(result.errors, newRecordError(pb.ErrorCode_ERROR_CODE_ANOMALY, i, record, "", anomalyMessage(i, findings[j])))
+		}
+		if len(findings) > 0 {
+			dataToSave["anomalies"] = anomalyRulesPayload(findings)
+		}
+
+		if opts.dryRun {
+			// Report what would be saved without touching the database
+			result.plan(pb.DryRunAction_DRY_RUN_ACTION_SAVE, pb.ErrorCode_ERROR_CODE_UNSPECIFIED, i, record, dataToSave)
+		} else if s.dbClient != nil {
+			// Save to database
+			if err := s.saveRecord(ctx, record, dataToSave); err != nil {
+				s.logger.ErrorContext(ctx, "failed to save record", s.recordAttrs(opts, record, "error", err)...)
+				result.errors = append(result.errors, newRecordError(pb.ErrorCode_ERROR_CODE_PERSISTENCE, i, record, "",
+					fmt.Sprintf("Record %d: save failed: %v", i+1, err)))
 				stats.ErrorRecords++
 				continue
 			}
 		}
 
-		processedKeys[key] = true
+		if !opts.dryRun {
+			s.logger.DebugContext(ctx, "record saved", s.recordAttrs(opts, record, "amount", simpleRecord.Amount)...)
+		}
+		if !opts.dryRun {
+			opts.saved.add(a.key)
+			// The record is already in db_service, so it still counts as saved
+			if err := s.recordUsage(a.key, opts.accountID, record, simpleRecord, vehicleID, tripIDs[i]); err != nil {
+				s.logger.ErrorContext(ctx, "failed to record usage", s.recordAttrs(opts, record, "error", err)...)
+				result.errors = append(result.errors, newRecordError(pb.ErrorCode_ERROR_CODE_PERSISTENCE, i, record, "",
+					fmt.Sprintf("Record %d: failed to record usage: %v", i+1, err)))
+			}
+		}
 		stats.SavedRecords++
+		stats.NetAmount += int64(simpleRecord.Amount)
+		statement.Add(simpleRecord.Amount)
+		if unknownCard {
+			stats.UnknownCardRecords++
+		}
+		if verified != nil && verified.Mismatch {
+			stats.DiscountMismatchRecords++
+			if verified.Difference > 0 {
+				stats.ClaimableDiscount += int64(verified.Difference)
+			}
+		}
+		if simpleRecord.IsReversal() {
+			stats.ReversalRecords++
+		}
+		if trip, ok := anomalyTrips[i]; ok {
+			savedTrips = append(savedTrips, trip)
+		}
+		for _, finding := range findings {
+			result.anomalies = append(result.anomalies, s.toAnomalyFindingProto(finding))
+		}
+		if len(findings) > 0 {
+			stats.AnomalyRecords++
+			savedFindings = append(savedFindings, findings...)
+		}
+		if collect {
+			exported := s.exportRecord(record, simpleRecord, opts)
+			exported.EntryICCode, exported.ExitICCode = entryICCode, exitICCode
+			exported.TaxExclusiveAmount, exported.TaxAmount = int64(breakdown.Exclusive), int64(breakdown.Tax)
+			exported.TripID, exported.VehicleID, exported.DriverID = tripIDs[i], vehicleID, driverID
+			result.exported = append(result.exported, exported)
+		}
+		if opts.journal && simpleRecord.Amount != 0 {
+			entry := s.journal.NewEntry(simpleRecord, journal.Vehicle{ID: vehicleID, Number: record.VehicleNumber})
+			entry.TaxAmount = breakdown.Tax
+			if entry.TaxAmount < 0 {
+				entry.TaxAmount = -entry.TaxAmount
+			}
+			result.entries = append(result.entries, entry)
+		}
+	}
+
+	// Rejected records were reported while screening, before the accepted ones; report everything in record order
+	sort.SliceStable(result.errors, func(a, b int) bool { return result.errors[a].RecordIndex < result.errors[b].RecordIndex })
+	sort.SliceStable(result.dryRunRecords, func(a, b int) bool {
+		return result.dryRunRecords[a].RecordIndex < result.dryRunRecords[b].RecordIndex
+	})
+
+	// Keep imported records so they can be exported later
+	if s.archive != nil && !opts.dryRun && len(result.exported) > 0 {
+		if err := s.archive.Add(result.exported...); err != nil {
+			s.logger.ErrorContext(ctx, "failed to keep records for export", "file", opts.filePath, "error", err)
+			result.errors = append(result.errors, newFileError(pb.ErrorCode_ERROR_CODE_PERSISTENCE, opts.filePath,
+				fmt.Sprintf("failed to keep records for export: %v", err)))
+		}
+	}
+
+	// Only imported records become history for later checks
+	if s.anomalies != nil && !opts.dryRun {
+		s.anomalies.Learn(savedTrips)
+		if s.findings != nil && len(savedFindings) > 0 {
+			if err := s.findings.Add(savedFindings...); err != nil {
+				s.logger.ErrorContext(ctx, "failed to keep anomaly findings", "file", opts.filePath, "error", err)
+				result.errors = append(result.errors, newFileError(pb.ErrorCode_ERROR_CODE_PERSISTENCE, opts.filePath,
+					fmt.Sprintf("failed to keep anomaly findings: %v", err)))
+			}
+		}
+	}
+
+	s.logger.InfoContext(ctx, "processed records", "file", opts.filePath, "dry_run", opts.dryRun,
+		"total", stats.TotalRecords, "saved", stats.SavedRecords, "skipped", stats.SkippedRecords, "errors", stats.ErrorRecords)
+	if observe {
+		s.metrics.AddRecords(metrics.OutcomeParsed, opts.accountID, opts.format, int(stats.TotalRecords))
+		s.metrics.AddRecords(metrics.OutcomeSaved, opts.accountID, opts.format, int(stats.SavedRecords))
+		s.metrics.AddRecords(metrics.OutcomeSkipped, opts.accountID, opts.format, int(stats.SkippedRecords))
+		s.metrics.AddRecords(metrics.OutcomeErrored, opts.accountID, opts.format, int(stats.ErrorRecords))
 	}
 
-	return stats, errors
-}
\ No newline at end of file
+	total := statement.Total()
+	stats.TaxExclusiveAmount = int64(total.Exclusive)
+	stats.TaxAmount = int64(total.Tax)
+	stats.RecordTaxAmount = int64(statement.RecordTax())
+	return result
+}
+
+// recordData formats a record for ValidationError.record_data with its card number masked
+func (s *DataProcessorService) recordData(record parser.ActualETCRecord) string {
+	record.CardNumber = s.cardMask.Mask(record.CardNumber)
+	return fmt.Sprintf("%v", record)
+}
+
+// withLogAccount tags log lines with the account of a call that did not come through the gRPC interceptor (etcproc)
+func withLogAccount(ctx context.Context, accountID string) context.Context {
+	if logging.AccountID(ctx) == "" && accountID != "" {
+		return logging.WithAccountID(ctx, accountID)
+	}
+	return ctx
+}
+
+// saveRecord saves a record in a span of its own, passing the trace context on to the
+// database when the client supports it
+func (s *DataProcessorService) saveRecord(ctx context.Context, record parser.ActualETCRecord, data map[string]interface{}) error {
+	ctx, span := tracing.Start(ctx, "SaveETCData", attribute.Int("line", record.LineNumber))
+	var err error
+	if client, ok := s.dbClient.(ContextDBClient); ok {
+		err = client.SaveETCDataContext(ctx, data)
+	} else {
+		err = s.dbClient.SaveETCData(data)
+	}
+	tracing.End(span, err)
+	return err
+}
+
+// recordAttrs returns the log attributes identifying a record, with its card number masked, followed by extra attributes
+func (s *DataProcessorService) recordAttrs(opts processOptions, record parser.ActualETCRecord, extra ...interface{}) []interface{} {
+	attrs := []interface{}{
+		"file", opts.filePath,
+		"line", record.LineNumber,
+		"card_number", s.cardMask.Mask(record.CardNumber),
+	}
+	return append(attrs, extra...)
+}
+
+// buildDBPayload builds the data passed to DBClient.SaveETCData for a converted record
+func buildDBPayload(accountID string, simpleRecord parser.ETCRecord) map[string]interface{} {
+	return map[string]interface{}{
+		"account_id":   accountID,
+		"date":         simpleRecord.Date.Format("2006-01-02"),
+		"entry_ic":     simpleRecord.EntryIC,
+		"exit_ic":      simpleRecord.ExitIC,
+		"route":        simpleRecord.Route,
+		"vehicle_type": int(simpleRecord.VehicleType),
+		"amount":       simpleRecord.Amount,
+		"card_number":  simpleRecord.CardNumber,
+
+		// Amount breakdown as it appears on the statement
+		"normal_amount":       simpleRecord.NormalAmount,
+		"discount_amount":     simpleRecord.DiscountAmount,
+		"etc_amount":          simpleRecord.ETCAmount,
+		"post_payment_amount": simpleRecord.PostPaymentAmount,
+		"mileage":             simpleRecord.Mileage,
+
+		"redemption_base_amount": simpleRecord.RedemptionBaseAmount,
+
+		// Refund and correction rows are stored as reversals with a negative amount
+		"reversal":          simpleRecord.IsReversal(),
+		"correction_reason": string(simpleRecord.Correction),
+
+		// 経路情報 split into ordered road sections
+		"route_segments": routeSegmentsPayload(simpleRecord.Segments),
+	}
+}
+
+// routeSegmentsPayload converts route segments to JSON-compatible values for the DB payload
+func routeSegmentsPayload(segments []parser.RouteSegment) []interface{} {
+	result := make([]interface{}, 0, len(segments))
+	for _, segment := range segments {
+		via := make([]interface{}, len(segment.Via))
+		for i, point := range segment.Via {
+			via[i] = point
+		}
+		result = append(result, map[string]interface{}{
+			"road": segment.Road,
+			"from": segment.From,
+			"to":   segment.To,
+			"via":  via,
+		})
+	}
+	return result
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+)
+
+// tripTimeLayout formats trip start and end times in responses
+const tripTimeLayout = "2006-01-02T15:04:05"
+
+// TripStitcher is implemented by parsers that can group consecutive rows into trips
+type TripStitcher interface {
+	StitchTrips(records []parser.ActualETCRecord, opts parser.StitchOptions) []parser.Trip
+}
+
+// SetTripStitchOptions sets the time gap and IC chaining rules used when a request asks for stitch_trips
+func (s *DataProcessorService) SetTripStitchOptions(opts parser.StitchOptions) {
+	s.tripOptions = opts
+}
+
+// stitchTrips groups records into trips and returns them as protos together with the trip ID of each record index.
+// indexes maps the position of each record to its position in the batch, for callers that stitch a subset
+// of the batch; nil means the records are the whole batch. Both results are nil when the configured parser cannot stitch trips.
+func (s *DataProcessorService) stitchTrips(records []parser.ActualETCRecord, indexes []int) ([]*pb.Trip, map[int]string) {
+	stitcher, ok := s.parser.(TripStitcher)
+	if !ok {
+		return nil, nil
+	}
+
+	var trips []*pb.Trip
+	tripIDs := make(map[int]string)
+	for _, trip := range stitcher.StitchTrips(records, s.tripOptions) {
+		tripProto := toTripProto(trip, records, indexes)
+		if !trip.Start.IsZero() {
+			tripProto.DayType = string(s.calendar.DayType(trip.Start))
+		}
+		trips = append(trips, tripProto)
+		for _, index := range trip.Records {
+			tripIDs[batchIndex(index, indexes)] = trip.ID
+		}
+	}
+	return trips, tripIDs
+}
+
+// batchIndex returns the position in the batch of a stitched record
+func batchIndex(index int, indexes []int) int {
+	if indexes == nil {
+		return index
+	}
+	return indexes[index]
+}
+
+// toTripProto converts a stitched trip to its proto representation
+func toTripProto(trip parser.Trip, records []parser.ActualETCRecord, indexes []int) *pb.Trip {
+	result := &pb.Trip{
+		Id:          trip.ID,
+		CardNumber:  trip.CardNumber,
+		EntryIc:     trip.EntryIC,
+		ExitIc:      trip.ExitIC,
+		TotalAmount: int32(trip.Amount),
+		Mileage:     int32(trip.Mileage),
+	}
+	if !trip.Start.IsZero() {
+		result.StartTime = trip.Start.Format(tripTimeLayout)
+	}
+	if !trip.End.IsZero() {
+		result.EndTime = trip.End.Format(tripTimeLayout)
+	}
+	for _, index := range trip.Records {
+		result.RecordIndexes = append(result.RecordIndexes, int32(batchIndex(index, indexes)+1))
+		result.LineNumbers = append(result.LineNumbers, int32(records[index].LineNumber))
+	}
+	return result
+}
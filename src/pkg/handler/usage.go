@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/usage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// usageDimensions maps request dimensions to store dimensions
+var usageDimensions = map[pb.UsageDimension]usage.Dimension{
+	pb.UsageDimension_USAGE_DIMENSION_CARD:    usage.DimensionCard,
+	pb.UsageDimension_USAGE_DIMENSION_VEHICLE: usage.DimensionVehicle,
+	pb.UsageDimension_USAGE_DIMENSION_ACCOUNT: usage.DimensionAccount,
+	pb.UsageDimension_USAGE_DIMENSION_ROUTE:   usage.DimensionRoute,
+	pb.UsageDimension_USAGE_DIMENSION_MONTH:   usage.DimensionMonth,
+}
+
+// SetUsageStore replaces the store saved records are reported from; nil disables usage reporting
+func (s *DataProcessorService) SetUsageStore(store usage.Store) {
+	s.usage = store
+}
+
+// GetUsageSummary totals imported records over a date range, grouped by card, vehicle, account, route or month
+func (s *DataProcessorService) GetUsageSummary(ctx context.Context, req *pb.GetUsageSummaryRequest) (*pb.GetUsageSummaryResponse, error) {
+	if s.usage == nil {
+		return nil, status.Error(codes.Unimplemented, "usage reporting is disabled")
+	}
+
+	filter := usage.Filter{AccountID: req.GetAccountId()}
+	var err error
+	if filter.From, err = parseUsageDate(req.GetFromDate()); err != nil {
+		return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "from_date", err.Error())
+	}
+	if filter.To, err = parseUsageDate(req.GetToDate()); err != nil {
+		return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "to_date", err.Error())
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "to_date", "to_date must not be before from_date")
+	}
+
+	var groupBy []usage.Dimension
+	seen := make(map[usage.Dimension]bool)
+	for _, requested := range req.GetGroupBy() {
+		dimension, ok := usageDimensions[requested]
+		if !ok {
+			return nil, statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "group_by",
+				fmt.Sprintf("unsupported dimension: %s", requested))
+		}
+		if !seen[dimension] {
+			seen[dimension] = true
+			groupBy = append(groupBy, dimension)
+		}
+	}
+
+	resp := &pb.GetUsageSummaryResponse{Total: &pb.UsageSummary{}}
+	for _, summary := range s.usage.Summarize(filter, groupBy) {
+		resp.Groups = append(resp.Groups, toUsageSummaryProto(summary))
+	}
+	if total := s.usage.Summarize(filter, nil); len(total) > 0 {
+		resp.Total = toUsageSummaryProto(total[0])
+	}
+	return resp, nil
+}
+
+// usageKey scopes a record's duplicate key to its account in the usage store
+func usageKey(accountID, key string) string {
+	return accountID + "\x00" + key
+}
+
+// imported reports whether a record with the duplicate key was saved by an earlier import of the account
+func (s *DataProcessorService) imported(accountID, key string) bool {
+	return s.usage != nil && s.usage.Has(usageKey(accountID, key))
+}
+
+// recordUsage adds a saved record to the usage store
+func (s *DataProcessorService) recordUsage(key, accountID string, record parser.ActualETCRecord, simpleRecord parser.ETCRecord, vehicleID, tripID string) error {
+	if s.usage == nil {
+		return nil
+	}
+	discount := simpleRecord.DiscountAmount
+	if discount < 0 {
+		discount = -discount
+	}
+	return s.usage.Add(usage.Entry{
+		Key:           usageKey(accountID, key),
+		Row:           parser.TripKey(record),
+		AccountID:     accountID,
+		Date:          simpleRecord.Date,
+		CardNumber:    simpleRecord.CardNumber,
+		VehicleID:     vehicleID,
+		VehicleNumber: record.VehicleNumber,
+		VehicleClass:  int(record.VehicleClass),
+		EntryIC:       simpleRecord.EntryIC,
+		ExitIC:        simpleRecord.ExitIC,
+		TripID:        tripID,
+		Amount:        simpleRecord.Amount,
+		NormalAmount:  simpleRecord.NormalAmount,
+		Discount:      discount,
+		Reversal:      simpleRecord.IsReversal(),
+	})
+}
+
+// parseUsageDate parses an optional YYYY-MM-DD date; an empty string is the zero time
+func parseUsageDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("date %q must be YYYY-MM-DD", value)
+	}
+	return date, nil
+}
+
+// toUsageSummaryProto converts a usage summary to its proto representation
+func toUsageSummaryProto(summary usage.Summary) *pb.UsageSummary {
+	return &pb.UsageSummary{
+		CardNumber:     summary.Group[usage.DimensionCard],
+		Vehicle:        summary.Group[usage.DimensionVehicle],
+		AccountId:      summary.Group[usage.DimensionAccount],
+		Route:          summary.Group[usage.DimensionRoute],
+		Month:          summary.Group[usage.DimensionMonth],
+		Amount:         summary.Amount,
+		NormalAmount:   summary.NormalAmount,
+		DiscountAmount: summary.Discount,
+		TripCount:      int32(summary.Trips),
+		RecordCount:    int32(summary.Records),
+		ReversalCount:  int32(summary.Reversals),
+	}
+}
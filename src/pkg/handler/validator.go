@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -28,7 +29,7 @@ func NewDefaultValidator() *DefaultValidator {
 func (v *DefaultValidator) ValidateCSVFilePath(path string) error {
 	// csv_file_path is optional when CSV_BASE_PATH is set
 	if path == "" && os.Getenv("CSV_BASE_PATH") == "" {
-		return status.Error(codes.InvalidArgument, "csv_file_path is required when CSV_BASE_PATH is not set")
+		return statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "csv_file_path", "csv_file_path is required when CSV_BASE_PATH is not set")
 	}
 	return nil
 }
@@ -41,7 +42,7 @@ func (v *DefaultValidator) ValidateAccountID(accountID string) error {
 	}
 	// Additional validation rules can be added here
 	if len(accountID) < 3 {
-		return status.Error(codes.InvalidArgument, "account_id must be at least 3 characters")
+		return statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "account_id", "account_id must be at least 3 characters")
 	}
 	return nil
 }
@@ -49,10 +50,10 @@ func (v *DefaultValidator) ValidateAccountID(accountID string) error {
 // ValidateCSVData validates CSV data
 func (v *DefaultValidator) ValidateCSVData(data string) error {
 	if data == "" {
-		return status.Error(codes.InvalidArgument, "csv_data is required")
+		return statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "csv_data", "csv_data is required")
 	}
 	if len(data) < 10 {
-		return status.Error(codes.InvalidArgument, "csv_data is too short")
+		return statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "csv_data", "csv_data is too short")
 	}
 	return nil
 }
@@ -60,7 +61,7 @@ func (v *DefaultValidator) ValidateCSVData(data string) error {
 // CheckFileExists checks if a file exists
 func (v *DefaultValidator) CheckFileExists(path string) error {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return status.Errorf(codes.NotFound, "file not found: %s", path)
+		return statusError(codes.NotFound, pb.ErrorCode_ERROR_CODE_VALIDATION, "csv_file_path", fmt.Sprintf("file not found: %s", path))
 	} else if err != nil {
 		return status.Errorf(codes.Internal, "failed to check file: %v", err)
 	}
@@ -70,7 +71,7 @@ func (v *DefaultValidator) CheckFileExists(path string) error {
 // ValidateProcessCSVFileRequest validates ProcessCSVFile request
 func ValidateProcessCSVFileRequest(req interface{}, v Validator) error {
 	if req == nil {
-		return status.Error(codes.InvalidArgument, "request is nil")
+		return statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "", "request is nil")
 	}
 
 	// Type assertion with interface to allow different request types
@@ -81,7 +82,7 @@ func ValidateProcessCSVFileRequest(req interface{}, v Validator) error {
 
 	fileReq, ok := req.(FileRequest)
 	if !ok {
-		return status.Error(codes.InvalidArgument, "invalid request type")
+		return statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "", "invalid request type")
 	}
 
 	csvFilePath := fileReq.GetCsvFilePath()
@@ -108,7 +109,7 @@ func ValidateProcessCSVFileRequest(req interface{}, v Validator) error {
 // ValidateProcessCSVDataRequest validates ProcessCSVData request
 func ValidateProcessCSVDataRequest(req interface{}, v Validator) error {
 	if req == nil {
-		return status.Error(codes.InvalidArgument, "request is nil")
+		return statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "", "request is nil")
 	}
 
 	type DataRequest interface {
@@ -118,7 +119,7 @@ func ValidateProcessCSVDataRequest(req interface{}, v Validator) error {
 
 	dataReq, ok := req.(DataRequest)
 	if !ok {
-		return status.Error(codes.InvalidArgument, "invalid request type")
+		return statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "", "invalid request type")
 	}
 
 	if err := v.ValidateCSVData(dataReq.GetCsvData()); err != nil {
@@ -135,7 +136,7 @@ func ValidateProcessCSVDataRequest(req interface{}, v Validator) error {
 // ValidateValidateCSVDataRequest validates ValidateCSVData request
 func ValidateValidateCSVDataRequest(req interface{}, v Validator) error {
 	if req == nil {
-		return status.Error(codes.InvalidArgument, "request is nil")
+		return statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "", "request is nil")
 	}
 
 	type ValidateRequest interface {
@@ -145,7 +146,7 @@ func ValidateValidateCSVDataRequest(req interface{}, v Validator) error {
 
 	validateReq, ok := req.(ValidateRequest)
 	if !ok {
-		return status.Error(codes.InvalidArgument, "invalid request type")
+		return statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "", "invalid request type")
 	}
 
 	if err := v.ValidateCSVData(validateReq.GetCsvData()); err != nil {
@@ -159,6 +160,110 @@ func ValidateValidateCSVDataRequest(req interface{}, v Validator) error {
 	return nil
 }
 
+// ValidatePreviewCSVRequest validates PreviewCSV request
+func ValidatePreviewCSVRequest(req interface{}, v Validator) error {
+	if req == nil {
+		return statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "", "request is nil")
+	}
+
+	type PreviewRequest interface {
+		GetCsvData() string
+		GetCsvFilePath() string
+		GetLimit() int32
+	}
+
+	previewReq, ok := req.(PreviewRequest)
+	if !ok {
+		return statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "", "invalid request type")
+	}
+
+	csvData := previewReq.GetCsvData()
+	csvFilePath := previewReq.GetCsvFilePath()
+
+	if (csvData == "") == (csvFilePath == "") {
+		return statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "csv_data", "exactly one of csv_data or csv_file_path is required")
+	}
+
+	if previewReq.GetLimit() < 0 {
+		return statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "limit", "limit must not be negative")
+	}
+
+	if csvData != "" {
+		return v.ValidateCSVData(csvData)
+	}
+	return checkCSVFileExists(csvFilePath, v)
+}
+
+// ValidateExportJournalRequest validates ExportJournal request
+func ValidateExportJournalRequest(req interface{}, v Validator) error {
+	if req == nil {
+		return statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "", "request is nil")
+	}
+
+	type ExportRequest interface {
+		GetCsvData() string
+		GetCsvFilePath() string
+	}
+
+	exportReq, ok := req.(ExportRequest)
+	if !ok {
+		return statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "", "invalid request type")
+	}
+
+	csvData := exportReq.GetCsvData()
+	csvFilePath := exportReq.GetCsvFilePath()
+
+	if (csvData == "") == (csvFilePath == "") {
+		return statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "csv_data", "exactly one of csv_data or csv_file_path is required")
+	}
+
+	if csvData != "" {
+		return v.ValidateCSVData(csvData)
+	}
+	return checkCSVFileExists(csvFilePath, v)
+}
+
+// ValidateExportRecordsRequest validates ExportRecords request; csv_data and csv_file_path are optional but exclusive
+func ValidateExportRecordsRequest(req interface{}, v Validator) error {
+	if req == nil {
+		return statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "", "request is nil")
+	}
+
+	type ExportRequest interface {
+		GetCsvData() string
+		GetCsvFilePath() string
+	}
+
+	exportReq, ok := req.(ExportRequest)
+	if !ok {
+		return statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "", "invalid request type")
+	}
+
+	csvData := exportReq.GetCsvData()
+	csvFilePath := exportReq.GetCsvFilePath()
+
+	if csvData != "" && csvFilePath != "" {
+		return statusError(codes.InvalidArgument, pb.ErrorCode_ERROR_CODE_VALIDATION, "csv_data", "at most one of csv_data or csv_file_path may be given")
+	}
+
+	if csvData != "" {
+		return v.ValidateCSVData(csvData)
+	}
+	if csvFilePath != "" {
+		return checkCSVFileExists(csvFilePath, v)
+	}
+	return nil
+}
+
+// checkCSVFileExists checks csv_file_path unless CSV_BASE_PATH is set, in which case the
+// path is resolved to the latest folder like ProcessCSVFile
+func checkCSVFileExists(csvFilePath string, v Validator) error {
+	if os.Getenv("CSV_BASE_PATH") != "" {
+		return nil
+	}
+	return v.CheckFileExists(csvFilePath)
+}
+
 // CreateDuplicateKey creates a unique key for duplicate detection
 func CreateDuplicateKey(entryDate, entryTime, exitDate, exitTime string, amount int, cardNumber string) string {
 	return fmt.Sprintf("%s_%s_%s_%s_%d_%s",
@@ -0,0 +1,193 @@
+// Package holiday tells whether a date is a Japanese national holiday or a weekend.
+package holiday
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	dateLayout     = "2006-01-02"
+	monthDayLayout = "01-02"
+)
+
+// DayType classifies a date
+type DayType string
+
+// Day types
+const (
+	Weekday DayType = "weekday"
+	Weekend DayType = "weekend" // Saturday or Sunday that is not a holiday
+	Holiday DayType = "holiday" // national holiday or an extra day from a data file
+)
+
+// Entry is a holiday on a specific date
+type Entry struct {
+	Date time.Time
+	Name string
+}
+
+// Calendar knows the built-in Japanese national holidays plus extra days loaded from a file.
+// Dates are compared by their calendar day as given, so the UTC midnights produced by the
+// parser and wall-clock statement times both work. It is safe for concurrent use.
+type Calendar struct {
+	mu       sync.Mutex
+	national map[int]map[string]string // year -> "MM-DD" -> name, computed on first use
+	extra    map[string]string         // "YYYY-MM-DD" -> name
+}
+
+// New creates a calendar with the built-in national holidays
+func New() *Calendar {
+	return &Calendar{
+		national: make(map[int]map[string]string),
+		extra:    make(map[string]string),
+	}
+}
+
+// Add registers an extra holiday, such as a company day off or a NEXCO year-end day
+func (c *Calendar) Add(date time.Time, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.extra[date.Format(dateLayout)] = name
+}
+
+// HolidayName returns the name of the holiday on date; extra days take precedence over national holidays
+func (c *Calendar) HolidayName(date time.Time) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if name, ok := c.extra[date.Format(dateLayout)]; ok {
+		return name, true
+	}
+	name, ok := c.nationalYear(date.Year())[date.Format(monthDayLayout)]
+	return name, ok
+}
+
+// IsHoliday reports whether date is a national holiday or an extra day
+func (c *Calendar) IsHoliday(date time.Time) bool {
+	_, ok := c.HolidayName(date)
+	return ok
+}
+
+// IsWeekend reports whether date is a Saturday or Sunday
+func (c *Calendar) IsWeekend(date time.Time) bool {
+	weekday := date.Weekday()
+	return weekday == time.Saturday || weekday == time.Sunday
+}
+
+// IsDayOff reports whether date is a weekend or a holiday (土日祝)
+func (c *Calendar) IsDayOff(date time.Time) bool {
+	return c.IsWeekend(date) || c.IsHoliday(date)
+}
+
+// DayType classifies date as a weekday, weekend or holiday
+func (c *Calendar) DayType(date time.Time) DayType {
+	switch {
+	case c.IsHoliday(date):
+		return Holiday
+	case c.IsWeekend(date):
+		return Weekend
+	}
+	return Weekday
+}
+
+// Holidays lists the holidays of a year in date order, including extra days
+func (c *Calendar) Holidays(year int) []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	names := make(map[string]string)
+	for monthDay, name := range c.nationalYear(year) {
+		names[fmt.Sprintf("%04d-%s", year, monthDay)] = name
+	}
+	prefix := fmt.Sprintf("%04d-", year)
+	for date, name := range c.extra {
+		if strings.HasPrefix(date, prefix) {
+			names[date] = name
+		}
+	}
+
+	entries := make([]Entry, 0, len(names))
+	for date, name := range names {
+		day, _ := time.Parse(dateLayout, date)
+		entries = append(entries, Entry{Date: day, Name: name})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Date.Before(entries[j].Date)
+	})
+	return entries
+}
+
+// nationalYear returns the cached national holidays of a year; callers must hold c.mu
+func (c *Calendar) nationalYear(year int) map[string]string {
+	holidays, ok := c.national[year]
+	if !ok {
+		holidays = nationalHolidays(year)
+		c.national[year] = holidays
+	}
+	return holidays
+}
+
+// fileEntry is one extra holiday in a data file
+type fileEntry struct {
+	Date string `yaml:"date"`
+	Name string `yaml:"name"`
+}
+
+// LoadFile creates a calendar with the national holidays plus the extra days in a YAML
+// ("holidays:" list of date/name) or CSV ("date,name") file, chosen by extension
+func LoadFile(path string) (*Calendar, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read holiday file: %w", err)
+	}
+
+	var entries []fileEntry
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		rows, err := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte{0xEF, 0xBB, 0xBF}))).ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse holiday file: %w", err)
+		}
+		for i, row := range rows {
+			if i == 0 && strings.TrimSpace(row[0]) == "date" {
+				continue
+			}
+			entry := fileEntry{Date: strings.TrimSpace(row[0])}
+			if len(row) > 1 {
+				entry.Name = strings.TrimSpace(row[1])
+			}
+			entries = append(entries, entry)
+		}
+	} else {
+		var file struct {
+			Holidays []fileEntry `yaml:"holidays"`
+		}
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse holiday file: %w", err)
+		}
+		entries = file.Holidays
+	}
+
+	c := New()
+	for i, entry := range entries {
+		date, err := time.Parse(dateLayout, entry.Date)
+		if err != nil {
+			return nil, fmt.Errorf("holiday entry %d: date %q must be YYYY-MM-DD", i+1, entry.Date)
+		}
+		name := entry.Name
+		if name == "" {
+			name = "休日"
+		}
+		c.Add(date, name)
+	}
+	return c, nil
+}
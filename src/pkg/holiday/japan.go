@@ -0,0 +1,160 @@
+package holiday
+
+import "time"
+
+// Range of years with built-in national holidays; the equinox formula is only valid within it
+const (
+	FirstYear = 1980
+	LastYear  = 2099
+)
+
+// nationalHolidays returns the national holidays (国民の祝日) of a year under the
+// Act on National Holidays, including 振替休日 and 国民の休日, keyed by "MM-DD"
+func nationalHolidays(year int) map[string]string {
+	if year < FirstYear || year > LastYear {
+		return nil
+	}
+
+	days := make(map[time.Time]string)
+	add := func(month time.Month, day int, name string) {
+		days[time.Date(year, month, day, 0, 0, 0, 0, time.UTC)] = name
+	}
+	monday := func(month time.Month, n int) int {
+		first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+		offset := (int(time.Monday) - int(first.Weekday()) + 7) % 7
+		return 1 + offset + (n-1)*7
+	}
+
+	add(time.January, 1, "元日")
+	if year >= 2000 {
+		add(time.January, monday(time.January, 2), "成人の日")
+	} else {
+		add(time.January, 15, "成人の日")
+	}
+	add(time.February, 11, "建国記念の日")
+	if year >= 2020 {
+		add(time.February, 23, "天皇誕生日")
+	}
+	add(time.March, vernalEquinox(year), "春分の日")
+	switch {
+	case year >= 2007:
+		add(time.April, 29, "昭和の日")
+		add(time.May, 4, "みどりの日")
+	case year >= 1989:
+		add(time.April, 29, "みどりの日")
+	default:
+		add(time.April, 29, "天皇誕生日")
+	}
+	add(time.May, 3, "憲法記念日")
+	add(time.May, 5, "こどもの日")
+
+	// Olympic years moved 海の日, スポーツの日 and 山の日
+	switch {
+	case year == 2020:
+		add(time.July, 23, "海の日")
+		add(time.July, 24, "スポーツの日")
+		add(time.August, 10, "山の日")
+	case year == 2021:
+		add(time.July, 22, "海の日")
+		add(time.July, 23, "スポーツの日")
+		add(time.August, 8, "山の日")
+	default:
+		switch {
+		case year >= 2003:
+			add(time.July, monday(time.July, 3), "海の日")
+		case year >= 1996:
+			add(time.July, 20, "海の日")
+		}
+		if year >= 2016 {
+			add(time.August, 11, "山の日")
+		}
+		switch {
+		case year >= 2022:
+			add(time.October, monday(time.October, 2), "スポーツの日")
+		case year >= 2000:
+			add(time.October, monday(time.October, 2), "体育の日")
+		default:
+			add(time.October, 10, "体育の日")
+		}
+	}
+
+	if year >= 2003 {
+		add(time.September, monday(time.September, 3), "敬老の日")
+	} else {
+		add(time.September, 15, "敬老の日")
+	}
+	add(time.September, autumnalEquinox(year), "秋分の日")
+	add(time.November, 3, "文化の日")
+	add(time.November, 23, "勤労感謝の日")
+	if year >= 1989 && year <= 2018 {
+		add(time.December, 23, "天皇誕生日")
+	}
+
+	// One-off holidays
+	switch year {
+	case 1989:
+		add(time.February, 24, "昭和天皇の大喪の礼")
+	case 1990:
+		add(time.November, 12, "即位礼正殿の儀")
+	case 1993:
+		add(time.June, 9, "皇太子徳仁親王の結婚の儀")
+	case 2019:
+		add(time.May, 1, "天皇の即位の日")
+		add(time.October, 22, "即位礼正殿の儀")
+	}
+
+	// 国民の休日: a weekday between two holidays (since 1986)
+	if year >= 1986 {
+		fixed := make([]time.Time, 0, len(days))
+		for day := range days {
+			fixed = append(fixed, day)
+		}
+		for _, day := range fixed {
+			between := day.AddDate(0, 0, 1)
+			_, isHoliday := days[between]
+			_, nextIsHoliday := days[between.AddDate(0, 0, 1)]
+			if !isHoliday && nextIsHoliday && between.Weekday() != time.Sunday {
+				days[between] = "国民の休日"
+			}
+		}
+	}
+
+	// 振替休日: a holiday on Sunday moves to the next day that is not a holiday (since 1973; before 2007 only Monday)
+	substitutes := make(map[time.Time]string)
+	for day := range days {
+		if day.Weekday() != time.Sunday {
+			continue
+		}
+		next := day.AddDate(0, 0, 1)
+		for year >= 2007 {
+			if _, ok := days[next]; !ok {
+				break
+			}
+			next = next.AddDate(0, 0, 1)
+		}
+		if _, ok := days[next]; !ok {
+			substitutes[next] = "振替休日"
+		}
+	}
+
+	result := make(map[string]string, len(days)+len(substitutes))
+	for day, name := range days {
+		result[day.Format(monthDayLayout)] = name
+	}
+	for day, name := range substitutes {
+		if day.Year() == year {
+			result[day.Format(monthDayLayout)] = name
+		}
+	}
+	return result
+}
+
+// vernalEquinox returns the day of 春分の日 in March (valid 1980-2099)
+func vernalEquinox(year int) int {
+	return int(20.8431+0.242194*float64(year-1980)) - (year-1980)/4
+}
+
+// autumnalEquinox returns the day of 秋分の日 in September (valid 1980-2099)
+func autumnalEquinox(year int) int {
+	return int(23.2488+0.242194*float64(year-1980)) - (year-1980)/4
+}
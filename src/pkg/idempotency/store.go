@@ -0,0 +1,179 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultTTL is how long completed responses are kept when no TTL is configured
+const DefaultTTL = 24 * time.Hour
+
+var (
+	// ErrConflict is returned when a key is reused with a different request payload
+	ErrConflict = errors.New("idempotency key was already used with a different request")
+	// ErrInProgress is returned when a request with the same key is still being processed
+	ErrInProgress = errors.New("a request with this idempotency key is still in progress")
+)
+
+// Store records request fingerprints and responses by idempotency key
+type Store interface {
+	// Begin reserves key for a request with the given fingerprint.
+	// It returns the stored response if the same request already completed,
+	// ErrConflict if the key belongs to a different request, or ErrInProgress if it is still running.
+	// A nil response and nil error mean the caller should process the request and call Complete or Abort.
+	Begin(key, fingerprint string) (proto.Message, error)
+	// Complete stores the response for a reserved key
+	Complete(key string, response proto.Message)
+	// Abort releases a reserved key so the request can be retried.
+	// Records marked as saved are kept until the TTL expires so the retry can skip them.
+	Abort(key string)
+	// MarkSaved records that the request reserved under key saved the record with recordKey
+	MarkSaved(key, recordKey string)
+	// Saved reports whether an earlier attempt with key already saved the record with recordKey
+	Saved(key, recordKey string) bool
+}
+
+// Fingerprint returns a stable hash of a request for comparing retries.
+// Fields that should not take part in the comparison (such as the key itself) must be cleared by the caller.
+func Fingerprint(method string, req proto.Message) (string, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+	hash := sha256.Sum256(append([]byte(method+"\x00"), data...))
+	return fmt.Sprintf("%x", hash), nil
+}
+
+// entry is a reserved, interrupted or completed key
+type entry struct {
+	fingerprint string
+	response    proto.Message
+	expiresAt   time.Time
+	// running is true while a request holds the key
+	running bool
+	// saved holds the records already saved under the key
+	saved map[string]bool
+}
+
+// MemoryStore is an in-process Store that expires completed entries after a TTL
+type MemoryStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*entry
+	now     func() time.Time
+}
+
+// NewMemoryStore creates an in-memory store; a non-positive ttl uses DefaultTTL
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &MemoryStore{
+		ttl:     ttl,
+		entries: make(map[string]*entry),
+		now:     time.Now,
+	}
+}
+
+// Begin implements Store
+func (s *MemoryStore) Begin(key, fingerprint string) (proto.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeExpired()
+
+	existing, ok := s.entries[key]
+	if !ok {
+		s.entries[key] = &entry{fingerprint: fingerprint, running: true}
+		return nil, nil
+	}
+
+	if existing.fingerprint != fingerprint {
+		return nil, ErrConflict
+	}
+	if existing.response != nil {
+		return proto.Clone(existing.response), nil
+	}
+	if existing.running {
+		return nil, ErrInProgress
+	}
+	// An interrupted attempt left saved records behind; the retry resumes from them
+	existing.running = true
+	return nil, nil
+}
+
+// Complete implements Store
+func (s *MemoryStore) Complete(key string, response proto.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.entries[key]; ok {
+		existing.response = proto.Clone(response)
+		existing.expiresAt = s.now().Add(s.ttl)
+		existing.running = false
+		existing.saved = nil
+	}
+}
+
+// Abort implements Store
+func (s *MemoryStore) Abort(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.entries[key]
+	if !ok || existing.response != nil {
+		return
+	}
+	if len(existing.saved) == 0 {
+		delete(s.entries, key)
+		return
+	}
+	existing.running = false
+	existing.expiresAt = s.now().Add(s.ttl)
+}
+
+// MarkSaved implements Store
+func (s *MemoryStore) MarkSaved(key, recordKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.entries[key]; ok && existing.running {
+		if existing.saved == nil {
+			existing.saved = make(map[string]bool)
+		}
+		existing.saved[recordKey] = true
+	}
+}
+
+// Saved implements Store
+func (s *MemoryStore) Saved(key, recordKey string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.entries[key]
+	return ok && existing.saved[recordKey]
+}
+
+// Len returns the number of reserved, interrupted and completed keys
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeExpired()
+	return len(s.entries)
+}
+
+// removeExpired drops completed and interrupted entries past their TTL; callers must hold s.mu
+func (s *MemoryStore) removeExpired() {
+	now := s.now()
+	for key, e := range s.entries {
+		if !e.running && now.After(e.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package interchange
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrConflict is returned when one name is an alias of two different IC codes
+var ErrConflict = errors.New("interchange alias conflict")
+
+// Entry is one interchange in the dictionary
+type Entry struct {
+	Code    string   `yaml:"code"`    // canonical IC code
+	Name    string   `yaml:"name"`    // canonical name stored on records
+	Aliases []string `yaml:"aliases"` // other spellings found on statements
+}
+
+// Dictionary maps IC names and their aliases to canonical entries.
+// It is built once at startup and is safe for concurrent lookups afterwards.
+type Dictionary struct {
+	entries map[string]Entry // keyed by Key(name or alias)
+}
+
+// dictionaryFile is the layout of YAML dictionary files
+type dictionaryFile struct {
+	Interchanges []Entry `yaml:"interchanges"`
+}
+
+// NewDictionary creates an empty dictionary
+func NewDictionary() *Dictionary {
+	return &Dictionary{entries: make(map[string]Entry)}
+}
+
+// LoadDictionary loads a dictionary from a YAML file with an "interchanges" list
+func LoadDictionary(path string) (*Dictionary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read interchange dictionary: %w", err)
+	}
+
+	var file dictionaryFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse interchange dictionary: %w", err)
+	}
+
+	d := NewDictionary()
+	for i, entry := range file.Interchanges {
+		if err := d.Add(entry); err != nil {
+			return nil, fmt.Errorf("interchange dictionary entry %d: %w", i+1, err)
+		}
+	}
+	return d, nil
+}
+
+// Add registers an entry under its name and all of its aliases
+func (d *Dictionary) Add(entry Entry) error {
+	entry.Code = strings.TrimSpace(entry.Code)
+	entry.Name = Normalize(entry.Name)
+	if entry.Code == "" || entry.Name == "" {
+		return fmt.Errorf("code and name are required")
+	}
+
+	for _, name := range append([]string{entry.Name}, entry.Aliases...) {
+		key := Key(name)
+		if key == "" {
+			continue
+		}
+		if existing, ok := d.entries[key]; ok && existing.Code != entry.Code {
+			return fmt.Errorf("%w: %q is used by %s and %s", ErrConflict, name, existing.Code, entry.Code)
+		}
+		d.entries[key] = entry
+	}
+	return nil
+}
+
+// Len returns the number of names (including aliases) in the dictionary
+func (d *Dictionary) Len() int {
+	return len(d.entries)
+}
+
+// Lookup finds the entry for a raw IC name as it appears on a statement
+func (d *Dictionary) Lookup(name string) (Entry, bool) {
+	entry, ok := d.entries[Key(name)]
+	return entry, ok
+}
+
+// UnmatchedName is an IC name that was not found in the dictionary
+type UnmatchedName struct {
+	Name     string   // normalized name
+	Count    int      // number of occurrences
+	RawNames []string // distinct spellings as they appeared on statements
+}
+
+// Unmatched collects IC names missing from the dictionary during an import
+type Unmatched struct {
+	names map[string]*UnmatchedName // keyed by Key(name)
+}
+
+// NewUnmatched creates an empty collector
+func NewUnmatched() *Unmatched {
+	return &Unmatched{names: make(map[string]*UnmatchedName)}
+}
+
+// Add records one occurrence of an unmatched name; empty names are ignored
+func (u *Unmatched) Add(raw string) {
+	key := Key(raw)
+	if key == "" {
+		return
+	}
+
+	name, ok := u.names[key]
+	if !ok {
+		name = &UnmatchedName{Name: Normalize(raw)}
+		u.names[key] = name
+	}
+	name.Count++
+	for _, existing := range name.RawNames {
+		if existing == raw {
+			return
+		}
+	}
+	name.RawNames = append(name.RawNames, raw)
+}
+
+// List returns the unmatched names, most frequent first
+func (u *Unmatched) List() []UnmatchedName {
+	list := make([]UnmatchedName, 0, len(u.names))
+	for _, name := range u.names {
+		list = append(list, *name)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
@@ -0,0 +1,36 @@
+// Package interchange normalizes interchange (IC) names and maps their spelling variants to canonical IC codes.
+package interchange
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// suffixes are dropped when comparing names, so "東京", "東京IC" and "東京ＩＣ" share a key
+var suffixes = []string{"インターチェンジ", "インター", "IC"}
+
+// Normalize applies Unicode NFKC (full-width letters and half-width katakana become their
+// standard forms), trims the name and collapses runs of whitespace into a single space
+func Normalize(name string) string {
+	return strings.Join(strings.Fields(norm.NFKC.String(name)), " ")
+}
+
+// Key is the form used to compare names: Normalize without spaces, upper-cased and
+// without a trailing "IC"/"インター" suffix. Key("東京 ＩＣ") == Key("東京")
+func Key(name string) string {
+	key := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, Normalize(name))
+
+	for _, suffix := range suffixes {
+		if trimmed := strings.TrimSuffix(key, suffix); trimmed != key && trimmed != "" {
+			return trimmed
+		}
+	}
+	return key
+}
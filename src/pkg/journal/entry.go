@@ -0,0 +1,69 @@
+package journal
+
+import (
+	"strings"
+	"time"
+
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+)
+
+// Entry is one journal entry (仕訳) with a single debit and credit line
+type Entry struct {
+	Date             time.Time
+	DebitAccount     string
+	DebitSubAccount  string
+	CreditAccount    string
+	CreditSubAccount string
+	TaxCategory      string // tax category of the expense line; empty uses the format's default
+	Department       string
+	Amount           int // tax-inclusive and always positive; reversals swap the debit and credit accounts
+	TaxAmount        int // consumption tax included in Amount
+	Description      string
+	Reversal         bool
+}
+
+// Vehicle identifies the vehicle of a record for department mapping and descriptions
+type Vehicle struct {
+	ID     string // vehicle ID from the master data
+	Number string // 車両番号 from the statement
+}
+
+// NewEntry turns a converted record into a journal entry. Refund and correction rows
+// (negative amounts) are booked the other way round so the expense is reduced.
+func (s Settings) NewEntry(record parser.ETCRecord, vehicle Vehicle) Entry {
+	s = s.withDefaults()
+	entry := Entry{
+		Date:             record.Date,
+		DebitAccount:     s.DebitAccount,
+		DebitSubAccount:  s.DebitSubAccount,
+		CreditAccount:    s.CreditAccount,
+		CreditSubAccount: s.CreditSubAccount,
+		TaxCategory:      s.TaxCategory,
+		Department:       s.DepartmentFor(record.CardNumber, vehicle.ID, vehicle.Number),
+		Amount:           record.Amount,
+		Reversal:         record.IsReversal(),
+	}
+	if entry.Amount < 0 {
+		entry.Amount = -entry.Amount
+	}
+	if entry.Reversal {
+		entry.DebitAccount, entry.CreditAccount = entry.CreditAccount, entry.DebitAccount
+		entry.DebitSubAccount, entry.CreditSubAccount = entry.CreditSubAccount, entry.DebitSubAccount
+	}
+
+	// 摘要: "ETC 東京→横浜 2302", with 取消 for reversals
+	parts := []string{s.Description}
+	if record.EntryIC != "" || record.ExitIC != "" {
+		parts = append(parts, record.EntryIC+"→"+record.ExitIC)
+	}
+	if vehicle.Number != "" {
+		parts = append(parts, vehicle.Number)
+	} else if vehicle.ID != "" {
+		parts = append(parts, vehicle.ID)
+	}
+	if entry.Reversal {
+		parts = append(parts, "取消")
+	}
+	entry.Description = strings.Join(parts, " ")
+	return entry
+}
@@ -0,0 +1,101 @@
+// Package journal turns converted ETC records into accounting journal entries (仕訳)
+// and writes them in the import formats of accounting software.
+package journal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/card"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/masterdata"
+	"gopkg.in/yaml.v3"
+)
+
+// Default account titles and description prefix
+const (
+	DefaultDebitAccount  = "旅費交通費"
+	DefaultCreditAccount = "未払金"
+	DefaultDescription   = "ETC"
+)
+
+// Department assigns a department (部門) to the records of some cards or vehicles
+type Department struct {
+	Name     string   `yaml:"name"`
+	Cards    []string `yaml:"cards"`    // masked numbers match by suffix
+	Vehicles []string `yaml:"vehicles"` // vehicle IDs from the master data or 車両番号 from the statement
+}
+
+// Settings controls how records are turned into journal entries
+type Settings struct {
+	DebitAccount      string       `yaml:"debit_account"`      // expense account; empty means DefaultDebitAccount
+	DebitSubAccount   string       `yaml:"debit_sub_account"`  // 補助科目 of the expense account
+	CreditAccount     string       `yaml:"credit_account"`     // account settled against; empty means DefaultCreditAccount
+	CreditSubAccount  string       `yaml:"credit_sub_account"` // 補助科目 of the credit account, such as the card issuer
+	TaxCategory       string       `yaml:"tax_category"`       // tax category of the expense; empty uses the format's name for 課税仕入 10%
+	Description       string       `yaml:"description"`        // prefix of 摘要; empty means DefaultDescription
+	DefaultDepartment string       `yaml:"default_department"` // department of records matching no entry in Departments
+	Departments       []Department `yaml:"departments"`
+}
+
+// DefaultSettings returns settings with the default account titles and no departments
+func DefaultSettings() Settings {
+	return Settings{
+		DebitAccount:  DefaultDebitAccount,
+		CreditAccount: DefaultCreditAccount,
+		Description:   DefaultDescription,
+	}
+}
+
+// LoadSettings loads settings from a YAML file; omitted fields keep their defaults
+func LoadSettings(path string) (Settings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Settings{}, fmt.Errorf("failed to read journal settings: %w", err)
+	}
+
+	settings := DefaultSettings()
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return Settings{}, fmt.Errorf("failed to parse journal settings: %w", err)
+	}
+	for i, department := range settings.Departments {
+		if strings.TrimSpace(department.Name) == "" {
+			return Settings{}, fmt.Errorf("journal department %d: name is required", i+1)
+		}
+	}
+	return settings.withDefaults(), nil
+}
+
+// withDefaults fills empty account titles and description with their defaults
+func (s Settings) withDefaults() Settings {
+	if s.DebitAccount == "" {
+		s.DebitAccount = DefaultDebitAccount
+	}
+	if s.CreditAccount == "" {
+		s.CreditAccount = DefaultCreditAccount
+	}
+	if s.Description == "" {
+		s.Description = DefaultDescription
+	}
+	return s
+}
+
+// DepartmentFor returns the department of a card or vehicle; cards are matched before vehicles
+func (s Settings) DepartmentFor(cardNumber, vehicleID, vehicleNumber string) string {
+	cardNumber = card.Normalize(cardNumber)
+	for _, department := range s.Departments {
+		for _, c := range department.Cards {
+			if masterdata.CardMatches(card.Normalize(c), cardNumber) {
+				return department.Name
+			}
+		}
+	}
+	for _, department := range s.Departments {
+		for _, vehicle := range department.Vehicles {
+			if vehicle != "" && (vehicle == vehicleID || vehicle == vehicleNumber) {
+				return department.Name
+			}
+		}
+	}
+	return s.DefaultDepartment
+}
@@ -0,0 +1,165 @@
+package journal
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// Format is the import format of an accounting software
+type Format string
+
+// Supported import formats
+const (
+	FormatFreee        Format = "freee"        // freee 取引インポート (未決済の支出・収入)
+	FormatMoneyForward Format = "moneyforward" // マネーフォワード クラウド会計 仕訳帳インポート
+	FormatYayoi        Format = "yayoi"        // 弥生会計 仕訳日記帳インポート (弥生インポート形式)
+)
+
+var (
+	// ErrUnknownFormat is returned for format names that are not supported
+	ErrUnknownFormat = errors.New("unknown journal format")
+	// ErrUnknownEncoding is returned for output encodings other than Shift_JIS and UTF-8
+	ErrUnknownEncoding = errors.New("unknown journal encoding")
+)
+
+// nonTaxable is the tax category of the settlement side of an entry
+const nonTaxable = "対象外"
+
+// ParseFormat parses a format name ("freee", "moneyforward" or "mf", "yayoi"), ignoring case
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "freee":
+		return FormatFreee, nil
+	case "moneyforward", "mf":
+		return FormatMoneyForward, nil
+	case "yayoi":
+		return FormatYayoi, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownFormat, name)
+}
+
+// DefaultTaxCategory returns the format's name for 課税仕入 10% (tax-inclusive amounts)
+func (f Format) DefaultTaxCategory() string {
+	switch f {
+	case FormatFreee:
+		return "課対仕入10%"
+	case FormatYayoi:
+		return "課対仕入込10%"
+	}
+	return "課税仕入 10%"
+}
+
+// Filename returns the name of an export file in this format
+func (f Format) Filename() string {
+	return fmt.Sprintf("etc_journal_%s.csv", f)
+}
+
+// Write writes entries as CSV in the given format and encoding (parser.EncodingShiftJIS or
+// parser.EncodingUTF8; empty means Shift_JIS, which all three importers accept).
+// Characters that Shift_JIS cannot represent are replaced.
+func Write(w io.Writer, format Format, outputEncoding string, entries []Entry) error {
+	switch outputEncoding {
+	case "", parser.EncodingShiftJIS:
+		w = encoding.ReplaceUnsupported(japanese.ShiftJIS.NewEncoder()).Writer(w)
+	case parser.EncodingUTF8:
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownEncoding, outputEncoding)
+	}
+
+	var header []string
+	var row func(i int, entry Entry) []string
+	switch format {
+	case FormatFreee:
+		header = freeeHeader
+		row = freeeRow
+	case FormatMoneyForward:
+		header = moneyForwardHeader
+		row = moneyForwardRow
+	case FormatYayoi:
+		// Yayoi import files have no header row
+		row = yayoiRow
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+
+	csvWriter := csv.NewWriter(w)
+	csvWriter.UseCRLF = true
+	if header != nil {
+		if err := csvWriter.Write(header); err != nil {
+			return err
+		}
+	}
+	for i, entry := range entries {
+		if entry.TaxCategory == "" {
+			entry.TaxCategory = format.DefaultTaxCategory()
+		}
+		if err := csvWriter.Write(row(i, entry)); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// taxCategories returns the debit and credit tax categories; the expense side carries the entry's category
+func (e Entry) taxCategories() (string, string) {
+	if e.Reversal {
+		return nonTaxable, e.TaxCategory
+	}
+	return e.TaxCategory, nonTaxable
+}
+
+// taxAmounts returns the debit and credit tax amounts; only the expense side carries tax
+func (e Entry) taxAmounts() (string, string) {
+	tax := strconv.Itoa(e.TaxAmount)
+	if e.Reversal {
+		return "", tax
+	}
+	return tax, ""
+}
+
+// freeeHeader is the header of freee's 取引インポート
+var freeeHeader = []string{"収支区分", "管理番号", "発生日", "決済期日", "取引先", "勘定科目", "税区分", "金額", "税計算区分", "税額", "備考", "品目", "部門", "メモタグ（複数指定可、カンマ区切り）"}
+
+// freeeRow books the expense as an unsettled 支出 (or 収入 for reversals); freee settles it against 未払金 itself
+func freeeRow(i int, e Entry) []string {
+	kind, account := "支出", e.DebitAccount
+	if e.Reversal {
+		kind, account = "収入", e.CreditAccount
+	}
+	return []string{kind, "", e.Date.Format("2006/01/02"), "", "", account, e.TaxCategory,
+		strconv.Itoa(e.Amount), "内税", strconv.Itoa(e.TaxAmount), e.Description, "", e.Department, ""}
+}
+
+// moneyForwardHeader is the header of Money Forward's 仕訳帳インポート
+var moneyForwardHeader = []string{"取引No", "取引日", "借方勘定科目", "借方補助科目", "借方部門", "借方税区分", "借方金額(円)", "借方税額",
+	"貸方勘定科目", "貸方補助科目", "貸方部門", "貸方税区分", "貸方金額(円)", "貸方税額", "摘要"}
+
+// moneyForwardRow writes one 仕訳 per entry; the department applies to both lines
+func moneyForwardRow(i int, e Entry) []string {
+	debitTax, creditTax := e.taxCategories()
+	debitTaxAmount, creditTaxAmount := e.taxAmounts()
+	amount := strconv.Itoa(e.Amount)
+	return []string{strconv.Itoa(i + 1), e.Date.Format("2006/01/02"),
+		e.DebitAccount, e.DebitSubAccount, e.Department, debitTax, amount, debitTaxAmount,
+		e.CreditAccount, e.CreditSubAccount, e.Department, creditTax, amount, creditTaxAmount, e.Description}
+}
+
+// yayoiRow writes the 25 columns of a single-line 仕訳 (識別フラグ 2000) in 弥生インポート形式
+func yayoiRow(i int, e Entry) []string {
+	debitTax, creditTax := e.taxCategories()
+	debitTaxAmount, creditTaxAmount := e.taxAmounts()
+	amount := strconv.Itoa(e.Amount)
+	return []string{"2000", strconv.Itoa(i + 1), "", e.Date.Format("2006/01/02"),
+		e.DebitAccount, e.DebitSubAccount, e.Department, debitTax, amount, debitTaxAmount,
+		e.CreditAccount, e.CreditSubAccount, e.Department, creditTax, amount, creditTaxAmount,
+		e.Description, "", "", "0", "", "", "0", "0", "no"}
+}
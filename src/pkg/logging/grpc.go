@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDHeader is the metadata key carrying the request ID; it is echoed in the response header
+const RequestIDHeader = "x-request-id"
+
+// accountRequest is implemented by requests that carry an account ID
+type accountRequest interface {
+	GetAccountId() string
+}
+
+// UnaryServerInterceptor tags each call with a request ID (taken from the x-request-id metadata or generated)
+// and the account ID of the request, and logs its outcome
+func UnaryServerInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+
+		requestID := ""
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(RequestIDHeader); len(values) > 0 {
+				requestID = values[0]
+			}
+		}
+		if requestID == "" {
+			requestID = NewRequestID()
+		}
+		ctx = WithRequestID(ctx, requestID)
+		if r, ok := req.(accountRequest); ok {
+			ctx = WithAccountID(ctx, r.GetAccountId())
+		}
+		// The header is informational; failing to set it must not fail the call
+		_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, requestID))
+
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		attrs := []slog.Attr{
+			slog.String("method", info.FullMethod),
+			slog.String("code", code.String()),
+			slog.Int64("duration_ms", time.Since(start).Milliseconds()),
+		}
+		switch code {
+		case codes.OK:
+			logger.LogAttrs(ctx, slog.LevelInfo, "request completed", attrs...)
+		case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+			logger.LogAttrs(ctx, slog.LevelError, "request failed", append(attrs, slog.String("error", err.Error()))...)
+		default:
+			logger.LogAttrs(ctx, slog.LevelWarn, "request failed", append(attrs, slog.String("error", err.Error()))...)
+		}
+		return resp, err
+	}
+}
@@ -0,0 +1,103 @@
+// Package logging sets up structured JSON logging with log/slog. Every line carries the
+// request ID and account ID of the gRPC call it belongs to (empty outside a call).
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Attribute keys added to every line
+const (
+	RequestIDKey = "request_id"
+	AccountIDKey = "account_id"
+)
+
+// ParseLevel parses a log level name (debug, info, warn or error); an empty name is info
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("invalid log_level %q (must be debug, info, warn or error)", name)
+}
+
+// New creates a logger writing JSON lines at the given level or above
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(&contextHandler{Handler: slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})})
+}
+
+// contextKey is the type of context keys set by this package
+type contextKey int
+
+const (
+	requestIDContextKey contextKey = iota
+	accountIDContextKey
+)
+
+// WithRequestID returns a context whose log lines carry the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, requestID)
+}
+
+// WithAccountID returns a context whose log lines carry the account ID
+func WithAccountID(ctx context.Context, accountID string) context.Context {
+	return context.WithValue(ctx, accountIDContextKey, accountID)
+}
+
+// RequestID returns the request ID of a context, or an empty string
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey).(string)
+	return id
+}
+
+// AccountID returns the account ID of a context, or an empty string
+func AccountID(ctx context.Context) string {
+	id, _ := ctx.Value(accountIDContextKey).(string)
+	return id
+}
+
+// NewRequestID generates a request ID for calls that do not bring one
+func NewRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// contextHandler adds the request and account IDs of the context to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+// Handle implements slog.Handler
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	record.AddAttrs(slog.String(RequestIDKey, RequestID(ctx)), slog.String(AccountIDKey, AccountID(ctx)))
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs implements slog.Handler
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
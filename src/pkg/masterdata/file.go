@@ -0,0 +1,221 @@
+package masterdata
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// csvHeader is the column layout of CSV registry files
+var csvHeader = []string{"id", "card_number", "vehicle_number", "vehicle_id", "driver_id", "valid_from", "valid_to", "vehicle_class"}
+
+// fileAssignment is the on-disk representation of an assignment
+type fileAssignment struct {
+	ID            string `yaml:"id"`
+	CardNumber    string `yaml:"card_number,omitempty"`
+	VehicleNumber string `yaml:"vehicle_number,omitempty"`
+	VehicleID     string `yaml:"vehicle_id,omitempty"`
+	DriverID      string `yaml:"driver_id,omitempty"`
+	VehicleClass  string `yaml:"vehicle_class,omitempty"` // code ("1") or label ("普通車")
+	ValidFrom     string `yaml:"valid_from,omitempty"`
+	ValidTo       string `yaml:"valid_to,omitempty"`
+}
+
+// fileRegistry is the layout of YAML registry files
+type fileRegistry struct {
+	Assignments []fileAssignment `yaml:"assignments"`
+}
+
+// LoadFile loads a registry from a CSV or YAML file (chosen by extension).
+// Changes made through the registry are written back to the same file.
+// A missing file yields an empty registry that is created on the first change.
+func LoadFile(path string) (*Registry, error) {
+	r := NewRegistry()
+	r.path = path
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read master data file: %w", err)
+	}
+
+	var records []fileAssignment
+	if isCSV(path) {
+		records, err = decodeCSV(data)
+	} else {
+		var file fileRegistry
+		err = yaml.Unmarshal(data, &file)
+		records = file.Assignments
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse master data file: %w", err)
+	}
+
+	for i, record := range records {
+		a, err := record.toAssignment()
+		if err == nil {
+			a, err = normalize(a)
+		}
+		if err == nil && a.ID == "" {
+			a.ID = newID()
+		}
+		if err == nil {
+			err = r.checkOverlap(a)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("master data entry %d: %w", i+1, err)
+		}
+		r.assignments[a.ID] = a
+	}
+	return r, nil
+}
+
+// save writes the registry to its file; callers must hold r.mu
+func (r *Registry) save() error {
+	if r.path == "" {
+		return nil
+	}
+
+	list := make([]Assignment, 0, len(r.assignments))
+	for _, a := range r.assignments {
+		list = append(list, a)
+	}
+	sortAssignments(list)
+
+	records := make([]fileAssignment, len(list))
+	for i, a := range list {
+		records[i] = fromAssignment(a)
+	}
+
+	var data []byte
+	var err error
+	if isCSV(r.path) {
+		data, err = encodeCSV(records)
+	} else {
+		data, err = yaml.Marshal(fileRegistry{Assignments: records})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode master data: %w", err)
+	}
+
+	// Write to a temporary file first so a failed write never truncates the registry
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write master data file: %w", err)
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		return fmt.Errorf("failed to write master data file: %w", err)
+	}
+	return nil
+}
+
+// toAssignment parses the vehicle class and dates of a file entry
+func (f fileAssignment) toAssignment() (Assignment, error) {
+	vehicleClass, err := parser.VehicleClassFromString(f.VehicleClass)
+	if err != nil {
+		return Assignment{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	validFrom, err := ParseDate(f.ValidFrom)
+	if err != nil {
+		return Assignment{}, err
+	}
+	validTo, err := ParseDate(f.ValidTo)
+	if err != nil {
+		return Assignment{}, err
+	}
+	return Assignment{
+		ID:            f.ID,
+		CardNumber:    f.CardNumber,
+		VehicleNumber: f.VehicleNumber,
+		VehicleID:     f.VehicleID,
+		DriverID:      f.DriverID,
+		VehicleClass:  vehicleClass,
+		ValidFrom:     validFrom,
+		ValidTo:       validTo,
+	}, nil
+}
+
+// fromAssignment converts an assignment to its file representation
+func fromAssignment(a Assignment) fileAssignment {
+	vehicleClass := ""
+	if a.VehicleClass != parser.VehicleClassUnknown {
+		vehicleClass = strconv.Itoa(int(a.VehicleClass))
+	}
+	return fileAssignment{
+		ID:            a.ID,
+		CardNumber:    a.CardNumber,
+		VehicleNumber: a.VehicleNumber,
+		VehicleID:     a.VehicleID,
+		DriverID:      a.DriverID,
+		VehicleClass:  vehicleClass,
+		ValidFrom:     FormatDate(a.ValidFrom),
+		ValidTo:       FormatDate(a.ValidTo),
+	}
+}
+
+// isCSV reports whether a registry file uses the CSV layout
+func isCSV(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".csv")
+}
+
+// decodeCSV reads registry entries from CSV with a header row
+func decodeCSV(data []byte) ([]fileAssignment, error) {
+	rows, err := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte{0xEF, 0xBB, 0xBF}))).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		columns[strings.TrimSpace(name)] = i
+	}
+	field := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var records []fileAssignment
+	for _, row := range rows[1:] {
+		records = append(records, fileAssignment{
+			ID:            field(row, "id"),
+			CardNumber:    field(row, "card_number"),
+			VehicleNumber: field(row, "vehicle_number"),
+			VehicleID:     field(row, "vehicle_id"),
+			DriverID:      field(row, "driver_id"),
+			VehicleClass:  field(row, "vehicle_class"),
+			ValidFrom:     field(row, "valid_from"),
+			ValidTo:       field(row, "valid_to"),
+		})
+	}
+	return records, nil
+}
+
+// encodeCSV writes registry entries as CSV with a header row
+func encodeCSV(records []fileAssignment) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(csvHeader); err != nil {
+		return nil, err
+	}
+	for _, f := range records {
+		if err := w.Write([]string{f.ID, f.CardNumber, f.VehicleNumber, f.VehicleID, f.DriverID, f.ValidFrom, f.ValidTo, f.VehicleClass}); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
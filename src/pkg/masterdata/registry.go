@@ -0,0 +1,337 @@
+// Package masterdata maps ETC cards and vehicle numbers to internal vehicles and drivers.
+package masterdata
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/card"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+)
+
+// DateLayout is the format of validity dates in files and RPCs
+const DateLayout = "2006-01-02"
+
+var (
+	// ErrNotFound is returned when no assignment has the requested ID
+	ErrNotFound = errors.New("card assignment not found")
+	// ErrInvalid is returned for assignments with missing or malformed fields
+	ErrInvalid = errors.New("invalid card assignment")
+	// ErrOverlap is returned when an assignment overlaps another one for the same card or vehicle
+	ErrOverlap = errors.New("card assignment overlaps an existing assignment")
+)
+
+// Assignment assigns an ETC card (or, without a card, a vehicle number) to a vehicle and driver for a period
+type Assignment struct {
+	ID            string
+	CardNumber    string              // normalized; may be masked like statements ("********12345678")
+	VehicleNumber string              // 車両番号 as printed on statements
+	VehicleID     string              // internal vehicle ID
+	DriverID      string              // internal driver ID
+	VehicleClass  parser.VehicleClass // registered 車種区分 of the vehicle; unknown means not registered
+	ValidFrom     time.Time           // first day the assignment applies; zero means no start
+	ValidTo       time.Time           // last day the assignment applies; zero means open-ended
+}
+
+// covers reports whether the assignment applies on the given day
+func (a Assignment) covers(date time.Time) bool {
+	day := truncateDay(date)
+	if !a.ValidFrom.IsZero() && day.Before(a.ValidFrom) {
+		return false
+	}
+	if !a.ValidTo.IsZero() && day.After(a.ValidTo) {
+		return false
+	}
+	return true
+}
+
+// overlaps reports whether the validity periods of two assignments share a day
+func (a Assignment) overlaps(other Assignment) bool {
+	startsBeforeOtherEnds := other.ValidTo.IsZero() || a.ValidFrom.IsZero() || !a.ValidFrom.After(other.ValidTo)
+	endsAfterOtherStarts := a.ValidTo.IsZero() || other.ValidFrom.IsZero() || !a.ValidTo.Before(other.ValidFrom)
+	return startsBeforeOtherEnds && endsAfterOtherStarts
+}
+
+// sameSubject reports whether two assignments are for the same card, or the same vehicle when neither has a card
+func (a Assignment) sameSubject(other Assignment) bool {
+	if a.CardNumber != "" || other.CardNumber != "" {
+		return CardMatches(a.CardNumber, other.CardNumber)
+	}
+	return a.VehicleNumber == other.VehicleNumber
+}
+
+// Registry holds card assignments in memory and optionally persists them to a CSV or YAML file
+type Registry struct {
+	mu          sync.RWMutex
+	assignments map[string]Assignment
+	path        string // file changes are written to; empty keeps the registry in memory only
+}
+
+// NewRegistry creates an empty in-memory registry
+func NewRegistry() *Registry {
+	return &Registry{assignments: make(map[string]Assignment)}
+}
+
+// Len returns the number of assignments
+func (r *Registry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.assignments)
+}
+
+// Create validates and adds an assignment, generating an ID when none is given
+func (r *Registry) Create(a Assignment) (Assignment, error) {
+	a, err := normalize(a)
+	if err != nil {
+		return Assignment{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if a.ID == "" {
+		a.ID = newID()
+	} else if _, exists := r.assignments[a.ID]; exists {
+		return Assignment{}, fmt.Errorf("%w: id %q already exists", ErrInvalid, a.ID)
+	}
+	if err := r.checkOverlap(a); err != nil {
+		return Assignment{}, err
+	}
+
+	r.assignments[a.ID] = a
+	if err := r.save(); err != nil {
+		delete(r.assignments, a.ID)
+		return Assignment{}, err
+	}
+	return a, nil
+}
+
+// Get returns the assignment with the given ID
+func (r *Registry) Get(id string) (Assignment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	a, ok := r.assignments[id]
+	if !ok {
+		return Assignment{}, fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+	return a, nil
+}
+
+// Update replaces an existing assignment
+func (r *Registry) Update(a Assignment) (Assignment, error) {
+	a, err := normalize(a)
+	if err != nil {
+		return Assignment{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, ok := r.assignments[a.ID]
+	if !ok {
+		return Assignment{}, fmt.Errorf("%w: %q", ErrNotFound, a.ID)
+	}
+	if err := r.checkOverlap(a); err != nil {
+		return Assignment{}, err
+	}
+
+	r.assignments[a.ID] = a
+	if err := r.save(); err != nil {
+		r.assignments[a.ID] = previous
+		return Assignment{}, err
+	}
+	return a, nil
+}
+
+// Delete removes an assignment
+func (r *Registry) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, ok := r.assignments[id]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+
+	delete(r.assignments, id)
+	if err := r.save(); err != nil {
+		r.assignments[id] = previous
+		return err
+	}
+	return nil
+}
+
+// List returns assignments ordered by card, vehicle and start date, optionally filtered by card or vehicle number
+func (r *Registry) List(cardNumber, vehicleNumber string) []Assignment {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cardNumber = card.Normalize(cardNumber)
+	var list []Assignment
+	for _, a := range r.assignments {
+		if cardNumber != "" && !CardMatches(a.CardNumber, cardNumber) {
+			continue
+		}
+		if vehicleNumber != "" && a.VehicleNumber != vehicleNumber {
+			continue
+		}
+		list = append(list, a)
+	}
+	sortAssignments(list)
+	return list
+}
+
+// Lookup finds the assignment for a trip: by card first, then by vehicle number for assignments without a card
+func (r *Registry) Lookup(cardNumber, vehicleNumber string, date time.Time) (Assignment, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cardNumber = card.Normalize(cardNumber)
+	var byVehicle *Assignment
+	for _, a := range r.assignments {
+		if !a.covers(date) {
+			continue
+		}
+		if a.CardNumber != "" {
+			if cardNumber != "" && CardMatches(a.CardNumber, cardNumber) {
+				return a, true
+			}
+			continue
+		}
+		if vehicleNumber != "" && a.VehicleNumber == vehicleNumber {
+			match := a
+			byVehicle = &match
+		}
+	}
+	if byVehicle != nil {
+		return *byVehicle, true
+	}
+	return Assignment{}, false
+}
+
+// checkOverlap rejects assignments that overlap another assignment for the same subject; callers must hold r.mu
+func (r *Registry) checkOverlap(a Assignment) error {
+	for id, existing := range r.assignments {
+		if id == a.ID {
+			continue
+		}
+		if existing.sameSubject(a) && existing.overlaps(a) {
+			return fmt.Errorf("%w: %q", ErrOverlap, id)
+		}
+	}
+	return nil
+}
+
+// CardMatches compares card numbers, treating leading '*' as unknown digits so masked statement numbers match full ones
+func CardMatches(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	if a == b {
+		return true
+	}
+	if len(a) != len(b) {
+		return false
+	}
+
+	visibleA := strings.TrimLeft(a, "*")
+	visibleB := strings.TrimLeft(b, "*")
+	visible := len(visibleA)
+	if len(visibleB) < visible {
+		visible = len(visibleB)
+	}
+	if visible < 4 {
+		return false
+	}
+	return a[len(a)-visible:] == b[len(b)-visible:]
+}
+
+// normalize validates an assignment and normalizes its card number and dates
+func normalize(a Assignment) (Assignment, error) {
+	a.ID = strings.TrimSpace(a.ID)
+	a.CardNumber = card.Normalize(a.CardNumber)
+	a.VehicleNumber = strings.TrimSpace(a.VehicleNumber)
+	a.VehicleID = strings.TrimSpace(a.VehicleID)
+	a.DriverID = strings.TrimSpace(a.DriverID)
+
+	if a.CardNumber == "" && a.VehicleNumber == "" {
+		return Assignment{}, fmt.Errorf("%w: card_number or vehicle_number is required", ErrInvalid)
+	}
+	if a.CardNumber != "" {
+		if err := card.Validate(a.CardNumber); err != nil {
+			return Assignment{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+	}
+	if a.VehicleID == "" && a.DriverID == "" {
+		return Assignment{}, fmt.Errorf("%w: vehicle_id or driver_id is required", ErrInvalid)
+	}
+	if a.VehicleClass != parser.VehicleClassUnknown && !a.VehicleClass.IsValid() {
+		return Assignment{}, fmt.Errorf("%w: %v: %d", ErrInvalid, parser.ErrUnknownVehicleClass, a.VehicleClass)
+	}
+
+	if !a.ValidFrom.IsZero() {
+		a.ValidFrom = truncateDay(a.ValidFrom)
+	}
+	if !a.ValidTo.IsZero() {
+		a.ValidTo = truncateDay(a.ValidTo)
+	}
+	if !a.ValidFrom.IsZero() && !a.ValidTo.IsZero() && a.ValidTo.Before(a.ValidFrom) {
+		return Assignment{}, fmt.Errorf("%w: valid_to is before valid_from", ErrInvalid)
+	}
+	return a, nil
+}
+
+// ParseDate parses an optional validity date; an empty string is the zero time
+func ParseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.Parse(DateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: date %q must be YYYY-MM-DD", ErrInvalid, value)
+	}
+	return date, nil
+}
+
+// FormatDate formats an optional validity date; the zero time is an empty string
+func FormatDate(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.Format(DateLayout)
+}
+
+// truncateDay drops the time of day, keeping the calendar date in UTC
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// sortAssignments orders assignments by card, vehicle and start date
+func sortAssignments(list []Assignment) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CardNumber != list[j].CardNumber {
+			return list[i].CardNumber < list[j].CardNumber
+		}
+		if list[i].VehicleNumber != list[j].VehicleNumber {
+			return list[i].VehicleNumber < list[j].VehicleNumber
+		}
+		return list[i].ValidFrom.Before(list[j].ValidFrom)
+	})
+}
+
+// newID generates a random assignment ID
+func newID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
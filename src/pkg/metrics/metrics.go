@@ -0,0 +1,171 @@
+// Package metrics exposes import throughput, RPC and db_service metrics in the Prometheus format.
+// A nil *Metrics is valid and records nothing, so callers do not need to check whether metrics are enabled.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// namespace prefixes every metric name
+const namespace = "etc_processor"
+
+// Record outcomes of the records_total metric
+const (
+	OutcomeParsed  = "parsed"
+	OutcomeSaved   = "saved"
+	OutcomeSkipped = "skipped"
+	OutcomeErrored = "errored"
+)
+
+// UnknownFormat labels records whose file format was not detected (CSV data sent in the request)
+const UnknownFormat = "unknown"
+
+// Metrics holds the collectors of the service and the registry they are exposed from
+type Metrics struct {
+	registry       *prometheus.Registry
+	rpcDuration    *prometheus.HistogramVec
+	rpcErrors      *prometheus.CounterVec
+	rpcInFlight    prometheus.Gauge
+	records        *prometheus.CounterVec
+	pendingRecords prometheus.Gauge
+	dedupChecks    prometheus.Counter
+	dedupHits      prometheus.Counter
+	dbDuration     *prometheus.HistogramVec
+	dbErrors       *prometheus.CounterVec
+}
+
+// New creates the collectors on a dedicated registry, together with the Go runtime and process collectors
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		rpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "rpc_duration_seconds",
+			Help:      "Latency of gRPC calls by method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+		rpcErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rpc_errors_total",
+			Help:      "gRPC calls that returned an error, by method and status code.",
+		}, []string{"method", "code"}),
+		rpcInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "rpc_in_flight",
+			Help:      "gRPC calls being processed.",
+		}),
+		records: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "records_total",
+			Help:      "Imported statement records by outcome (parsed, saved, skipped, errored), account and file format.",
+		}, []string{"outcome", "account", "format"}),
+		pendingRecords: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "pending_records",
+			Help:      "Parsed records of imports in progress.",
+		}),
+		dedupChecks: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dedup_checks_total",
+			Help:      "Records checked against the duplicates of their import.",
+		}),
+		dedupHits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dedup_hits_total",
+			Help:      "Records skipped as duplicates; divide by dedup_checks_total for the hit rate.",
+		}),
+		dbDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_call_duration_seconds",
+			Help:      "Latency of db_service calls by operation.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation"}),
+		dbErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "db_call_errors_total",
+			Help:      "db_service calls that failed, by operation.",
+		}, []string{"operation"}),
+	}
+	m.registry.MustRegister(
+		m.rpcDuration, m.rpcErrors, m.rpcInFlight,
+		m.records, m.pendingRecords, m.dedupChecks, m.dedupHits,
+		m.dbDuration, m.dbErrors,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// UnaryServerInterceptor records the latency and errors of each call
+func (m *Metrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if m == nil {
+			return handler(ctx, req)
+		}
+		m.rpcInFlight.Inc()
+		defer m.rpcInFlight.Dec()
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		code := status.Code(err).String()
+		m.rpcDuration.WithLabelValues(info.FullMethod, code).Observe(time.Since(start).Seconds())
+		if err != nil {
+			m.rpcErrors.WithLabelValues(info.FullMethod, code).Inc()
+		}
+		return resp, err
+	}
+}
+
+// AddRecords counts n records of an import with the given outcome
+func (m *Metrics) AddRecords(outcome, account, format string, n int) {
+	if m == nil || n == 0 {
+		return
+	}
+	if format == "" {
+		format = UnknownFormat
+	}
+	m.records.WithLabelValues(outcome, account, format).Add(float64(n))
+}
+
+// AddPending adjusts the number of parsed records waiting to be processed; negative n removes them
+func (m *Metrics) AddPending(n int) {
+	if m == nil {
+		return
+	}
+	m.pendingRecords.Add(float64(n))
+}
+
+// ObserveDedup counts a duplicate check and whether it found a duplicate
+func (m *Metrics) ObserveDedup(hit bool) {
+	if m == nil {
+		return
+	}
+	m.dedupChecks.Inc()
+	if hit {
+		m.dedupHits.Inc()
+	}
+}
+
+// ObserveDBCall records the latency and outcome of a db_service call
+func (m *Metrics) ObserveDBCall(operation string, duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.dbDuration.WithLabelValues(operation).Observe(duration.Seconds())
+	if err != nil {
+		m.dbErrors.WithLabelValues(operation).Inc()
+	}
+}
@@ -0,0 +1,63 @@
+package parser
+
+import "strings"
+
+// CorrectionReason explains why a record was detected as a refund or correction row
+type CorrectionReason string
+
+// Correction reasons
+const (
+	CorrectionNone        CorrectionReason = ""                     // regular charge
+	CorrectionNegative    CorrectionReason = "negative_amount"      // a fare column is negative
+	CorrectionNotes       CorrectionReason = "notes"                // 備考 marks the row as a refund or correction
+	CorrectionEarlierTrip CorrectionReason = "matches_earlier_trip" // the row returns the discount of a trip seen earlier
+)
+
+// correctionKeywords are 備考 terms used on refund and correction rows
+var correctionKeywords = []string{"取消", "取り消し", "訂正", "返金", "払戻", "払い戻し", "減額", "赤伝"}
+
+// chargedAmount returns the amount billed for a record: the post-payment fare first,
+// then the charged fare, then the fare before discount
+func chargedAmount(actual ActualETCRecord) int {
+	amount := actual.PostPaymentAmount
+	if amount == 0 {
+		amount = actual.ETCAmount
+	}
+	if amount == 0 {
+		amount = actual.NormalAmount
+	}
+	return amount
+}
+
+// signedAmount returns the charged amount of a record, negative for refund and correction rows
+func signedAmount(actual ActualETCRecord) int {
+	amount := chargedAmount(actual)
+	if DetectCorrection(actual) != CorrectionNone && amount > 0 {
+		amount = -amount
+	}
+	return amount
+}
+
+// DetectCorrection reports whether a record is a refund or correction row based on its own values.
+// Matching against earlier trips needs the rest of the statement and is left to the caller.
+func DetectCorrection(actual ActualETCRecord) CorrectionReason {
+	if actual.NormalAmount < 0 || actual.ETCAmount < 0 || actual.PostPaymentAmount < 0 {
+		return CorrectionNegative
+	}
+	for _, keyword := range correctionKeywords {
+		if strings.Contains(actual.Notes, keyword) {
+			return CorrectionNotes
+		}
+	}
+	return CorrectionNone
+}
+
+// TripKey identifies the trip a record belongs to, independent of its amounts
+func TripKey(actual ActualETCRecord) string {
+	return strings.Join([]string{
+		actual.EntryDate, actual.EntryTime,
+		actual.ExitDate, actual.ExitTime,
+		actual.EntryIC, actual.ExitIC,
+		actual.CardNumber,
+	}, "_")
+}
@@ -15,9 +15,30 @@ type ETCRecord struct {
 	EntryIC     string
 	ExitIC      string
 	Route       string
-	VehicleType int
+	VehicleType VehicleClass
 	Amount      int
 	CardNumber  string
+
+	// Amount breakdown as it appears on the statement
+	NormalAmount      int // 割引前料金
+	DiscountAmount    int // ETC割引額 (negative)
+	ETCAmount         int // 通行料金
+	PostPaymentAmount int // 後納料金
+	Mileage           int // マイレージ
+
+	// RedemptionBaseAmount is the yen amount the mileage redemption applies to (還元額適用料金)
+	RedemptionBaseAmount int
+
+	// Correction is set on refund and correction rows, which carry a negative Amount
+	Correction CorrectionReason
+
+	// Segments is Route split into ordered road sections (see ParseRoute)
+	Segments []RouteSegment
+}
+
+// IsReversal reports whether the record reverses an earlier charge
+func (r ETCRecord) IsReversal() bool {
+	return r.Correction != CorrectionNone
 }
 
 // CSVParser handles CSV file parsing
@@ -79,7 +100,7 @@ func (p *CSVParser) ProcessRecords(records [][]string, startIndex int) ([]ETCRec
 		}
 
 		// Parse vehicle type
-		vehicleType, err := strconv.Atoi(record[4])
+		vehicleType, err := VehicleClassFromString(record[4])
 		if err != nil {
 			return nil, fmt.Errorf("invalid vehicle type at line %d: %w", i+1, err)
 		}
@@ -128,8 +149,8 @@ func (p *CSVParser) ValidateRecord(record ETCRecord) error {
 	if record.Route == "" {
 		return fmt.Errorf("route cannot be empty")
 	}
-	if record.VehicleType == 0 {
-		return fmt.Errorf("vehicle type cannot be zero")
+	if err := record.VehicleType.Validate(); err != nil {
+		return err
 	}
 	if record.CardNumber == "" {
 		return fmt.Errorf("card number cannot be empty")
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"bytes"
 	"encoding/csv"
 	"fmt"
 	"io"
@@ -8,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"golang.org/x/text/encoding/japanese"
 	"golang.org/x/text/transform"
@@ -26,10 +28,34 @@ type ActualETCRecord struct {
 	NormalAmount  int    // 通行料金
 	DiscountApplied int  // 割引金額適用
 	Mileage       int    // マイレージ
-	VehicleClass  int    // 車種
+	PostPaymentAmount int // 後納料金
+	RedemptionBaseAmount int // 還元額適用料金
+	VehicleClass  VehicleClass // 車種
 	VehicleNumber string // 車両番号
 	CardNumber    string // ETCカード番号
 	Notes         string // 備考
+	LineNumber    int    // 元CSVの行番号（1始まり、ヘッダー行を含む）
+}
+
+// Detected CSV layouts
+const (
+	FormatHeader     = "header"     // columns mapped by header names
+	FormatPositional = "positional" // columns mapped by position (no header row)
+)
+
+// Detected file encodings
+const (
+	EncodingShiftJIS = "Shift_JIS"
+	EncodingUTF8     = "UTF-8"
+)
+
+// utf8BOM is the byte order mark some tools prepend to UTF-8 CSV exports
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// FileInfo describes how a CSV file was decoded and laid out
+type FileInfo struct {
+	Format   string // FormatHeader or FormatPositional
+	Encoding string // EncodingShiftJIS or EncodingUTF8
 }
 
 // ETCCSVParser handles actual ETC CSV file parsing
@@ -40,24 +66,93 @@ func NewETCCSVParser() *ETCCSVParser {
 	return &ETCCSVParser{}
 }
 
-// ParseFile parses an actual ETC CSV file with Shift-JIS encoding
+// ParseFile parses an actual ETC CSV file (Shift-JIS or UTF-8 encoded)
 func (p *ETCCSVParser) ParseFile(filepath string) ([]ActualETCRecord, error) {
-	file, err := os.Open(filepath)
+	records, _, err := p.ParseFileWithInfo(filepath)
+	return records, err
+}
+
+// ParseFileWithInfo parses an ETC CSV file and reports its detected format and encoding
+func (p *ETCCSVParser) ParseFileWithInfo(filepath string) ([]ActualETCRecord, FileInfo, error) {
+	reader, info, err := p.openFile(filepath)
+	if err != nil {
+		return nil, info, err
+	}
+
+	records, format, err := p.parse(reader)
+	info.Format = format
+	return records, info, err
+}
+
+// ReadFile reads a CSV file as UTF-8 text (converting Shift-JIS) without parsing it
+func (p *ETCCSVParser) ReadFile(filepath string) (string, FileInfo, error) {
+	reader, info, err := p.openFile(filepath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
+		return "", info, err
 	}
-	defer file.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", info, fmt.Errorf("failed to read file: %w", err)
+	}
+	return string(data), info, nil
+}
 
-	// Convert from Shift-JIS to UTF-8
-	reader := transform.NewReader(file, japanese.ShiftJIS.NewDecoder())
+// openFile reads a CSV file and returns a UTF-8 reader over its contents
+func (p *ETCCSVParser) openFile(filepath string) (io.Reader, FileInfo, error) {
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, FileInfo{}, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	info := FileInfo{Encoding: DetectEncoding(data)}
 
-	return p.Parse(reader)
+	if info.Encoding == EncodingShiftJIS {
+		// Convert from Shift-JIS to UTF-8
+		return transform.NewReader(bytes.NewReader(data), japanese.ShiftJIS.NewDecoder()), info, nil
+	}
+	return bytes.NewReader(bytes.TrimPrefix(data, utf8BOM)), info, nil
+}
+
+// DetectEncoding guesses the encoding of raw CSV bytes.
+// Data with a UTF-8 BOM or that is valid UTF-8 is treated as UTF-8, anything else as Shift-JIS.
+func DetectEncoding(data []byte) string {
+	if bytes.HasPrefix(data, utf8BOM) || utf8.Valid(data) {
+		return EncodingUTF8
+	}
+	return EncodingShiftJIS
 }
 
 // Parse parses CSV data from a reader
 func (p *ETCCSVParser) Parse(reader io.Reader) ([]ActualETCRecord, error) {
+	records, _, err := p.parse(reader)
+	return records, err
+}
+
+// parse parses CSV data from a reader and reports whether a header row was used
+func (p *ETCCSVParser) parse(reader io.Reader) ([]ActualETCRecord, string, error) {
+	rows, format, err := p.parseRows(reader)
+	if err != nil {
+		return nil, format, err
+	}
+
+	var etcRecords []ActualETCRecord
+	for _, row := range rows {
+		etcRecords = append(etcRecords, row.record)
+	}
+	return etcRecords, format, nil
+}
+
+// parsedRow is a parsed record together with its raw columns and field mappings
+type parsedRow struct {
+	record   ActualETCRecord
+	raw      []string
+	mappings []FieldMapping
+}
+
+// parseRows reads CSV data and maps each data row onto an ActualETCRecord
+func (p *ETCCSVParser) parseRows(reader io.Reader) ([]parsedRow, string, error) {
 	if reader == nil {
-		return nil, fmt.Errorf("reader cannot be nil")
+		return nil, "", fmt.Errorf("reader cannot be nil")
 	}
 
 	csvReader := csv.NewReader(reader)
@@ -67,16 +162,17 @@ func (p *ETCCSVParser) Parse(reader io.Reader) ([]ActualETCRecord, error) {
 	// Read all records
 	records, err := csvReader.ReadAll()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV: %w", err)
+		return nil, "", fmt.Errorf("failed to read CSV: %w", err)
 	}
 
 	if len(records) == 0 {
-		return nil, fmt.Errorf("CSV file is empty")
+		return nil, "", fmt.Errorf("CSV file is empty")
 	}
 
 	// Parse header and create column mapping
 	headerMap := make(map[string]int)
 	startIndex := 0
+	format := FormatPositional
 
 	// Check if first row is header
 	if len(records) > 0 {
@@ -99,23 +195,24 @@ func (p *ETCCSVParser) Parse(reader io.Reader) ([]ActualETCRecord, error) {
 				headerMap[col] = idx
 			}
 			startIndex = 1
+			format = FormatHeader
 		}
 	}
 
 	if err := p.ValidateRecordsAvailable(records, startIndex); err != nil {
-		return nil, err
+		return nil, format, err
 	}
 
-	var etcRecords []ActualETCRecord
+	var rows []parsedRow
 	for i := startIndex; i < len(records); i++ {
 		record := records[i]
 
 		// Parse using header mapping if available, otherwise use positional
-		var etcRecord ActualETCRecord
+		var mapper *recordMapper
 
 		if len(headerMap) > 0 {
 			// Use header-based mapping
-			etcRecord = p.parseWithHeaders(record, headerMap)
+			mapper = p.mapWithHeaders(record, headerMap)
 		} else {
 			// Use positional mapping (backward compatibility)
 			// Ensure we have minimum required fields
@@ -123,82 +220,22 @@ func (p *ETCCSVParser) Parse(reader io.Reader) ([]ActualETCRecord, error) {
 				// Skip this record silently - insufficient fields
 				continue
 			}
-
-			etcRecord = ActualETCRecord{
-				EntryDate:     record[0],
-				EntryTime:     record[1],
-				ExitDate:      record[2],
-				ExitTime:      record[3],
-				EntryIC:       record[4],
-				ExitIC:        record[5],
-				RouteInfo:     p.getFieldSafe(record, 6),
-				Notes:         "",
-			}
-
-			// Parse ETC amount (field 7)
-			if p.getFieldSafe(record, 7) != "" {
-				amount, err := p.parseAmount(p.getFieldSafe(record, 7))
-				if err != nil {
-					// Log warning but continue
-					etcRecord.ETCAmount = 0
-				} else {
-					etcRecord.ETCAmount = amount
-				}
-			}
-
-			// Parse normal amount (field 8)
-			if p.getFieldSafe(record, 8) != "" {
-				amount, err := p.parseAmount(p.getFieldSafe(record, 8))
-				if err != nil {
-					etcRecord.NormalAmount = 0
-				} else {
-					etcRecord.NormalAmount = amount
-				}
-			}
-
-			// Parse discount amount (field 9)
-			if p.getFieldSafe(record, 9) != "" {
-				amount, err := p.parseAmount(p.getFieldSafe(record, 9))
-				if err != nil {
-					etcRecord.DiscountApplied = 0
-				} else {
-					etcRecord.DiscountApplied = amount
-				}
-			}
-
-			// Parse mileage (field 10)
-			if p.getFieldSafe(record, 10) != "" {
-				amount, err := p.parseAmount(p.getFieldSafe(record, 10))
-				if err != nil {
-					etcRecord.Mileage = 0
-				} else {
-					etcRecord.Mileage = amount
-				}
-			}
-
-			// Parse vehicle class (field 11)
-			etcRecord.VehicleClass = p.ParseVehicleClass(record, 11)
-
-			// Vehicle number (field 12)
-			etcRecord.VehicleNumber = p.getFieldSafe(record, 12)
-
-			// Card number (field 13)
-			etcRecord.CardNumber = p.getFieldSafe(record, 13)
-
-			// Notes (field 14)
-			etcRecord.Notes = p.getFieldSafe(record, 14)
+			mapper = p.mapPositional(record)
 		}
 
+		etcRecord := mapper.record
+		etcRecord.LineNumber = i + 1
+
 		// Validate the record
 		if err := p.ValidateRecord(etcRecord); err != nil {
 			// Skip validation errors silently - continue processing
 			// Validation errors are expected for some records
 		}
 
-		etcRecords = append(etcRecords, etcRecord)
+		rows = append(rows, parsedRow{record: etcRecord, raw: record, mappings: mapper.mappings})
 	}
 
-	return etcRecords, nil
+	return rows, format, nil
 }
 
 // parseAmount parses amount strings that may have negative values
@@ -233,6 +270,10 @@ func (p *ETCCSVParser) ValidateRecord(record ActualETCRecord) error {
 		return fmt.Errorf("card number cannot be empty")
 	}
 
+	if err := record.VehicleClass.Validate(); err != nil {
+		return err
+	}
+
 	// Parse and validate dates
 	if record.EntryDate != "" {
 		_, err := p.parseDate(record.EntryDate)
@@ -296,15 +337,9 @@ func (p *ETCCSVParser) ConvertToSimpleRecord(actual ActualETCRecord) (ETCRecord,
 		}
 	}
 
-	// Determine the amount to use
-	amount := actual.ETCAmount
-	if amount == 0 {
-		amount = actual.NormalAmount
-	}
-	// Handle negative amounts
-	if amount < 0 {
-		amount = -amount
-	}
+	// Determine the amount to use; refund and correction rows are stored as reversals with a negative amount
+	amount := signedAmount(actual)
+	correction := DetectCorrection(actual)
 
 	return ETCRecord{
 		Date:        date,
@@ -314,6 +349,17 @@ func (p *ETCCSVParser) ConvertToSimpleRecord(actual ActualETCRecord) (ETCRecord,
 		VehicleType: actual.VehicleClass,
 		Amount:      amount,
 		CardNumber:  actual.CardNumber,
+
+		NormalAmount:      actual.NormalAmount,
+		DiscountAmount:    actual.DiscountApplied,
+		ETCAmount:         actual.ETCAmount,
+		PostPaymentAmount: actual.PostPaymentAmount,
+		Mileage:           actual.Mileage,
+
+		RedemptionBaseAmount: actual.RedemptionBaseAmount,
+
+		Correction: correction,
+		Segments:   ParseRoute(actual.RouteInfo),
 	}, nil
 }
 
@@ -325,97 +371,11 @@ func (p *ETCCSVParser) getFieldSafe(record []string, index int) string {
 	return ""
 }
 
-// parseWithHeaders parses a record using header mapping
-func (p *ETCCSVParser) parseWithHeaders(record []string, headerMap map[string]int) ActualETCRecord {
-	etcRecord := ActualETCRecord{}
-
-	// Map header names to fields - handle different formats
-	// Some files use （自）/（至） while others use （入）/（出）
-	etcRecord.EntryDate = p.getFieldByHeader(record, headerMap, "利用年月日（入）", "利用年月日(入)", "利用年月日（自）", "入口日付")
-	etcRecord.EntryTime = p.getFieldByHeader(record, headerMap, "時刻（入）", "時刻(入)", "時分（自）", "入口時刻")
-	etcRecord.ExitDate = p.getFieldByHeader(record, headerMap, "利用年月日（出）", "利用年月日(出)", "利用年月日（至）", "出口日付")
-	etcRecord.ExitTime = p.getFieldByHeader(record, headerMap, "時刻（出）", "時刻(出)", "時分（至）", "出口時刻")
-	etcRecord.EntryIC = p.getFieldByHeader(record, headerMap, "利用IC（入）", "利用IC(入)", "利用ＩＣ（自）", "入口IC", "入口")
-	etcRecord.ExitIC = p.getFieldByHeader(record, headerMap, "利用IC（出）", "利用IC(出)", "利用ＩＣ（至）", "出口IC", "出口")
-	etcRecord.RouteInfo = p.getFieldByHeader(record, headerMap, "経路情報", "路線", "経路")
-
-	// Parse amounts - handle different header formats
-	// 割引前料金 = Normal amount (before discount)
-	normalAmountStr := p.getFieldByHeader(record, headerMap, "割引前料金", "通行料金", "通常料金")
-	if normalAmountStr != "" {
-		amount, err := p.parseAmount(normalAmountStr)
-		if err == nil {
-			etcRecord.NormalAmount = amount
-		}
-	}
-
-	// ＥＴＣ割引額 = Discount amount (negative value)
-	discountStr := p.getFieldByHeader(record, headerMap, "ＥＴＣ割引額", "ETC割引額", "割引額")
-	if discountStr != "" {
-		amount, err := p.parseAmount(discountStr)
-		if err == nil {
-			etcRecord.DiscountApplied = amount
-		}
-	}
-
-	// 通行料金 = Actual charged amount
-	etcAmountStr := p.getFieldByHeader(record, headerMap, "通行料金", "ETC料金", "料金")
-	if etcAmountStr != "" {
-		amount, err := p.parseAmount(etcAmountStr)
-		if err == nil {
-			etcRecord.ETCAmount = amount
-		}
-	}
-
-	// 後納料金 = Post-payment amount (if exists)
-	postPaymentStr := p.getFieldByHeader(record, headerMap, "後納料金", "後払料金")
-	if postPaymentStr != "" {
-		amount, err := p.parseAmount(postPaymentStr)
-		if err == nil && amount != 0 {
-			// Use post-payment amount if available
-			etcRecord.ETCAmount = amount
-		}
-	}
-
-	// Parse vehicle info
-	vehicleClassStr := p.getFieldByHeader(record, headerMap, "車種", "車両区分", "車種区分")
-	if vehicleClassStr != "" {
-		class, err := strconv.Atoi(vehicleClassStr)
-		if err == nil {
-			etcRecord.VehicleClass = class
-		}
-	}
-
-	etcRecord.VehicleNumber = p.getFieldByHeader(record, headerMap, "車両番号", "ナンバー", "車番")
-	etcRecord.CardNumber = p.getFieldByHeader(record, headerMap, "ＥＴＣカード番号", "ETCカード番号", "カード番号", "カード")
-	etcRecord.Notes = p.getFieldByHeader(record, headerMap, "備考", "メモ", "注記")
-
-	return etcRecord
-}
-
-// getFieldByHeader gets a field value using multiple possible header names
-func (p *ETCCSVParser) getFieldByHeader(record []string, headerMap map[string]int, headerNames ...string) string {
-	for _, headerName := range headerNames {
-		if idx, exists := headerMap[headerName]; exists {
-			if idx < len(record) {
-				return record[idx]
-			}
-		}
-	}
-	return ""
-}
-
-// ParseVehicleClass parses vehicle class from record field, returns 0 if parsing fails
-func (p *ETCCSVParser) ParseVehicleClass(record []string, fieldIndex int) int {
-	fieldValue := p.getFieldSafe(record, fieldIndex)
-	if fieldValue != "" {
-		class, err := strconv.Atoi(fieldValue)
-		if err != nil {
-			return 0
-		}
-		return class
-	}
-	return 0
+// ParseVehicleClass parses vehicle class from record field, accepting numeric codes and Japanese labels.
+// Unrecognised labels return VehicleClassUnknown; unknown numeric codes are returned as-is (see VehicleClassFromString).
+func (p *ETCCSVParser) ParseVehicleClass(record []string, fieldIndex int) VehicleClass {
+	class, _ := VehicleClassFromString(p.getFieldSafe(record, fieldIndex))
+	return class
 }
 
 // ValidateRecordsAvailable checks if there are data records available for processing
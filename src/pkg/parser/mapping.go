@@ -0,0 +1,281 @@
+package parser
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/card"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/interchange"
+)
+
+// FieldMapping describes how one ActualETCRecord field was read from the raw CSV columns
+type FieldMapping struct {
+	Field    string // ActualETCRecord field name (e.g. "ETCAmount")
+	Header   string // header that matched; empty for positional files
+	Column   int    // 0-based column index
+	RawValue string // value as it appeared in the CSV
+	Value    string // value stored on the record
+	Coerced  bool   // true when the stored value differs from the raw value
+	Note     string // why the value was coerced
+}
+
+// recordMapper maps one CSV row onto an ActualETCRecord and keeps track of where each field came from
+type recordMapper struct {
+	p         *ETCCSVParser
+	row       []string
+	headerMap map[string]int
+	record    ActualETCRecord
+	mappings  []FieldMapping
+}
+
+// lookup returns the value of the first header name present in the row
+func (m *recordMapper) lookup(headerNames ...string) (value, header string, column int, ok bool) {
+	for _, headerName := range headerNames {
+		if idx, exists := m.headerMap[headerName]; exists {
+			if idx < len(m.row) {
+				return m.row[idx], headerName, idx, true
+			}
+		}
+	}
+	return "", "", 0, false
+}
+
+// textByHeader maps a string field using multiple possible header names
+func (m *recordMapper) textByHeader(field string, headerNames ...string) string {
+	value, header, column, ok := m.lookup(headerNames...)
+	if ok {
+		m.mappings = append(m.mappings, FieldMapping{Field: field, Header: header, Column: column, RawValue: value, Value: value})
+	}
+	return value
+}
+
+// textAt maps a string field from a fixed column
+func (m *recordMapper) textAt(field string, column int) string {
+	value := m.p.getFieldSafe(m.row, column)
+	if column < len(m.row) {
+		m.mappings = append(m.mappings, FieldMapping{Field: field, Column: column, RawValue: value, Value: value})
+	}
+	return value
+}
+
+// amountByHeader maps an amount field using multiple possible header names.
+// ok is false when no header matched or the value is not a valid amount.
+func (m *recordMapper) amountByHeader(field string, headerNames ...string) (int, bool) {
+	raw, header, column, found := m.lookup(headerNames...)
+	if !found {
+		return 0, false
+	}
+	return m.amount(field, header, column, raw)
+}
+
+// amountAt maps an amount field from a fixed column
+func (m *recordMapper) amountAt(field string, column int) (int, bool) {
+	if column >= len(m.row) {
+		return 0, false
+	}
+	return m.amount(field, "", column, m.row[column])
+}
+
+// amount parses a raw amount and records the mapping, defaulting to 0 when the value is empty or invalid
+func (m *recordMapper) amount(field, header string, column int, raw string) (int, bool) {
+	mapping := FieldMapping{Field: field, Header: header, Column: column, RawValue: raw, Value: "0"}
+
+	if raw == "" {
+		m.mappings = append(m.mappings, mapping)
+		return 0, false
+	}
+
+	value, err := m.p.parseAmount(raw)
+	if err != nil {
+		mapping.Coerced = true
+		mapping.Note = "not a number, defaulted to 0"
+		m.mappings = append(m.mappings, mapping)
+		return 0, false
+	}
+
+	mapping.Value = strconv.Itoa(value)
+	if mapping.Value != raw {
+		mapping.Coerced = true
+		mapping.Note = "thousands separators removed"
+	}
+	m.mappings = append(m.mappings, mapping)
+	return value, true
+}
+
+// vehicleClass maps the vehicle class from a numeric code or Japanese label, defaulting to 0 when it is not recognised
+func (m *recordMapper) vehicleClass(header string, column int, raw string) VehicleClass {
+	class, err := VehicleClassFromString(raw)
+
+	mapping := FieldMapping{Field: "VehicleClass", Header: header, Column: column, RawValue: raw, Value: strconv.Itoa(int(class))}
+	if err != nil {
+		mapping.Note = err.Error()
+	}
+	if mapping.Value != raw && raw != "" {
+		mapping.Coerced = true
+		if err == nil {
+			mapping.Note = class.String()
+		}
+	}
+	m.mappings = append(m.mappings, mapping)
+	return class
+}
+
+// cardNumber normalizes the card number that was just mapped (spaces, hyphens, full-width digits)
+func (m *recordMapper) cardNumber(raw string) string {
+	number := card.Normalize(raw)
+	if number != raw && len(m.mappings) > 0 {
+		mapping := &m.mappings[len(m.mappings)-1]
+		mapping.Value = number
+		mapping.Coerced = true
+		mapping.Note = "card number normalized"
+	}
+	return number
+}
+
+// icName normalizes the IC name that was just mapped (NFKC, whitespace)
+func (m *recordMapper) icName(raw string) string {
+	name := interchange.Normalize(raw)
+	if name != raw && len(m.mappings) > 0 {
+		mapping := &m.mappings[len(m.mappings)-1]
+		mapping.Value = name
+		mapping.Coerced = true
+		mapping.Note = "IC name normalized"
+	}
+	return name
+}
+
+// mapWithHeaders maps a row using the header mapping
+func (p *ETCCSVParser) mapWithHeaders(row []string, headerMap map[string]int) *recordMapper {
+	m := &recordMapper{p: p, row: row, headerMap: headerMap}
+	r := &m.record
+
+	// Map header names to fields - handle different formats
+	// Some files use （自）/（至） while others use （入）/（出）
+	r.EntryDate = m.textByHeader("EntryDate", "利用年月日（入）", "利用年月日(入)", "利用年月日（自）", "入口日付")
+	r.EntryTime = m.textByHeader("EntryTime", "時刻（入）", "時刻(入)", "時分（自）", "入口時刻")
+	r.ExitDate = m.textByHeader("ExitDate", "利用年月日（出）", "利用年月日(出)", "利用年月日（至）", "出口日付")
+	r.ExitTime = m.textByHeader("ExitTime", "時刻（出）", "時刻(出)", "時分（至）", "出口時刻")
+	r.EntryIC = m.icName(m.textByHeader("EntryIC", "利用IC（入）", "利用IC(入)", "利用ＩＣ（自）", "入口IC", "入口"))
+	r.ExitIC = m.icName(m.textByHeader("ExitIC", "利用IC（出）", "利用IC(出)", "利用ＩＣ（至）", "出口IC", "出口"))
+	r.RouteInfo = m.textByHeader("RouteInfo", "経路情報", "路線", "経路")
+
+	// Parse amounts - handle different header formats
+	// 割引前料金 = Normal amount (before discount)
+	if amount, ok := m.amountByHeader("NormalAmount", "割引前料金", "通行料金", "通常料金"); ok {
+		r.NormalAmount = amount
+	}
+
+	// ＥＴＣ割引額 = Discount amount (negative value)
+	if amount, ok := m.amountByHeader("DiscountApplied", "ＥＴＣ割引額", "ETC割引額", "割引額"); ok {
+		r.DiscountApplied = amount
+	}
+
+	// 通行料金 = Actual charged amount
+	if amount, ok := m.amountByHeader("ETCAmount", "通行料金", "ETC料金", "料金"); ok {
+		r.ETCAmount = amount
+	}
+
+	// マイレージ = Mileage points (if exists)
+	if amount, ok := m.amountByHeader("Mileage", "マイレージ"); ok {
+		r.Mileage = amount
+	}
+
+	// 還元額適用料金 = Yen amount the mileage redemption applies to (if exists)
+	if amount, ok := m.amountByHeader("RedemptionBaseAmount", "還元額適用料金"); ok {
+		r.RedemptionBaseAmount = amount
+	}
+
+	// 後納料金 = Post-payment amount (if exists); kept separately from the charged amount
+	if amount, ok := m.amountByHeader("PostPaymentAmount", "後納料金", "後払料金"); ok {
+		r.PostPaymentAmount = amount
+	}
+
+	// Parse vehicle info
+	if raw, header, column, ok := m.lookup("車種", "車両区分", "車種区分"); ok {
+		r.VehicleClass = m.vehicleClass(header, column, raw)
+	}
+
+	r.VehicleNumber = m.textByHeader("VehicleNumber", "車両番号", "ナンバー", "車番")
+	r.CardNumber = m.cardNumber(m.textByHeader("CardNumber", "ＥＴＣカード番号", "ETCカード番号", "カード番号", "カード"))
+	r.Notes = m.textByHeader("Notes", "備考", "メモ", "注記")
+
+	return m
+}
+
+// mapPositional maps a row without a header using fixed column positions (backward compatibility)
+func (p *ETCCSVParser) mapPositional(row []string) *recordMapper {
+	m := &recordMapper{p: p, row: row}
+	r := &m.record
+
+	r.EntryDate = m.textAt("EntryDate", 0)
+	r.EntryTime = m.textAt("EntryTime", 1)
+	r.ExitDate = m.textAt("ExitDate", 2)
+	r.ExitTime = m.textAt("ExitTime", 3)
+	r.EntryIC = m.icName(m.textAt("EntryIC", 4))
+	r.ExitIC = m.icName(m.textAt("ExitIC", 5))
+	r.RouteInfo = m.textAt("RouteInfo", 6)
+
+	// Amounts (fields 7-10); invalid values are left as 0
+	r.ETCAmount, _ = m.amountAt("ETCAmount", 7)
+	r.NormalAmount, _ = m.amountAt("NormalAmount", 8)
+	r.DiscountApplied, _ = m.amountAt("DiscountApplied", 9)
+	r.Mileage, _ = m.amountAt("Mileage", 10)
+
+	// Vehicle class (field 11)
+	r.VehicleClass = m.vehicleClass("", 11, p.getFieldSafe(row, 11))
+
+	r.VehicleNumber = m.textAt("VehicleNumber", 12)
+	r.CardNumber = m.cardNumber(m.textAt("CardNumber", 13))
+	r.Notes = m.textAt("Notes", 14)
+
+	return m
+}
+
+// PreviewRecord is a parsed record together with its raw columns and how each field was mapped
+type PreviewRecord struct {
+	Record   ActualETCRecord
+	Raw      []string
+	Mappings []FieldMapping
+}
+
+// PreviewResult holds the first records of a CSV and what was detected about it
+type PreviewResult struct {
+	Records      []PreviewRecord
+	Info         FileInfo // Encoding is only set for files
+	TotalRecords int
+}
+
+// Preview parses CSV data and returns at most limit records with their field mappings
+func (p *ETCCSVParser) Preview(reader io.Reader, limit int) (*PreviewResult, error) {
+	rows, format, err := p.parseRows(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PreviewResult{
+		Info:         FileInfo{Format: format},
+		TotalRecords: len(rows),
+	}
+	for i, row := range rows {
+		if i >= limit {
+			break
+		}
+		result.Records = append(result.Records, PreviewRecord{Record: row.record, Raw: row.raw, Mappings: row.mappings})
+	}
+	return result, nil
+}
+
+// PreviewFile previews a CSV file, detecting its encoding like ParseFileWithInfo
+func (p *ETCCSVParser) PreviewFile(filepath string, limit int) (*PreviewResult, error) {
+	reader, info, err := p.openFile(filepath)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := p.Preview(reader, limit)
+	if err != nil {
+		return nil, err
+	}
+	result.Info.Encoding = info.Encoding
+	return result, nil
+}
@@ -0,0 +1,223 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/interchange"
+)
+
+// RouteSegment is one section of a trip's 経路情報, in travel order
+type RouteSegment struct {
+	Road string   // road name (e.g. "東名高速道路"); empty when the route only lists via-points
+	From string   // first IC of the section, when given as "道路(自~至)"
+	To   string   // last IC of the section
+	Via  []string // junctions, smart ICs and other points passed on this section
+}
+
+// routeSeparators split 経路情報 into ordered parts (after NFKC normalization)
+var routeSeparators = []string{"->", "→", "⇒", ">", "/"}
+
+// sectionSeparators split "自~至" inside a road's parentheses
+var sectionSeparators = []string{"~", "->", "〜", "-", "→", "⇒"}
+
+// viaSeparators split lists of via-points
+var viaSeparators = []string{"・", ",", "、"}
+
+// viaPrefixes introduce via-point lists ("経由:海老名JCT")
+var viaPrefixes = []string{"経由:", "経由"}
+
+// viaPointSuffixes mark names that are points on a road rather than roads themselves
+var viaPointSuffixes = []string{"JCT", "IC", "SIC", "スマートIC", "インター", "PA", "SA", "TB", "料金所", "出入口", "入口", "出口"}
+
+// ParseRoute splits 経路情報 into ordered segments.
+//
+// Parts are separated by arrows or "/"; each part is either a road, optionally with its
+// section in parentheses ("東名高速(東京~厚木)"), or a via-point such as a junction or
+// smart IC ("海老名JCT", "経由:厚木IC・伊勢原JCT"), which is attached to the current road.
+// An empty string yields no segments.
+func ParseRoute(info string) []RouteSegment {
+	var segments []RouteSegment
+	current := func() *RouteSegment {
+		if len(segments) == 0 {
+			segments = append(segments, RouteSegment{})
+		}
+		return &segments[len(segments)-1]
+	}
+
+	for _, part := range splitRoute(interchange.Normalize(info)) {
+		if via, ok := cutViaPrefix(part); ok {
+			current().Via = append(current().Via, splitAny(via, viaSeparators)...)
+			continue
+		}
+
+		road, section, hasSection := cutParentheses(part)
+		if !hasSection && isViaPoint(road) {
+			current().Via = append(current().Via, road)
+			continue
+		}
+
+		segment := RouteSegment{Road: road}
+		if hasSection {
+			if via, ok := cutViaPrefix(section); ok {
+				segment.Via = splitAny(via, viaSeparators)
+			} else if ends := splitAny(section, sectionSeparators); len(ends) > 0 {
+				segment.From = ends[0]
+				segment.To = ends[len(ends)-1]
+				if len(ends) > 2 {
+					segment.Via = ends[1 : len(ends)-1]
+				}
+			}
+		}
+		segments = append(segments, segment)
+	}
+	return segments
+}
+
+// Roads returns the distinct road names of a route in travel order
+func Roads(segments []RouteSegment) []string {
+	var roads []string
+	seen := make(map[string]bool)
+	for _, segment := range segments {
+		if segment.Road != "" && !seen[segment.Road] {
+			seen[segment.Road] = true
+			roads = append(roads, segment.Road)
+		}
+	}
+	return roads
+}
+
+// RoadSpend is the amount attributed to one road
+type RoadSpend struct {
+	Road   string
+	Amount int
+	Trips  int
+}
+
+// SpendByRoad totals record amounts per road, largest first. Statements do not itemize fares
+// per section, so a trip over several roads is split evenly between them (any remainder goes
+// to the first road); trips without a road are reported under an empty name.
+// Reversals carry negative amounts and reduce the totals.
+func SpendByRoad(records []ETCRecord) []RoadSpend {
+	totals := make(map[string]*RoadSpend)
+	add := func(road string, amount int) {
+		spend, ok := totals[road]
+		if !ok {
+			spend = &RoadSpend{Road: road}
+			totals[road] = spend
+		}
+		spend.Amount += amount
+		spend.Trips++
+	}
+
+	for _, record := range records {
+		roads := Roads(record.Segments)
+		if len(roads) == 0 {
+			add("", record.Amount)
+			continue
+		}
+		share := record.Amount / len(roads)
+		for i, road := range roads {
+			amount := share
+			if i == 0 {
+				amount += record.Amount - share*len(roads)
+			}
+			add(road, amount)
+		}
+	}
+
+	list := make([]RoadSpend, 0, len(totals))
+	for _, spend := range totals {
+		list = append(list, *spend)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Amount != list[j].Amount {
+			return list[i].Amount > list[j].Amount
+		}
+		return list[i].Road < list[j].Road
+	})
+	return list
+}
+
+// splitRoute splits normalized 経路情報 on route separators outside parentheses
+func splitRoute(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+			continue
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+			continue
+		}
+		if depth > 0 {
+			continue
+		}
+		for _, separator := range routeSeparators {
+			if strings.HasPrefix(s[i:], separator) {
+				parts = append(parts, s[start:i])
+				start = i + len(separator)
+				i = start - 1
+				break
+			}
+		}
+	}
+	parts = append(parts, s[start:])
+
+	var result []string
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+// splitAny splits s on any of the separators, dropping empty parts
+func splitAny(s string, separators []string) []string {
+	for _, separator := range separators[1:] {
+		s = strings.ReplaceAll(s, separator, separators[0])
+	}
+	var parts []string
+	for _, part := range strings.Split(s, separators[0]) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// cutViaPrefix returns the via-point list of a part starting with "経由"
+func cutViaPrefix(part string) (string, bool) {
+	for _, prefix := range viaPrefixes {
+		if rest, ok := strings.CutPrefix(part, prefix); ok {
+			return strings.TrimSpace(rest), true
+		}
+	}
+	return "", false
+}
+
+// cutParentheses splits "道路(区間)" into the road name and the section
+func cutParentheses(part string) (string, string, bool) {
+	open := strings.Index(part, "(")
+	if open < 0 {
+		return part, "", false
+	}
+	section := strings.TrimSuffix(part[open+1:], ")")
+	return strings.TrimSpace(part[:open]), strings.TrimSpace(section), true
+}
+
+// isViaPoint reports whether a name is a junction, IC or similar point rather than a road
+func isViaPoint(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, suffix := range viaPointSuffixes {
+		if strings.HasSuffix(upper, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/interchange"
+)
+
+// DefaultTripMaxGap is the longest break between two rows of the same trip
+const DefaultTripMaxGap = 30 * time.Minute
+
+// StitchOptions controls how consecutive rows are grouped into trips
+type StitchOptions struct {
+	// MaxGap is the longest time between one row's exit and the next row's entry; zero means DefaultTripMaxGap
+	MaxGap time.Duration
+	// IgnoreICChain also joins rows whose exit and next entry IC differ, such as at a border
+	// between NEXCO and a metropolitan expressway where the toll systems use different names
+	IgnoreICChain bool
+}
+
+// Trip is a logical journey made of one or more consecutive statement rows for the same card
+type Trip struct {
+	ID         string
+	CardNumber string
+	EntryIC    string    // entry IC of the first row
+	ExitIC     string    // exit IC of the last row
+	Start      time.Time // entry time of the first row
+	End        time.Time // exit time of the last row
+	Records    []int     // 0-based indexes of the rows in travel order
+	Amount     int       // sum of charged amounts, with corrections counted negative
+	Mileage    int
+}
+
+// stitchRow is a row with its parsed entry and exit times
+type stitchRow struct {
+	index       int
+	record      ActualETCRecord
+	entry, exit time.Time
+	timed       bool
+}
+
+// StitchTrips groups consecutive rows of the same card into trips. A row continues the previous
+// trip when it starts within MaxGap of the previous row's exit and, unless IgnoreICChain is set,
+// enters at the IC where the previous row exited. Correction rows and rows without valid
+// times always form their own trip. Trips are returned in order of their first row.
+func (p *ETCCSVParser) StitchTrips(records []ActualETCRecord, opts StitchOptions) []Trip {
+	if opts.MaxGap <= 0 {
+		opts.MaxGap = DefaultTripMaxGap
+	}
+
+	byCard := make(map[string][]stitchRow)
+	var cards []string
+	for i, record := range records {
+		row := stitchRow{index: i, record: record}
+		var entryErr, exitErr error
+		row.entry, entryErr = ParseDateTime(record.EntryDate, record.EntryTime)
+		row.exit, exitErr = ParseDateTime(record.ExitDate, record.ExitTime)
+		row.timed = entryErr == nil && exitErr == nil
+
+		if _, ok := byCard[record.CardNumber]; !ok {
+			cards = append(cards, record.CardNumber)
+		}
+		byCard[record.CardNumber] = append(byCard[record.CardNumber], row)
+	}
+
+	var trips []Trip
+	ids := make(map[string]int) // trips started per ID key, so repeated rows get distinct IDs
+	for _, cardNumber := range cards {
+		rows := byCard[cardNumber]
+		sort.SliceStable(rows, func(i, j int) bool {
+			if rows[i].timed && rows[j].timed {
+				return rows[i].entry.Before(rows[j].entry)
+			}
+			return rows[i].index < rows[j].index
+		})
+
+		current := -1 // index in trips of the trip the next row may continue
+		var previous stitchRow
+		for _, row := range rows {
+			// Correction rows stand alone and do not break the chain of the trip around them
+			if DetectCorrection(row.record) != CorrectionNone {
+				trips = append(trips, newTrip(row, ids))
+				continue
+			}
+			if current >= 0 && continuesTrip(previous, row, opts) {
+				trips[current].add(row)
+			} else {
+				trips = append(trips, newTrip(row, ids))
+				current = len(trips) - 1
+			}
+			previous = row
+		}
+	}
+
+	sort.SliceStable(trips, func(i, j int) bool {
+		return trips[i].Records[0] < trips[j].Records[0]
+	})
+	return trips
+}
+
+// continuesTrip reports whether row is the next leg of the trip ending with previous
+func continuesTrip(previous, row stitchRow, opts StitchOptions) bool {
+	if !previous.timed || !row.timed {
+		return false
+	}
+	gap := row.entry.Sub(previous.exit)
+	if gap < 0 || gap > opts.MaxGap {
+		return false
+	}
+	return opts.IgnoreICChain || interchange.Key(previous.record.ExitIC) == interchange.Key(row.record.EntryIC)
+}
+
+// newTrip starts a trip with its first row; the ID is derived from that row so it is stable across runs.
+// A correction row gets a different ID than the charge it reverses, and a row repeating the start
+// of an earlier trip in the same run is numbered by ids so no two trips share an ID.
+func newTrip(row stitchRow, ids map[string]int) Trip {
+	key := TripKey(row.record)
+	if DetectCorrection(row.record) != CorrectionNone {
+		key += "_correction"
+	}
+	ids[key]++
+	if n := ids[key]; n > 1 {
+		key = fmt.Sprintf("%s#%d", key, n)
+	}
+	hash := sha256.Sum256([]byte(key))
+	trip := Trip{
+		ID:         fmt.Sprintf("trip-%x", hash[:8]),
+		CardNumber: row.record.CardNumber,
+		EntryIC:    row.record.EntryIC,
+		Start:      row.entry,
+	}
+	trip.add(row)
+	return trip
+}
+
+// add appends a row to the trip and updates its totals
+func (t *Trip) add(row stitchRow) {
+	t.Records = append(t.Records, row.index)
+	t.ExitIC = row.record.ExitIC
+	t.End = row.exit
+	t.Amount += signedAmount(row.record)
+	t.Mileage += row.record.Mileage
+}
+
+// ParseDateTime combines a statement date ("25/09/01") and time ("08:00" or "08:00:00")
+func ParseDateTime(dateStr, timeStr string) (time.Time, error) {
+	date, err := (&ETCCSVParser{}).parseDate(dateStr)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	timeStr = strings.TrimSpace(timeStr)
+	clock, err := time.Parse("15:04", timeStr)
+	if err != nil {
+		clock, err = time.Parse("15:04:05", timeStr)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time format: %s", timeStr)
+	}
+	return date.Add(time.Duration(clock.Hour())*time.Hour +
+		time.Duration(clock.Minute())*time.Minute +
+		time.Duration(clock.Second())*time.Second), nil
+}
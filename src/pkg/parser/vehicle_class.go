@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// VehicleClass is a NEXCO toll vehicle class (車種区分) as printed in the 車種 column
+type VehicleClass int
+
+// NEXCO vehicle classes
+const (
+	VehicleClassUnknown    VehicleClass = 0 // missing or not recognised
+	VehicleClassStandard   VehicleClass = 1 // 普通車
+	VehicleClassMedium     VehicleClass = 2 // 中型車
+	VehicleClassLarge      VehicleClass = 3 // 大型車
+	VehicleClassExtraLarge VehicleClass = 4 // 特大車
+	VehicleClassLight      VehicleClass = 5 // 軽自動車等
+)
+
+// ErrUnknownVehicleClass is returned for values that are not a NEXCO vehicle class
+var ErrUnknownVehicleClass = errors.New("unknown vehicle class")
+
+// vehicleClassLabels maps Japanese labels found in statements to vehicle classes
+var vehicleClassLabels = map[string]VehicleClass{
+	"普通車":   VehicleClassStandard,
+	"普通":    VehicleClassStandard,
+	"中型車":   VehicleClassMedium,
+	"中型":    VehicleClassMedium,
+	"大型車":   VehicleClassLarge,
+	"大型":    VehicleClassLarge,
+	"特大車":   VehicleClassExtraLarge,
+	"特大":    VehicleClassExtraLarge,
+	"軽自動車等": VehicleClassLight,
+	"軽自動車":  VehicleClassLight,
+	"軽":     VehicleClassLight,
+	"二輪車":   VehicleClassLight,
+	"二輪":    VehicleClassLight,
+}
+
+// String returns the Japanese label of the vehicle class
+func (c VehicleClass) String() string {
+	switch c {
+	case VehicleClassStandard:
+		return "普通車"
+	case VehicleClassMedium:
+		return "中型車"
+	case VehicleClassLarge:
+		return "大型車"
+	case VehicleClassExtraLarge:
+		return "特大車"
+	case VehicleClassLight:
+		return "軽自動車等"
+	case VehicleClassUnknown:
+		return "不明"
+	}
+	return fmt.Sprintf("VehicleClass(%d)", int(c))
+}
+
+// IsValid reports whether c is one of the NEXCO vehicle classes
+func (c VehicleClass) IsValid() bool {
+	return c >= VehicleClassStandard && c <= VehicleClassLight
+}
+
+// Validate rejects a missing or unrecognised class (0) as well as codes outside the NEXCO classes
+func (c VehicleClass) Validate() error {
+	if c == VehicleClassUnknown {
+		return fmt.Errorf("%w: missing or not recognised", ErrUnknownVehicleClass)
+	}
+	if !c.IsValid() {
+		return fmt.Errorf("%w: %d", ErrUnknownVehicleClass, int(c))
+	}
+	return nil
+}
+
+// VehicleClassFromString parses a numeric code or Japanese label (e.g. "2", "２", "中型車").
+// An empty value is VehicleClassUnknown without error. Numeric codes outside the NEXCO classes
+// are returned as-is together with ErrUnknownVehicleClass so callers can report them.
+func VehicleClassFromString(value string) (VehicleClass, error) {
+	value = strings.TrimSpace(strings.Map(toHalfWidthDigit, value))
+	if value == "" {
+		return VehicleClassUnknown, nil
+	}
+
+	if code, err := strconv.Atoi(value); err == nil {
+		class := VehicleClass(code)
+		if !class.IsValid() {
+			return class, fmt.Errorf("%w: %d", ErrUnknownVehicleClass, code)
+		}
+		return class, nil
+	}
+
+	if class, ok := vehicleClassLabels[value]; ok {
+		return class, nil
+	}
+	return VehicleClassUnknown, fmt.Errorf("%w: %q", ErrUnknownVehicleClass, value)
+}
+
+// toHalfWidthDigit converts full-width digits to ASCII
+func toHalfWidthDigit(r rune) rune {
+	if r >= '０' && r <= '９' {
+		return '0' + (r - '０')
+	}
+	return r
+}
@@ -0,0 +1,159 @@
+// Package reconcile compares imported records with the totals on an ETC card issuer's invoice
+// and points out the rows that most likely explain a difference.
+package reconcile
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// MonthLayout is the format of statement months
+const MonthLayout = "2006-01"
+
+// IssueKind classifies a likely cause of a difference
+type IssueKind string
+
+// Issue kinds
+const (
+	IssueMissing   IssueKind = "missing"   // a row on the statement was not imported
+	IssueExtra     IssueKind = "extra"     // an imported row is not on the statement
+	IssueDuplicate IssueKind = "duplicate" // the same row was imported more than once
+)
+
+// Expected is the invoice total of one card for one month
+type Expected struct {
+	CardNumber string
+	Month      string // "2025-09"
+	Amount     int64
+	Records    int // number of rows on the invoice; zero means unknown
+}
+
+// Row is an imported record or a row of the issuer's statement
+type Row struct {
+	Key        string // identity independent of account and amount (parser.TripKey)
+	Date       time.Time
+	EntryIC    string
+	ExitIC     string
+	Amount     int
+	Reversal   bool
+	LineNumber int // statement line; zero for imported records
+}
+
+// Issue is a row that likely explains part of a difference
+type Issue struct {
+	Kind IssueKind
+	Row  Row
+	Note string
+}
+
+// Result is the reconciliation of one card and month
+type Result struct {
+	Expected
+	ImportedAmount  int64
+	ImportedRecords int
+	Difference      int64 // ImportedAmount - Amount; positive means more was imported than invoiced
+	Matched         bool
+	Issues          []Issue
+}
+
+// Reconcile compares the imported rows of one card and month with the invoice total.
+//
+// Rows imported more than once (same row and amount) are always reported as duplicates.
+// When the statement's own rows are given, rows are matched one to one and every unmatched
+// row is reported as missing or extra. Without them, an unexplained surplus is attributed to
+// imported rows whose amount equals it, and a shortfall is reported as one missing amount.
+func Reconcile(expected Expected, imported []Row, statement []Row) Result {
+	result := Result{Expected: expected, ImportedRecords: len(imported)}
+	for _, row := range imported {
+		result.ImportedAmount += int64(row.Amount)
+	}
+	result.Difference = result.ImportedAmount - expected.Amount
+	result.Matched = result.Difference == 0 && (expected.Records == 0 || expected.Records == len(imported))
+
+	// Duplicates: every further copy of a row with the same identity and amount
+	seen := make(map[string]bool)
+	var unique []Row
+	var duplicated int64
+	for _, row := range imported {
+		key := rowKey(row)
+		if seen[key] {
+			result.Issues = append(result.Issues, Issue{Kind: IssueDuplicate, Row: row, Note: "imported more than once"})
+			duplicated += int64(row.Amount)
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, row)
+	}
+
+	if statement != nil {
+		result.Issues = append(result.Issues, compareRows(unique, statement)...)
+		sortIssues(result.Issues)
+		return result
+	}
+
+	remaining := result.Difference - duplicated
+	switch {
+	case remaining > 0:
+		candidates := 0
+		for _, row := range unique {
+			if int64(row.Amount) == remaining {
+				result.Issues = append(result.Issues, Issue{Kind: IssueExtra, Row: row, Note: "amount equals the unexplained surplus"})
+				candidates++
+			}
+		}
+		if candidates == 0 {
+			result.Issues = append(result.Issues, Issue{Kind: IssueExtra, Row: Row{Amount: int(remaining)},
+				Note: fmt.Sprintf("%d yen more than invoiced; compare with the statement to find the rows", remaining)})
+		}
+	case remaining < 0:
+		result.Issues = append(result.Issues, Issue{Kind: IssueMissing, Row: Row{Amount: int(-remaining)},
+			Note: fmt.Sprintf("%d yen less than invoiced; compare with the statement to find the rows", -remaining)})
+	}
+	sortIssues(result.Issues)
+	return result
+}
+
+// compareRows matches imported rows with statement rows one to one by identity and amount
+func compareRows(imported, statement []Row) []Issue {
+	pending := make(map[string][]Row)
+	for _, row := range imported {
+		key := rowKey(row)
+		pending[key] = append(pending[key], row)
+	}
+
+	var issues []Issue
+	for _, row := range statement {
+		key := rowKey(row)
+		if rows := pending[key]; len(rows) > 0 {
+			pending[key] = rows[1:]
+			continue
+		}
+		issues = append(issues, Issue{Kind: IssueMissing, Row: row, Note: "on the statement but not imported"})
+	}
+	for _, rows := range pending {
+		for _, row := range rows {
+			issues = append(issues, Issue{Kind: IssueExtra, Row: row, Note: "imported but not on the statement"})
+		}
+	}
+	return issues
+}
+
+// rowKey identifies a row together with its amount, so a charge and its refund differ
+func rowKey(row Row) string {
+	return fmt.Sprintf("%s\x00%d", row.Key, row.Amount)
+}
+
+// sortIssues orders issues by date, then kind and statement line
+func sortIssues(issues []Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if !a.Row.Date.Equal(b.Row.Date) {
+			return a.Row.Date.Before(b.Row.Date)
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Row.LineNumber < b.Row.LineNumber
+	})
+}
@@ -0,0 +1,120 @@
+// Package tax splits tax-inclusive toll amounts into the tax-exclusive amount and consumption tax.
+package tax
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultRatePercent is the standard consumption tax rate applied to tolls
+const DefaultRatePercent = 10
+
+// Rounding is how fractions of a yen of tax are rounded
+type Rounding string
+
+// Rounding methods
+const (
+	RoundDown    Rounding = "floor" // 切り捨て
+	RoundHalfUp  Rounding = "round" // 四捨五入
+	RoundUp      Rounding = "ceil"  // 切り上げ
+	DefaultRound          = RoundDown
+)
+
+// ErrUnknownRounding is returned for rounding names that are not supported
+var ErrUnknownRounding = errors.New("unknown tax rounding")
+
+// ParseRounding parses a rounding name; an empty name means DefaultRound
+func ParseRounding(name string) (Rounding, error) {
+	switch Rounding(strings.ToLower(strings.TrimSpace(name))) {
+	case "":
+		return DefaultRound, nil
+	case RoundDown:
+		return RoundDown, nil
+	case RoundHalfUp:
+		return RoundHalfUp, nil
+	case RoundUp:
+		return RoundUp, nil
+	}
+	return "", fmt.Errorf("%w: %q (use floor, round or ceil)", ErrUnknownRounding, name)
+}
+
+// Breakdown is a tax-inclusive amount split into its parts
+type Breakdown struct {
+	Inclusive int
+	Exclusive int
+	Tax       int
+}
+
+// Calculator splits amounts at one rate with one rounding method
+type Calculator struct {
+	RatePercent int // zero means DefaultRatePercent
+	Rounding    Rounding
+}
+
+// NewCalculator creates a calculator for the default rate
+func NewCalculator(rounding Rounding) Calculator {
+	return Calculator{RatePercent: DefaultRatePercent, Rounding: rounding}
+}
+
+// Split returns the tax included in a tax-inclusive amount. Negative amounts (reversals)
+// are split like their positive counterpart so a refund cancels its charge exactly.
+func (c Calculator) Split(inclusive int) Breakdown {
+	rate := c.RatePercent
+	if rate == 0 {
+		rate = DefaultRatePercent
+	}
+
+	amount := inclusive
+	if amount < 0 {
+		amount = -amount
+	}
+	tax := c.divide(amount*rate, 100+rate)
+	if inclusive < 0 {
+		tax = -tax
+	}
+	return Breakdown{Inclusive: inclusive, Exclusive: inclusive - tax, Tax: tax}
+}
+
+// divide divides non-negative integers with the calculator's rounding
+func (c Calculator) divide(numerator, denominator int) int {
+	switch c.Rounding {
+	case RoundHalfUp:
+		return (2*numerator + denominator) / (2 * denominator)
+	case RoundUp:
+		return (numerator + denominator - 1) / denominator
+	}
+	return numerator / denominator
+}
+
+// Statement accumulates the records of one statement. Under the invoice system tax is
+// computed once on the statement total, which can differ from the sum of per-record tax
+// by the rounding of each record.
+type Statement struct {
+	calculator Calculator
+	inclusive  int
+	recordTax  int
+}
+
+// NewStatement starts an empty statement
+func (c Calculator) NewStatement() *Statement {
+	return &Statement{calculator: c}
+}
+
+// Add adds a record's tax-inclusive amount and returns its own breakdown
+func (s *Statement) Add(inclusive int) Breakdown {
+	breakdown := s.calculator.Split(inclusive)
+	s.inclusive += inclusive
+	s.recordTax += breakdown.Tax
+	return breakdown
+}
+
+// Total returns the breakdown of the statement total
+func (s *Statement) Total() Breakdown {
+	return s.calculator.Split(s.inclusive)
+}
+
+// RecordTax returns the sum of the per-record tax amounts
+func (s *Statement) RecordTax() int {
+	return s.recordTax
+}
@@ -0,0 +1,138 @@
+// Package tracing sets up OpenTelemetry tracing. Spans of an import are exported to an OTLP
+// collector, stdout or a file, and the W3C trace context is propagated to db_service.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName identifies this service in exported spans
+const ServiceName = "etc_data_processor"
+
+// instrumentationName names the tracer of the spans created by this service
+const instrumentationName = "github.com/yhonda-ohishi-pub-dev/etc_data_processor"
+
+// Exporter selects where spans are sent
+type Exporter string
+
+const (
+	// ExporterNone exports nothing; incoming trace context is still passed on to db_service
+	ExporterNone Exporter = "none"
+	// ExporterStdout writes spans to standard output as JSON, one span per line
+	ExporterStdout Exporter = "stdout"
+	// ExporterFile appends spans to a file as JSON, one span per line
+	ExporterFile Exporter = "file"
+	// ExporterOTLP sends spans to an OpenTelemetry collector over OTLP/gRPC
+	ExporterOTLP Exporter = "otlp"
+)
+
+// ParseExporter parses an exporter name (none, stdout, file or otlp); an empty name is none
+func ParseExporter(name string) (Exporter, error) {
+	switch exporter := Exporter(strings.ToLower(strings.TrimSpace(name))); exporter {
+	case "":
+		return ExporterNone, nil
+	case ExporterNone, ExporterStdout, ExporterFile, ExporterOTLP:
+		return exporter, nil
+	}
+	return ExporterNone, fmt.Errorf("invalid trace_exporter %q (must be none, stdout, file or otlp)", name)
+}
+
+// Options configures Setup
+type Options struct {
+	Exporter Exporter
+	// Endpoint is the host:port of the OTLP collector; empty uses OTEL_EXPORTER_OTLP_ENDPOINT
+	Endpoint string
+	// File is the output of the file exporter
+	File string
+}
+
+// Setup installs the global tracer provider and trace context propagator.
+// The returned function flushes pending spans and must be called before exiting.
+func Setup(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var closer io.Closer
+	switch opts.Exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		e, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+		exporter = e
+	case ExporterFile:
+		if opts.File == "" {
+			return nil, fmt.Errorf("trace_file is required for the file exporter")
+		}
+		f, err := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+		e, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to create file exporter: %w", err)
+		}
+		exporter, closer = e, f
+	case ExporterOTLP:
+		var clientOpts []otlptracegrpc.Option
+		if opts.Endpoint != "" {
+			// Collectors are reached on the internal network, like db_service
+			clientOpts = append(clientOpts, otlptracegrpc.WithEndpoint(opts.Endpoint), otlptracegrpc.WithInsecure())
+		}
+		e, err := otlptracegrpc.New(ctx, clientOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		exporter = e
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", opts.Exporter)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			if closeErr := closer.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}, nil
+}
+
+// Start starts a span as a child of the span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err (if any) on the span and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,400 @@
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dimension is a field usage can be grouped by
+type Dimension string
+
+// Grouping dimensions
+const (
+	DimensionCard    Dimension = "card"    // ETC card number
+	DimensionVehicle Dimension = "vehicle" // assigned vehicle ID, or the statement's 車両番号 when unassigned
+	DimensionAccount Dimension = "account" // account the records were imported for
+	DimensionRoute   Dimension = "route"   // entry and exit IC ("東京 → 横浜")
+	DimensionMonth   Dimension = "month"   // usage month ("2025-09")
+)
+
+// Entry is one imported record as kept for usage reporting
+type Entry struct {
+	// Key identifies the record; adding an entry with the same key again replaces it,
+	// so re-importing a statement does not count its records twice in summaries
+	Key           string    `json:"key"`
+	Row           string    `json:"row"` // statement row identity independent of account and amount (parser.TripKey)
+	AccountID     string    `json:"account_id"`
+	Date          time.Time `json:"date"`
+	CardNumber    string    `json:"card_number"`
+	VehicleID     string    `json:"vehicle_id"`
+	VehicleNumber string    `json:"vehicle_number"`
+	VehicleClass  int       `json:"vehicle_class,omitempty"` // 車種 on the statement (parser.VehicleClass)
+	EntryIC       string    `json:"entry_ic"`
+	ExitIC        string    `json:"exit_ic"`
+	TripID        string    `json:"trip_id"` // stitched trip; empty means the record is a trip of its own
+	Amount        int       `json:"amount"`  // charged amount, negative for reversals
+	NormalAmount  int       `json:"normal_amount"`
+	Discount      int       `json:"discount"` // ETC discount as a positive number
+	Reversal      bool      `json:"reversal"`
+	// Saves is how many times the record was saved to db_service (each import adds one);
+	// more than one means the statement row was imported again
+	Saves int `json:"saves"`
+}
+
+// Value returns the entry's value for a dimension
+func (e Entry) Value(dimension Dimension) string {
+	switch dimension {
+	case DimensionCard:
+		return e.CardNumber
+	case DimensionVehicle:
+		if e.VehicleID != "" {
+			return e.VehicleID
+		}
+		return e.VehicleNumber
+	case DimensionAccount:
+		return e.AccountID
+	case DimensionRoute:
+		return e.EntryIC + " → " + e.ExitIC
+	case DimensionMonth:
+		return e.Date.Format("2006-01")
+	}
+	return ""
+}
+
+// Filter selects the entries to summarize
+type Filter struct {
+	From      time.Time // first day, inclusive; zero means unbounded
+	To        time.Time // last day, inclusive; zero means unbounded
+	AccountID string    // empty means all accounts
+}
+
+// matches reports whether an entry passes the filter
+func (f Filter) matches(e Entry) bool {
+	if !f.From.IsZero() && e.Date.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && e.Date.After(f.To) {
+		return false
+	}
+	return f.AccountID == "" || e.AccountID == f.AccountID
+}
+
+// Summary is the usage of one group of entries
+type Summary struct {
+	Group        map[Dimension]string // value of each grouping dimension
+	Amount       int64                // charged total, with reversals counted negative
+	NormalAmount int64                // total before discounts of regular rows
+	Discount     int64                // total ETC discount of regular rows
+	Trips        int                  // distinct trips among regular rows
+	Records      int
+	Reversals    int
+}
+
+// Store keeps imported records for usage reporting
+type Store interface {
+	// Add records an entry, replacing any entry with the same key and adding up their saves
+	Add(entry Entry) error
+	// Summarize totals the entries passing filter, grouped by the given dimensions.
+	// Without dimensions the result is a single summary over all matching entries.
+	Summarize(filter Filter, groupBy []Dimension) []Summary
+	// Entries returns the entries passing filter, ordered by date and key
+	Entries(filter Filter) []Entry
+	// Has reports whether an entry with the key was recorded and has not expired
+	Has(key string) bool
+}
+
+// DefaultRetentionDays is how many days of usage a store keeps unless configured otherwise
+const DefaultRetentionDays = 731
+
+// MemoryStore is an in-process Store. Its entries are lost when the process exits;
+// use a FileStore to keep them across restarts.
+type MemoryStore struct {
+	mu            sync.RWMutex
+	entries       map[string]Entry
+	retentionDays int
+	latest        time.Time // newest usage date added
+	prunedBefore  time.Time // cutoff of the last prune
+}
+
+// NewMemoryStore creates an empty in-memory store keeping DefaultRetentionDays of usage
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]Entry), retentionDays: DefaultRetentionDays}
+}
+
+// SetRetention drops entries whose usage date is more than days before the newest usage
+// date in the store, now and as newer records are added; 0 keeps every entry
+func (s *MemoryStore) SetRetention(days int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.retentionDays = days
+	s.prunedBefore = time.Time{}
+	s.prune()
+}
+
+// Add implements Store. Entries older than the retention period are ignored.
+func (s *MemoryStore) Add(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.add(entry)
+	return nil
+}
+
+// add records an entry; the caller holds the lock
+func (s *MemoryStore) add(entry Entry) {
+	if entry.Date.After(s.latest) {
+		s.latest = entry.Date
+		s.prune()
+	}
+	if s.expired(entry) {
+		return
+	}
+	if entry.Saves < 1 {
+		entry.Saves = 1
+	}
+	if existing, ok := s.entries[entry.Key]; ok {
+		entry.Saves += existing.Saves
+	}
+	s.entries[entry.Key] = entry
+}
+
+// cutoff returns the first usage date kept, or the zero time without retention.
+// It follows the newest usage date rather than the clock, so imports of old statements
+// are summarized as long as nothing newer pushes them out.
+func (s *MemoryStore) cutoff() time.Time {
+	if s.retentionDays <= 0 || s.latest.IsZero() {
+		return time.Time{}
+	}
+	return s.latest.AddDate(0, 0, -s.retentionDays)
+}
+
+// expired reports whether an entry is older than the retention period
+func (s *MemoryStore) expired(entry Entry) bool {
+	cutoff := s.cutoff()
+	return !cutoff.IsZero() && entry.Date.Before(cutoff)
+}
+
+// prune drops expired entries when the cutoff has moved; the caller holds the lock
+func (s *MemoryStore) prune() {
+	cutoff := s.cutoff()
+	if cutoff.IsZero() || cutoff.Equal(s.prunedBefore) {
+		return
+	}
+	for key, entry := range s.entries {
+		if entry.Date.Before(cutoff) {
+			delete(s.entries, key)
+		}
+	}
+	s.prunedBefore = cutoff
+}
+
+// Len returns the number of stored entries
+func (s *MemoryStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.entries)
+}
+
+// Has implements Store
+func (s *MemoryStore) Has(key string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.entries[key]
+	return ok
+}
+
+// Summarize implements Store
+func (s *MemoryStore) Summarize(filter Filter, groupBy []Dimension) []Summary {
+	return Summarize(s.Entries(filter), groupBy)
+}
+
+// Entries implements Store
+func (s *MemoryStore) Entries(filter Filter) []Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var entries []Entry
+	for _, entry := range s.entries {
+		if filter.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].Date.Equal(entries[j].Date) {
+			return entries[i].Date.Before(entries[j].Date)
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+// FileStore is a Store persisted as JSON Lines, so usage summaries and reconciliation survive
+// restarts. Each save is appended as a line; when the file is loaded, lines with the same key
+// add up their saves again, and the file is rewritten without replaced and expired lines.
+type FileStore struct {
+	*MemoryStore
+	mu   sync.Mutex // serializes appends
+	path string
+}
+
+// OpenFileStore loads a usage store file keeping retentionDays of usage (0 keeps every entry, see SetRetention);
+// a missing file yields an empty store that is created on the first import
+func OpenFileStore(path string, retentionDays int) (*FileStore, error) {
+	s := &FileStore{MemoryStore: NewMemoryStore(), path: path}
+	s.MemoryStore.retentionDays = retentionDays
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open usage store: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line, lines := 0, 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("usage store line %d: %w", line, err)
+		}
+		s.MemoryStore.add(entry)
+		lines++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read usage store: %w", err)
+	}
+
+	if lines > s.Len() {
+		if err := s.compact(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Add implements Store by appending the entry to the file before keeping it in memory
+func (s *FileStore) Add(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry.Saves < 1 {
+		entry.Saves = 1
+	}
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open usage store: %w", err)
+	}
+	if err := writeEntries(file, []Entry{entry}); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write usage store: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write usage store: %w", err)
+	}
+	return s.MemoryStore.Add(entry)
+}
+
+// compact rewrites the file with one line per kept entry, carrying its total saves
+func (s *FileStore) compact() error {
+	tmp := s.path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to compact usage store: %w", err)
+	}
+	if err := writeEntries(file, s.Entries(Filter{})); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to compact usage store: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to compact usage store: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to compact usage store: %w", err)
+	}
+	return nil
+}
+
+// writeEntries writes entries as JSON Lines
+func writeEntries(file *os.File, entries []Entry) error {
+	encoder := json.NewEncoder(file)
+	encoder.SetEscapeHTML(false)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Summarize totals entries grouped by the given dimensions, ordered by the group values.
+// A stitched trip is counted once in each group it has rows in; a record without a trip ID
+// counts as a trip of its own.
+func Summarize(entries []Entry, groupBy []Dimension) []Summary {
+	groups := make(map[string]*Summary)
+	trips := make(map[string]map[string]bool)
+	for _, entry := range entries {
+		values := make([]string, len(groupBy))
+		for i, dimension := range groupBy {
+			values[i] = entry.Value(dimension)
+		}
+		groupKey := strings.Join(values, "\x00")
+
+		summary, ok := groups[groupKey]
+		if !ok {
+			summary = &Summary{Group: make(map[Dimension]string, len(groupBy))}
+			for i, dimension := range groupBy {
+				summary.Group[dimension] = values[i]
+			}
+			groups[groupKey] = summary
+			trips[groupKey] = make(map[string]bool)
+		}
+
+		summary.Amount += int64(entry.Amount)
+		summary.Records++
+		if entry.Reversal {
+			summary.Reversals++
+			continue
+		}
+		summary.NormalAmount += int64(entry.NormalAmount)
+		summary.Discount += int64(entry.Discount)
+
+		tripID := entry.TripID
+		if tripID == "" {
+			tripID = "record:" + entry.Key
+		}
+		if !trips[groupKey][tripID] {
+			trips[groupKey][tripID] = true
+			summary.Trips++
+		}
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]Summary, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, *groups[key])
+	}
+	return result
+}
@@ -10,6 +10,7 @@ import (
 	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -22,11 +23,423 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// NEXCO vehicle class (車種区分); values match the 車種 column of ETC statements
+type VehicleClass int32
+
+const (
+	VehicleClass_VEHICLE_CLASS_UNSPECIFIED VehicleClass = 0
+	VehicleClass_VEHICLE_CLASS_STANDARD    VehicleClass = 1 // 普通車
+	VehicleClass_VEHICLE_CLASS_MEDIUM      VehicleClass = 2 // 中型車
+	VehicleClass_VEHICLE_CLASS_LARGE       VehicleClass = 3 // 大型車
+	VehicleClass_VEHICLE_CLASS_EXTRA_LARGE VehicleClass = 4 // 特大車
+	VehicleClass_VEHICLE_CLASS_LIGHT       VehicleClass = 5 // 軽自動車等
+)
+
+// Enum value maps for VehicleClass.
+var (
+	VehicleClass_name = map[int32]string{
+		0: "VEHICLE_CLASS_UNSPECIFIED",
+		1: "VEHICLE_CLASS_STANDARD",
+		2: "VEHICLE_CLASS_MEDIUM",
+		3: "VEHICLE_CLASS_LARGE",
+		4: "VEHICLE_CLASS_EXTRA_LARGE",
+		5: "VEHICLE_CLASS_LIGHT",
+	}
+	VehicleClass_value = map[string]int32{
+		"VEHICLE_CLASS_UNSPECIFIED": 0,
+		"VEHICLE_CLASS_STANDARD":    1,
+		"VEHICLE_CLASS_MEDIUM":      2,
+		"VEHICLE_CLASS_LARGE":       3,
+		"VEHICLE_CLASS_EXTRA_LARGE": 4,
+		"VEHICLE_CLASS_LIGHT":       5,
+	}
+)
+
+func (x VehicleClass) Enum() *VehicleClass {
+	p := new(VehicleClass)
+	*p = x
+	return p
+}
+
+func (x VehicleClass) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (VehicleClass) Descriptor() protoreflect.EnumDescriptor {
+	return file_src_proto_data_processor_proto_enumTypes[0].Descriptor()
+}
+
+func (VehicleClass) Type() protoreflect.EnumType {
+	return &file_src_proto_data_processor_proto_enumTypes[0]
+}
+
+func (x VehicleClass) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use VehicleClass.Descriptor instead.
+func (VehicleClass) EnumDescriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{0}
+}
+
+// Dimensions usage can be grouped by
+type UsageDimension int32
+
+const (
+	UsageDimension_USAGE_DIMENSION_UNSPECIFIED UsageDimension = 0
+	UsageDimension_USAGE_DIMENSION_CARD        UsageDimension = 1
+	// Vehicle ID from the master data, or the statement's 車両番号 when the card is not assigned
+	UsageDimension_USAGE_DIMENSION_VEHICLE UsageDimension = 2
+	UsageDimension_USAGE_DIMENSION_ACCOUNT UsageDimension = 3
+	// Entry and exit IC ("東京 → 横浜")
+	UsageDimension_USAGE_DIMENSION_ROUTE UsageDimension = 4
+	// Usage month ("2025-09")
+	UsageDimension_USAGE_DIMENSION_MONTH UsageDimension = 5
+)
+
+// Enum value maps for UsageDimension.
+var (
+	UsageDimension_name = map[int32]string{
+		0: "USAGE_DIMENSION_UNSPECIFIED",
+		1: "USAGE_DIMENSION_CARD",
+		2: "USAGE_DIMENSION_VEHICLE",
+		3: "USAGE_DIMENSION_ACCOUNT",
+		4: "USAGE_DIMENSION_ROUTE",
+		5: "USAGE_DIMENSION_MONTH",
+	}
+	UsageDimension_value = map[string]int32{
+		"USAGE_DIMENSION_UNSPECIFIED": 0,
+		"USAGE_DIMENSION_CARD":        1,
+		"USAGE_DIMENSION_VEHICLE":     2,
+		"USAGE_DIMENSION_ACCOUNT":     3,
+		"USAGE_DIMENSION_ROUTE":       4,
+		"USAGE_DIMENSION_MONTH":       5,
+	}
+)
+
+func (x UsageDimension) Enum() *UsageDimension {
+	p := new(UsageDimension)
+	*p = x
+	return p
+}
+
+func (x UsageDimension) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (UsageDimension) Descriptor() protoreflect.EnumDescriptor {
+	return file_src_proto_data_processor_proto_enumTypes[1].Descriptor()
+}
+
+func (UsageDimension) Type() protoreflect.EnumType {
+	return &file_src_proto_data_processor_proto_enumTypes[1]
+}
+
+func (x UsageDimension) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use UsageDimension.Descriptor instead.
+func (UsageDimension) EnumDescriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{1}
+}
+
+// Import formats of accounting software
+type JournalFormat int32
+
+const (
+	JournalFormat_JOURNAL_FORMAT_UNSPECIFIED   JournalFormat = 0
+	JournalFormat_JOURNAL_FORMAT_FREEE         JournalFormat = 1 // freee 取引インポート
+	JournalFormat_JOURNAL_FORMAT_MONEY_FORWARD JournalFormat = 2 // マネーフォワード クラウド会計 仕訳帳インポート
+	JournalFormat_JOURNAL_FORMAT_YAYOI         JournalFormat = 3 // 弥生会計 弥生インポート形式
+)
+
+// Enum value maps for JournalFormat.
+var (
+	JournalFormat_name = map[int32]string{
+		0: "JOURNAL_FORMAT_UNSPECIFIED",
+		1: "JOURNAL_FORMAT_FREEE",
+		2: "JOURNAL_FORMAT_MONEY_FORWARD",
+		3: "JOURNAL_FORMAT_YAYOI",
+	}
+	JournalFormat_value = map[string]int32{
+		"JOURNAL_FORMAT_UNSPECIFIED":   0,
+		"JOURNAL_FORMAT_FREEE":         1,
+		"JOURNAL_FORMAT_MONEY_FORWARD": 2,
+		"JOURNAL_FORMAT_YAYOI":         3,
+	}
+)
+
+func (x JournalFormat) Enum() *JournalFormat {
+	p := new(JournalFormat)
+	*p = x
+	return p
+}
+
+func (x JournalFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JournalFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_src_proto_data_processor_proto_enumTypes[2].Descriptor()
+}
+
+func (JournalFormat) Type() protoreflect.EnumType {
+	return &file_src_proto_data_processor_proto_enumTypes[2]
+}
+
+func (x JournalFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JournalFormat.Descriptor instead.
+func (JournalFormat) EnumDescriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{2}
+}
+
+type ReconciliationIssueKind int32
+
+const (
+	ReconciliationIssueKind_RECONCILIATION_ISSUE_KIND_UNSPECIFIED ReconciliationIssueKind = 0
+	// A row on the statement was not imported
+	ReconciliationIssueKind_RECONCILIATION_ISSUE_KIND_MISSING ReconciliationIssueKind = 1
+	// An imported row is not on the statement
+	ReconciliationIssueKind_RECONCILIATION_ISSUE_KIND_EXTRA ReconciliationIssueKind = 2
+	// The same row was imported more than once
+	ReconciliationIssueKind_RECONCILIATION_ISSUE_KIND_DUPLICATE ReconciliationIssueKind = 3
+)
+
+// Enum value maps for ReconciliationIssueKind.
+var (
+	ReconciliationIssueKind_name = map[int32]string{
+		0: "RECONCILIATION_ISSUE_KIND_UNSPECIFIED",
+		1: "RECONCILIATION_ISSUE_KIND_MISSING",
+		2: "RECONCILIATION_ISSUE_KIND_EXTRA",
+		3: "RECONCILIATION_ISSUE_KIND_DUPLICATE",
+	}
+	ReconciliationIssueKind_value = map[string]int32{
+		"RECONCILIATION_ISSUE_KIND_UNSPECIFIED": 0,
+		"RECONCILIATION_ISSUE_KIND_MISSING":     1,
+		"RECONCILIATION_ISSUE_KIND_EXTRA":       2,
+		"RECONCILIATION_ISSUE_KIND_DUPLICATE":   3,
+	}
+)
+
+func (x ReconciliationIssueKind) Enum() *ReconciliationIssueKind {
+	p := new(ReconciliationIssueKind)
+	*p = x
+	return p
+}
+
+func (x ReconciliationIssueKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReconciliationIssueKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_src_proto_data_processor_proto_enumTypes[3].Descriptor()
+}
+
+func (ReconciliationIssueKind) Type() protoreflect.EnumType {
+	return &file_src_proto_data_processor_proto_enumTypes[3]
+}
+
+func (x ReconciliationIssueKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReconciliationIssueKind.Descriptor instead.
+func (ReconciliationIssueKind) EnumDescriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{3}
+}
+
+type ErrorCode int32
+
+const (
+	ErrorCode_ERROR_CODE_UNSPECIFIED          ErrorCode = 0
+	ErrorCode_ERROR_CODE_PARSE                ErrorCode = 1
+	ErrorCode_ERROR_CODE_VALIDATION           ErrorCode = 2
+	ErrorCode_ERROR_CODE_DUPLICATE            ErrorCode = 3
+	ErrorCode_ERROR_CODE_CONVERSION           ErrorCode = 4
+	ErrorCode_ERROR_CODE_PERSISTENCE          ErrorCode = 5
+	ErrorCode_ERROR_CODE_CANCELLED            ErrorCode = 6
+	ErrorCode_ERROR_CODE_IDEMPOTENCY_CONFLICT ErrorCode = 7
+	// The card is not in the master data; the record is still saved
+	ErrorCode_ERROR_CODE_UNKNOWN_CARD ErrorCode = 8
+	// The discount on the statement differs from the expected discount; the record is still saved
+	ErrorCode_ERROR_CODE_DISCOUNT_MISMATCH ErrorCode = 9
+	// An anomaly rule flagged the record; the record is still saved
+	ErrorCode_ERROR_CODE_ANOMALY ErrorCode = 10
+)
+
+// Enum value maps for ErrorCode.
+var (
+	ErrorCode_name = map[int32]string{
+		0:  "ERROR_CODE_UNSPECIFIED",
+		1:  "ERROR_CODE_PARSE",
+		2:  "ERROR_CODE_VALIDATION",
+		3:  "ERROR_CODE_DUPLICATE",
+		4:  "ERROR_CODE_CONVERSION",
+		5:  "ERROR_CODE_PERSISTENCE",
+		6:  "ERROR_CODE_CANCELLED",
+		7:  "ERROR_CODE_IDEMPOTENCY_CONFLICT",
+		8:  "ERROR_CODE_UNKNOWN_CARD",
+		9:  "ERROR_CODE_DISCOUNT_MISMATCH",
+		10: "ERROR_CODE_ANOMALY",
+	}
+	ErrorCode_value = map[string]int32{
+		"ERROR_CODE_UNSPECIFIED":          0,
+		"ERROR_CODE_PARSE":                1,
+		"ERROR_CODE_VALIDATION":           2,
+		"ERROR_CODE_DUPLICATE":            3,
+		"ERROR_CODE_CONVERSION":           4,
+		"ERROR_CODE_PERSISTENCE":          5,
+		"ERROR_CODE_CANCELLED":            6,
+		"ERROR_CODE_IDEMPOTENCY_CONFLICT": 7,
+		"ERROR_CODE_UNKNOWN_CARD":         8,
+		"ERROR_CODE_DISCOUNT_MISMATCH":    9,
+		"ERROR_CODE_ANOMALY":              10,
+	}
+)
+
+func (x ErrorCode) Enum() *ErrorCode {
+	p := new(ErrorCode)
+	*p = x
+	return p
+}
+
+func (x ErrorCode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ErrorCode) Descriptor() protoreflect.EnumDescriptor {
+	return file_src_proto_data_processor_proto_enumTypes[4].Descriptor()
+}
+
+func (ErrorCode) Type() protoreflect.EnumType {
+	return &file_src_proto_data_processor_proto_enumTypes[4]
+}
+
+func (x ErrorCode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ErrorCode.Descriptor instead.
+func (ErrorCode) EnumDescriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{4}
+}
+
+type DryRunAction int32
+
+const (
+	DryRunAction_DRY_RUN_ACTION_UNSPECIFIED DryRunAction = 0
+	DryRunAction_DRY_RUN_ACTION_SAVE        DryRunAction = 1
+	DryRunAction_DRY_RUN_ACTION_SKIP        DryRunAction = 2
+	DryRunAction_DRY_RUN_ACTION_REJECT      DryRunAction = 3
+)
+
+// Enum value maps for DryRunAction.
+var (
+	DryRunAction_name = map[int32]string{
+		0: "DRY_RUN_ACTION_UNSPECIFIED",
+		1: "DRY_RUN_ACTION_SAVE",
+		2: "DRY_RUN_ACTION_SKIP",
+		3: "DRY_RUN_ACTION_REJECT",
+	}
+	DryRunAction_value = map[string]int32{
+		"DRY_RUN_ACTION_UNSPECIFIED": 0,
+		"DRY_RUN_ACTION_SAVE":        1,
+		"DRY_RUN_ACTION_SKIP":        2,
+		"DRY_RUN_ACTION_REJECT":      3,
+	}
+)
+
+func (x DryRunAction) Enum() *DryRunAction {
+	p := new(DryRunAction)
+	*p = x
+	return p
+}
+
+func (x DryRunAction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DryRunAction) Descriptor() protoreflect.EnumDescriptor {
+	return file_src_proto_data_processor_proto_enumTypes[5].Descriptor()
+}
+
+func (DryRunAction) Type() protoreflect.EnumType {
+	return &file_src_proto_data_processor_proto_enumTypes[5]
+}
+
+func (x DryRunAction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DryRunAction.Descriptor instead.
+func (DryRunAction) EnumDescriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{5}
+}
+
+type ExportFormat int32
+
+const (
+	ExportFormat_EXPORT_FORMAT_UNSPECIFIED ExportFormat = 0 // CSV
+	ExportFormat_EXPORT_FORMAT_CSV         ExportFormat = 1
+	ExportFormat_EXPORT_FORMAT_JSONL       ExportFormat = 2 // JSON Lines
+	ExportFormat_EXPORT_FORMAT_PARQUET     ExportFormat = 3
+)
+
+// Enum value maps for ExportFormat.
+var (
+	ExportFormat_name = map[int32]string{
+		0: "EXPORT_FORMAT_UNSPECIFIED",
+		1: "EXPORT_FORMAT_CSV",
+		2: "EXPORT_FORMAT_JSONL",
+		3: "EXPORT_FORMAT_PARQUET",
+	}
+	ExportFormat_value = map[string]int32{
+		"EXPORT_FORMAT_UNSPECIFIED": 0,
+		"EXPORT_FORMAT_CSV":         1,
+		"EXPORT_FORMAT_JSONL":       2,
+		"EXPORT_FORMAT_PARQUET":     3,
+	}
+)
+
+func (x ExportFormat) Enum() *ExportFormat {
+	p := new(ExportFormat)
+	*p = x
+	return p
+}
+
+func (x ExportFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ExportFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_src_proto_data_processor_proto_enumTypes[6].Descriptor()
+}
+
+func (ExportFormat) Type() protoreflect.EnumType {
+	return &file_src_proto_data_processor_proto_enumTypes[6]
+}
+
+func (x ExportFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ExportFormat.Descriptor instead.
+func (ExportFormat) EnumDescriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{6}
+}
+
 type ProcessCSVFileRequest struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
 	CsvFilePath    *string                `protobuf:"bytes,1,opt,name=csv_file_path,json=csvFilePath,proto3,oneof" json:"csv_file_path,omitempty"`
 	AccountId      *string                `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3,oneof" json:"account_id,omitempty"`
 	SkipDuplicates *bool                  `protobuf:"varint,3,opt,name=skip_duplicates,json=skipDuplicates,proto3,oneof" json:"skip_duplicates,omitempty"`
+	DryRun         *bool                  `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3,oneof" json:"dry_run,omitempty"`
+	IdempotencyKey *string                `protobuf:"bytes,5,opt,name=idempotency_key,json=idempotencyKey,proto3,oneof" json:"idempotency_key,omitempty"`
+	StitchTrips    *bool                  `protobuf:"varint,6,opt,name=stitch_trips,json=stitchTrips,proto3,oneof" json:"stitch_trips,omitempty"`
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
@@ -82,12 +495,43 @@ func (x *ProcessCSVFileRequest) GetSkipDuplicates() bool {
 	return false
 }
 
+func (x *ProcessCSVFileRequest) GetDryRun() bool {
+	if x != nil && x.DryRun != nil {
+		return *x.DryRun
+	}
+	return false
+}
+
+func (x *ProcessCSVFileRequest) GetIdempotencyKey() string {
+	if x != nil && x.IdempotencyKey != nil {
+		return *x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *ProcessCSVFileRequest) GetStitchTrips() bool {
+	if x != nil && x.StitchTrips != nil {
+		return *x.StitchTrips
+	}
+	return false
+}
+
 type ProcessCSVFileResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	Stats         *ProcessingStats       `protobuf:"bytes,3,opt,name=stats,proto3" json:"stats,omitempty"`
 	Errors        []string               `protobuf:"bytes,4,rep,name=errors,proto3" json:"errors,omitempty"`
+	FileResults   []*FileResult          `protobuf:"bytes,5,rep,name=file_results,json=fileResults,proto3" json:"file_results,omitempty"`
+	RecordErrors  []*RecordError         `protobuf:"bytes,6,rep,name=record_errors,json=recordErrors,proto3" json:"record_errors,omitempty"`
+	DryRun        bool                   `protobuf:"varint,7,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	DryRunRecords []*DryRunRecord        `protobuf:"bytes,8,rep,name=dry_run_records,json=dryRunRecords,proto3" json:"dry_run_records,omitempty"`
+	Replayed      bool                   `protobuf:"varint,9,opt,name=replayed,proto3" json:"replayed,omitempty"`
+	UnmatchedIcs  []*UnmatchedIC         `protobuf:"bytes,10,rep,name=unmatched_ics,json=unmatchedIcs,proto3" json:"unmatched_ics,omitempty"`
+	Trips         []*Trip                `protobuf:"bytes,11,rep,name=trips,proto3" json:"trips,omitempty"`
+	Anomalies     []*AnomalyFinding      `protobuf:"bytes,12,rep,name=anomalies,proto3" json:"anomalies,omitempty"`
+	// Identifies the records of this call in the record store (ExportRecords import_job_id)
+	ImportJobId   string `protobuf:"bytes,13,opt,name=import_job_id,json=importJobId,proto3" json:"import_job_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -150,11 +594,77 @@ func (x *ProcessCSVFileResponse) GetErrors() []string {
 	return nil
 }
 
+func (x *ProcessCSVFileResponse) GetFileResults() []*FileResult {
+	if x != nil {
+		return x.FileResults
+	}
+	return nil
+}
+
+func (x *ProcessCSVFileResponse) GetRecordErrors() []*RecordError {
+	if x != nil {
+		return x.RecordErrors
+	}
+	return nil
+}
+
+func (x *ProcessCSVFileResponse) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *ProcessCSVFileResponse) GetDryRunRecords() []*DryRunRecord {
+	if x != nil {
+		return x.DryRunRecords
+	}
+	return nil
+}
+
+func (x *ProcessCSVFileResponse) GetReplayed() bool {
+	if x != nil {
+		return x.Replayed
+	}
+	return false
+}
+
+func (x *ProcessCSVFileResponse) GetUnmatchedIcs() []*UnmatchedIC {
+	if x != nil {
+		return x.UnmatchedIcs
+	}
+	return nil
+}
+
+func (x *ProcessCSVFileResponse) GetTrips() []*Trip {
+	if x != nil {
+		return x.Trips
+	}
+	return nil
+}
+
+func (x *ProcessCSVFileResponse) GetAnomalies() []*AnomalyFinding {
+	if x != nil {
+		return x.Anomalies
+	}
+	return nil
+}
+
+func (x *ProcessCSVFileResponse) GetImportJobId() string {
+	if x != nil {
+		return x.ImportJobId
+	}
+	return ""
+}
+
 type ProcessCSVDataRequest struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
 	CsvData        string                 `protobuf:"bytes,1,opt,name=csv_data,json=csvData,proto3" json:"csv_data,omitempty"`
 	AccountId      *string                `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3,oneof" json:"account_id,omitempty"`
 	SkipDuplicates *bool                  `protobuf:"varint,3,opt,name=skip_duplicates,json=skipDuplicates,proto3,oneof" json:"skip_duplicates,omitempty"`
+	DryRun         *bool                  `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3,oneof" json:"dry_run,omitempty"`
+	IdempotencyKey *string                `protobuf:"bytes,5,opt,name=idempotency_key,json=idempotencyKey,proto3,oneof" json:"idempotency_key,omitempty"`
+	StitchTrips    *bool                  `protobuf:"varint,6,opt,name=stitch_trips,json=stitchTrips,proto3,oneof" json:"stitch_trips,omitempty"`
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
@@ -184,57 +694,3128 @@ func (x *ProcessCSVDataRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ProcessCSVDataRequest.ProtoReflect.Descriptor instead.
-func (*ProcessCSVDataRequest) Descriptor() ([]byte, []int) {
-	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use ProcessCSVDataRequest.ProtoReflect.Descriptor instead.
+func (*ProcessCSVDataRequest) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ProcessCSVDataRequest) GetCsvData() string {
+	if x != nil {
+		return x.CsvData
+	}
+	return ""
+}
+
+func (x *ProcessCSVDataRequest) GetAccountId() string {
+	if x != nil && x.AccountId != nil {
+		return *x.AccountId
+	}
+	return ""
+}
+
+func (x *ProcessCSVDataRequest) GetSkipDuplicates() bool {
+	if x != nil && x.SkipDuplicates != nil {
+		return *x.SkipDuplicates
+	}
+	return false
+}
+
+func (x *ProcessCSVDataRequest) GetDryRun() bool {
+	if x != nil && x.DryRun != nil {
+		return *x.DryRun
+	}
+	return false
+}
+
+func (x *ProcessCSVDataRequest) GetIdempotencyKey() string {
+	if x != nil && x.IdempotencyKey != nil {
+		return *x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *ProcessCSVDataRequest) GetStitchTrips() bool {
+	if x != nil && x.StitchTrips != nil {
+		return *x.StitchTrips
+	}
+	return false
+}
+
+type ProcessCSVDataResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Stats         *ProcessingStats       `protobuf:"bytes,3,opt,name=stats,proto3" json:"stats,omitempty"`
+	Errors        []string               `protobuf:"bytes,4,rep,name=errors,proto3" json:"errors,omitempty"`
+	RecordErrors  []*RecordError         `protobuf:"bytes,5,rep,name=record_errors,json=recordErrors,proto3" json:"record_errors,omitempty"`
+	DryRun        bool                   `protobuf:"varint,6,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	DryRunRecords []*DryRunRecord        `protobuf:"bytes,7,rep,name=dry_run_records,json=dryRunRecords,proto3" json:"dry_run_records,omitempty"`
+	Replayed      bool                   `protobuf:"varint,8,opt,name=replayed,proto3" json:"replayed,omitempty"`
+	UnmatchedIcs  []*UnmatchedIC         `protobuf:"bytes,9,rep,name=unmatched_ics,json=unmatchedIcs,proto3" json:"unmatched_ics,omitempty"`
+	Trips         []*Trip                `protobuf:"bytes,10,rep,name=trips,proto3" json:"trips,omitempty"`
+	Anomalies     []*AnomalyFinding      `protobuf:"bytes,11,rep,name=anomalies,proto3" json:"anomalies,omitempty"`
+	// Identifies the records of this call in the record store (ExportRecords import_job_id)
+	ImportJobId   string `protobuf:"bytes,12,opt,name=import_job_id,json=importJobId,proto3" json:"import_job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessCSVDataResponse) Reset() {
+	*x = ProcessCSVDataResponse{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessCSVDataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessCSVDataResponse) ProtoMessage() {}
+
+func (x *ProcessCSVDataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessCSVDataResponse.ProtoReflect.Descriptor instead.
+func (*ProcessCSVDataResponse) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ProcessCSVDataResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ProcessCSVDataResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ProcessCSVDataResponse) GetStats() *ProcessingStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *ProcessCSVDataResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *ProcessCSVDataResponse) GetRecordErrors() []*RecordError {
+	if x != nil {
+		return x.RecordErrors
+	}
+	return nil
+}
+
+func (x *ProcessCSVDataResponse) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *ProcessCSVDataResponse) GetDryRunRecords() []*DryRunRecord {
+	if x != nil {
+		return x.DryRunRecords
+	}
+	return nil
+}
+
+func (x *ProcessCSVDataResponse) GetReplayed() bool {
+	if x != nil {
+		return x.Replayed
+	}
+	return false
+}
+
+func (x *ProcessCSVDataResponse) GetUnmatchedIcs() []*UnmatchedIC {
+	if x != nil {
+		return x.UnmatchedIcs
+	}
+	return nil
+}
+
+func (x *ProcessCSVDataResponse) GetTrips() []*Trip {
+	if x != nil {
+		return x.Trips
+	}
+	return nil
+}
+
+func (x *ProcessCSVDataResponse) GetAnomalies() []*AnomalyFinding {
+	if x != nil {
+		return x.Anomalies
+	}
+	return nil
+}
+
+func (x *ProcessCSVDataResponse) GetImportJobId() string {
+	if x != nil {
+		return x.ImportJobId
+	}
+	return ""
+}
+
+type ValidateCSVDataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CsvData       string                 `protobuf:"bytes,1,opt,name=csv_data,json=csvData,proto3" json:"csv_data,omitempty"`
+	AccountId     *string                `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3,oneof" json:"account_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateCSVDataRequest) Reset() {
+	*x = ValidateCSVDataRequest{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateCSVDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateCSVDataRequest) ProtoMessage() {}
+
+func (x *ValidateCSVDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateCSVDataRequest.ProtoReflect.Descriptor instead.
+func (*ValidateCSVDataRequest) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ValidateCSVDataRequest) GetCsvData() string {
+	if x != nil {
+		return x.CsvData
+	}
+	return ""
+}
+
+func (x *ValidateCSVDataRequest) GetAccountId() string {
+	if x != nil && x.AccountId != nil {
+		return *x.AccountId
+	}
+	return ""
+}
+
+type ValidateCSVDataResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	IsValid        bool                   `protobuf:"varint,1,opt,name=is_valid,json=isValid,proto3" json:"is_valid,omitempty"`
+	Errors         []*ValidationError     `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	DuplicateCount int32                  `protobuf:"varint,3,opt,name=duplicate_count,json=duplicateCount,proto3" json:"duplicate_count,omitempty"`
+	TotalRecords   int32                  `protobuf:"varint,4,opt,name=total_records,json=totalRecords,proto3" json:"total_records,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ValidateCSVDataResponse) Reset() {
+	*x = ValidateCSVDataResponse{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateCSVDataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateCSVDataResponse) ProtoMessage() {}
+
+func (x *ValidateCSVDataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateCSVDataResponse.ProtoReflect.Descriptor instead.
+func (*ValidateCSVDataResponse) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ValidateCSVDataResponse) GetIsValid() bool {
+	if x != nil {
+		return x.IsValid
+	}
+	return false
+}
+
+func (x *ValidateCSVDataResponse) GetErrors() []*ValidationError {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *ValidateCSVDataResponse) GetDuplicateCount() int32 {
+	if x != nil {
+		return x.DuplicateCount
+	}
+	return 0
+}
+
+func (x *ValidateCSVDataResponse) GetTotalRecords() int32 {
+	if x != nil {
+		return x.TotalRecords
+	}
+	return 0
+}
+
+type PreviewCSVRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CsvData       *string                `protobuf:"bytes,1,opt,name=csv_data,json=csvData,proto3,oneof" json:"csv_data,omitempty"`
+	CsvFilePath   *string                `protobuf:"bytes,2,opt,name=csv_file_path,json=csvFilePath,proto3,oneof" json:"csv_file_path,omitempty"`
+	Limit         *int32                 `protobuf:"varint,3,opt,name=limit,proto3,oneof" json:"limit,omitempty"`
+	StitchTrips   *bool                  `protobuf:"varint,4,opt,name=stitch_trips,json=stitchTrips,proto3,oneof" json:"stitch_trips,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PreviewCSVRequest) Reset() {
+	*x = PreviewCSVRequest{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PreviewCSVRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PreviewCSVRequest) ProtoMessage() {}
+
+func (x *PreviewCSVRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PreviewCSVRequest.ProtoReflect.Descriptor instead.
+func (*PreviewCSVRequest) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *PreviewCSVRequest) GetCsvData() string {
+	if x != nil && x.CsvData != nil {
+		return *x.CsvData
+	}
+	return ""
+}
+
+func (x *PreviewCSVRequest) GetCsvFilePath() string {
+	if x != nil && x.CsvFilePath != nil {
+		return *x.CsvFilePath
+	}
+	return ""
+}
+
+func (x *PreviewCSVRequest) GetLimit() int32 {
+	if x != nil && x.Limit != nil {
+		return *x.Limit
+	}
+	return 0
+}
+
+func (x *PreviewCSVRequest) GetStitchTrips() bool {
+	if x != nil && x.StitchTrips != nil {
+		return *x.StitchTrips
+	}
+	return false
+}
+
+type PreviewCSVResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Format        string                 `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	Encoding      string                 `protobuf:"bytes,2,opt,name=encoding,proto3" json:"encoding,omitempty"`
+	TotalRecords  int32                  `protobuf:"varint,3,opt,name=total_records,json=totalRecords,proto3" json:"total_records,omitempty"`
+	Records       []*PreviewRecord       `protobuf:"bytes,4,rep,name=records,proto3" json:"records,omitempty"`
+	Trips         []*Trip                `protobuf:"bytes,5,rep,name=trips,proto3" json:"trips,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PreviewCSVResponse) Reset() {
+	*x = PreviewCSVResponse{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PreviewCSVResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PreviewCSVResponse) ProtoMessage() {}
+
+func (x *PreviewCSVResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PreviewCSVResponse.ProtoReflect.Descriptor instead.
+func (*PreviewCSVResponse) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PreviewCSVResponse) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *PreviewCSVResponse) GetEncoding() string {
+	if x != nil {
+		return x.Encoding
+	}
+	return ""
+}
+
+func (x *PreviewCSVResponse) GetTotalRecords() int32 {
+	if x != nil {
+		return x.TotalRecords
+	}
+	return 0
+}
+
+func (x *PreviewCSVResponse) GetRecords() []*PreviewRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+func (x *PreviewCSVResponse) GetTrips() []*Trip {
+	if x != nil {
+		return x.Trips
+	}
+	return nil
+}
+
+type PreviewRecord struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	LineNumber      int32                  `protobuf:"varint,1,opt,name=line_number,json=lineNumber,proto3" json:"line_number,omitempty"`
+	RawColumns      []string               `protobuf:"bytes,2,rep,name=raw_columns,json=rawColumns,proto3" json:"raw_columns,omitempty"`
+	Parsed          *ParsedRecord          `protobuf:"bytes,3,opt,name=parsed,proto3" json:"parsed,omitempty"`
+	Converted       *ConvertedRecord       `protobuf:"bytes,4,opt,name=converted,proto3" json:"converted,omitempty"`
+	ConversionError string                 `protobuf:"bytes,5,opt,name=conversion_error,json=conversionError,proto3" json:"conversion_error,omitempty"`
+	Mappings        []*FieldMapping        `protobuf:"bytes,6,rep,name=mappings,proto3" json:"mappings,omitempty"`
+	TripId          string                 `protobuf:"bytes,7,opt,name=trip_id,json=tripId,proto3" json:"trip_id,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *PreviewRecord) Reset() {
+	*x = PreviewRecord{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PreviewRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PreviewRecord) ProtoMessage() {}
+
+func (x *PreviewRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PreviewRecord.ProtoReflect.Descriptor instead.
+func (*PreviewRecord) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PreviewRecord) GetLineNumber() int32 {
+	if x != nil {
+		return x.LineNumber
+	}
+	return 0
+}
+
+func (x *PreviewRecord) GetRawColumns() []string {
+	if x != nil {
+		return x.RawColumns
+	}
+	return nil
+}
+
+func (x *PreviewRecord) GetParsed() *ParsedRecord {
+	if x != nil {
+		return x.Parsed
+	}
+	return nil
+}
+
+func (x *PreviewRecord) GetConverted() *ConvertedRecord {
+	if x != nil {
+		return x.Converted
+	}
+	return nil
+}
+
+func (x *PreviewRecord) GetConversionError() string {
+	if x != nil {
+		return x.ConversionError
+	}
+	return ""
+}
+
+func (x *PreviewRecord) GetMappings() []*FieldMapping {
+	if x != nil {
+		return x.Mappings
+	}
+	return nil
+}
+
+func (x *PreviewRecord) GetTripId() string {
+	if x != nil {
+		return x.TripId
+	}
+	return ""
+}
+
+type ParsedRecord struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	EntryDate            string                 `protobuf:"bytes,1,opt,name=entry_date,json=entryDate,proto3" json:"entry_date,omitempty"`
+	EntryTime            string                 `protobuf:"bytes,2,opt,name=entry_time,json=entryTime,proto3" json:"entry_time,omitempty"`
+	ExitDate             string                 `protobuf:"bytes,3,opt,name=exit_date,json=exitDate,proto3" json:"exit_date,omitempty"`
+	ExitTime             string                 `protobuf:"bytes,4,opt,name=exit_time,json=exitTime,proto3" json:"exit_time,omitempty"`
+	EntryIc              string                 `protobuf:"bytes,5,opt,name=entry_ic,json=entryIc,proto3" json:"entry_ic,omitempty"`
+	ExitIc               string                 `protobuf:"bytes,6,opt,name=exit_ic,json=exitIc,proto3" json:"exit_ic,omitempty"`
+	RouteInfo            string                 `protobuf:"bytes,7,opt,name=route_info,json=routeInfo,proto3" json:"route_info,omitempty"`
+	EtcAmount            int32                  `protobuf:"varint,8,opt,name=etc_amount,json=etcAmount,proto3" json:"etc_amount,omitempty"`
+	NormalAmount         int32                  `protobuf:"varint,9,opt,name=normal_amount,json=normalAmount,proto3" json:"normal_amount,omitempty"`
+	DiscountApplied      int32                  `protobuf:"varint,10,opt,name=discount_applied,json=discountApplied,proto3" json:"discount_applied,omitempty"`
+	Mileage              int32                  `protobuf:"varint,11,opt,name=mileage,proto3" json:"mileage,omitempty"`
+	VehicleClass         VehicleClass           `protobuf:"varint,12,opt,name=vehicle_class,json=vehicleClass,proto3,enum=etcdataprocessor.v1.VehicleClass" json:"vehicle_class,omitempty"`
+	VehicleNumber        string                 `protobuf:"bytes,13,opt,name=vehicle_number,json=vehicleNumber,proto3" json:"vehicle_number,omitempty"`
+	CardNumber           string                 `protobuf:"bytes,14,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`
+	Notes                string                 `protobuf:"bytes,15,opt,name=notes,proto3" json:"notes,omitempty"`
+	PostPaymentAmount    int32                  `protobuf:"varint,16,opt,name=post_payment_amount,json=postPaymentAmount,proto3" json:"post_payment_amount,omitempty"`
+	RedemptionBaseAmount int32                  `protobuf:"varint,17,opt,name=redemption_base_amount,json=redemptionBaseAmount,proto3" json:"redemption_base_amount,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *ParsedRecord) Reset() {
+	*x = ParsedRecord{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParsedRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParsedRecord) ProtoMessage() {}
+
+func (x *ParsedRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParsedRecord.ProtoReflect.Descriptor instead.
+func (*ParsedRecord) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ParsedRecord) GetEntryDate() string {
+	if x != nil {
+		return x.EntryDate
+	}
+	return ""
+}
+
+func (x *ParsedRecord) GetEntryTime() string {
+	if x != nil {
+		return x.EntryTime
+	}
+	return ""
+}
+
+func (x *ParsedRecord) GetExitDate() string {
+	if x != nil {
+		return x.ExitDate
+	}
+	return ""
+}
+
+func (x *ParsedRecord) GetExitTime() string {
+	if x != nil {
+		return x.ExitTime
+	}
+	return ""
+}
+
+func (x *ParsedRecord) GetEntryIc() string {
+	if x != nil {
+		return x.EntryIc
+	}
+	return ""
+}
+
+func (x *ParsedRecord) GetExitIc() string {
+	if x != nil {
+		return x.ExitIc
+	}
+	return ""
+}
+
+func (x *ParsedRecord) GetRouteInfo() string {
+	if x != nil {
+		return x.RouteInfo
+	}
+	return ""
+}
+
+func (x *ParsedRecord) GetEtcAmount() int32 {
+	if x != nil {
+		return x.EtcAmount
+	}
+	return 0
+}
+
+func (x *ParsedRecord) GetNormalAmount() int32 {
+	if x != nil {
+		return x.NormalAmount
+	}
+	return 0
+}
+
+func (x *ParsedRecord) GetDiscountApplied() int32 {
+	if x != nil {
+		return x.DiscountApplied
+	}
+	return 0
+}
+
+func (x *ParsedRecord) GetMileage() int32 {
+	if x != nil {
+		return x.Mileage
+	}
+	return 0
+}
+
+func (x *ParsedRecord) GetVehicleClass() VehicleClass {
+	if x != nil {
+		return x.VehicleClass
+	}
+	return VehicleClass_VEHICLE_CLASS_UNSPECIFIED
+}
+
+func (x *ParsedRecord) GetVehicleNumber() string {
+	if x != nil {
+		return x.VehicleNumber
+	}
+	return ""
+}
+
+func (x *ParsedRecord) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+func (x *ParsedRecord) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+func (x *ParsedRecord) GetPostPaymentAmount() int32 {
+	if x != nil {
+		return x.PostPaymentAmount
+	}
+	return 0
+}
+
+func (x *ParsedRecord) GetRedemptionBaseAmount() int32 {
+	if x != nil {
+		return x.RedemptionBaseAmount
+	}
+	return 0
+}
+
+type ConvertedRecord struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Date        string                 `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	EntryIc     string                 `protobuf:"bytes,2,opt,name=entry_ic,json=entryIc,proto3" json:"entry_ic,omitempty"`
+	ExitIc      string                 `protobuf:"bytes,3,opt,name=exit_ic,json=exitIc,proto3" json:"exit_ic,omitempty"`
+	Route       string                 `protobuf:"bytes,4,opt,name=route,proto3" json:"route,omitempty"`
+	VehicleType VehicleClass           `protobuf:"varint,5,opt,name=vehicle_type,json=vehicleType,proto3,enum=etcdataprocessor.v1.VehicleClass" json:"vehicle_type,omitempty"`
+	Amount      int32                  `protobuf:"varint,6,opt,name=amount,proto3" json:"amount,omitempty"`
+	CardNumber  string                 `protobuf:"bytes,7,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`
+	// Amount breakdown as it appears on the statement
+	NormalAmount      int32 `protobuf:"varint,8,opt,name=normal_amount,json=normalAmount,proto3" json:"normal_amount,omitempty"`
+	DiscountAmount    int32 `protobuf:"varint,9,opt,name=discount_amount,json=discountAmount,proto3" json:"discount_amount,omitempty"`
+	EtcAmount         int32 `protobuf:"varint,10,opt,name=etc_amount,json=etcAmount,proto3" json:"etc_amount,omitempty"`
+	PostPaymentAmount int32 `protobuf:"varint,11,opt,name=post_payment_amount,json=postPaymentAmount,proto3" json:"post_payment_amount,omitempty"`
+	Mileage           int32 `protobuf:"varint,12,opt,name=mileage,proto3" json:"mileage,omitempty"`
+	// Refund and correction rows carry a negative amount
+	Reversal         bool   `protobuf:"varint,13,opt,name=reversal,proto3" json:"reversal,omitempty"`
+	CorrectionReason string `protobuf:"bytes,14,opt,name=correction_reason,json=correctionReason,proto3" json:"correction_reason,omitempty"`
+	// route split into ordered road sections
+	RouteSegments []*RouteSegment `protobuf:"bytes,15,rep,name=route_segments,json=routeSegments,proto3" json:"route_segments,omitempty"`
+	// "weekday", "weekend" or "holiday" (Japanese national holidays and configured days off)
+	DayType string `protobuf:"bytes,16,opt,name=day_type,json=dayType,proto3" json:"day_type,omitempty"`
+	// amount split into its tax-exclusive part and the consumption tax it includes
+	TaxExclusiveAmount int32 `protobuf:"varint,17,opt,name=tax_exclusive_amount,json=taxExclusiveAmount,proto3" json:"tax_exclusive_amount,omitempty"`
+	TaxAmount          int32 `protobuf:"varint,18,opt,name=tax_amount,json=taxAmount,proto3" json:"tax_amount,omitempty"`
+	// 還元額適用料金: yen amount the mileage redemption applies to
+	RedemptionBaseAmount int32 `protobuf:"varint,19,opt,name=redemption_base_amount,json=redemptionBaseAmount,proto3" json:"redemption_base_amount,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *ConvertedRecord) Reset() {
+	*x = ConvertedRecord{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConvertedRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertedRecord) ProtoMessage() {}
+
+func (x *ConvertedRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertedRecord.ProtoReflect.Descriptor instead.
+func (*ConvertedRecord) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ConvertedRecord) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *ConvertedRecord) GetEntryIc() string {
+	if x != nil {
+		return x.EntryIc
+	}
+	return ""
+}
+
+func (x *ConvertedRecord) GetExitIc() string {
+	if x != nil {
+		return x.ExitIc
+	}
+	return ""
+}
+
+func (x *ConvertedRecord) GetRoute() string {
+	if x != nil {
+		return x.Route
+	}
+	return ""
+}
+
+func (x *ConvertedRecord) GetVehicleType() VehicleClass {
+	if x != nil {
+		return x.VehicleType
+	}
+	return VehicleClass_VEHICLE_CLASS_UNSPECIFIED
+}
+
+func (x *ConvertedRecord) GetAmount() int32 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *ConvertedRecord) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+func (x *ConvertedRecord) GetNormalAmount() int32 {
+	if x != nil {
+		return x.NormalAmount
+	}
+	return 0
+}
+
+func (x *ConvertedRecord) GetDiscountAmount() int32 {
+	if x != nil {
+		return x.DiscountAmount
+	}
+	return 0
+}
+
+func (x *ConvertedRecord) GetEtcAmount() int32 {
+	if x != nil {
+		return x.EtcAmount
+	}
+	return 0
+}
+
+func (x *ConvertedRecord) GetPostPaymentAmount() int32 {
+	if x != nil {
+		return x.PostPaymentAmount
+	}
+	return 0
+}
+
+func (x *ConvertedRecord) GetMileage() int32 {
+	if x != nil {
+		return x.Mileage
+	}
+	return 0
+}
+
+func (x *ConvertedRecord) GetReversal() bool {
+	if x != nil {
+		return x.Reversal
+	}
+	return false
+}
+
+func (x *ConvertedRecord) GetCorrectionReason() string {
+	if x != nil {
+		return x.CorrectionReason
+	}
+	return ""
+}
+
+func (x *ConvertedRecord) GetRouteSegments() []*RouteSegment {
+	if x != nil {
+		return x.RouteSegments
+	}
+	return nil
+}
+
+func (x *ConvertedRecord) GetDayType() string {
+	if x != nil {
+		return x.DayType
+	}
+	return ""
+}
+
+func (x *ConvertedRecord) GetTaxExclusiveAmount() int32 {
+	if x != nil {
+		return x.TaxExclusiveAmount
+	}
+	return 0
+}
+
+func (x *ConvertedRecord) GetTaxAmount() int32 {
+	if x != nil {
+		return x.TaxAmount
+	}
+	return 0
+}
+
+func (x *ConvertedRecord) GetRedemptionBaseAmount() int32 {
+	if x != nil {
+		return x.RedemptionBaseAmount
+	}
+	return 0
+}
+
+// One section of 経路情報: a road with its start/end IC and the junctions or smart ICs passed
+type RouteSegment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Road          string                 `protobuf:"bytes,1,opt,name=road,proto3" json:"road,omitempty"`
+	From          string                 `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	Via           []string               `protobuf:"bytes,4,rep,name=via,proto3" json:"via,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RouteSegment) Reset() {
+	*x = RouteSegment{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RouteSegment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RouteSegment) ProtoMessage() {}
+
+func (x *RouteSegment) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RouteSegment.ProtoReflect.Descriptor instead.
+func (*RouteSegment) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RouteSegment) GetRoad() string {
+	if x != nil {
+		return x.Road
+	}
+	return ""
+}
+
+func (x *RouteSegment) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *RouteSegment) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *RouteSegment) GetVia() []string {
+	if x != nil {
+		return x.Via
+	}
+	return nil
+}
+
+type FieldMapping struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Header        string                 `protobuf:"bytes,2,opt,name=header,proto3" json:"header,omitempty"`
+	Column        int32                  `protobuf:"varint,3,opt,name=column,proto3" json:"column,omitempty"`
+	RawValue      string                 `protobuf:"bytes,4,opt,name=raw_value,json=rawValue,proto3" json:"raw_value,omitempty"`
+	Value         string                 `protobuf:"bytes,5,opt,name=value,proto3" json:"value,omitempty"`
+	Coerced       bool                   `protobuf:"varint,6,opt,name=coerced,proto3" json:"coerced,omitempty"`
+	Note          string                 `protobuf:"bytes,7,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FieldMapping) Reset() {
+	*x = FieldMapping{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FieldMapping) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldMapping) ProtoMessage() {}
+
+func (x *FieldMapping) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldMapping.ProtoReflect.Descriptor instead.
+func (*FieldMapping) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *FieldMapping) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *FieldMapping) GetHeader() string {
+	if x != nil {
+		return x.Header
+	}
+	return ""
+}
+
+func (x *FieldMapping) GetColumn() int32 {
+	if x != nil {
+		return x.Column
+	}
+	return 0
+}
+
+func (x *FieldMapping) GetRawValue() string {
+	if x != nil {
+		return x.RawValue
+	}
+	return ""
+}
+
+func (x *FieldMapping) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *FieldMapping) GetCoerced() bool {
+	if x != nil {
+		return x.Coerced
+	}
+	return false
+}
+
+func (x *FieldMapping) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+// Assigns an ETC card (or a vehicle number when card_number is empty) to an internal vehicle and driver
+type CardAssignment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CardNumber    string                 `protobuf:"bytes,2,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`
+	VehicleNumber string                 `protobuf:"bytes,3,opt,name=vehicle_number,json=vehicleNumber,proto3" json:"vehicle_number,omitempty"`
+	VehicleId     string                 `protobuf:"bytes,4,opt,name=vehicle_id,json=vehicleId,proto3" json:"vehicle_id,omitempty"`
+	DriverId      string                 `protobuf:"bytes,5,opt,name=driver_id,json=driverId,proto3" json:"driver_id,omitempty"`
+	// Validity period (YYYY-MM-DD, inclusive); empty means unbounded
+	ValidFrom string `protobuf:"bytes,6,opt,name=valid_from,json=validFrom,proto3" json:"valid_from,omitempty"`
+	ValidTo   string `protobuf:"bytes,7,opt,name=valid_to,json=validTo,proto3" json:"valid_to,omitempty"`
+	// Registered NEXCO vehicle class; VEHICLE_CLASS_UNSPECIFIED means not registered
+	VehicleClass  VehicleClass `protobuf:"varint,8,opt,name=vehicle_class,json=vehicleClass,proto3,enum=etcdataprocessor.v1.VehicleClass" json:"vehicle_class,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CardAssignment) Reset() {
+	*x = CardAssignment{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CardAssignment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CardAssignment) ProtoMessage() {}
+
+func (x *CardAssignment) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CardAssignment.ProtoReflect.Descriptor instead.
+func (*CardAssignment) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CardAssignment) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CardAssignment) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+func (x *CardAssignment) GetVehicleNumber() string {
+	if x != nil {
+		return x.VehicleNumber
+	}
+	return ""
+}
+
+func (x *CardAssignment) GetVehicleId() string {
+	if x != nil {
+		return x.VehicleId
+	}
+	return ""
+}
+
+func (x *CardAssignment) GetDriverId() string {
+	if x != nil {
+		return x.DriverId
+	}
+	return ""
+}
+
+func (x *CardAssignment) GetValidFrom() string {
+	if x != nil {
+		return x.ValidFrom
+	}
+	return ""
+}
+
+func (x *CardAssignment) GetValidTo() string {
+	if x != nil {
+		return x.ValidTo
+	}
+	return ""
+}
+
+func (x *CardAssignment) GetVehicleClass() VehicleClass {
+	if x != nil {
+		return x.VehicleClass
+	}
+	return VehicleClass_VEHICLE_CLASS_UNSPECIFIED
+}
+
+type CreateCardAssignmentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Assignment    *CardAssignment        `protobuf:"bytes,1,opt,name=assignment,proto3" json:"assignment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateCardAssignmentRequest) Reset() {
+	*x = CreateCardAssignmentRequest{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateCardAssignmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCardAssignmentRequest) ProtoMessage() {}
+
+func (x *CreateCardAssignmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCardAssignmentRequest.ProtoReflect.Descriptor instead.
+func (*CreateCardAssignmentRequest) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CreateCardAssignmentRequest) GetAssignment() *CardAssignment {
+	if x != nil {
+		return x.Assignment
+	}
+	return nil
+}
+
+type GetCardAssignmentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCardAssignmentRequest) Reset() {
+	*x = GetCardAssignmentRequest{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCardAssignmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCardAssignmentRequest) ProtoMessage() {}
+
+func (x *GetCardAssignmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCardAssignmentRequest.ProtoReflect.Descriptor instead.
+func (*GetCardAssignmentRequest) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetCardAssignmentRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListCardAssignmentsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CardNumber    string                 `protobuf:"bytes,1,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`
+	VehicleNumber string                 `protobuf:"bytes,2,opt,name=vehicle_number,json=vehicleNumber,proto3" json:"vehicle_number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCardAssignmentsRequest) Reset() {
+	*x = ListCardAssignmentsRequest{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCardAssignmentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCardAssignmentsRequest) ProtoMessage() {}
+
+func (x *ListCardAssignmentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCardAssignmentsRequest.ProtoReflect.Descriptor instead.
+func (*ListCardAssignmentsRequest) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListCardAssignmentsRequest) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+func (x *ListCardAssignmentsRequest) GetVehicleNumber() string {
+	if x != nil {
+		return x.VehicleNumber
+	}
+	return ""
+}
+
+type ListCardAssignmentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Assignments   []*CardAssignment      `protobuf:"bytes,1,rep,name=assignments,proto3" json:"assignments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCardAssignmentsResponse) Reset() {
+	*x = ListCardAssignmentsResponse{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCardAssignmentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCardAssignmentsResponse) ProtoMessage() {}
+
+func (x *ListCardAssignmentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCardAssignmentsResponse.ProtoReflect.Descriptor instead.
+func (*ListCardAssignmentsResponse) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListCardAssignmentsResponse) GetAssignments() []*CardAssignment {
+	if x != nil {
+		return x.Assignments
+	}
+	return nil
+}
+
+type UpdateCardAssignmentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Assignment    *CardAssignment        `protobuf:"bytes,1,opt,name=assignment,proto3" json:"assignment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateCardAssignmentRequest) Reset() {
+	*x = UpdateCardAssignmentRequest{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateCardAssignmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCardAssignmentRequest) ProtoMessage() {}
+
+func (x *UpdateCardAssignmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCardAssignmentRequest.ProtoReflect.Descriptor instead.
+func (*UpdateCardAssignmentRequest) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *UpdateCardAssignmentRequest) GetAssignment() *CardAssignment {
+	if x != nil {
+		return x.Assignment
+	}
+	return nil
+}
+
+type DeleteCardAssignmentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCardAssignmentRequest) Reset() {
+	*x = DeleteCardAssignmentRequest{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCardAssignmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCardAssignmentRequest) ProtoMessage() {}
+
+func (x *DeleteCardAssignmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCardAssignmentRequest.ProtoReflect.Descriptor instead.
+func (*DeleteCardAssignmentRequest) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *DeleteCardAssignmentRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteCardAssignmentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCardAssignmentResponse) Reset() {
+	*x = DeleteCardAssignmentResponse{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCardAssignmentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCardAssignmentResponse) ProtoMessage() {}
+
+func (x *DeleteCardAssignmentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCardAssignmentResponse.ProtoReflect.Descriptor instead.
+func (*DeleteCardAssignmentResponse) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{20}
+}
+
+type GetUsageSummaryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Usage date range (YYYY-MM-DD, inclusive); empty means unbounded
+	FromDate string           `protobuf:"bytes,1,opt,name=from_date,json=fromDate,proto3" json:"from_date,omitempty"`
+	ToDate   string           `protobuf:"bytes,2,opt,name=to_date,json=toDate,proto3" json:"to_date,omitempty"`
+	GroupBy  []UsageDimension `protobuf:"varint,3,rep,packed,name=group_by,json=groupBy,proto3,enum=etcdataprocessor.v1.UsageDimension" json:"group_by,omitempty"`
+	// Only records imported for this account; empty means all accounts
+	AccountId     string `protobuf:"bytes,4,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsageSummaryRequest) Reset() {
+	*x = GetUsageSummaryRequest{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsageSummaryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsageSummaryRequest) ProtoMessage() {}
+
+func (x *GetUsageSummaryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsageSummaryRequest.ProtoReflect.Descriptor instead.
+func (*GetUsageSummaryRequest) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GetUsageSummaryRequest) GetFromDate() string {
+	if x != nil {
+		return x.FromDate
+	}
+	return ""
+}
+
+func (x *GetUsageSummaryRequest) GetToDate() string {
+	if x != nil {
+		return x.ToDate
+	}
+	return ""
+}
+
+func (x *GetUsageSummaryRequest) GetGroupBy() []UsageDimension {
+	if x != nil {
+		return x.GroupBy
+	}
+	return nil
+}
+
+func (x *GetUsageSummaryRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+// Usage totals of one group; only the grouped dimensions are set
+type UsageSummary struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	CardNumber string                 `protobuf:"bytes,1,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`
+	Vehicle    string                 `protobuf:"bytes,2,opt,name=vehicle,proto3" json:"vehicle,omitempty"`
+	AccountId  string                 `protobuf:"bytes,3,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Route      string                 `protobuf:"bytes,4,opt,name=route,proto3" json:"route,omitempty"`
+	Month      string                 `protobuf:"bytes,5,opt,name=month,proto3" json:"month,omitempty"`
+	// Charged total, with reversals counted negative
+	Amount int64 `protobuf:"varint,6,opt,name=amount,proto3" json:"amount,omitempty"`
+	// Totals of regular rows before and of the ETC discount
+	NormalAmount   int64 `protobuf:"varint,7,opt,name=normal_amount,json=normalAmount,proto3" json:"normal_amount,omitempty"`
+	DiscountAmount int64 `protobuf:"varint,8,opt,name=discount_amount,json=discountAmount,proto3" json:"discount_amount,omitempty"`
+	TripCount      int32 `protobuf:"varint,9,opt,name=trip_count,json=tripCount,proto3" json:"trip_count,omitempty"`
+	RecordCount    int32 `protobuf:"varint,10,opt,name=record_count,json=recordCount,proto3" json:"record_count,omitempty"`
+	ReversalCount  int32 `protobuf:"varint,11,opt,name=reversal_count,json=reversalCount,proto3" json:"reversal_count,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UsageSummary) Reset() {
+	*x = UsageSummary{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UsageSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsageSummary) ProtoMessage() {}
+
+func (x *UsageSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsageSummary.ProtoReflect.Descriptor instead.
+func (*UsageSummary) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *UsageSummary) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+func (x *UsageSummary) GetVehicle() string {
+	if x != nil {
+		return x.Vehicle
+	}
+	return ""
+}
+
+func (x *UsageSummary) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *UsageSummary) GetRoute() string {
+	if x != nil {
+		return x.Route
+	}
+	return ""
+}
+
+func (x *UsageSummary) GetMonth() string {
+	if x != nil {
+		return x.Month
+	}
+	return ""
+}
+
+func (x *UsageSummary) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *UsageSummary) GetNormalAmount() int64 {
+	if x != nil {
+		return x.NormalAmount
+	}
+	return 0
+}
+
+func (x *UsageSummary) GetDiscountAmount() int64 {
+	if x != nil {
+		return x.DiscountAmount
+	}
+	return 0
+}
+
+func (x *UsageSummary) GetTripCount() int32 {
+	if x != nil {
+		return x.TripCount
+	}
+	return 0
+}
+
+func (x *UsageSummary) GetRecordCount() int32 {
+	if x != nil {
+		return x.RecordCount
+	}
+	return 0
+}
+
+func (x *UsageSummary) GetReversalCount() int32 {
+	if x != nil {
+		return x.ReversalCount
+	}
+	return 0
+}
+
+type GetUsageSummaryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Groups        []*UsageSummary        `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"`
+	Total         *UsageSummary          `protobuf:"bytes,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsageSummaryResponse) Reset() {
+	*x = GetUsageSummaryResponse{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsageSummaryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsageSummaryResponse) ProtoMessage() {}
+
+func (x *GetUsageSummaryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsageSummaryResponse.ProtoReflect.Descriptor instead.
+func (*GetUsageSummaryResponse) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *GetUsageSummaryResponse) GetGroups() []*UsageSummary {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+func (x *GetUsageSummaryResponse) GetTotal() *UsageSummary {
+	if x != nil {
+		return x.Total
+	}
+	return nil
+}
+
+type ExportJournalRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Exactly one of csv_data or csv_file_path is required
+	CsvData     *string       `protobuf:"bytes,1,opt,name=csv_data,json=csvData,proto3,oneof" json:"csv_data,omitempty"`
+	CsvFilePath *string       `protobuf:"bytes,2,opt,name=csv_file_path,json=csvFilePath,proto3,oneof" json:"csv_file_path,omitempty"`
+	Format      JournalFormat `protobuf:"varint,3,opt,name=format,proto3,enum=etcdataprocessor.v1.JournalFormat" json:"format,omitempty"`
+	// "Shift_JIS" (default) or "UTF-8"
+	Encoding      string `protobuf:"bytes,4,opt,name=encoding,proto3" json:"encoding,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportJournalRequest) Reset() {
+	*x = ExportJournalRequest{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportJournalRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportJournalRequest) ProtoMessage() {}
+
+func (x *ExportJournalRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportJournalRequest.ProtoReflect.Descriptor instead.
+func (*ExportJournalRequest) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ExportJournalRequest) GetCsvData() string {
+	if x != nil && x.CsvData != nil {
+		return *x.CsvData
+	}
+	return ""
+}
+
+func (x *ExportJournalRequest) GetCsvFilePath() string {
+	if x != nil && x.CsvFilePath != nil {
+		return *x.CsvFilePath
+	}
+	return ""
+}
+
+func (x *ExportJournalRequest) GetFormat() JournalFormat {
+	if x != nil {
+		return x.Format
+	}
+	return JournalFormat_JOURNAL_FORMAT_UNSPECIFIED
+}
+
+func (x *ExportJournalRequest) GetEncoding() string {
+	if x != nil {
+		return x.Encoding
+	}
+	return ""
+}
+
+type ExportJournalResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// CSV file in the requested format and encoding
+	Content     []byte `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	Filename    string `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`
+	ContentType string `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	EntryCount  int32  `protobuf:"varint,4,opt,name=entry_count,json=entryCount,proto3" json:"entry_count,omitempty"`
+	// Rows an import would reject or skip as duplicates, left out of the journal
+	RecordErrors []*RecordError `protobuf:"bytes,5,rep,name=record_errors,json=recordErrors,proto3" json:"record_errors,omitempty"`
+	// Consumption tax of the exported statement, computed once on its total
+	TaxAmount int64 `protobuf:"varint,6,opt,name=tax_amount,json=taxAmount,proto3" json:"tax_amount,omitempty"`
+	// Sum of the tax of each entry; differs from tax_amount by per-entry rounding
+	RecordTaxAmount int64 `protobuf:"varint,7,opt,name=record_tax_amount,json=recordTaxAmount,proto3" json:"record_tax_amount,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ExportJournalResponse) Reset() {
+	*x = ExportJournalResponse{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportJournalResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportJournalResponse) ProtoMessage() {}
+
+func (x *ExportJournalResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportJournalResponse.ProtoReflect.Descriptor instead.
+func (*ExportJournalResponse) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ExportJournalResponse) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *ExportJournalResponse) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *ExportJournalResponse) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *ExportJournalResponse) GetEntryCount() int32 {
+	if x != nil {
+		return x.EntryCount
+	}
+	return 0
+}
+
+func (x *ExportJournalResponse) GetRecordErrors() []*RecordError {
+	if x != nil {
+		return x.RecordErrors
+	}
+	return nil
+}
+
+func (x *ExportJournalResponse) GetTaxAmount() int64 {
+	if x != nil {
+		return x.TaxAmount
+	}
+	return 0
+}
+
+func (x *ExportJournalResponse) GetRecordTaxAmount() int64 {
+	if x != nil {
+		return x.RecordTaxAmount
+	}
+	return 0
+}
+
+// Invoice total of one card for one month
+type ExpectedTotal struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	CardNumber string                 `protobuf:"bytes,1,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`
+	// Statement month ("2025-09"); records are assigned to the month of their usage date
+	Month  string `protobuf:"bytes,2,opt,name=month,proto3" json:"month,omitempty"`
+	Amount int64  `protobuf:"varint,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	// Number of rows on the invoice; 0 means unknown
+	RecordCount   int32 `protobuf:"varint,4,opt,name=record_count,json=recordCount,proto3" json:"record_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExpectedTotal) Reset() {
+	*x = ExpectedTotal{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExpectedTotal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpectedTotal) ProtoMessage() {}
+
+func (x *ExpectedTotal) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpectedTotal.ProtoReflect.Descriptor instead.
+func (*ExpectedTotal) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ExpectedTotal) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+func (x *ExpectedTotal) GetMonth() string {
+	if x != nil {
+		return x.Month
+	}
+	return ""
+}
+
+func (x *ExpectedTotal) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *ExpectedTotal) GetRecordCount() int32 {
+	if x != nil {
+		return x.RecordCount
+	}
+	return 0
+}
+
+type ReconcileStatementRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Expected []*ExpectedTotal       `protobuf:"bytes,1,rep,name=expected,proto3" json:"expected,omitempty"`
+	// Only records imported for this account; empty means all accounts
+	AccountId string `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// Optional statement CSV to compare row by row with the imported records
+	CsvData       *string `protobuf:"bytes,3,opt,name=csv_data,json=csvData,proto3,oneof" json:"csv_data,omitempty"`
+	CsvFilePath   *string `protobuf:"bytes,4,opt,name=csv_file_path,json=csvFilePath,proto3,oneof" json:"csv_file_path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReconcileStatementRequest) Reset() {
+	*x = ReconcileStatementRequest{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReconcileStatementRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconcileStatementRequest) ProtoMessage() {}
+
+func (x *ReconcileStatementRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconcileStatementRequest.ProtoReflect.Descriptor instead.
+func (*ReconcileStatementRequest) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ReconcileStatementRequest) GetExpected() []*ExpectedTotal {
+	if x != nil {
+		return x.Expected
+	}
+	return nil
+}
+
+func (x *ReconcileStatementRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *ReconcileStatementRequest) GetCsvData() string {
+	if x != nil && x.CsvData != nil {
+		return *x.CsvData
+	}
+	return ""
+}
+
+func (x *ReconcileStatementRequest) GetCsvFilePath() string {
+	if x != nil && x.CsvFilePath != nil {
+		return *x.CsvFilePath
+	}
+	return ""
+}
+
+// A row that likely explains part of a difference; only amount is set when no row could be identified
+type ReconciliationIssue struct {
+	state   protoimpl.MessageState  `protogen:"open.v1"`
+	Kind    ReconciliationIssueKind `protobuf:"varint,1,opt,name=kind,proto3,enum=etcdataprocessor.v1.ReconciliationIssueKind" json:"kind,omitempty"`
+	Date    string                  `protobuf:"bytes,2,opt,name=date,proto3" json:"date,omitempty"`
+	EntryIc string                  `protobuf:"bytes,3,opt,name=entry_ic,json=entryIc,proto3" json:"entry_ic,omitempty"`
+	ExitIc  string                  `protobuf:"bytes,4,opt,name=exit_ic,json=exitIc,proto3" json:"exit_ic,omitempty"`
+	Amount  int64                   `protobuf:"varint,5,opt,name=amount,proto3" json:"amount,omitempty"`
+	// Line in the statement CSV; 0 for imported records
+	LineNumber    int32  `protobuf:"varint,6,opt,name=line_number,json=lineNumber,proto3" json:"line_number,omitempty"`
+	Note          string `protobuf:"bytes,7,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReconciliationIssue) Reset() {
+	*x = ReconciliationIssue{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReconciliationIssue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconciliationIssue) ProtoMessage() {}
+
+func (x *ReconciliationIssue) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconciliationIssue.ProtoReflect.Descriptor instead.
+func (*ReconciliationIssue) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ReconciliationIssue) GetKind() ReconciliationIssueKind {
+	if x != nil {
+		return x.Kind
+	}
+	return ReconciliationIssueKind_RECONCILIATION_ISSUE_KIND_UNSPECIFIED
+}
+
+func (x *ReconciliationIssue) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *ReconciliationIssue) GetEntryIc() string {
+	if x != nil {
+		return x.EntryIc
+	}
+	return ""
+}
+
+func (x *ReconciliationIssue) GetExitIc() string {
+	if x != nil {
+		return x.ExitIc
+	}
+	return ""
+}
+
+func (x *ReconciliationIssue) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *ReconciliationIssue) GetLineNumber() int32 {
+	if x != nil {
+		return x.LineNumber
+	}
+	return 0
+}
+
+func (x *ReconciliationIssue) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+type CardReconciliation struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	CardNumber     string                 `protobuf:"bytes,1,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`
+	Month          string                 `protobuf:"bytes,2,opt,name=month,proto3" json:"month,omitempty"`
+	ExpectedAmount int64                  `protobuf:"varint,3,opt,name=expected_amount,json=expectedAmount,proto3" json:"expected_amount,omitempty"`
+	ImportedAmount int64                  `protobuf:"varint,4,opt,name=imported_amount,json=importedAmount,proto3" json:"imported_amount,omitempty"`
+	// imported_amount - expected_amount
+	Difference          int64                  `protobuf:"varint,5,opt,name=difference,proto3" json:"difference,omitempty"`
+	ExpectedRecordCount int32                  `protobuf:"varint,6,opt,name=expected_record_count,json=expectedRecordCount,proto3" json:"expected_record_count,omitempty"`
+	ImportedRecordCount int32                  `protobuf:"varint,7,opt,name=imported_record_count,json=importedRecordCount,proto3" json:"imported_record_count,omitempty"`
+	Matched             bool                   `protobuf:"varint,8,opt,name=matched,proto3" json:"matched,omitempty"`
+	Issues              []*ReconciliationIssue `protobuf:"bytes,9,rep,name=issues,proto3" json:"issues,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *CardReconciliation) Reset() {
+	*x = CardReconciliation{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CardReconciliation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CardReconciliation) ProtoMessage() {}
+
+func (x *CardReconciliation) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CardReconciliation.ProtoReflect.Descriptor instead.
+func (*CardReconciliation) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *CardReconciliation) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+func (x *CardReconciliation) GetMonth() string {
+	if x != nil {
+		return x.Month
+	}
+	return ""
+}
+
+func (x *CardReconciliation) GetExpectedAmount() int64 {
+	if x != nil {
+		return x.ExpectedAmount
+	}
+	return 0
+}
+
+func (x *CardReconciliation) GetImportedAmount() int64 {
+	if x != nil {
+		return x.ImportedAmount
+	}
+	return 0
+}
+
+func (x *CardReconciliation) GetDifference() int64 {
+	if x != nil {
+		return x.Difference
+	}
+	return 0
+}
+
+func (x *CardReconciliation) GetExpectedRecordCount() int32 {
+	if x != nil {
+		return x.ExpectedRecordCount
+	}
+	return 0
+}
+
+func (x *CardReconciliation) GetImportedRecordCount() int32 {
+	if x != nil {
+		return x.ImportedRecordCount
+	}
+	return 0
+}
+
+func (x *CardReconciliation) GetMatched() bool {
+	if x != nil {
+		return x.Matched
+	}
+	return false
+}
+
+func (x *CardReconciliation) GetIssues() []*ReconciliationIssue {
+	if x != nil {
+		return x.Issues
+	}
+	return nil
+}
+
+type ReconcileStatementResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// True when every card and month matches its invoice total
+	Matched       bool                  `protobuf:"varint,1,opt,name=matched,proto3" json:"matched,omitempty"`
+	Results       []*CardReconciliation `protobuf:"bytes,2,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReconcileStatementResponse) Reset() {
+	*x = ReconcileStatementResponse{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReconcileStatementResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconcileStatementResponse) ProtoMessage() {}
+
+func (x *ReconcileStatementResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconcileStatementResponse.ProtoReflect.Descriptor instead.
+func (*ReconcileStatementResponse) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ReconcileStatementResponse) GetMatched() bool {
+	if x != nil {
+		return x.Matched
+	}
+	return false
+}
+
+func (x *ReconcileStatementResponse) GetResults() []*CardReconciliation {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type HealthCheckRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthCheckRequest) Reset() {
+	*x = HealthCheckRequest{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthCheckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthCheckRequest) ProtoMessage() {}
+
+func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
+func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{31}
+}
+
+type HealthCheckResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Details       map[string]string      `protobuf:"bytes,4,rep,name=details,proto3" json:"details,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthCheckResponse) Reset() {
+	*x = HealthCheckResponse{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthCheckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthCheckResponse) ProtoMessage() {}
+
+func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
+func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *HealthCheckResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *HealthCheckResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *HealthCheckResponse) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *HealthCheckResponse) GetDetails() map[string]string {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+type ProcessingStats struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TotalRecords   int32                  `protobuf:"varint,1,opt,name=total_records,json=totalRecords,proto3" json:"total_records,omitempty"`
+	SavedRecords   int32                  `protobuf:"varint,2,opt,name=saved_records,json=savedRecords,proto3" json:"saved_records,omitempty"`
+	SkippedRecords int32                  `protobuf:"varint,3,opt,name=skipped_records,json=skippedRecords,proto3" json:"skipped_records,omitempty"`
+	ErrorRecords   int32                  `protobuf:"varint,4,opt,name=error_records,json=errorRecords,proto3" json:"error_records,omitempty"`
+	// Saved refund and correction rows
+	ReversalRecords int32 `protobuf:"varint,5,opt,name=reversal_records,json=reversalRecords,proto3" json:"reversal_records,omitempty"`
+	// Sum of saved amounts, with reversals counted negative
+	NetAmount int64 `protobuf:"varint,6,opt,name=net_amount,json=netAmount,proto3" json:"net_amount,omitempty"`
+	// Saved records whose card is not in the master data
+	UnknownCardRecords int32 `protobuf:"varint,7,opt,name=unknown_card_records,json=unknownCardRecords,proto3" json:"unknown_card_records,omitempty"`
+	// Saved records whose discount differs from the expected discount
+	DiscountMismatchRecords int32 `protobuf:"varint,8,opt,name=discount_mismatch_records,json=discountMismatchRecords,proto3" json:"discount_mismatch_records,omitempty"`
+	// Sum of discounts that were expected but not applied (claimable from the issuer)
+	ClaimableDiscount int64 `protobuf:"varint,9,opt,name=claimable_discount,json=claimableDiscount,proto3" json:"claimable_discount,omitempty"`
+	// Tax-exclusive part of net_amount and its consumption tax, computed once per statement (file)
+	TaxExclusiveAmount int64 `protobuf:"varint,10,opt,name=tax_exclusive_amount,json=taxExclusiveAmount,proto3" json:"tax_exclusive_amount,omitempty"`
+	TaxAmount          int64 `protobuf:"varint,11,opt,name=tax_amount,json=taxAmount,proto3" json:"tax_amount,omitempty"`
+	// Sum of the tax of each saved record; differs from tax_amount by per-record rounding
+	RecordTaxAmount int64 `protobuf:"varint,12,opt,name=record_tax_amount,json=recordTaxAmount,proto3" json:"record_tax_amount,omitempty"`
+	// Saved records with at least one anomaly finding
+	AnomalyRecords int32 `protobuf:"varint,13,opt,name=anomaly_records,json=anomalyRecords,proto3" json:"anomaly_records,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ProcessingStats) Reset() {
+	*x = ProcessingStats{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessingStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessingStats) ProtoMessage() {}
+
+func (x *ProcessingStats) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessingStats.ProtoReflect.Descriptor instead.
+func (*ProcessingStats) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ProcessingStats) GetTotalRecords() int32 {
+	if x != nil {
+		return x.TotalRecords
+	}
+	return 0
+}
+
+func (x *ProcessingStats) GetSavedRecords() int32 {
+	if x != nil {
+		return x.SavedRecords
+	}
+	return 0
+}
+
+func (x *ProcessingStats) GetSkippedRecords() int32 {
+	if x != nil {
+		return x.SkippedRecords
+	}
+	return 0
+}
+
+func (x *ProcessingStats) GetErrorRecords() int32 {
+	if x != nil {
+		return x.ErrorRecords
+	}
+	return 0
+}
+
+func (x *ProcessingStats) GetReversalRecords() int32 {
+	if x != nil {
+		return x.ReversalRecords
+	}
+	return 0
+}
+
+func (x *ProcessingStats) GetNetAmount() int64 {
+	if x != nil {
+		return x.NetAmount
+	}
+	return 0
+}
+
+func (x *ProcessingStats) GetUnknownCardRecords() int32 {
+	if x != nil {
+		return x.UnknownCardRecords
+	}
+	return 0
+}
+
+func (x *ProcessingStats) GetDiscountMismatchRecords() int32 {
+	if x != nil {
+		return x.DiscountMismatchRecords
+	}
+	return 0
+}
+
+func (x *ProcessingStats) GetClaimableDiscount() int64 {
+	if x != nil {
+		return x.ClaimableDiscount
+	}
+	return 0
+}
+
+func (x *ProcessingStats) GetTaxExclusiveAmount() int64 {
+	if x != nil {
+		return x.TaxExclusiveAmount
+	}
+	return 0
+}
+
+func (x *ProcessingStats) GetTaxAmount() int64 {
+	if x != nil {
+		return x.TaxAmount
+	}
+	return 0
+}
+
+func (x *ProcessingStats) GetRecordTaxAmount() int64 {
+	if x != nil {
+		return x.RecordTaxAmount
+	}
+	return 0
+}
+
+func (x *ProcessingStats) GetAnomalyRecords() int32 {
+	if x != nil {
+		return x.AnomalyRecords
+	}
+	return 0
+}
+
+type FileResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FilePath      string                 `protobuf:"bytes,1,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"`
+	Format        string                 `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"`
+	Encoding      string                 `protobuf:"bytes,3,opt,name=encoding,proto3" json:"encoding,omitempty"`
+	Stats         *ProcessingStats       `protobuf:"bytes,4,opt,name=stats,proto3" json:"stats,omitempty"`
+	Errors        []string               `protobuf:"bytes,5,rep,name=errors,proto3" json:"errors,omitempty"`
+	DurationMs    int64                  `protobuf:"varint,6,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	RecordErrors  []*RecordError         `protobuf:"bytes,7,rep,name=record_errors,json=recordErrors,proto3" json:"record_errors,omitempty"`
+	DryRunRecords []*DryRunRecord        `protobuf:"bytes,8,rep,name=dry_run_records,json=dryRunRecords,proto3" json:"dry_run_records,omitempty"`
+	Trips         []*Trip                `protobuf:"bytes,9,rep,name=trips,proto3" json:"trips,omitempty"`
+	Anomalies     []*AnomalyFinding      `protobuf:"bytes,10,rep,name=anomalies,proto3" json:"anomalies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileResult) Reset() {
+	*x = FileResult{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileResult) ProtoMessage() {}
+
+func (x *FileResult) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileResult.ProtoReflect.Descriptor instead.
+func (*FileResult) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *FileResult) GetFilePath() string {
+	if x != nil {
+		return x.FilePath
+	}
+	return ""
+}
+
+func (x *FileResult) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *FileResult) GetEncoding() string {
+	if x != nil {
+		return x.Encoding
+	}
+	return ""
+}
+
+func (x *FileResult) GetStats() *ProcessingStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *FileResult) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *FileResult) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *FileResult) GetRecordErrors() []*RecordError {
+	if x != nil {
+		return x.RecordErrors
+	}
+	return nil
+}
+
+func (x *FileResult) GetDryRunRecords() []*DryRunRecord {
+	if x != nil {
+		return x.DryRunRecords
+	}
+	return nil
+}
+
+func (x *FileResult) GetTrips() []*Trip {
+	if x != nil {
+		return x.Trips
+	}
+	return nil
+}
+
+func (x *FileResult) GetAnomalies() []*AnomalyFinding {
+	if x != nil {
+		return x.Anomalies
+	}
+	return nil
+}
+
+type RecordError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          ErrorCode              `protobuf:"varint,1,opt,name=code,proto3,enum=etcdataprocessor.v1.ErrorCode" json:"code,omitempty"`
+	RecordIndex   int32                  `protobuf:"varint,2,opt,name=record_index,json=recordIndex,proto3" json:"record_index,omitempty"`
+	LineNumber    int32                  `protobuf:"varint,3,opt,name=line_number,json=lineNumber,proto3" json:"line_number,omitempty"`
+	FilePath      string                 `protobuf:"bytes,4,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"`
+	Field         string                 `protobuf:"bytes,5,opt,name=field,proto3" json:"field,omitempty"`
+	Message       string                 `protobuf:"bytes,6,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecordError) Reset() {
+	*x = RecordError{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecordError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordError) ProtoMessage() {}
+
+func (x *RecordError) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordError.ProtoReflect.Descriptor instead.
+func (*RecordError) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *RecordError) GetCode() ErrorCode {
+	if x != nil {
+		return x.Code
+	}
+	return ErrorCode_ERROR_CODE_UNSPECIFIED
+}
+
+func (x *RecordError) GetRecordIndex() int32 {
+	if x != nil {
+		return x.RecordIndex
+	}
+	return 0
+}
+
+func (x *RecordError) GetLineNumber() int32 {
+	if x != nil {
+		return x.LineNumber
+	}
+	return 0
+}
+
+func (x *RecordError) GetFilePath() string {
+	if x != nil {
+		return x.FilePath
+	}
+	return ""
+}
+
+func (x *RecordError) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *RecordError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type DryRunRecord struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RecordIndex   int32                  `protobuf:"varint,1,opt,name=record_index,json=recordIndex,proto3" json:"record_index,omitempty"`
+	LineNumber    int32                  `protobuf:"varint,2,opt,name=line_number,json=lineNumber,proto3" json:"line_number,omitempty"`
+	FilePath      string                 `protobuf:"bytes,3,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"`
+	Action        DryRunAction           `protobuf:"varint,4,opt,name=action,proto3,enum=etcdataprocessor.v1.DryRunAction" json:"action,omitempty"`
+	Reason        ErrorCode              `protobuf:"varint,5,opt,name=reason,proto3,enum=etcdataprocessor.v1.ErrorCode" json:"reason,omitempty"`
+	Payload       *structpb.Struct       `protobuf:"bytes,6,opt,name=payload,proto3" json:"payload,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DryRunRecord) Reset() {
+	*x = DryRunRecord{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DryRunRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DryRunRecord) ProtoMessage() {}
+
+func (x *DryRunRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DryRunRecord.ProtoReflect.Descriptor instead.
+func (*DryRunRecord) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *DryRunRecord) GetRecordIndex() int32 {
+	if x != nil {
+		return x.RecordIndex
+	}
+	return 0
+}
+
+func (x *DryRunRecord) GetLineNumber() int32 {
+	if x != nil {
+		return x.LineNumber
+	}
+	return 0
+}
+
+func (x *DryRunRecord) GetFilePath() string {
+	if x != nil {
+		return x.FilePath
+	}
+	return ""
+}
+
+func (x *DryRunRecord) GetAction() DryRunAction {
+	if x != nil {
+		return x.Action
+	}
+	return DryRunAction_DRY_RUN_ACTION_UNSPECIFIED
+}
+
+func (x *DryRunRecord) GetReason() ErrorCode {
+	if x != nil {
+		return x.Reason
+	}
+	return ErrorCode_ERROR_CODE_UNSPECIFIED
+}
+
+func (x *DryRunRecord) GetPayload() *structpb.Struct {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+// Consecutive rows for the same card grouped into one journey (stitch_trips)
+type Trip struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CardNumber string                 `protobuf:"bytes,2,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`
+	EntryIc    string                 `protobuf:"bytes,3,opt,name=entry_ic,json=entryIc,proto3" json:"entry_ic,omitempty"`
+	ExitIc     string                 `protobuf:"bytes,4,opt,name=exit_ic,json=exitIc,proto3" json:"exit_ic,omitempty"`
+	// Entry time of the first row and exit time of the last row (YYYY-MM-DDTHH:MM:SS)
+	StartTime string `protobuf:"bytes,5,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime   string `protobuf:"bytes,6,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	// 1-based record indexes and CSV line numbers of the rows, in travel order
+	RecordIndexes []int32 `protobuf:"varint,7,rep,packed,name=record_indexes,json=recordIndexes,proto3" json:"record_indexes,omitempty"`
+	LineNumbers   []int32 `protobuf:"varint,8,rep,packed,name=line_numbers,json=lineNumbers,proto3" json:"line_numbers,omitempty"`
+	// Sum of charged amounts, with reversals counted negative
+	TotalAmount int32  `protobuf:"varint,9,opt,name=total_amount,json=totalAmount,proto3" json:"total_amount,omitempty"`
+	Mileage     int32  `protobuf:"varint,10,opt,name=mileage,proto3" json:"mileage,omitempty"`
+	FilePath    string `protobuf:"bytes,11,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"`
+	// Day type of the start date: "weekday", "weekend" or "holiday"
+	DayType       string `protobuf:"bytes,12,opt,name=day_type,json=dayType,proto3" json:"day_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Trip) Reset() {
+	*x = Trip{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Trip) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Trip) ProtoMessage() {}
+
+func (x *Trip) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Trip.ProtoReflect.Descriptor instead.
+func (*Trip) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *Trip) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Trip) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+func (x *Trip) GetEntryIc() string {
+	if x != nil {
+		return x.EntryIc
+	}
+	return ""
+}
+
+func (x *Trip) GetExitIc() string {
+	if x != nil {
+		return x.ExitIc
+	}
+	return ""
+}
+
+func (x *Trip) GetStartTime() string {
+	if x != nil {
+		return x.StartTime
+	}
+	return ""
+}
+
+func (x *Trip) GetEndTime() string {
+	if x != nil {
+		return x.EndTime
+	}
+	return ""
+}
+
+func (x *Trip) GetRecordIndexes() []int32 {
+	if x != nil {
+		return x.RecordIndexes
+	}
+	return nil
+}
+
+func (x *Trip) GetLineNumbers() []int32 {
+	if x != nil {
+		return x.LineNumbers
+	}
+	return nil
+}
+
+func (x *Trip) GetTotalAmount() int32 {
+	if x != nil {
+		return x.TotalAmount
+	}
+	return 0
+}
+
+func (x *Trip) GetMileage() int32 {
+	if x != nil {
+		return x.Mileage
+	}
+	return 0
+}
+
+func (x *Trip) GetFilePath() string {
+	if x != nil {
+		return x.FilePath
+	}
+	return ""
+}
+
+func (x *Trip) GetDayType() string {
+	if x != nil {
+		return x.DayType
+	}
+	return ""
+}
+
+// A suspicious trip reported by an anomaly rule
+type AnomalyFinding struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Rule name: "impossible_time", "card_overlap", "vehicle_class_mismatch" or "amount_outlier"
+	Rule string `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
+	// 1-based record index and CSV line of the flagged row
+	RecordIndex int32  `protobuf:"varint,2,opt,name=record_index,json=recordIndex,proto3" json:"record_index,omitempty"`
+	LineNumber  int32  `protobuf:"varint,3,opt,name=line_number,json=lineNumber,proto3" json:"line_number,omitempty"`
+	FilePath    string `protobuf:"bytes,4,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"`
+	AccountId   string `protobuf:"bytes,5,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	CardNumber  string `protobuf:"bytes,6,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`
+	Date        string `protobuf:"bytes,7,opt,name=date,proto3" json:"date,omitempty"`
+	EntryIc     string `protobuf:"bytes,8,opt,name=entry_ic,json=entryIc,proto3" json:"entry_ic,omitempty"`
+	ExitIc      string `protobuf:"bytes,9,opt,name=exit_ic,json=exitIc,proto3" json:"exit_ic,omitempty"`
+	Amount      int32  `protobuf:"varint,10,opt,name=amount,proto3" json:"amount,omitempty"`
+	Message     string `protobuf:"bytes,11,opt,name=message,proto3" json:"message,omitempty"`
+	// CSV line of the other row involved (card_overlap); 0 if none
+	RelatedLineNumber int32 `protobuf:"varint,12,opt,name=related_line_number,json=relatedLineNumber,proto3" json:"related_line_number,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *AnomalyFinding) Reset() {
+	*x = AnomalyFinding{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnomalyFinding) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnomalyFinding) ProtoMessage() {}
+
+func (x *AnomalyFinding) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnomalyFinding.ProtoReflect.Descriptor instead.
+func (*AnomalyFinding) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *AnomalyFinding) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *AnomalyFinding) GetRecordIndex() int32 {
+	if x != nil {
+		return x.RecordIndex
+	}
+	return 0
+}
+
+func (x *AnomalyFinding) GetLineNumber() int32 {
+	if x != nil {
+		return x.LineNumber
+	}
+	return 0
+}
+
+func (x *AnomalyFinding) GetFilePath() string {
+	if x != nil {
+		return x.FilePath
+	}
+	return ""
+}
+
+func (x *AnomalyFinding) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *AnomalyFinding) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+func (x *AnomalyFinding) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *AnomalyFinding) GetEntryIc() string {
+	if x != nil {
+		return x.EntryIc
+	}
+	return ""
+}
+
+func (x *AnomalyFinding) GetExitIc() string {
+	if x != nil {
+		return x.ExitIc
+	}
+	return ""
 }
 
-func (x *ProcessCSVDataRequest) GetCsvData() string {
+func (x *AnomalyFinding) GetAmount() int32 {
 	if x != nil {
-		return x.CsvData
+		return x.Amount
 	}
-	return ""
+	return 0
 }
 
-func (x *ProcessCSVDataRequest) GetAccountId() string {
-	if x != nil && x.AccountId != nil {
-		return *x.AccountId
+func (x *AnomalyFinding) GetMessage() string {
+	if x != nil {
+		return x.Message
 	}
 	return ""
 }
 
-func (x *ProcessCSVDataRequest) GetSkipDuplicates() bool {
-	if x != nil && x.SkipDuplicates != nil {
-		return *x.SkipDuplicates
+func (x *AnomalyFinding) GetRelatedLineNumber() int32 {
+	if x != nil {
+		return x.RelatedLineNumber
 	}
-	return false
+	return 0
 }
 
-type ProcessCSVDataResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Stats         *ProcessingStats       `protobuf:"bytes,3,opt,name=stats,proto3" json:"stats,omitempty"`
-	Errors        []string               `protobuf:"bytes,4,rep,name=errors,proto3" json:"errors,omitempty"`
+type ListAnomaliesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Trip date range (YYYY-MM-DD, inclusive); empty means unbounded
+	FromDate      string `protobuf:"bytes,1,opt,name=from_date,json=fromDate,proto3" json:"from_date,omitempty"`
+	ToDate        string `protobuf:"bytes,2,opt,name=to_date,json=toDate,proto3" json:"to_date,omitempty"`
+	AccountId     string `protobuf:"bytes,3,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	CardNumber    string `protobuf:"bytes,4,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`
+	Rule          string `protobuf:"bytes,5,opt,name=rule,proto3" json:"rule,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ProcessCSVDataResponse) Reset() {
-	*x = ProcessCSVDataResponse{}
-	mi := &file_src_proto_data_processor_proto_msgTypes[3]
+func (x *ListAnomaliesRequest) Reset() {
+	*x = ListAnomaliesRequest{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ProcessCSVDataResponse) String() string {
+func (x *ListAnomaliesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ProcessCSVDataResponse) ProtoMessage() {}
+func (*ListAnomaliesRequest) ProtoMessage() {}
 
-func (x *ProcessCSVDataResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_src_proto_data_processor_proto_msgTypes[3]
+func (x *ListAnomaliesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -245,62 +3826,68 @@ func (x *ProcessCSVDataResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ProcessCSVDataResponse.ProtoReflect.Descriptor instead.
-func (*ProcessCSVDataResponse) Descriptor() ([]byte, []int) {
-	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use ListAnomaliesRequest.ProtoReflect.Descriptor instead.
+func (*ListAnomaliesRequest) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{39}
 }
 
-func (x *ProcessCSVDataResponse) GetSuccess() bool {
+func (x *ListAnomaliesRequest) GetFromDate() string {
 	if x != nil {
-		return x.Success
+		return x.FromDate
 	}
-	return false
+	return ""
 }
 
-func (x *ProcessCSVDataResponse) GetMessage() string {
+func (x *ListAnomaliesRequest) GetToDate() string {
 	if x != nil {
-		return x.Message
+		return x.ToDate
 	}
 	return ""
 }
 
-func (x *ProcessCSVDataResponse) GetStats() *ProcessingStats {
+func (x *ListAnomaliesRequest) GetAccountId() string {
 	if x != nil {
-		return x.Stats
+		return x.AccountId
 	}
-	return nil
+	return ""
 }
 
-func (x *ProcessCSVDataResponse) GetErrors() []string {
+func (x *ListAnomaliesRequest) GetCardNumber() string {
 	if x != nil {
-		return x.Errors
+		return x.CardNumber
 	}
-	return nil
+	return ""
 }
 
-type ValidateCSVDataRequest struct {
+func (x *ListAnomaliesRequest) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+type ListAnomaliesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	CsvData       string                 `protobuf:"bytes,1,opt,name=csv_data,json=csvData,proto3" json:"csv_data,omitempty"`
-	AccountId     *string                `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3,oneof" json:"account_id,omitempty"`
+	Findings      []*AnomalyFinding      `protobuf:"bytes,1,rep,name=findings,proto3" json:"findings,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ValidateCSVDataRequest) Reset() {
-	*x = ValidateCSVDataRequest{}
-	mi := &file_src_proto_data_processor_proto_msgTypes[4]
+func (x *ListAnomaliesResponse) Reset() {
+	*x = ListAnomaliesResponse{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ValidateCSVDataRequest) String() string {
+func (x *ListAnomaliesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ValidateCSVDataRequest) ProtoMessage() {}
+func (*ListAnomaliesResponse) ProtoMessage() {}
 
-func (x *ValidateCSVDataRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_src_proto_data_processor_proto_msgTypes[4]
+func (x *ListAnomaliesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -311,50 +3898,50 @@ func (x *ValidateCSVDataRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ValidateCSVDataRequest.ProtoReflect.Descriptor instead.
-func (*ValidateCSVDataRequest) Descriptor() ([]byte, []int) {
-	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use ListAnomaliesResponse.ProtoReflect.Descriptor instead.
+func (*ListAnomaliesResponse) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{40}
 }
 
-func (x *ValidateCSVDataRequest) GetCsvData() string {
+func (x *ListAnomaliesResponse) GetFindings() []*AnomalyFinding {
 	if x != nil {
-		return x.CsvData
+		return x.Findings
 	}
-	return ""
-}
-
-func (x *ValidateCSVDataRequest) GetAccountId() string {
-	if x != nil && x.AccountId != nil {
-		return *x.AccountId
-	}
-	return ""
+	return nil
 }
 
-type ValidateCSVDataResponse struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	IsValid        bool                   `protobuf:"varint,1,opt,name=is_valid,json=isValid,proto3" json:"is_valid,omitempty"`
-	Errors         []*ValidationError     `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
-	DuplicateCount int32                  `protobuf:"varint,3,opt,name=duplicate_count,json=duplicateCount,proto3" json:"duplicate_count,omitempty"`
-	TotalRecords   int32                  `protobuf:"varint,4,opt,name=total_records,json=totalRecords,proto3" json:"total_records,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+type ExportRecordsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Format ExportFormat           `protobuf:"varint,1,opt,name=format,proto3,enum=etcdataprocessor.v1.ExportFormat" json:"format,omitempty"`
+	// Selection from the record store; every set field must match
+	ImportJobId string `protobuf:"bytes,2,opt,name=import_job_id,json=importJobId,proto3" json:"import_job_id,omitempty"`
+	SourceFile  string `protobuf:"bytes,3,opt,name=source_file,json=sourceFile,proto3" json:"source_file,omitempty"`
+	// Usage date range (YYYY-MM-DD, inclusive); empty means unbounded
+	FromDate  string `protobuf:"bytes,4,opt,name=from_date,json=fromDate,proto3" json:"from_date,omitempty"`
+	ToDate    string `protobuf:"bytes,5,opt,name=to_date,json=toDate,proto3" json:"to_date,omitempty"`
+	AccountId string `protobuf:"bytes,6,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// Export a CSV directly instead of the record store (at most one; nothing is saved)
+	CsvData       *string `protobuf:"bytes,7,opt,name=csv_data,json=csvData,proto3,oneof" json:"csv_data,omitempty"`
+	CsvFilePath   *string `protobuf:"bytes,8,opt,name=csv_file_path,json=csvFilePath,proto3,oneof" json:"csv_file_path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ValidateCSVDataResponse) Reset() {
-	*x = ValidateCSVDataResponse{}
-	mi := &file_src_proto_data_processor_proto_msgTypes[5]
+func (x *ExportRecordsRequest) Reset() {
+	*x = ExportRecordsRequest{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ValidateCSVDataResponse) String() string {
+func (x *ExportRecordsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ValidateCSVDataResponse) ProtoMessage() {}
+func (*ExportRecordsRequest) ProtoMessage() {}
 
-func (x *ValidateCSVDataResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_src_proto_data_processor_proto_msgTypes[5]
+func (x *ExportRecordsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -365,100 +3952,96 @@ func (x *ValidateCSVDataResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ValidateCSVDataResponse.ProtoReflect.Descriptor instead.
-func (*ValidateCSVDataResponse) Descriptor() ([]byte, []int) {
-	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use ExportRecordsRequest.ProtoReflect.Descriptor instead.
+func (*ExportRecordsRequest) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{41}
 }
 
-func (x *ValidateCSVDataResponse) GetIsValid() bool {
+func (x *ExportRecordsRequest) GetFormat() ExportFormat {
 	if x != nil {
-		return x.IsValid
+		return x.Format
 	}
-	return false
+	return ExportFormat_EXPORT_FORMAT_UNSPECIFIED
 }
 
-func (x *ValidateCSVDataResponse) GetErrors() []*ValidationError {
+func (x *ExportRecordsRequest) GetImportJobId() string {
 	if x != nil {
-		return x.Errors
+		return x.ImportJobId
 	}
-	return nil
+	return ""
 }
 
-func (x *ValidateCSVDataResponse) GetDuplicateCount() int32 {
+func (x *ExportRecordsRequest) GetSourceFile() string {
 	if x != nil {
-		return x.DuplicateCount
+		return x.SourceFile
 	}
-	return 0
+	return ""
 }
 
-func (x *ValidateCSVDataResponse) GetTotalRecords() int32 {
+func (x *ExportRecordsRequest) GetFromDate() string {
 	if x != nil {
-		return x.TotalRecords
+		return x.FromDate
 	}
-	return 0
-}
-
-type HealthCheckRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	return ""
 }
 
-func (x *HealthCheckRequest) Reset() {
-	*x = HealthCheckRequest{}
-	mi := &file_src_proto_data_processor_proto_msgTypes[6]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *ExportRecordsRequest) GetToDate() string {
+	if x != nil {
+		return x.ToDate
+	}
+	return ""
 }
 
-func (x *HealthCheckRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *ExportRecordsRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
 }
 
-func (*HealthCheckRequest) ProtoMessage() {}
-
-func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_src_proto_data_processor_proto_msgTypes[6]
-	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+func (x *ExportRecordsRequest) GetCsvData() string {
+	if x != nil && x.CsvData != nil {
+		return *x.CsvData
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
-func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
-	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{6}
+func (x *ExportRecordsRequest) GetCsvFilePath() string {
+	if x != nil && x.CsvFilePath != nil {
+		return *x.CsvFilePath
+	}
+	return ""
 }
 
-type HealthCheckResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
-	Timestamp     int64                  `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	Details       map[string]string      `protobuf:"bytes,4,rep,name=details,proto3" json:"details,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+type ExportRecordsResponse struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Content     []byte                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	Filename    string                 `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`
+	ContentType string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	RecordCount int32                  `protobuf:"varint,4,opt,name=record_count,json=recordCount,proto3" json:"record_count,omitempty"`
+	// Version of the column layout ("etc_record.v1")
+	SchemaVersion string `protobuf:"bytes,5,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	// Rows of a direct CSV export that could not be converted and were left out
+	RecordErrors  []*RecordError `protobuf:"bytes,6,rep,name=record_errors,json=recordErrors,proto3" json:"record_errors,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthCheckResponse) Reset() {
-	*x = HealthCheckResponse{}
-	mi := &file_src_proto_data_processor_proto_msgTypes[7]
+func (x *ExportRecordsResponse) Reset() {
+	*x = ExportRecordsResponse{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthCheckResponse) String() string {
+func (x *ExportRecordsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthCheckResponse) ProtoMessage() {}
+func (*ExportRecordsResponse) ProtoMessage() {}
 
-func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_src_proto_data_processor_proto_msgTypes[7]
+func (x *ExportRecordsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -469,64 +4052,78 @@ func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
-func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
-	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use ExportRecordsResponse.ProtoReflect.Descriptor instead.
+func (*ExportRecordsResponse) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{42}
 }
 
-func (x *HealthCheckResponse) GetStatus() string {
+func (x *ExportRecordsResponse) GetContent() []byte {
 	if x != nil {
-		return x.Status
+		return x.Content
+	}
+	return nil
+}
+
+func (x *ExportRecordsResponse) GetFilename() string {
+	if x != nil {
+		return x.Filename
 	}
 	return ""
 }
 
-func (x *HealthCheckResponse) GetVersion() string {
+func (x *ExportRecordsResponse) GetContentType() string {
 	if x != nil {
-		return x.Version
+		return x.ContentType
 	}
 	return ""
 }
 
-func (x *HealthCheckResponse) GetTimestamp() int64 {
+func (x *ExportRecordsResponse) GetRecordCount() int32 {
 	if x != nil {
-		return x.Timestamp
+		return x.RecordCount
 	}
 	return 0
 }
 
-func (x *HealthCheckResponse) GetDetails() map[string]string {
+func (x *ExportRecordsResponse) GetSchemaVersion() string {
 	if x != nil {
-		return x.Details
+		return x.SchemaVersion
+	}
+	return ""
+}
+
+func (x *ExportRecordsResponse) GetRecordErrors() []*RecordError {
+	if x != nil {
+		return x.RecordErrors
 	}
 	return nil
 }
 
-type ProcessingStats struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	TotalRecords   int32                  `protobuf:"varint,1,opt,name=total_records,json=totalRecords,proto3" json:"total_records,omitempty"`
-	SavedRecords   int32                  `protobuf:"varint,2,opt,name=saved_records,json=savedRecords,proto3" json:"saved_records,omitempty"`
-	SkippedRecords int32                  `protobuf:"varint,3,opt,name=skipped_records,json=skippedRecords,proto3" json:"skipped_records,omitempty"`
-	ErrorRecords   int32                  `protobuf:"varint,4,opt,name=error_records,json=errorRecords,proto3" json:"error_records,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+// An IC name that is not in the interchange dictionary, grouped across spelling variants
+type UnmatchedIC struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	RawNames      []string               `protobuf:"bytes,3,rep,name=raw_names,json=rawNames,proto3" json:"raw_names,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ProcessingStats) Reset() {
-	*x = ProcessingStats{}
-	mi := &file_src_proto_data_processor_proto_msgTypes[8]
+func (x *UnmatchedIC) Reset() {
+	*x = UnmatchedIC{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ProcessingStats) String() string {
+func (x *UnmatchedIC) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ProcessingStats) ProtoMessage() {}
+func (*UnmatchedIC) ProtoMessage() {}
 
-func (x *ProcessingStats) ProtoReflect() protoreflect.Message {
-	mi := &file_src_proto_data_processor_proto_msgTypes[8]
+func (x *UnmatchedIC) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -537,37 +4134,30 @@ func (x *ProcessingStats) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ProcessingStats.ProtoReflect.Descriptor instead.
-func (*ProcessingStats) Descriptor() ([]byte, []int) {
-	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{8}
-}
-
-func (x *ProcessingStats) GetTotalRecords() int32 {
-	if x != nil {
-		return x.TotalRecords
-	}
-	return 0
+// Deprecated: Use UnmatchedIC.ProtoReflect.Descriptor instead.
+func (*UnmatchedIC) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{43}
 }
 
-func (x *ProcessingStats) GetSavedRecords() int32 {
+func (x *UnmatchedIC) GetName() string {
 	if x != nil {
-		return x.SavedRecords
+		return x.Name
 	}
-	return 0
+	return ""
 }
 
-func (x *ProcessingStats) GetSkippedRecords() int32 {
+func (x *UnmatchedIC) GetCount() int32 {
 	if x != nil {
-		return x.SkippedRecords
+		return x.Count
 	}
 	return 0
 }
 
-func (x *ProcessingStats) GetErrorRecords() int32 {
+func (x *UnmatchedIC) GetRawNames() []string {
 	if x != nil {
-		return x.ErrorRecords
+		return x.RawNames
 	}
-	return 0
+	return nil
 }
 
 type ValidationError struct {
@@ -582,7 +4172,7 @@ type ValidationError struct {
 
 func (x *ValidationError) Reset() {
 	*x = ValidationError{}
-	mi := &file_src_proto_data_processor_proto_msgTypes[9]
+	mi := &file_src_proto_data_processor_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -594,7 +4184,7 @@ func (x *ValidationError) String() string {
 func (*ValidationError) ProtoMessage() {}
 
 func (x *ValidationError) ProtoReflect() protoreflect.Message {
-	mi := &file_src_proto_data_processor_proto_msgTypes[9]
+	mi := &file_src_proto_data_processor_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -607,7 +4197,7 @@ func (x *ValidationError) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ValidationError.ProtoReflect.Descriptor instead.
 func (*ValidationError) Descriptor() ([]byte, []int) {
-	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{9}
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{44}
 }
 
 func (x *ValidationError) GetLineNumber() int32 {
@@ -642,32 +4232,65 @@ var File_src_proto_data_processor_proto protoreflect.FileDescriptor
 
 const file_src_proto_data_processor_proto_rawDesc = "" +
 	"\n" +
-	"\x1esrc/proto/data_processor.proto\x12\x13etcdataprocessor.v1\x1a\x1cgoogle/api/annotations.proto\"\xc7\x01\n" +
+	"\x1esrc/proto/data_processor.proto\x12\x13etcdataprocessor.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1cgoogle/protobuf/struct.proto\"\xec\x02\n" +
 	"\x15ProcessCSVFileRequest\x12'\n" +
 	"\rcsv_file_path\x18\x01 \x01(\tH\x00R\vcsvFilePath\x88\x01\x01\x12\"\n" +
 	"\n" +
 	"account_id\x18\x02 \x01(\tH\x01R\taccountId\x88\x01\x01\x12,\n" +
-	"\x0fskip_duplicates\x18\x03 \x01(\bH\x02R\x0eskipDuplicates\x88\x01\x01B\x10\n" +
+	"\x0fskip_duplicates\x18\x03 \x01(\bH\x02R\x0eskipDuplicates\x88\x01\x01\x12\x1c\n" +
+	"\adry_run\x18\x04 \x01(\bH\x03R\x06dryRun\x88\x01\x01\x12,\n" +
+	"\x0fidempotency_key\x18\x05 \x01(\tH\x04R\x0eidempotencyKey\x88\x01\x01\x12&\n" +
+	"\fstitch_trips\x18\x06 \x01(\bH\x05R\vstitchTrips\x88\x01\x01B\x10\n" +
 	"\x0e_csv_file_pathB\r\n" +
 	"\v_account_idB\x12\n" +
-	"\x10_skip_duplicates\"\xa0\x01\n" +
+	"\x10_skip_duplicatesB\n" +
+	"\n" +
+	"\b_dry_runB\x12\n" +
+	"\x10_idempotency_keyB\x0f\n" +
+	"\r_stitch_trips\"\x8a\x05\n" +
 	"\x16ProcessCSVFileResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12:\n" +
 	"\x05stats\x18\x03 \x01(\v2$.etcdataprocessor.v1.ProcessingStatsR\x05stats\x12\x16\n" +
-	"\x06errors\x18\x04 \x03(\tR\x06errors\"\xa7\x01\n" +
+	"\x06errors\x18\x04 \x03(\tR\x06errors\x12B\n" +
+	"\ffile_results\x18\x05 \x03(\v2\x1f.etcdataprocessor.v1.FileResultR\vfileResults\x12E\n" +
+	"\rrecord_errors\x18\x06 \x03(\v2 .etcdataprocessor.v1.RecordErrorR\frecordErrors\x12\x17\n" +
+	"\adry_run\x18\a \x01(\bR\x06dryRun\x12I\n" +
+	"\x0fdry_run_records\x18\b \x03(\v2!.etcdataprocessor.v1.DryRunRecordR\rdryRunRecords\x12\x1a\n" +
+	"\breplayed\x18\t \x01(\bR\breplayed\x12E\n" +
+	"\runmatched_ics\x18\n" +
+	" \x03(\v2 .etcdataprocessor.v1.UnmatchedICR\funmatchedIcs\x12/\n" +
+	"\x05trips\x18\v \x03(\v2\x19.etcdataprocessor.v1.TripR\x05trips\x12A\n" +
+	"\tanomalies\x18\f \x03(\v2#.etcdataprocessor.v1.AnomalyFindingR\tanomalies\x12\"\n" +
+	"\rimport_job_id\x18\r \x01(\tR\vimportJobId\"\xcc\x02\n" +
 	"\x15ProcessCSVDataRequest\x12\x19\n" +
 	"\bcsv_data\x18\x01 \x01(\tR\acsvData\x12\"\n" +
 	"\n" +
 	"account_id\x18\x02 \x01(\tH\x00R\taccountId\x88\x01\x01\x12,\n" +
-	"\x0fskip_duplicates\x18\x03 \x01(\bH\x01R\x0eskipDuplicates\x88\x01\x01B\r\n" +
+	"\x0fskip_duplicates\x18\x03 \x01(\bH\x01R\x0eskipDuplicates\x88\x01\x01\x12\x1c\n" +
+	"\adry_run\x18\x04 \x01(\bH\x02R\x06dryRun\x88\x01\x01\x12,\n" +
+	"\x0fidempotency_key\x18\x05 \x01(\tH\x03R\x0eidempotencyKey\x88\x01\x01\x12&\n" +
+	"\fstitch_trips\x18\x06 \x01(\bH\x04R\vstitchTrips\x88\x01\x01B\r\n" +
 	"\v_account_idB\x12\n" +
-	"\x10_skip_duplicates\"\xa0\x01\n" +
+	"\x10_skip_duplicatesB\n" +
+	"\n" +
+	"\b_dry_runB\x12\n" +
+	"\x10_idempotency_keyB\x0f\n" +
+	"\r_stitch_trips\"\xc6\x04\n" +
 	"\x16ProcessCSVDataResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12:\n" +
 	"\x05stats\x18\x03 \x01(\v2$.etcdataprocessor.v1.ProcessingStatsR\x05stats\x12\x16\n" +
-	"\x06errors\x18\x04 \x03(\tR\x06errors\"f\n" +
+	"\x06errors\x18\x04 \x03(\tR\x06errors\x12E\n" +
+	"\rrecord_errors\x18\x05 \x03(\v2 .etcdataprocessor.v1.RecordErrorR\frecordErrors\x12\x17\n" +
+	"\adry_run\x18\x06 \x01(\bR\x06dryRun\x12I\n" +
+	"\x0fdry_run_records\x18\a \x03(\v2!.etcdataprocessor.v1.DryRunRecordR\rdryRunRecords\x12\x1a\n" +
+	"\breplayed\x18\b \x01(\bR\breplayed\x12E\n" +
+	"\runmatched_ics\x18\t \x03(\v2 .etcdataprocessor.v1.UnmatchedICR\funmatchedIcs\x12/\n" +
+	"\x05trips\x18\n" +
+	" \x03(\v2\x19.etcdataprocessor.v1.TripR\x05trips\x12A\n" +
+	"\tanomalies\x18\v \x03(\v2#.etcdataprocessor.v1.AnomalyFindingR\tanomalies\x12\"\n" +
+	"\rimport_job_id\x18\f \x01(\tR\vimportJobId\"f\n" +
 	"\x16ValidateCSVDataRequest\x12\x19\n" +
 	"\bcsv_data\x18\x01 \x01(\tR\acsvData\x12\"\n" +
 	"\n" +
@@ -677,7 +4300,205 @@ const file_src_proto_data_processor_proto_rawDesc = "" +
 	"\bis_valid\x18\x01 \x01(\bR\aisValid\x12<\n" +
 	"\x06errors\x18\x02 \x03(\v2$.etcdataprocessor.v1.ValidationErrorR\x06errors\x12'\n" +
 	"\x0fduplicate_count\x18\x03 \x01(\x05R\x0eduplicateCount\x12#\n" +
-	"\rtotal_records\x18\x04 \x01(\x05R\ftotalRecords\"\x14\n" +
+	"\rtotal_records\x18\x04 \x01(\x05R\ftotalRecords\"\xd9\x01\n" +
+	"\x11PreviewCSVRequest\x12\x1e\n" +
+	"\bcsv_data\x18\x01 \x01(\tH\x00R\acsvData\x88\x01\x01\x12'\n" +
+	"\rcsv_file_path\x18\x02 \x01(\tH\x01R\vcsvFilePath\x88\x01\x01\x12\x19\n" +
+	"\x05limit\x18\x03 \x01(\x05H\x02R\x05limit\x88\x01\x01\x12&\n" +
+	"\fstitch_trips\x18\x04 \x01(\bH\x03R\vstitchTrips\x88\x01\x01B\v\n" +
+	"\t_csv_dataB\x10\n" +
+	"\x0e_csv_file_pathB\b\n" +
+	"\x06_limitB\x0f\n" +
+	"\r_stitch_trips\"\xdc\x01\n" +
+	"\x12PreviewCSVResponse\x12\x16\n" +
+	"\x06format\x18\x01 \x01(\tR\x06format\x12\x1a\n" +
+	"\bencoding\x18\x02 \x01(\tR\bencoding\x12#\n" +
+	"\rtotal_records\x18\x03 \x01(\x05R\ftotalRecords\x12<\n" +
+	"\arecords\x18\x04 \x03(\v2\".etcdataprocessor.v1.PreviewRecordR\arecords\x12/\n" +
+	"\x05trips\x18\x05 \x03(\v2\x19.etcdataprocessor.v1.TripR\x05trips\"\xd3\x02\n" +
+	"\rPreviewRecord\x12\x1f\n" +
+	"\vline_number\x18\x01 \x01(\x05R\n" +
+	"lineNumber\x12\x1f\n" +
+	"\vraw_columns\x18\x02 \x03(\tR\n" +
+	"rawColumns\x129\n" +
+	"\x06parsed\x18\x03 \x01(\v2!.etcdataprocessor.v1.ParsedRecordR\x06parsed\x12B\n" +
+	"\tconverted\x18\x04 \x01(\v2$.etcdataprocessor.v1.ConvertedRecordR\tconverted\x12)\n" +
+	"\x10conversion_error\x18\x05 \x01(\tR\x0fconversionError\x12=\n" +
+	"\bmappings\x18\x06 \x03(\v2!.etcdataprocessor.v1.FieldMappingR\bmappings\x12\x17\n" +
+	"\atrip_id\x18\a \x01(\tR\x06tripId\"\xee\x04\n" +
+	"\fParsedRecord\x12\x1d\n" +
+	"\n" +
+	"entry_date\x18\x01 \x01(\tR\tentryDate\x12\x1d\n" +
+	"\n" +
+	"entry_time\x18\x02 \x01(\tR\tentryTime\x12\x1b\n" +
+	"\texit_date\x18\x03 \x01(\tR\bexitDate\x12\x1b\n" +
+	"\texit_time\x18\x04 \x01(\tR\bexitTime\x12\x19\n" +
+	"\bentry_ic\x18\x05 \x01(\tR\aentryIc\x12\x17\n" +
+	"\aexit_ic\x18\x06 \x01(\tR\x06exitIc\x12\x1d\n" +
+	"\n" +
+	"route_info\x18\a \x01(\tR\trouteInfo\x12\x1d\n" +
+	"\n" +
+	"etc_amount\x18\b \x01(\x05R\tetcAmount\x12#\n" +
+	"\rnormal_amount\x18\t \x01(\x05R\fnormalAmount\x12)\n" +
+	"\x10discount_applied\x18\n" +
+	" \x01(\x05R\x0fdiscountApplied\x12\x18\n" +
+	"\amileage\x18\v \x01(\x05R\amileage\x12F\n" +
+	"\rvehicle_class\x18\f \x01(\x0e2!.etcdataprocessor.v1.VehicleClassR\fvehicleClass\x12%\n" +
+	"\x0evehicle_number\x18\r \x01(\tR\rvehicleNumber\x12\x1f\n" +
+	"\vcard_number\x18\x0e \x01(\tR\n" +
+	"cardNumber\x12\x14\n" +
+	"\x05notes\x18\x0f \x01(\tR\x05notes\x12.\n" +
+	"\x13post_payment_amount\x18\x10 \x01(\x05R\x11postPaymentAmount\x124\n" +
+	"\x16redemption_base_amount\x18\x11 \x01(\x05R\x14redemptionBaseAmount\"\xda\x05\n" +
+	"\x0fConvertedRecord\x12\x12\n" +
+	"\x04date\x18\x01 \x01(\tR\x04date\x12\x19\n" +
+	"\bentry_ic\x18\x02 \x01(\tR\aentryIc\x12\x17\n" +
+	"\aexit_ic\x18\x03 \x01(\tR\x06exitIc\x12\x14\n" +
+	"\x05route\x18\x04 \x01(\tR\x05route\x12D\n" +
+	"\fvehicle_type\x18\x05 \x01(\x0e2!.etcdataprocessor.v1.VehicleClassR\vvehicleType\x12\x16\n" +
+	"\x06amount\x18\x06 \x01(\x05R\x06amount\x12\x1f\n" +
+	"\vcard_number\x18\a \x01(\tR\n" +
+	"cardNumber\x12#\n" +
+	"\rnormal_amount\x18\b \x01(\x05R\fnormalAmount\x12'\n" +
+	"\x0fdiscount_amount\x18\t \x01(\x05R\x0ediscountAmount\x12\x1d\n" +
+	"\n" +
+	"etc_amount\x18\n" +
+	" \x01(\x05R\tetcAmount\x12.\n" +
+	"\x13post_payment_amount\x18\v \x01(\x05R\x11postPaymentAmount\x12\x18\n" +
+	"\amileage\x18\f \x01(\x05R\amileage\x12\x1a\n" +
+	"\breversal\x18\r \x01(\bR\breversal\x12+\n" +
+	"\x11correction_reason\x18\x0e \x01(\tR\x10correctionReason\x12H\n" +
+	"\x0eroute_segments\x18\x0f \x03(\v2!.etcdataprocessor.v1.RouteSegmentR\rrouteSegments\x12\x19\n" +
+	"\bday_type\x18\x10 \x01(\tR\adayType\x120\n" +
+	"\x14tax_exclusive_amount\x18\x11 \x01(\x05R\x12taxExclusiveAmount\x12\x1d\n" +
+	"\n" +
+	"tax_amount\x18\x12 \x01(\x05R\ttaxAmount\x124\n" +
+	"\x16redemption_base_amount\x18\x13 \x01(\x05R\x14redemptionBaseAmount\"X\n" +
+	"\fRouteSegment\x12\x12\n" +
+	"\x04road\x18\x01 \x01(\tR\x04road\x12\x12\n" +
+	"\x04from\x18\x02 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x03 \x01(\tR\x02to\x12\x10\n" +
+	"\x03via\x18\x04 \x03(\tR\x03via\"\xb5\x01\n" +
+	"\fFieldMapping\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x16\n" +
+	"\x06header\x18\x02 \x01(\tR\x06header\x12\x16\n" +
+	"\x06column\x18\x03 \x01(\x05R\x06column\x12\x1b\n" +
+	"\traw_value\x18\x04 \x01(\tR\brawValue\x12\x14\n" +
+	"\x05value\x18\x05 \x01(\tR\x05value\x12\x18\n" +
+	"\acoerced\x18\x06 \x01(\bR\acoerced\x12\x12\n" +
+	"\x04note\x18\a \x01(\tR\x04note\"\xa6\x02\n" +
+	"\x0eCardAssignment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
+	"\vcard_number\x18\x02 \x01(\tR\n" +
+	"cardNumber\x12%\n" +
+	"\x0evehicle_number\x18\x03 \x01(\tR\rvehicleNumber\x12\x1d\n" +
+	"\n" +
+	"vehicle_id\x18\x04 \x01(\tR\tvehicleId\x12\x1b\n" +
+	"\tdriver_id\x18\x05 \x01(\tR\bdriverId\x12\x1d\n" +
+	"\n" +
+	"valid_from\x18\x06 \x01(\tR\tvalidFrom\x12\x19\n" +
+	"\bvalid_to\x18\a \x01(\tR\avalidTo\x12F\n" +
+	"\rvehicle_class\x18\b \x01(\x0e2!.etcdataprocessor.v1.VehicleClassR\fvehicleClass\"b\n" +
+	"\x1bCreateCardAssignmentRequest\x12C\n" +
+	"\n" +
+	"assignment\x18\x01 \x01(\v2#.etcdataprocessor.v1.CardAssignmentR\n" +
+	"assignment\"*\n" +
+	"\x18GetCardAssignmentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"d\n" +
+	"\x1aListCardAssignmentsRequest\x12\x1f\n" +
+	"\vcard_number\x18\x01 \x01(\tR\n" +
+	"cardNumber\x12%\n" +
+	"\x0evehicle_number\x18\x02 \x01(\tR\rvehicleNumber\"d\n" +
+	"\x1bListCardAssignmentsResponse\x12E\n" +
+	"\vassignments\x18\x01 \x03(\v2#.etcdataprocessor.v1.CardAssignmentR\vassignments\"b\n" +
+	"\x1bUpdateCardAssignmentRequest\x12C\n" +
+	"\n" +
+	"assignment\x18\x01 \x01(\v2#.etcdataprocessor.v1.CardAssignmentR\n" +
+	"assignment\"-\n" +
+	"\x1bDeleteCardAssignmentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x1e\n" +
+	"\x1cDeleteCardAssignmentResponse\"\xad\x01\n" +
+	"\x16GetUsageSummaryRequest\x12\x1b\n" +
+	"\tfrom_date\x18\x01 \x01(\tR\bfromDate\x12\x17\n" +
+	"\ato_date\x18\x02 \x01(\tR\x06toDate\x12>\n" +
+	"\bgroup_by\x18\x03 \x03(\x0e2#.etcdataprocessor.v1.UsageDimensionR\agroupBy\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x04 \x01(\tR\taccountId\"\xe3\x02\n" +
+	"\fUsageSummary\x12\x1f\n" +
+	"\vcard_number\x18\x01 \x01(\tR\n" +
+	"cardNumber\x12\x18\n" +
+	"\avehicle\x18\x02 \x01(\tR\avehicle\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x03 \x01(\tR\taccountId\x12\x14\n" +
+	"\x05route\x18\x04 \x01(\tR\x05route\x12\x14\n" +
+	"\x05month\x18\x05 \x01(\tR\x05month\x12\x16\n" +
+	"\x06amount\x18\x06 \x01(\x03R\x06amount\x12#\n" +
+	"\rnormal_amount\x18\a \x01(\x03R\fnormalAmount\x12'\n" +
+	"\x0fdiscount_amount\x18\b \x01(\x03R\x0ediscountAmount\x12\x1d\n" +
+	"\n" +
+	"trip_count\x18\t \x01(\x05R\ttripCount\x12!\n" +
+	"\frecord_count\x18\n" +
+	" \x01(\x05R\vrecordCount\x12%\n" +
+	"\x0ereversal_count\x18\v \x01(\x05R\rreversalCount\"\x8d\x01\n" +
+	"\x17GetUsageSummaryResponse\x129\n" +
+	"\x06groups\x18\x01 \x03(\v2!.etcdataprocessor.v1.UsageSummaryR\x06groups\x127\n" +
+	"\x05total\x18\x02 \x01(\v2!.etcdataprocessor.v1.UsageSummaryR\x05total\"\xd6\x01\n" +
+	"\x14ExportJournalRequest\x12\x1e\n" +
+	"\bcsv_data\x18\x01 \x01(\tH\x00R\acsvData\x88\x01\x01\x12'\n" +
+	"\rcsv_file_path\x18\x02 \x01(\tH\x01R\vcsvFilePath\x88\x01\x01\x12:\n" +
+	"\x06format\x18\x03 \x01(\x0e2\".etcdataprocessor.v1.JournalFormatR\x06format\x12\x1a\n" +
+	"\bencoding\x18\x04 \x01(\tR\bencodingB\v\n" +
+	"\t_csv_dataB\x10\n" +
+	"\x0e_csv_file_path\"\xa3\x02\n" +
+	"\x15ExportJournalResponse\x12\x18\n" +
+	"\acontent\x18\x01 \x01(\fR\acontent\x12\x1a\n" +
+	"\bfilename\x18\x02 \x01(\tR\bfilename\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12\x1f\n" +
+	"\ventry_count\x18\x04 \x01(\x05R\n" +
+	"entryCount\x12E\n" +
+	"\rrecord_errors\x18\x05 \x03(\v2 .etcdataprocessor.v1.RecordErrorR\frecordErrors\x12\x1d\n" +
+	"\n" +
+	"tax_amount\x18\x06 \x01(\x03R\ttaxAmount\x12*\n" +
+	"\x11record_tax_amount\x18\a \x01(\x03R\x0frecordTaxAmount\"\x81\x01\n" +
+	"\rExpectedTotal\x12\x1f\n" +
+	"\vcard_number\x18\x01 \x01(\tR\n" +
+	"cardNumber\x12\x14\n" +
+	"\x05month\x18\x02 \x01(\tR\x05month\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\x03R\x06amount\x12!\n" +
+	"\frecord_count\x18\x04 \x01(\x05R\vrecordCount\"\xe2\x01\n" +
+	"\x19ReconcileStatementRequest\x12>\n" +
+	"\bexpected\x18\x01 \x03(\v2\".etcdataprocessor.v1.ExpectedTotalR\bexpected\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x02 \x01(\tR\taccountId\x12\x1e\n" +
+	"\bcsv_data\x18\x03 \x01(\tH\x00R\acsvData\x88\x01\x01\x12'\n" +
+	"\rcsv_file_path\x18\x04 \x01(\tH\x01R\vcsvFilePath\x88\x01\x01B\v\n" +
+	"\t_csv_dataB\x10\n" +
+	"\x0e_csv_file_path\"\xec\x01\n" +
+	"\x13ReconciliationIssue\x12@\n" +
+	"\x04kind\x18\x01 \x01(\x0e2,.etcdataprocessor.v1.ReconciliationIssueKindR\x04kind\x12\x12\n" +
+	"\x04date\x18\x02 \x01(\tR\x04date\x12\x19\n" +
+	"\bentry_ic\x18\x03 \x01(\tR\aentryIc\x12\x17\n" +
+	"\aexit_ic\x18\x04 \x01(\tR\x06exitIc\x12\x16\n" +
+	"\x06amount\x18\x05 \x01(\x03R\x06amount\x12\x1f\n" +
+	"\vline_number\x18\x06 \x01(\x05R\n" +
+	"lineNumber\x12\x12\n" +
+	"\x04note\x18\a \x01(\tR\x04note\"\x81\x03\n" +
+	"\x12CardReconciliation\x12\x1f\n" +
+	"\vcard_number\x18\x01 \x01(\tR\n" +
+	"cardNumber\x12\x14\n" +
+	"\x05month\x18\x02 \x01(\tR\x05month\x12'\n" +
+	"\x0fexpected_amount\x18\x03 \x01(\x03R\x0eexpectedAmount\x12'\n" +
+	"\x0fimported_amount\x18\x04 \x01(\x03R\x0eimportedAmount\x12\x1e\n" +
+	"\n" +
+	"difference\x18\x05 \x01(\x03R\n" +
+	"difference\x122\n" +
+	"\x15expected_record_count\x18\x06 \x01(\x05R\x13expectedRecordCount\x122\n" +
+	"\x15imported_record_count\x18\a \x01(\x05R\x13importedRecordCount\x12\x18\n" +
+	"\amatched\x18\b \x01(\bR\amatched\x12@\n" +
+	"\x06issues\x18\t \x03(\v2(.etcdataprocessor.v1.ReconciliationIssueR\x06issues\"y\n" +
+	"\x1aReconcileStatementResponse\x12\x18\n" +
+	"\amatched\x18\x01 \x01(\bR\amatched\x12A\n" +
+	"\aresults\x18\x02 \x03(\v2'.etcdataprocessor.v1.CardReconciliationR\aresults\"\x14\n" +
 	"\x12HealthCheckRequest\"\xf2\x01\n" +
 	"\x13HealthCheckResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x18\n" +
@@ -686,23 +4507,193 @@ const file_src_proto_data_processor_proto_rawDesc = "" +
 	"\adetails\x18\x04 \x03(\v25.etcdataprocessor.v1.HealthCheckResponse.DetailsEntryR\adetails\x1a:\n" +
 	"\fDetailsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa9\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb6\x04\n" +
 	"\x0fProcessingStats\x12#\n" +
 	"\rtotal_records\x18\x01 \x01(\x05R\ftotalRecords\x12#\n" +
 	"\rsaved_records\x18\x02 \x01(\x05R\fsavedRecords\x12'\n" +
 	"\x0fskipped_records\x18\x03 \x01(\x05R\x0eskippedRecords\x12#\n" +
-	"\rerror_records\x18\x04 \x01(\x05R\ferrorRecords\"\x83\x01\n" +
+	"\rerror_records\x18\x04 \x01(\x05R\ferrorRecords\x12)\n" +
+	"\x10reversal_records\x18\x05 \x01(\x05R\x0freversalRecords\x12\x1d\n" +
+	"\n" +
+	"net_amount\x18\x06 \x01(\x03R\tnetAmount\x120\n" +
+	"\x14unknown_card_records\x18\a \x01(\x05R\x12unknownCardRecords\x12:\n" +
+	"\x19discount_mismatch_records\x18\b \x01(\x05R\x17discountMismatchRecords\x12-\n" +
+	"\x12claimable_discount\x18\t \x01(\x03R\x11claimableDiscount\x120\n" +
+	"\x14tax_exclusive_amount\x18\n" +
+	" \x01(\x03R\x12taxExclusiveAmount\x12\x1d\n" +
+	"\n" +
+	"tax_amount\x18\v \x01(\x03R\ttaxAmount\x12*\n" +
+	"\x11record_tax_amount\x18\f \x01(\x03R\x0frecordTaxAmount\x12'\n" +
+	"\x0fanomaly_records\x18\r \x01(\x05R\x0eanomalyRecords\"\xd8\x03\n" +
+	"\n" +
+	"FileResult\x12\x1b\n" +
+	"\tfile_path\x18\x01 \x01(\tR\bfilePath\x12\x16\n" +
+	"\x06format\x18\x02 \x01(\tR\x06format\x12\x1a\n" +
+	"\bencoding\x18\x03 \x01(\tR\bencoding\x12:\n" +
+	"\x05stats\x18\x04 \x01(\v2$.etcdataprocessor.v1.ProcessingStatsR\x05stats\x12\x16\n" +
+	"\x06errors\x18\x05 \x03(\tR\x06errors\x12\x1f\n" +
+	"\vduration_ms\x18\x06 \x01(\x03R\n" +
+	"durationMs\x12E\n" +
+	"\rrecord_errors\x18\a \x03(\v2 .etcdataprocessor.v1.RecordErrorR\frecordErrors\x12I\n" +
+	"\x0fdry_run_records\x18\b \x03(\v2!.etcdataprocessor.v1.DryRunRecordR\rdryRunRecords\x12/\n" +
+	"\x05trips\x18\t \x03(\v2\x19.etcdataprocessor.v1.TripR\x05trips\x12A\n" +
+	"\tanomalies\x18\n" +
+	" \x03(\v2#.etcdataprocessor.v1.AnomalyFindingR\tanomalies\"\xd2\x01\n" +
+	"\vRecordError\x122\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x1e.etcdataprocessor.v1.ErrorCodeR\x04code\x12!\n" +
+	"\frecord_index\x18\x02 \x01(\x05R\vrecordIndex\x12\x1f\n" +
+	"\vline_number\x18\x03 \x01(\x05R\n" +
+	"lineNumber\x12\x1b\n" +
+	"\tfile_path\x18\x04 \x01(\tR\bfilePath\x12\x14\n" +
+	"\x05field\x18\x05 \x01(\tR\x05field\x12\x18\n" +
+	"\amessage\x18\x06 \x01(\tR\amessage\"\x95\x02\n" +
+	"\fDryRunRecord\x12!\n" +
+	"\frecord_index\x18\x01 \x01(\x05R\vrecordIndex\x12\x1f\n" +
+	"\vline_number\x18\x02 \x01(\x05R\n" +
+	"lineNumber\x12\x1b\n" +
+	"\tfile_path\x18\x03 \x01(\tR\bfilePath\x129\n" +
+	"\x06action\x18\x04 \x01(\x0e2!.etcdataprocessor.v1.DryRunActionR\x06action\x126\n" +
+	"\x06reason\x18\x05 \x01(\x0e2\x1e.etcdataprocessor.v1.ErrorCodeR\x06reason\x121\n" +
+	"\apayload\x18\x06 \x01(\v2\x17.google.protobuf.StructR\apayload\"\xe4\x02\n" +
+	"\x04Trip\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
+	"\vcard_number\x18\x02 \x01(\tR\n" +
+	"cardNumber\x12\x19\n" +
+	"\bentry_ic\x18\x03 \x01(\tR\aentryIc\x12\x17\n" +
+	"\aexit_ic\x18\x04 \x01(\tR\x06exitIc\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\x05 \x01(\tR\tstartTime\x12\x19\n" +
+	"\bend_time\x18\x06 \x01(\tR\aendTime\x12%\n" +
+	"\x0erecord_indexes\x18\a \x03(\x05R\rrecordIndexes\x12!\n" +
+	"\fline_numbers\x18\b \x03(\x05R\vlineNumbers\x12!\n" +
+	"\ftotal_amount\x18\t \x01(\x05R\vtotalAmount\x12\x18\n" +
+	"\amileage\x18\n" +
+	" \x01(\x05R\amileage\x12\x1b\n" +
+	"\tfile_path\x18\v \x01(\tR\bfilePath\x12\x19\n" +
+	"\bday_type\x18\f \x01(\tR\adayType\"\xef\x02\n" +
+	"\x0eAnomalyFinding\x12\x12\n" +
+	"\x04rule\x18\x01 \x01(\tR\x04rule\x12!\n" +
+	"\frecord_index\x18\x02 \x01(\x05R\vrecordIndex\x12\x1f\n" +
+	"\vline_number\x18\x03 \x01(\x05R\n" +
+	"lineNumber\x12\x1b\n" +
+	"\tfile_path\x18\x04 \x01(\tR\bfilePath\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x05 \x01(\tR\taccountId\x12\x1f\n" +
+	"\vcard_number\x18\x06 \x01(\tR\n" +
+	"cardNumber\x12\x12\n" +
+	"\x04date\x18\a \x01(\tR\x04date\x12\x19\n" +
+	"\bentry_ic\x18\b \x01(\tR\aentryIc\x12\x17\n" +
+	"\aexit_ic\x18\t \x01(\tR\x06exitIc\x12\x16\n" +
+	"\x06amount\x18\n" +
+	" \x01(\x05R\x06amount\x12\x18\n" +
+	"\amessage\x18\v \x01(\tR\amessage\x12.\n" +
+	"\x13related_line_number\x18\f \x01(\x05R\x11relatedLineNumber\"\xa0\x01\n" +
+	"\x14ListAnomaliesRequest\x12\x1b\n" +
+	"\tfrom_date\x18\x01 \x01(\tR\bfromDate\x12\x17\n" +
+	"\ato_date\x18\x02 \x01(\tR\x06toDate\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x03 \x01(\tR\taccountId\x12\x1f\n" +
+	"\vcard_number\x18\x04 \x01(\tR\n" +
+	"cardNumber\x12\x12\n" +
+	"\x04rule\x18\x05 \x01(\tR\x04rule\"X\n" +
+	"\x15ListAnomaliesResponse\x12?\n" +
+	"\bfindings\x18\x01 \x03(\v2#.etcdataprocessor.v1.AnomalyFindingR\bfindings\"\xd3\x02\n" +
+	"\x14ExportRecordsRequest\x129\n" +
+	"\x06format\x18\x01 \x01(\x0e2!.etcdataprocessor.v1.ExportFormatR\x06format\x12\"\n" +
+	"\rimport_job_id\x18\x02 \x01(\tR\vimportJobId\x12\x1f\n" +
+	"\vsource_file\x18\x03 \x01(\tR\n" +
+	"sourceFile\x12\x1b\n" +
+	"\tfrom_date\x18\x04 \x01(\tR\bfromDate\x12\x17\n" +
+	"\ato_date\x18\x05 \x01(\tR\x06toDate\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x06 \x01(\tR\taccountId\x12\x1e\n" +
+	"\bcsv_data\x18\a \x01(\tH\x00R\acsvData\x88\x01\x01\x12'\n" +
+	"\rcsv_file_path\x18\b \x01(\tH\x01R\vcsvFilePath\x88\x01\x01B\v\n" +
+	"\t_csv_dataB\x10\n" +
+	"\x0e_csv_file_path\"\x81\x02\n" +
+	"\x15ExportRecordsResponse\x12\x18\n" +
+	"\acontent\x18\x01 \x01(\fR\acontent\x12\x1a\n" +
+	"\bfilename\x18\x02 \x01(\tR\bfilename\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12!\n" +
+	"\frecord_count\x18\x04 \x01(\x05R\vrecordCount\x12%\n" +
+	"\x0eschema_version\x18\x05 \x01(\tR\rschemaVersion\x12E\n" +
+	"\rrecord_errors\x18\x06 \x03(\v2 .etcdataprocessor.v1.RecordErrorR\frecordErrors\"T\n" +
+	"\vUnmatchedIC\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\x12\x1b\n" +
+	"\traw_names\x18\x03 \x03(\tR\brawNames\"\x83\x01\n" +
 	"\x0fValidationError\x12\x1f\n" +
 	"\vline_number\x18\x01 \x01(\x05R\n" +
 	"lineNumber\x12\x14\n" +
 	"\x05field\x18\x02 \x01(\tR\x05field\x12\x18\n" +
 	"\amessage\x18\x03 \x01(\tR\amessage\x12\x1f\n" +
 	"\vrecord_data\x18\x04 \x01(\tR\n" +
-	"recordData2\xa6\x04\n" +
+	"recordData*\xb4\x01\n" +
+	"\fVehicleClass\x12\x1d\n" +
+	"\x19VEHICLE_CLASS_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16VEHICLE_CLASS_STANDARD\x10\x01\x12\x18\n" +
+	"\x14VEHICLE_CLASS_MEDIUM\x10\x02\x12\x17\n" +
+	"\x13VEHICLE_CLASS_LARGE\x10\x03\x12\x1d\n" +
+	"\x19VEHICLE_CLASS_EXTRA_LARGE\x10\x04\x12\x17\n" +
+	"\x13VEHICLE_CLASS_LIGHT\x10\x05*\xbb\x01\n" +
+	"\x0eUsageDimension\x12\x1f\n" +
+	"\x1bUSAGE_DIMENSION_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14USAGE_DIMENSION_CARD\x10\x01\x12\x1b\n" +
+	"\x17USAGE_DIMENSION_VEHICLE\x10\x02\x12\x1b\n" +
+	"\x17USAGE_DIMENSION_ACCOUNT\x10\x03\x12\x19\n" +
+	"\x15USAGE_DIMENSION_ROUTE\x10\x04\x12\x19\n" +
+	"\x15USAGE_DIMENSION_MONTH\x10\x05*\x85\x01\n" +
+	"\rJournalFormat\x12\x1e\n" +
+	"\x1aJOURNAL_FORMAT_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14JOURNAL_FORMAT_FREEE\x10\x01\x12 \n" +
+	"\x1cJOURNAL_FORMAT_MONEY_FORWARD\x10\x02\x12\x18\n" +
+	"\x14JOURNAL_FORMAT_YAYOI\x10\x03*\xb9\x01\n" +
+	"\x17ReconciliationIssueKind\x12)\n" +
+	"%RECONCILIATION_ISSUE_KIND_UNSPECIFIED\x10\x00\x12%\n" +
+	"!RECONCILIATION_ISSUE_KIND_MISSING\x10\x01\x12#\n" +
+	"\x1fRECONCILIATION_ISSUE_KIND_EXTRA\x10\x02\x12'\n" +
+	"#RECONCILIATION_ISSUE_KIND_DUPLICATE\x10\x03*\xbf\x02\n" +
+	"\tErrorCode\x12\x1a\n" +
+	"\x16ERROR_CODE_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10ERROR_CODE_PARSE\x10\x01\x12\x19\n" +
+	"\x15ERROR_CODE_VALIDATION\x10\x02\x12\x18\n" +
+	"\x14ERROR_CODE_DUPLICATE\x10\x03\x12\x19\n" +
+	"\x15ERROR_CODE_CONVERSION\x10\x04\x12\x1a\n" +
+	"\x16ERROR_CODE_PERSISTENCE\x10\x05\x12\x18\n" +
+	"\x14ERROR_CODE_CANCELLED\x10\x06\x12#\n" +
+	"\x1fERROR_CODE_IDEMPOTENCY_CONFLICT\x10\a\x12\x1b\n" +
+	"\x17ERROR_CODE_UNKNOWN_CARD\x10\b\x12 \n" +
+	"\x1cERROR_CODE_DISCOUNT_MISMATCH\x10\t\x12\x16\n" +
+	"\x12ERROR_CODE_ANOMALY\x10\n" +
+	"*{\n" +
+	"\fDryRunAction\x12\x1e\n" +
+	"\x1aDRY_RUN_ACTION_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13DRY_RUN_ACTION_SAVE\x10\x01\x12\x17\n" +
+	"\x13DRY_RUN_ACTION_SKIP\x10\x02\x12\x19\n" +
+	"\x15DRY_RUN_ACTION_REJECT\x10\x03*x\n" +
+	"\fExportFormat\x12\x1d\n" +
+	"\x19EXPORT_FORMAT_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11EXPORT_FORMAT_CSV\x10\x01\x12\x17\n" +
+	"\x13EXPORT_FORMAT_JSONL\x10\x02\x12\x19\n" +
+	"\x15EXPORT_FORMAT_PARQUET\x10\x032\xd3\x10\n" +
 	"\x14DataProcessorService\x12\x86\x01\n" +
 	"\x0eProcessCSVFile\x12*.etcdataprocessor.v1.ProcessCSVFileRequest\x1a+.etcdataprocessor.v1.ProcessCSVFileResponse\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/v1/process/file\x12\x86\x01\n" +
 	"\x0eProcessCSVData\x12*.etcdataprocessor.v1.ProcessCSVDataRequest\x1a+.etcdataprocessor.v1.ProcessCSVDataResponse\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/v1/process/data\x12\x85\x01\n" +
-	"\x0fValidateCSVData\x12+.etcdataprocessor.v1.ValidateCSVDataRequest\x1a,.etcdataprocessor.v1.ValidateCSVDataResponse\"\x17\x82\xd3\xe4\x93\x02\x11:\x01*\"\f/v1/validate\x12t\n" +
+	"\x0fValidateCSVData\x12+.etcdataprocessor.v1.ValidateCSVDataRequest\x1a,.etcdataprocessor.v1.ValidateCSVDataResponse\"\x17\x82\xd3\xe4\x93\x02\x11:\x01*\"\f/v1/validate\x12u\n" +
+	"\n" +
+	"PreviewCSV\x12&.etcdataprocessor.v1.PreviewCSVRequest\x1a'.etcdataprocessor.v1.PreviewCSVResponse\"\x16\x82\xd3\xe4\x93\x02\x10:\x01*\"\v/v1/preview\x12\x97\x01\n" +
+	"\x14CreateCardAssignment\x120.etcdataprocessor.v1.CreateCardAssignmentRequest\x1a#.etcdataprocessor.v1.CardAssignment\"(\x82\xd3\xe4\x93\x02\":\n" +
+	"assignment\"\x14/v1/card-assignments\x12\x8a\x01\n" +
+	"\x11GetCardAssignment\x12-.etcdataprocessor.v1.GetCardAssignmentRequest\x1a#.etcdataprocessor.v1.CardAssignment\"!\x82\xd3\xe4\x93\x02\x1b\x12\x19/v1/card-assignments/{id}\x12\x96\x01\n" +
+	"\x13ListCardAssignments\x12/.etcdataprocessor.v1.ListCardAssignmentsRequest\x1a0.etcdataprocessor.v1.ListCardAssignmentsResponse\"\x1c\x82\xd3\xe4\x93\x02\x16\x12\x14/v1/card-assignments\x12\xa7\x01\n" +
+	"\x14UpdateCardAssignment\x120.etcdataprocessor.v1.UpdateCardAssignmentRequest\x1a#.etcdataprocessor.v1.CardAssignment\"8\x82\xd3\xe4\x93\x022:\n" +
+	"assignment\x1a$/v1/card-assignments/{assignment.id}\x12\x9e\x01\n" +
+	"\x14DeleteCardAssignment\x120.etcdataprocessor.v1.DeleteCardAssignmentRequest\x1a1.etcdataprocessor.v1.DeleteCardAssignmentResponse\"!\x82\xd3\xe4\x93\x02\x1b*\x19/v1/card-assignments/{id}\x12\x87\x01\n" +
+	"\x0fGetUsageSummary\x12+.etcdataprocessor.v1.GetUsageSummaryRequest\x1a,.etcdataprocessor.v1.GetUsageSummaryResponse\"\x19\x82\xd3\xe4\x93\x02\x13\x12\x11/v1/usage/summary\x12\x85\x01\n" +
+	"\rExportJournal\x12).etcdataprocessor.v1.ExportJournalRequest\x1a*.etcdataprocessor.v1.ExportJournalResponse\"\x1d\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/v1/journal/export\x12\x8f\x01\n" +
+	"\x12ReconcileStatement\x12..etcdataprocessor.v1.ReconcileStatementRequest\x1a/.etcdataprocessor.v1.ReconcileStatementResponse\"\x18\x82\xd3\xe4\x93\x02\x12:\x01*\"\r/v1/reconcile\x12}\n" +
+	"\rListAnomalies\x12).etcdataprocessor.v1.ListAnomaliesRequest\x1a*.etcdataprocessor.v1.ListAnomaliesResponse\"\x15\x82\xd3\xe4\x93\x02\x0f\x12\r/v1/anomalies\x12\x85\x01\n" +
+	"\rExportRecords\x12).etcdataprocessor.v1.ExportRecordsRequest\x1a*.etcdataprocessor.v1.ExportRecordsResponse\"\x1d\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/v1/records/export\x12t\n" +
 	"\vHealthCheck\x12'.etcdataprocessor.v1.HealthCheckRequest\x1a(.etcdataprocessor.v1.HealthCheckResponse\"\x12\x82\xd3\xe4\x93\x02\f\x12\n" +
 	"/v1/healthBCZAgithub.com/yhonda-ohishi-pub-dev/etc_data_processor/src/api/pb;pbb\x06proto3"
 
@@ -718,38 +4709,148 @@ func file_src_proto_data_processor_proto_rawDescGZIP() []byte {
 	return file_src_proto_data_processor_proto_rawDescData
 }
 
-var file_src_proto_data_processor_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_src_proto_data_processor_proto_enumTypes = make([]protoimpl.EnumInfo, 7)
+var file_src_proto_data_processor_proto_msgTypes = make([]protoimpl.MessageInfo, 46)
 var file_src_proto_data_processor_proto_goTypes = []any{
-	(*ProcessCSVFileRequest)(nil),   // 0: etcdataprocessor.v1.ProcessCSVFileRequest
-	(*ProcessCSVFileResponse)(nil),  // 1: etcdataprocessor.v1.ProcessCSVFileResponse
-	(*ProcessCSVDataRequest)(nil),   // 2: etcdataprocessor.v1.ProcessCSVDataRequest
-	(*ProcessCSVDataResponse)(nil),  // 3: etcdataprocessor.v1.ProcessCSVDataResponse
-	(*ValidateCSVDataRequest)(nil),  // 4: etcdataprocessor.v1.ValidateCSVDataRequest
-	(*ValidateCSVDataResponse)(nil), // 5: etcdataprocessor.v1.ValidateCSVDataResponse
-	(*HealthCheckRequest)(nil),      // 6: etcdataprocessor.v1.HealthCheckRequest
-	(*HealthCheckResponse)(nil),     // 7: etcdataprocessor.v1.HealthCheckResponse
-	(*ProcessingStats)(nil),         // 8: etcdataprocessor.v1.ProcessingStats
-	(*ValidationError)(nil),         // 9: etcdataprocessor.v1.ValidationError
-	nil,                             // 10: etcdataprocessor.v1.HealthCheckResponse.DetailsEntry
+	(VehicleClass)(0),                    // 0: etcdataprocessor.v1.VehicleClass
+	(UsageDimension)(0),                  // 1: etcdataprocessor.v1.UsageDimension
+	(JournalFormat)(0),                   // 2: etcdataprocessor.v1.JournalFormat
+	(ReconciliationIssueKind)(0),         // 3: etcdataprocessor.v1.ReconciliationIssueKind
+	(ErrorCode)(0),                       // 4: etcdataprocessor.v1.ErrorCode
+	(DryRunAction)(0),                    // 5: etcdataprocessor.v1.DryRunAction
+	(ExportFormat)(0),                    // 6: etcdataprocessor.v1.ExportFormat
+	(*ProcessCSVFileRequest)(nil),        // 7: etcdataprocessor.v1.ProcessCSVFileRequest
+	(*ProcessCSVFileResponse)(nil),       // 8: etcdataprocessor.v1.ProcessCSVFileResponse
+	(*ProcessCSVDataRequest)(nil),        // 9: etcdataprocessor.v1.ProcessCSVDataRequest
+	(*ProcessCSVDataResponse)(nil),       // 10: etcdataprocessor.v1.ProcessCSVDataResponse
+	(*ValidateCSVDataRequest)(nil),       // 11: etcdataprocessor.v1.ValidateCSVDataRequest
+	(*ValidateCSVDataResponse)(nil),      // 12: etcdataprocessor.v1.ValidateCSVDataResponse
+	(*PreviewCSVRequest)(nil),            // 13: etcdataprocessor.v1.PreviewCSVRequest
+	(*PreviewCSVResponse)(nil),           // 14: etcdataprocessor.v1.PreviewCSVResponse
+	(*PreviewRecord)(nil),                // 15: etcdataprocessor.v1.PreviewRecord
+	(*ParsedRecord)(nil),                 // 16: etcdataprocessor.v1.ParsedRecord
+	(*ConvertedRecord)(nil),              // 17: etcdataprocessor.v1.ConvertedRecord
+	(*RouteSegment)(nil),                 // 18: etcdataprocessor.v1.RouteSegment
+	(*FieldMapping)(nil),                 // 19: etcdataprocessor.v1.FieldMapping
+	(*CardAssignment)(nil),               // 20: etcdataprocessor.v1.CardAssignment
+	(*CreateCardAssignmentRequest)(nil),  // 21: etcdataprocessor.v1.CreateCardAssignmentRequest
+	(*GetCardAssignmentRequest)(nil),     // 22: etcdataprocessor.v1.GetCardAssignmentRequest
+	(*ListCardAssignmentsRequest)(nil),   // 23: etcdataprocessor.v1.ListCardAssignmentsRequest
+	(*ListCardAssignmentsResponse)(nil),  // 24: etcdataprocessor.v1.ListCardAssignmentsResponse
+	(*UpdateCardAssignmentRequest)(nil),  // 25: etcdataprocessor.v1.UpdateCardAssignmentRequest
+	(*DeleteCardAssignmentRequest)(nil),  // 26: etcdataprocessor.v1.DeleteCardAssignmentRequest
+	(*DeleteCardAssignmentResponse)(nil), // 27: etcdataprocessor.v1.DeleteCardAssignmentResponse
+	(*GetUsageSummaryRequest)(nil),       // 28: etcdataprocessor.v1.GetUsageSummaryRequest
+	(*UsageSummary)(nil),                 // 29: etcdataprocessor.v1.UsageSummary
+	(*GetUsageSummaryResponse)(nil),      // 30: etcdataprocessor.v1.GetUsageSummaryResponse
+	(*ExportJournalRequest)(nil),         // 31: etcdataprocessor.v1.ExportJournalRequest
+	(*ExportJournalResponse)(nil),        // 32: etcdataprocessor.v1.ExportJournalResponse
+	(*ExpectedTotal)(nil),                // 33: etcdataprocessor.v1.ExpectedTotal
+	(*ReconcileStatementRequest)(nil),    // 34: etcdataprocessor.v1.ReconcileStatementRequest
+	(*ReconciliationIssue)(nil),          // 35: etcdataprocessor.v1.ReconciliationIssue
+	(*CardReconciliation)(nil),           // 36: etcdataprocessor.v1.CardReconciliation
+	(*ReconcileStatementResponse)(nil),   // 37: etcdataprocessor.v1.ReconcileStatementResponse
+	(*HealthCheckRequest)(nil),           // 38: etcdataprocessor.v1.HealthCheckRequest
+	(*HealthCheckResponse)(nil),          // 39: etcdataprocessor.v1.HealthCheckResponse
+	(*ProcessingStats)(nil),              // 40: etcdataprocessor.v1.ProcessingStats
+	(*FileResult)(nil),                   // 41: etcdataprocessor.v1.FileResult
+	(*RecordError)(nil),                  // 42: etcdataprocessor.v1.RecordError
+	(*DryRunRecord)(nil),                 // 43: etcdataprocessor.v1.DryRunRecord
+	(*Trip)(nil),                         // 44: etcdataprocessor.v1.Trip
+	(*AnomalyFinding)(nil),               // 45: etcdataprocessor.v1.AnomalyFinding
+	(*ListAnomaliesRequest)(nil),         // 46: etcdataprocessor.v1.ListAnomaliesRequest
+	(*ListAnomaliesResponse)(nil),        // 47: etcdataprocessor.v1.ListAnomaliesResponse
+	(*ExportRecordsRequest)(nil),         // 48: etcdataprocessor.v1.ExportRecordsRequest
+	(*ExportRecordsResponse)(nil),        // 49: etcdataprocessor.v1.ExportRecordsResponse
+	(*UnmatchedIC)(nil),                  // 50: etcdataprocessor.v1.UnmatchedIC
+	(*ValidationError)(nil),              // 51: etcdataprocessor.v1.ValidationError
+	nil,                                  // 52: etcdataprocessor.v1.HealthCheckResponse.DetailsEntry
+	(*structpb.Struct)(nil),              // 53: google.protobuf.Struct
 }
 var file_src_proto_data_processor_proto_depIdxs = []int32{
-	8,  // 0: etcdataprocessor.v1.ProcessCSVFileResponse.stats:type_name -> etcdataprocessor.v1.ProcessingStats
-	8,  // 1: etcdataprocessor.v1.ProcessCSVDataResponse.stats:type_name -> etcdataprocessor.v1.ProcessingStats
-	9,  // 2: etcdataprocessor.v1.ValidateCSVDataResponse.errors:type_name -> etcdataprocessor.v1.ValidationError
-	10, // 3: etcdataprocessor.v1.HealthCheckResponse.details:type_name -> etcdataprocessor.v1.HealthCheckResponse.DetailsEntry
-	0,  // 4: etcdataprocessor.v1.DataProcessorService.ProcessCSVFile:input_type -> etcdataprocessor.v1.ProcessCSVFileRequest
-	2,  // 5: etcdataprocessor.v1.DataProcessorService.ProcessCSVData:input_type -> etcdataprocessor.v1.ProcessCSVDataRequest
-	4,  // 6: etcdataprocessor.v1.DataProcessorService.ValidateCSVData:input_type -> etcdataprocessor.v1.ValidateCSVDataRequest
-	6,  // 7: etcdataprocessor.v1.DataProcessorService.HealthCheck:input_type -> etcdataprocessor.v1.HealthCheckRequest
-	1,  // 8: etcdataprocessor.v1.DataProcessorService.ProcessCSVFile:output_type -> etcdataprocessor.v1.ProcessCSVFileResponse
-	3,  // 9: etcdataprocessor.v1.DataProcessorService.ProcessCSVData:output_type -> etcdataprocessor.v1.ProcessCSVDataResponse
-	5,  // 10: etcdataprocessor.v1.DataProcessorService.ValidateCSVData:output_type -> etcdataprocessor.v1.ValidateCSVDataResponse
-	7,  // 11: etcdataprocessor.v1.DataProcessorService.HealthCheck:output_type -> etcdataprocessor.v1.HealthCheckResponse
-	8,  // [8:12] is the sub-list for method output_type
-	4,  // [4:8] is the sub-list for method input_type
-	4,  // [4:4] is the sub-list for extension type_name
-	4,  // [4:4] is the sub-list for extension extendee
-	0,  // [0:4] is the sub-list for field type_name
+	40, // 0: etcdataprocessor.v1.ProcessCSVFileResponse.stats:type_name -> etcdataprocessor.v1.ProcessingStats
+	41, // 1: etcdataprocessor.v1.ProcessCSVFileResponse.file_results:type_name -> etcdataprocessor.v1.FileResult
+	42, // 2: etcdataprocessor.v1.ProcessCSVFileResponse.record_errors:type_name -> etcdataprocessor.v1.RecordError
+	43, // 3: etcdataprocessor.v1.ProcessCSVFileResponse.dry_run_records:type_name -> etcdataprocessor.v1.DryRunRecord
+	50, // 4: etcdataprocessor.v1.ProcessCSVFileResponse.unmatched_ics:type_name -> etcdataprocessor.v1.UnmatchedIC
+	44, // 5: etcdataprocessor.v1.ProcessCSVFileResponse.trips:type_name -> etcdataprocessor.v1.Trip
+	45, // 6: etcdataprocessor.v1.ProcessCSVFileResponse.anomalies:type_name -> etcdataprocessor.v1.AnomalyFinding
+	40, // 7: etcdataprocessor.v1.ProcessCSVDataResponse.stats:type_name -> etcdataprocessor.v1.ProcessingStats
+	42, // 8: etcdataprocessor.v1.ProcessCSVDataResponse.record_errors:type_name -> etcdataprocessor.v1.RecordError
+	43, // 9: etcdataprocessor.v1.ProcessCSVDataResponse.dry_run_records:type_name -> etcdataprocessor.v1.DryRunRecord
+	50, // 10: etcdataprocessor.v1.ProcessCSVDataResponse.unmatched_ics:type_name -> etcdataprocessor.v1.UnmatchedIC
+	44, // 11: etcdataprocessor.v1.ProcessCSVDataResponse.trips:type_name -> etcdataprocessor.v1.Trip
+	45, // 12: etcdataprocessor.v1.ProcessCSVDataResponse.anomalies:type_name -> etcdataprocessor.v1.AnomalyFinding
+	51, // 13: etcdataprocessor.v1.ValidateCSVDataResponse.errors:type_name -> etcdataprocessor.v1.ValidationError
+	15, // 14: etcdataprocessor.v1.PreviewCSVResponse.records:type_name -> etcdataprocessor.v1.PreviewRecord
+	44, // 15: etcdataprocessor.v1.PreviewCSVResponse.trips:type_name -> etcdataprocessor.v1.Trip
+	16, // 16: etcdataprocessor.v1.PreviewRecord.parsed:type_name -> etcdataprocessor.v1.ParsedRecord
+	17, // 17: etcdataprocessor.v1.PreviewRecord.converted:type_name -> etcdataprocessor.v1.ConvertedRecord
+	19, // 18: etcdataprocessor.v1.PreviewRecord.mappings:type_name -> etcdataprocessor.v1.FieldMapping
+	0,  // 19: etcdataprocessor.v1.ParsedRecord.vehicle_class:type_name -> etcdataprocessor.v1.VehicleClass
+	0,  // 20: etcdataprocessor.v1.ConvertedRecord.vehicle_type:type_name -> etcdataprocessor.v1.VehicleClass
+	18, // 21: etcdataprocessor.v1.ConvertedRecord.route_segments:type_name -> etcdataprocessor.v1.RouteSegment
+	0,  // 22: etcdataprocessor.v1.CardAssignment.vehicle_class:type_name -> etcdataprocessor.v1.VehicleClass
+	20, // 23: etcdataprocessor.v1.CreateCardAssignmentRequest.assignment:type_name -> etcdataprocessor.v1.CardAssignment
+	20, // 24: etcdataprocessor.v1.ListCardAssignmentsResponse.assignments:type_name -> etcdataprocessor.v1.CardAssignment
+	20, // 25: etcdataprocessor.v1.UpdateCardAssignmentRequest.assignment:type_name -> etcdataprocessor.v1.CardAssignment
+	1,  // 26: etcdataprocessor.v1.GetUsageSummaryRequest.group_by:type_name -> etcdataprocessor.v1.UsageDimension
+	29, // 27: etcdataprocessor.v1.GetUsageSummaryResponse.groups:type_name -> etcdataprocessor.v1.UsageSummary
+	29, // 28: etcdataprocessor.v1.GetUsageSummaryResponse.total:type_name -> etcdataprocessor.v1.UsageSummary
+	2,  // 29: etcdataprocessor.v1.ExportJournalRequest.format:type_name -> etcdataprocessor.v1.JournalFormat
+	42, // 30: etcdataprocessor.v1.ExportJournalResponse.record_errors:type_name -> etcdataprocessor.v1.RecordError
+	33, // 31: etcdataprocessor.v1.ReconcileStatementRequest.expected:type_name -> etcdataprocessor.v1.ExpectedTotal
+	3,  // 32: etcdataprocessor.v1.ReconciliationIssue.kind:type_name -> etcdataprocessor.v1.ReconciliationIssueKind
+	35, // 33: etcdataprocessor.v1.CardReconciliation.issues:type_name -> etcdataprocessor.v1.ReconciliationIssue
+	36, // 34: etcdataprocessor.v1.ReconcileStatementResponse.results:type_name -> etcdataprocessor.v1.CardReconciliation
+	52, // 35: etcdataprocessor.v1.HealthCheckResponse.details:type_name -> etcdataprocessor.v1.HealthCheckResponse.DetailsEntry
+	40, // 36: etcdataprocessor.v1.FileResult.stats:type_name -> etcdataprocessor.v1.ProcessingStats
+	42, // 37: etcdataprocessor.v1.FileResult.record_errors:type_name -> etcdataprocessor.v1.RecordError
+	43, // 38: etcdataprocessor.v1.FileResult.dry_run_records:type_name -> etcdataprocessor.v1.DryRunRecord
+	44, // 39: etcdataprocessor.v1.FileResult.trips:type_name -> etcdataprocessor.v1.Trip
+	45, // 40: etcdataprocessor.v1.FileResult.anomalies:type_name -> etcdataprocessor.v1.AnomalyFinding
+	4,  // 41: etcdataprocessor.v1.RecordError.code:type_name -> etcdataprocessor.v1.ErrorCode
+	5,  // 42: etcdataprocessor.v1.DryRunRecord.action:type_name -> etcdataprocessor.v1.DryRunAction
+	4,  // 43: etcdataprocessor.v1.DryRunRecord.reason:type_name -> etcdataprocessor.v1.ErrorCode
+	53, // 44: etcdataprocessor.v1.DryRunRecord.payload:type_name -> google.protobuf.Struct
+	45, // 45: etcdataprocessor.v1.ListAnomaliesResponse.findings:type_name -> etcdataprocessor.v1.AnomalyFinding
+	6,  // 46: etcdataprocessor.v1.ExportRecordsRequest.format:type_name -> etcdataprocessor.v1.ExportFormat
+	42, // 47: etcdataprocessor.v1.ExportRecordsResponse.record_errors:type_name -> etcdataprocessor.v1.RecordError
+	7,  // 48: etcdataprocessor.v1.DataProcessorService.ProcessCSVFile:input_type -> etcdataprocessor.v1.ProcessCSVFileRequest
+	9,  // 49: etcdataprocessor.v1.DataProcessorService.ProcessCSVData:input_type -> etcdataprocessor.v1.ProcessCSVDataRequest
+	11, // 50: etcdataprocessor.v1.DataProcessorService.ValidateCSVData:input_type -> etcdataprocessor.v1.ValidateCSVDataRequest
+	13, // 51: etcdataprocessor.v1.DataProcessorService.PreviewCSV:input_type -> etcdataprocessor.v1.PreviewCSVRequest
+	21, // 52: etcdataprocessor.v1.DataProcessorService.CreateCardAssignment:input_type -> etcdataprocessor.v1.CreateCardAssignmentRequest
+	22, // 53: etcdataprocessor.v1.DataProcessorService.GetCardAssignment:input_type -> etcdataprocessor.v1.GetCardAssignmentRequest
+	23, // 54: etcdataprocessor.v1.DataProcessorService.ListCardAssignments:input_type -> etcdataprocessor.v1.ListCardAssignmentsRequest
+	25, // 55: etcdataprocessor.v1.DataProcessorService.UpdateCardAssignment:input_type -> etcdataprocessor.v1.UpdateCardAssignmentRequest
+	26, // 56: etcdataprocessor.v1.DataProcessorService.DeleteCardAssignment:input_type -> etcdataprocessor.v1.DeleteCardAssignmentRequest
+	28, // 57: etcdataprocessor.v1.DataProcessorService.GetUsageSummary:input_type -> etcdataprocessor.v1.GetUsageSummaryRequest
+	31, // 58: etcdataprocessor.v1.DataProcessorService.ExportJournal:input_type -> etcdataprocessor.v1.ExportJournalRequest
+	34, // 59: etcdataprocessor.v1.DataProcessorService.ReconcileStatement:input_type -> etcdataprocessor.v1.ReconcileStatementRequest
+	46, // 60: etcdataprocessor.v1.DataProcessorService.ListAnomalies:input_type -> etcdataprocessor.v1.ListAnomaliesRequest
+	48, // 61: etcdataprocessor.v1.DataProcessorService.ExportRecords:input_type -> etcdataprocessor.v1.ExportRecordsRequest
+	38, // 62: etcdataprocessor.v1.DataProcessorService.HealthCheck:input_type -> etcdataprocessor.v1.HealthCheckRequest
+	8,  // 63: etcdataprocessor.v1.DataProcessorService.ProcessCSVFile:output_type -> etcdataprocessor.v1.ProcessCSVFileResponse
+	10, // 64: etcdataprocessor.v1.DataProcessorService.ProcessCSVData:output_type -> etcdataprocessor.v1.ProcessCSVDataResponse
+	12, // 65: etcdataprocessor.v1.DataProcessorService.ValidateCSVData:output_type -> etcdataprocessor.v1.ValidateCSVDataResponse
+	14, // 66: etcdataprocessor.v1.DataProcessorService.PreviewCSV:output_type -> etcdataprocessor.v1.PreviewCSVResponse
+	20, // 67: etcdataprocessor.v1.DataProcessorService.CreateCardAssignment:output_type -> etcdataprocessor.v1.CardAssignment
+	20, // 68: etcdataprocessor.v1.DataProcessorService.GetCardAssignment:output_type -> etcdataprocessor.v1.CardAssignment
+	24, // 69: etcdataprocessor.v1.DataProcessorService.ListCardAssignments:output_type -> etcdataprocessor.v1.ListCardAssignmentsResponse
+	20, // 70: etcdataprocessor.v1.DataProcessorService.UpdateCardAssignment:output_type -> etcdataprocessor.v1.CardAssignment
+	27, // 71: etcdataprocessor.v1.DataProcessorService.DeleteCardAssignment:output_type -> etcdataprocessor.v1.DeleteCardAssignmentResponse
+	30, // 72: etcdataprocessor.v1.DataProcessorService.GetUsageSummary:output_type -> etcdataprocessor.v1.GetUsageSummaryResponse
+	32, // 73: etcdataprocessor.v1.DataProcessorService.ExportJournal:output_type -> etcdataprocessor.v1.ExportJournalResponse
+	37, // 74: etcdataprocessor.v1.DataProcessorService.ReconcileStatement:output_type -> etcdataprocessor.v1.ReconcileStatementResponse
+	47, // 75: etcdataprocessor.v1.DataProcessorService.ListAnomalies:output_type -> etcdataprocessor.v1.ListAnomaliesResponse
+	49, // 76: etcdataprocessor.v1.DataProcessorService.ExportRecords:output_type -> etcdataprocessor.v1.ExportRecordsResponse
+	39, // 77: etcdataprocessor.v1.DataProcessorService.HealthCheck:output_type -> etcdataprocessor.v1.HealthCheckResponse
+	63, // [63:78] is the sub-list for method output_type
+	48, // [48:63] is the sub-list for method input_type
+	48, // [48:48] is the sub-list for extension type_name
+	48, // [48:48] is the sub-list for extension extendee
+	0,  // [0:48] is the sub-list for field type_name
 }
 
 func init() { file_src_proto_data_processor_proto_init() }
@@ -760,18 +4861,23 @@ func file_src_proto_data_processor_proto_init() {
 	file_src_proto_data_processor_proto_msgTypes[0].OneofWrappers = []any{}
 	file_src_proto_data_processor_proto_msgTypes[2].OneofWrappers = []any{}
 	file_src_proto_data_processor_proto_msgTypes[4].OneofWrappers = []any{}
+	file_src_proto_data_processor_proto_msgTypes[6].OneofWrappers = []any{}
+	file_src_proto_data_processor_proto_msgTypes[24].OneofWrappers = []any{}
+	file_src_proto_data_processor_proto_msgTypes[27].OneofWrappers = []any{}
+	file_src_proto_data_processor_proto_msgTypes[41].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_src_proto_data_processor_proto_rawDesc), len(file_src_proto_data_processor_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   11,
+			NumEnums:      7,
+			NumMessages:   46,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_src_proto_data_processor_proto_goTypes,
 		DependencyIndexes: file_src_proto_data_processor_proto_depIdxs,
+		EnumInfos:         file_src_proto_data_processor_proto_enumTypes,
 		MessageInfos:      file_src_proto_data_processor_proto_msgTypes,
 	}.Build()
 	File_src_proto_data_processor_proto = out.File
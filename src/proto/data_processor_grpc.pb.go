@@ -19,10 +19,21 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	DataProcessorService_ProcessCSVFile_FullMethodName  = "/etcdataprocessor.v1.DataProcessorService/ProcessCSVFile"
-	DataProcessorService_ProcessCSVData_FullMethodName  = "/etcdataprocessor.v1.DataProcessorService/ProcessCSVData"
-	DataProcessorService_ValidateCSVData_FullMethodName = "/etcdataprocessor.v1.DataProcessorService/ValidateCSVData"
-	DataProcessorService_HealthCheck_FullMethodName     = "/etcdataprocessor.v1.DataProcessorService/HealthCheck"
+	DataProcessorService_ProcessCSVFile_FullMethodName       = "/etcdataprocessor.v1.DataProcessorService/ProcessCSVFile"
+	DataProcessorService_ProcessCSVData_FullMethodName       = "/etcdataprocessor.v1.DataProcessorService/ProcessCSVData"
+	DataProcessorService_ValidateCSVData_FullMethodName      = "/etcdataprocessor.v1.DataProcessorService/ValidateCSVData"
+	DataProcessorService_PreviewCSV_FullMethodName           = "/etcdataprocessor.v1.DataProcessorService/PreviewCSV"
+	DataProcessorService_CreateCardAssignment_FullMethodName = "/etcdataprocessor.v1.DataProcessorService/CreateCardAssignment"
+	DataProcessorService_GetCardAssignment_FullMethodName    = "/etcdataprocessor.v1.DataProcessorService/GetCardAssignment"
+	DataProcessorService_ListCardAssignments_FullMethodName  = "/etcdataprocessor.v1.DataProcessorService/ListCardAssignments"
+	DataProcessorService_UpdateCardAssignment_FullMethodName = "/etcdataprocessor.v1.DataProcessorService/UpdateCardAssignment"
+	DataProcessorService_DeleteCardAssignment_FullMethodName = "/etcdataprocessor.v1.DataProcessorService/DeleteCardAssignment"
+	DataProcessorService_GetUsageSummary_FullMethodName      = "/etcdataprocessor.v1.DataProcessorService/GetUsageSummary"
+	DataProcessorService_ExportJournal_FullMethodName        = "/etcdataprocessor.v1.DataProcessorService/ExportJournal"
+	DataProcessorService_ReconcileStatement_FullMethodName   = "/etcdataprocessor.v1.DataProcessorService/ReconcileStatement"
+	DataProcessorService_ListAnomalies_FullMethodName        = "/etcdataprocessor.v1.DataProcessorService/ListAnomalies"
+	DataProcessorService_ExportRecords_FullMethodName        = "/etcdataprocessor.v1.DataProcessorService/ExportRecords"
+	DataProcessorService_HealthCheck_FullMethodName          = "/etcdataprocessor.v1.DataProcessorService/HealthCheck"
 )
 
 // DataProcessorServiceClient is the client API for DataProcessorService service.
@@ -32,6 +43,17 @@ type DataProcessorServiceClient interface {
 	ProcessCSVFile(ctx context.Context, in *ProcessCSVFileRequest, opts ...grpc.CallOption) (*ProcessCSVFileResponse, error)
 	ProcessCSVData(ctx context.Context, in *ProcessCSVDataRequest, opts ...grpc.CallOption) (*ProcessCSVDataResponse, error)
 	ValidateCSVData(ctx context.Context, in *ValidateCSVDataRequest, opts ...grpc.CallOption) (*ValidateCSVDataResponse, error)
+	PreviewCSV(ctx context.Context, in *PreviewCSVRequest, opts ...grpc.CallOption) (*PreviewCSVResponse, error)
+	CreateCardAssignment(ctx context.Context, in *CreateCardAssignmentRequest, opts ...grpc.CallOption) (*CardAssignment, error)
+	GetCardAssignment(ctx context.Context, in *GetCardAssignmentRequest, opts ...grpc.CallOption) (*CardAssignment, error)
+	ListCardAssignments(ctx context.Context, in *ListCardAssignmentsRequest, opts ...grpc.CallOption) (*ListCardAssignmentsResponse, error)
+	UpdateCardAssignment(ctx context.Context, in *UpdateCardAssignmentRequest, opts ...grpc.CallOption) (*CardAssignment, error)
+	DeleteCardAssignment(ctx context.Context, in *DeleteCardAssignmentRequest, opts ...grpc.CallOption) (*DeleteCardAssignmentResponse, error)
+	GetUsageSummary(ctx context.Context, in *GetUsageSummaryRequest, opts ...grpc.CallOption) (*GetUsageSummaryResponse, error)
+	ExportJournal(ctx context.Context, in *ExportJournalRequest, opts ...grpc.CallOption) (*ExportJournalResponse, error)
+	ReconcileStatement(ctx context.Context, in *ReconcileStatementRequest, opts ...grpc.CallOption) (*ReconcileStatementResponse, error)
+	ListAnomalies(ctx context.Context, in *ListAnomaliesRequest, opts ...grpc.CallOption) (*ListAnomaliesResponse, error)
+	ExportRecords(ctx context.Context, in *ExportRecordsRequest, opts ...grpc.CallOption) (*ExportRecordsResponse, error)
 	HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
 }
 
@@ -73,6 +95,116 @@ func (c *dataProcessorServiceClient) ValidateCSVData(ctx context.Context, in *Va
 	return out, nil
 }
 
+func (c *dataProcessorServiceClient) PreviewCSV(ctx context.Context, in *PreviewCSVRequest, opts ...grpc.CallOption) (*PreviewCSVResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PreviewCSVResponse)
+	err := c.cc.Invoke(ctx, DataProcessorService_PreviewCSV_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataProcessorServiceClient) CreateCardAssignment(ctx context.Context, in *CreateCardAssignmentRequest, opts ...grpc.CallOption) (*CardAssignment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CardAssignment)
+	err := c.cc.Invoke(ctx, DataProcessorService_CreateCardAssignment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataProcessorServiceClient) GetCardAssignment(ctx context.Context, in *GetCardAssignmentRequest, opts ...grpc.CallOption) (*CardAssignment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CardAssignment)
+	err := c.cc.Invoke(ctx, DataProcessorService_GetCardAssignment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataProcessorServiceClient) ListCardAssignments(ctx context.Context, in *ListCardAssignmentsRequest, opts ...grpc.CallOption) (*ListCardAssignmentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCardAssignmentsResponse)
+	err := c.cc.Invoke(ctx, DataProcessorService_ListCardAssignments_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataProcessorServiceClient) UpdateCardAssignment(ctx context.Context, in *UpdateCardAssignmentRequest, opts ...grpc.CallOption) (*CardAssignment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CardAssignment)
+	err := c.cc.Invoke(ctx, DataProcessorService_UpdateCardAssignment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataProcessorServiceClient) DeleteCardAssignment(ctx context.Context, in *DeleteCardAssignmentRequest, opts ...grpc.CallOption) (*DeleteCardAssignmentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteCardAssignmentResponse)
+	err := c.cc.Invoke(ctx, DataProcessorService_DeleteCardAssignment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataProcessorServiceClient) GetUsageSummary(ctx context.Context, in *GetUsageSummaryRequest, opts ...grpc.CallOption) (*GetUsageSummaryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUsageSummaryResponse)
+	err := c.cc.Invoke(ctx, DataProcessorService_GetUsageSummary_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataProcessorServiceClient) ExportJournal(ctx context.Context, in *ExportJournalRequest, opts ...grpc.CallOption) (*ExportJournalResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExportJournalResponse)
+	err := c.cc.Invoke(ctx, DataProcessorService_ExportJournal_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataProcessorServiceClient) ReconcileStatement(ctx context.Context, in *ReconcileStatementRequest, opts ...grpc.CallOption) (*ReconcileStatementResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReconcileStatementResponse)
+	err := c.cc.Invoke(ctx, DataProcessorService_ReconcileStatement_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataProcessorServiceClient) ListAnomalies(ctx context.Context, in *ListAnomaliesRequest, opts ...grpc.CallOption) (*ListAnomaliesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAnomaliesResponse)
+	err := c.cc.Invoke(ctx, DataProcessorService_ListAnomalies_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataProcessorServiceClient) ExportRecords(ctx context.Context, in *ExportRecordsRequest, opts ...grpc.CallOption) (*ExportRecordsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExportRecordsResponse)
+	err := c.cc.Invoke(ctx, DataProcessorService_ExportRecords_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *dataProcessorServiceClient) HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(HealthCheckResponse)
@@ -90,6 +222,17 @@ type DataProcessorServiceServer interface {
 	ProcessCSVFile(context.Context, *ProcessCSVFileRequest) (*ProcessCSVFileResponse, error)
 	ProcessCSVData(context.Context, *ProcessCSVDataRequest) (*ProcessCSVDataResponse, error)
 	ValidateCSVData(context.Context, *ValidateCSVDataRequest) (*ValidateCSVDataResponse, error)
+	PreviewCSV(context.Context, *PreviewCSVRequest) (*PreviewCSVResponse, error)
+	CreateCardAssignment(context.Context, *CreateCardAssignmentRequest) (*CardAssignment, error)
+	GetCardAssignment(context.Context, *GetCardAssignmentRequest) (*CardAssignment, error)
+	ListCardAssignments(context.Context, *ListCardAssignmentsRequest) (*ListCardAssignmentsResponse, error)
+	UpdateCardAssignment(context.Context, *UpdateCardAssignmentRequest) (*CardAssignment, error)
+	DeleteCardAssignment(context.Context, *DeleteCardAssignmentRequest) (*DeleteCardAssignmentResponse, error)
+	GetUsageSummary(context.Context, *GetUsageSummaryRequest) (*GetUsageSummaryResponse, error)
+	ExportJournal(context.Context, *ExportJournalRequest) (*ExportJournalResponse, error)
+	ReconcileStatement(context.Context, *ReconcileStatementRequest) (*ReconcileStatementResponse, error)
+	ListAnomalies(context.Context, *ListAnomaliesRequest) (*ListAnomaliesResponse, error)
+	ExportRecords(context.Context, *ExportRecordsRequest) (*ExportRecordsResponse, error)
 	HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
 	mustEmbedUnimplementedDataProcessorServiceServer()
 }
@@ -110,6 +253,39 @@ func (UnimplementedDataProcessorServiceServer) ProcessCSVData(context.Context, *
 func (UnimplementedDataProcessorServiceServer) ValidateCSVData(context.Context, *ValidateCSVDataRequest) (*ValidateCSVDataResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ValidateCSVData not implemented")
 }
+func (UnimplementedDataProcessorServiceServer) PreviewCSV(context.Context, *PreviewCSVRequest) (*PreviewCSVResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PreviewCSV not implemented")
+}
+func (UnimplementedDataProcessorServiceServer) CreateCardAssignment(context.Context, *CreateCardAssignmentRequest) (*CardAssignment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCardAssignment not implemented")
+}
+func (UnimplementedDataProcessorServiceServer) GetCardAssignment(context.Context, *GetCardAssignmentRequest) (*CardAssignment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCardAssignment not implemented")
+}
+func (UnimplementedDataProcessorServiceServer) ListCardAssignments(context.Context, *ListCardAssignmentsRequest) (*ListCardAssignmentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCardAssignments not implemented")
+}
+func (UnimplementedDataProcessorServiceServer) UpdateCardAssignment(context.Context, *UpdateCardAssignmentRequest) (*CardAssignment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCardAssignment not implemented")
+}
+func (UnimplementedDataProcessorServiceServer) DeleteCardAssignment(context.Context, *DeleteCardAssignmentRequest) (*DeleteCardAssignmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCardAssignment not implemented")
+}
+func (UnimplementedDataProcessorServiceServer) GetUsageSummary(context.Context, *GetUsageSummaryRequest) (*GetUsageSummaryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUsageSummary not implemented")
+}
+func (UnimplementedDataProcessorServiceServer) ExportJournal(context.Context, *ExportJournalRequest) (*ExportJournalResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportJournal not implemented")
+}
+func (UnimplementedDataProcessorServiceServer) ReconcileStatement(context.Context, *ReconcileStatementRequest) (*ReconcileStatementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReconcileStatement not implemented")
+}
+func (UnimplementedDataProcessorServiceServer) ListAnomalies(context.Context, *ListAnomaliesRequest) (*ListAnomaliesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAnomalies not implemented")
+}
+func (UnimplementedDataProcessorServiceServer) ExportRecords(context.Context, *ExportRecordsRequest) (*ExportRecordsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportRecords not implemented")
+}
 func (UnimplementedDataProcessorServiceServer) HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HealthCheck not implemented")
 }
@@ -188,6 +364,204 @@ func _DataProcessorService_ValidateCSVData_Handler(srv interface{}, ctx context.
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DataProcessorService_PreviewCSV_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PreviewCSVRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataProcessorServiceServer).PreviewCSV(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataProcessorService_PreviewCSV_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataProcessorServiceServer).PreviewCSV(ctx, req.(*PreviewCSVRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataProcessorService_CreateCardAssignment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCardAssignmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataProcessorServiceServer).CreateCardAssignment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataProcessorService_CreateCardAssignment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataProcessorServiceServer).CreateCardAssignment(ctx, req.(*CreateCardAssignmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataProcessorService_GetCardAssignment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCardAssignmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataProcessorServiceServer).GetCardAssignment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataProcessorService_GetCardAssignment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataProcessorServiceServer).GetCardAssignment(ctx, req.(*GetCardAssignmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataProcessorService_ListCardAssignments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCardAssignmentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataProcessorServiceServer).ListCardAssignments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataProcessorService_ListCardAssignments_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataProcessorServiceServer).ListCardAssignments(ctx, req.(*ListCardAssignmentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataProcessorService_UpdateCardAssignment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCardAssignmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataProcessorServiceServer).UpdateCardAssignment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataProcessorService_UpdateCardAssignment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataProcessorServiceServer).UpdateCardAssignment(ctx, req.(*UpdateCardAssignmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataProcessorService_DeleteCardAssignment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteCardAssignmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataProcessorServiceServer).DeleteCardAssignment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataProcessorService_DeleteCardAssignment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataProcessorServiceServer).DeleteCardAssignment(ctx, req.(*DeleteCardAssignmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataProcessorService_GetUsageSummary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUsageSummaryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataProcessorServiceServer).GetUsageSummary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataProcessorService_GetUsageSummary_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataProcessorServiceServer).GetUsageSummary(ctx, req.(*GetUsageSummaryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataProcessorService_ExportJournal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportJournalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataProcessorServiceServer).ExportJournal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataProcessorService_ExportJournal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataProcessorServiceServer).ExportJournal(ctx, req.(*ExportJournalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataProcessorService_ReconcileStatement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReconcileStatementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataProcessorServiceServer).ReconcileStatement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataProcessorService_ReconcileStatement_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataProcessorServiceServer).ReconcileStatement(ctx, req.(*ReconcileStatementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataProcessorService_ListAnomalies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAnomaliesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataProcessorServiceServer).ListAnomalies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataProcessorService_ListAnomalies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataProcessorServiceServer).ListAnomalies(ctx, req.(*ListAnomaliesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataProcessorService_ExportRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportRecordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataProcessorServiceServer).ExportRecords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataProcessorService_ExportRecords_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataProcessorServiceServer).ExportRecords(ctx, req.(*ExportRecordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DataProcessorService_HealthCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(HealthCheckRequest)
 	if err := dec(in); err != nil {
@@ -225,6 +599,50 @@ var DataProcessorService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ValidateCSVData",
 			Handler:    _DataProcessorService_ValidateCSVData_Handler,
 		},
+		{
+			MethodName: "PreviewCSV",
+			Handler:    _DataProcessorService_PreviewCSV_Handler,
+		},
+		{
+			MethodName: "CreateCardAssignment",
+			Handler:    _DataProcessorService_CreateCardAssignment_Handler,
+		},
+		{
+			MethodName: "GetCardAssignment",
+			Handler:    _DataProcessorService_GetCardAssignment_Handler,
+		},
+		{
+			MethodName: "ListCardAssignments",
+			Handler:    _DataProcessorService_ListCardAssignments_Handler,
+		},
+		{
+			MethodName: "UpdateCardAssignment",
+			Handler:    _DataProcessorService_UpdateCardAssignment_Handler,
+		},
+		{
+			MethodName: "DeleteCardAssignment",
+			Handler:    _DataProcessorService_DeleteCardAssignment_Handler,
+		},
+		{
+			MethodName: "GetUsageSummary",
+			Handler:    _DataProcessorService_GetUsageSummary_Handler,
+		},
+		{
+			MethodName: "ExportJournal",
+			Handler:    _DataProcessorService_ExportJournal_Handler,
+		},
+		{
+			MethodName: "ReconcileStatement",
+			Handler:    _DataProcessorService_ReconcileStatement_Handler,
+		},
+		{
+			MethodName: "ListAnomalies",
+			Handler:    _DataProcessorService_ListAnomalies_Handler,
+		},
+		{
+			MethodName: "ExportRecords",
+			Handler:    _DataProcessorService_ExportRecords_Handler,
+		},
 		{
 			MethodName: "HealthCheck",
 			Handler:    _DataProcessorService_HealthCheck_Handler,
@@ -67,7 +67,7 @@ func TestParseActualETCFiles(t *testing.T) {
 					}
 
 					// Verify converted record
-					if simple.Amount < 0 {
+					if simple.Amount < 0 && !simple.IsReversal() {
 						t.Errorf("Negative amount in converted record: %d", simple.Amount)
 					}
 
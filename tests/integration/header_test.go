@@ -86,4 +86,33 @@ func TestDifferentHeaderFormats(t *testing.T) {
 	}
 
 	t.Log("Parser created successfully with header mapping support")
-}
\ No newline at end of file
+}
+func TestHeaderBasedParsing_RedemptionBaseAmount(t *testing.T) {
+	records, err := parser.NewETCCSVParser().ParseFile(filepath.Join("../file", "202509282007.csv"))
+	if err != nil {
+		t.Fatalf("Failed to parse 202509282007.csv: %v", err)
+	}
+
+	// Line 38: 25/09/08 筑後小郡 -> 五霞 with 還元額適用料金 5000 (a yen amount, not mileage points)
+	var found bool
+	for _, record := range records {
+		if record.LineNumber != 38 {
+			continue
+		}
+		found = true
+		if record.RedemptionBaseAmount != 5000 || record.Mileage != 0 || record.ETCAmount != 29130 || record.PostPaymentAmount != 24130 {
+			t.Errorf("Expected redemption base 5000 and no mileage (ETC 29130, post-payment 24130), got %d, %d (%d, %d)",
+				record.RedemptionBaseAmount, record.Mileage, record.ETCAmount, record.PostPaymentAmount)
+		}
+		simple, err := parser.NewETCCSVParser().ConvertToSimpleRecord(record)
+		if err != nil {
+			t.Fatalf("Unexpected conversion error: %v", err)
+		}
+		if simple.RedemptionBaseAmount != 5000 || simple.Mileage != 0 {
+			t.Errorf("Expected redemption base 5000 and no mileage after conversion, got %d, %d", simple.RedemptionBaseAmount, simple.Mileage)
+		}
+	}
+	if !found {
+		t.Fatal("Line 38 was not parsed")
+	}
+}
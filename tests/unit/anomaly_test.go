@@ -0,0 +1,356 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/anomaly"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/masterdata"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/usage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const anomalyCSV = `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,0,1500,1,1234,********12345678,
+25/09/01,08:30,25/09/01,09:30,大阪,京都,1000,0,1000,1,1234,********12345678,
+25/09/02,10:00,25/09/02,09:00,東京,横浜,1500,0,1500,1,1234,********12345678,`
+
+func TestImpossibleTime_JSTMidnight(t *testing.T) {
+	// 2025-09-01 23:30 JST; statement times are JST wall-clock values
+	now := time.Date(2025, 9, 1, 14, 30, 0, 0, time.UTC)
+	rule := anomaly.ImpossibleTime{Now: func() time.Time { return now }}
+	trips := []anomaly.Trip{
+		{Index: 0, Entry: at("2025-09-01 21:00"), Exit: at("2025-09-01 22:00")},
+		{Index: 1, Entry: at("2025-09-01 23:00"), Exit: at("2025-09-02 00:10")},
+	}
+
+	findings := rule.Check(trips)
+	if len(findings) != 1 || findings[0].Trip.Index != 1 {
+		t.Errorf("Expected only the trip ending after 23:30 JST to be in the future, got %+v", findings)
+	}
+}
+
+func TestImpossibleTime(t *testing.T) {
+	rule := anomaly.ImpossibleTime{Now: func() time.Time { return at("2025-10-01 00:00") }}
+	trips := []anomaly.Trip{
+		{Index: 0, Entry: at("2025-09-01 08:00"), Exit: at("2025-09-01 09:00")},
+		{Index: 1, Entry: at("2025-09-01 10:00"), Exit: at("2025-09-01 09:00")},
+		{Index: 2, Entry: at("2025-09-01 08:00"), Exit: at("2025-09-03 08:00")},
+		{Index: 3, Entry: at("2025-10-02 08:00"), Exit: at("2025-10-02 09:00")},
+		{Index: 4, Exit: at("2025-09-01 09:00")},
+	}
+
+	findings := rule.Check(trips)
+	if len(findings) != 3 {
+		t.Fatalf("Expected 3 findings, got %+v", findings)
+	}
+	for i, want := range []string{"before entry", "longer than", "in the future"} {
+		if findings[i].Trip.Index != i+1 || !strings.Contains(findings[i].Message, want) {
+			t.Errorf("Expected trip %d flagged as %q, got %+v", i+1, want, findings[i])
+		}
+	}
+}
+
+func TestCardOverlap(t *testing.T) {
+	trips := []anomaly.Trip{
+		{Index: 0, LineNumber: 2, CardNumber: "********12345678", EntryIC: "東京", ExitIC: "横浜", Entry: at("2025-09-01 08:00"), Exit: at("2025-09-01 09:00")},
+		{Index: 1, LineNumber: 3, CardNumber: "********12345678", EntryIC: "大阪", ExitIC: "京都", Entry: at("2025-09-01 08:30"), Exit: at("2025-09-01 09:30")},
+		// Same card on consecutive legs, another card at the same time, and a reversal
+		{Index: 2, LineNumber: 4, CardNumber: "********12345678", EntryIC: "京都", ExitIC: "大津", Entry: at("2025-09-01 09:30"), Exit: at("2025-09-01 10:00")},
+		{Index: 3, LineNumber: 5, CardNumber: "********87654321", EntryIC: "名古屋", ExitIC: "岐阜", Entry: at("2025-09-01 08:15"), Exit: at("2025-09-01 09:00")},
+		{Index: 4, LineNumber: 6, CardNumber: "********12345678", EntryIC: "東京", ExitIC: "横浜", Entry: at("2025-09-01 08:10"), Exit: at("2025-09-01 08:50"), Reversal: true},
+	}
+
+	findings := anomaly.CardOverlap{}.Check(trips)
+	if len(findings) != 1 || findings[0].Trip.Index != 1 || findings[0].RelatedLine != 2 {
+		t.Errorf("Expected only the Osaka trip to overlap line 2, got %+v", findings)
+	}
+}
+
+func TestVehicleClassMismatch(t *testing.T) {
+	trips := []anomaly.Trip{
+		{Index: 0, VehicleID: "V1", VehicleClass: parser.VehicleClassMedium, RegisteredClass: parser.VehicleClassStandard},
+		{Index: 1, VehicleID: "V1", VehicleClass: parser.VehicleClassStandard, RegisteredClass: parser.VehicleClassStandard},
+		{Index: 2, VehicleID: "V2", VehicleClass: parser.VehicleClassLarge},
+	}
+
+	findings := anomaly.VehicleClassMismatch{}.Check(trips)
+	if len(findings) != 1 || findings[0].Trip.Index != 0 || !strings.Contains(findings[0].Message, "中型車") {
+		t.Errorf("Expected the medium-class trip of V1 flagged, got %+v", findings)
+	}
+}
+
+func TestAmountOutlier(t *testing.T) {
+	rule := anomaly.NewAmountOutlier(3, 2)
+	trip := anomaly.Trip{EntryIC: "東京", ExitIC: "横浜", VehicleClass: parser.VehicleClassStandard, Amount: 5000}
+
+	if findings := rule.Check([]anomaly.Trip{trip}); len(findings) != 0 {
+		t.Errorf("Expected no findings without history, got %+v", findings)
+	}
+
+	rule.Learn([]anomaly.Trip{
+		{EntryIC: "東京", ExitIC: "横浜", VehicleClass: parser.VehicleClassStandard, Amount: 1000},
+		{EntryIC: "東京", ExitIC: "横浜", VehicleClass: parser.VehicleClassStandard, Amount: 1400},
+		{EntryIC: "東京", ExitIC: "横浜", VehicleClass: parser.VehicleClassStandard, Amount: -1400, Reversal: true},
+	})
+	if findings := rule.Check([]anomaly.Trip{trip}); len(findings) != 1 || findings[0].Rule != anomaly.RuleAmountOutlier {
+		t.Errorf("Expected 5000 yen flagged against a 1200 yen average, got %+v", findings)
+	}
+
+	// Another vehicle class has its own history
+	trip.VehicleClass = parser.VehicleClassLarge
+	if findings := rule.Check([]anomaly.Trip{trip}); len(findings) != 0 {
+		t.Errorf("Expected no findings for a class without history, got %+v", findings)
+	}
+}
+
+func TestNewDetectorFromConfig(t *testing.T) {
+	detector, err := anomaly.NewDetectorFromConfig(anomaly.Config{Rules: []string{anomaly.RuleCardOverlap, anomaly.RuleCardOverlap}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if names := detector.Rules(); len(names) != 1 || names[0] != anomaly.RuleCardOverlap {
+		t.Errorf("Expected only the listed rule, got %v", names)
+	}
+
+	if detector, _ := anomaly.NewDetectorFromConfig(anomaly.Config{}); len(detector.Rules()) != 4 {
+		t.Errorf("Expected all built-in rules by default, got %v", detector.Rules())
+	}
+
+	for _, cfg := range []anomaly.Config{
+		{Rules: []string{"speeding"}},
+		{AmountFactor: 0.5},
+		{MaxTripHours: -1},
+	} {
+		if _, err := anomaly.NewDetectorFromConfig(cfg); err == nil {
+			t.Errorf("Expected error for %+v", cfg)
+		}
+	}
+}
+
+func TestAnomalyMemoryStore(t *testing.T) {
+	store := anomaly.NewMemoryStore()
+	finding := anomaly.Finding{Rule: anomaly.RuleCardOverlap, AccountID: "acc-1",
+		Trip: anomaly.Trip{Row: "a", Date: mustDate("2025-09-01"), CardNumber: "********12345678"}}
+	store.Add(finding, finding)
+	store.Add(anomaly.Finding{Rule: anomaly.RuleImpossibleTime, AccountID: "acc-2",
+		Trip: anomaly.Trip{Row: "b", Date: mustDate("2025-09-20"), CardNumber: "********87654321"}})
+
+	if all := store.List(anomaly.Filter{}); len(all) != 2 {
+		t.Errorf("Expected the same finding to be kept once, got %+v", all)
+	}
+	if byCard := store.List(anomaly.Filter{CardNumber: "4111222212345678"}); len(byCard) != 1 || byCard[0].Trip.Row != "a" {
+		t.Errorf("Expected card filter to match masked numbers, got %+v", byCard)
+	}
+	if byDate := store.List(anomaly.Filter{From: mustDate("2025-09-10"), Rule: anomaly.RuleImpossibleTime}); len(byDate) != 1 {
+		t.Errorf("Expected date and rule filters, got %+v", byDate)
+	}
+}
+
+func TestAnomalyFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "anomalies.jsonl")
+	store, err := anomaly.OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	finding := anomaly.Finding{Rule: anomaly.RuleCardOverlap, AccountID: "acc-1", Message: "first", RelatedLine: 2,
+		Trip: anomaly.Trip{Row: "a", LineNumber: 3, Date: mustDate("2025-09-01"), CardNumber: "********12345678", VehicleClass: parser.VehicleClassStandard}}
+	if err := store.Add(finding); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	finding.Message = "again"
+	if err := store.Add(finding, anomaly.Finding{Rule: anomaly.RuleImpossibleTime, AccountID: "acc-1",
+		Trip: anomaly.Trip{Row: "b", Date: mustDate("2025-09-02")}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Reopening keeps the latest finding per rule and row, and drops the replaced line from the file
+	reopened, err := anomaly.OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	list := reopened.List(anomaly.Filter{AccountID: "acc-1"})
+	if len(list) != 2 || list[0].Message != "again" || list[0].RelatedLine != 2 || list[0].Trip.LineNumber != 3 ||
+		list[0].Trip.VehicleClass != parser.VehicleClassStandard || !list[0].Trip.Date.Equal(mustDate("2025-09-01")) {
+		t.Errorf("Unexpected findings after reopening: %+v", list)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Errorf("Expected the file to be compacted to 2 lines, got %d", lines)
+	}
+
+	if err := os.WriteFile(path, []byte("not json\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := anomaly.OpenFileStore(path); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Expected a parse error for line 1, got %v", err)
+	}
+}
+
+func TestProcessCSVFile_AnomaliesAcrossFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"a.csv": `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,0,1500,1,1234,********12345678,`,
+		"b.csv": `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:30,25/09/01,09:30,大阪,京都,1000,0,1000,1,1234,********12345678,`,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	service := handler.NewDataProcessorService(&mockDBClient{})
+	service.SetAnomalyDetector(anomaly.NewDetector(anomaly.CardOverlap{}))
+
+	resp, err := service.ProcessCSVFile(context.Background(), &pb.ProcessCSVFileRequest{CsvFilePath: strPtr(tmpDir), AccountId: strPtr("acc-1")})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The overlapping trips are in different files of the same request
+	if len(resp.Anomalies) != 1 {
+		t.Fatalf("Expected one overlap finding across files, got %v", resp.Anomalies)
+	}
+	overlap := resp.Anomalies[0]
+	if overlap.Rule != anomaly.RuleCardOverlap || filepath.Base(overlap.FilePath) != "b.csv" || overlap.RecordIndex != 1 || overlap.LineNumber != 2 {
+		t.Errorf("Unexpected overlap finding: %v", overlap)
+	}
+	if resp.FileResults[0].Stats.AnomalyRecords != 0 || resp.FileResults[1].Stats.AnomalyRecords != 1 {
+		t.Errorf("Expected the finding in the second file's result, got %v / %v", resp.FileResults[0].Stats, resp.FileResults[1].Stats)
+	}
+}
+
+func TestSetAnomalyDetector_SeedsHistoryFromUsage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.jsonl")
+	store, err := usage.OpenFileStore(path, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	service := handler.NewDataProcessorService(&mockDBClient{})
+	service.SetUsageStore(store)
+	if _, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData: `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,0,1500,1,1234,********12345678,
+25/09/02,08:00,25/09/02,09:00,東京,横浜,1500,0,1500,1,1234,********12345678,`,
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// After a restart the amount history comes back from the usage file
+	reopened, err := usage.OpenFileStore(path, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	restarted := handler.NewDataProcessorService(&mockDBClient{})
+	restarted.SetUsageStore(reopened)
+	restarted.SetAnomalyDetector(anomaly.NewDetector(anomaly.NewAmountOutlier(3, 2)))
+
+	resp, err := restarted.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData: `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/03,08:00,25/09/03,09:00,東京,横浜,6000,0,6000,1,1234,********12345678,`,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resp.Anomalies) != 1 || resp.Anomalies[0].Rule != anomaly.RuleAmountOutlier {
+		t.Errorf("Expected the amount outlier against the imported history, got %v", resp.Anomalies)
+	}
+}
+
+func TestProcessCSVData_Anomalies(t *testing.T) {
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorService(mockDB)
+	registry := masterdata.NewRegistry()
+	if _, err := registry.Create(masterdata.Assignment{CardNumber: "********12345678", VehicleID: "V1", VehicleClass: parser.VehicleClassMedium}); err != nil {
+		t.Fatal(err)
+	}
+	service.SetMasterData(registry)
+	service.SetAnomalyDetector(anomaly.NewDetector(anomaly.ImpossibleTime{}, anomaly.CardOverlap{}, anomaly.VehicleClassMismatch{}))
+
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{CsvData: anomalyCSV, AccountId: strPtr("acc-1")})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.Stats.SavedRecords != 3 || resp.Stats.AnomalyRecords != 3 {
+		t.Errorf("Expected flagged records to be saved, got %v", resp.Stats)
+	}
+	// Every trip is charged as 普通車 for a 中型車; the second overlaps the first, the third ends before it starts
+	if len(resp.Anomalies) != 5 {
+		t.Fatalf("Expected 5 findings, got %v", resp.Anomalies)
+	}
+	overlap := resp.Anomalies[1]
+	if overlap.Rule != anomaly.RuleCardOverlap || overlap.LineNumber != 3 || overlap.RelatedLineNumber != 2 || overlap.CardNumber != "************5678" {
+		t.Errorf("Unexpected overlap finding: %v", overlap)
+	}
+	if rules, ok := mockDB.savedData[1].(map[string]interface{})["anomalies"].([]interface{}); !ok || len(rules) != 2 {
+		t.Errorf("Expected rule names in the saved payload, got %v", mockDB.savedData[1])
+	}
+	anomalyErrors := 0
+	for _, recordError := range resp.RecordErrors {
+		if recordError.Code == pb.ErrorCode_ERROR_CODE_ANOMALY {
+			anomalyErrors++
+		}
+	}
+	if anomalyErrors != 5 {
+		t.Errorf("Expected 5 anomaly record errors, got %d", anomalyErrors)
+	}
+
+	list, err := service.ListAnomalies(context.Background(), &pb.ListAnomaliesRequest{AccountId: "acc-1", Rule: anomaly.RuleImpossibleTime})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(list.Findings) != 1 || list.Findings[0].Date != "2025-09-02" {
+		t.Errorf("Expected the stored impossible-time finding, got %v", list.Findings)
+	}
+}
+
+func TestProcessCSVData_AnomaliesDryRun(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+	service.SetAnomalyDetector(anomaly.NewDetector(anomaly.DefaultRules()...))
+
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{CsvData: anomalyCSV, DryRun: boolPtr(true)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resp.Anomalies) != 2 {
+		t.Errorf("Expected findings in the dry-run response, got %v", resp.Anomalies)
+	}
+
+	list, err := service.ListAnomalies(context.Background(), &pb.ListAnomaliesRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(list.Findings) != 0 {
+		t.Errorf("Expected dry runs to keep no findings, got %v", list.Findings)
+	}
+}
+
+func TestListAnomalies_Errors(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+
+	if _, err := service.ListAnomalies(context.Background(), &pb.ListAnomaliesRequest{FromDate: "2025/09/01"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got %v", err)
+	}
+	if _, err := service.ListAnomalies(context.Background(), &pb.ListAnomaliesRequest{FromDate: "2025-09-30", ToDate: "2025-09-01"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a reversed range, got %v", err)
+	}
+
+	service.SetAnomalyStore(nil)
+	if _, err := service.ListAnomalies(context.Background(), &pb.ListAnomaliesRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected Unimplemented without a store, got %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/card"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+)
+
+func TestCardNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"4111-1111-1111-1111", "4111111111111111"},
+		{"4111 1111 1111 1111", "4111111111111111"},
+		{"４１１１　１１１１－１１１１－１１１１", "4111111111111111"},
+		{"＊＊＊＊＊＊＊＊12345678", "********12345678"},
+		{"********12345678", "********12345678"},
+	}
+
+	for _, tt := range tests {
+		if got := card.Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCardValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		number  string
+		wantErr bool
+	}{
+		{name: "masked statement number", number: "********12345678"},
+		{name: "masked to last 4", number: "************5678"},
+		{name: "full number with valid check digit", number: "4111111111111111"},
+		{name: "full number with bad check digit", number: "4111111111111112", wantErr: true},
+		{name: "too short", number: "1234567890", wantErr: true},
+		{name: "empty", number: "", wantErr: true},
+		{name: "masking in the middle", number: "1234****12345678", wantErr: true},
+		{name: "fewer than 4 visible digits", number: "*************678", wantErr: true},
+		{name: "letters", number: "********1234ABCD", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := card.Validate(tt.number)
+			if tt.wantErr != errors.Is(err, card.ErrInvalidNumber) {
+				t.Errorf("Validate(%q) error = %v, wantErr %v", tt.number, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCardMaskPolicy(t *testing.T) {
+	number := "4111111111111111"
+
+	if got := card.MaskLast4.Mask(number); got != "************1111" {
+		t.Errorf("MaskLast4 = %q", got)
+	}
+	if got := card.MaskAll.Mask(number); got != "****************" {
+		t.Errorf("MaskAll = %q", got)
+	}
+	if got := card.MaskNone.Mask(number); got != number {
+		t.Errorf("MaskNone = %q", got)
+	}
+	if got := card.MaskLast4.Mask("123"); got != "***" {
+		t.Errorf("Short numbers should be fully masked, got %q", got)
+	}
+
+	if policy, err := card.ParseMaskPolicy(""); err != nil || policy != card.MaskLast4 {
+		t.Errorf("Expected last4 default, got %q / %v", policy, err)
+	}
+	if policy, err := card.ParseMaskPolicy("ALL"); err != nil || policy != card.MaskAll {
+		t.Errorf("Expected all, got %q / %v", policy, err)
+	}
+	if _, err := card.ParseMaskPolicy("first6"); err == nil {
+		t.Error("Expected error for unknown policy")
+	}
+}
+
+func TestETCCSVParser_NormalizesCardNumber(t *testing.T) {
+	p := parser.NewETCCSVParser()
+
+	records, err := p.Parse(strings.NewReader(`利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,＊＊＊＊＊＊＊＊1234-5678,`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if records[0].CardNumber != "********12345678" {
+		t.Errorf("Expected normalized card number, got %q", records[0].CardNumber)
+	}
+}
+
+func TestProcessCSVData_CardValidationAndMasking(t *testing.T) {
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorService(mockDB)
+
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData: `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,4111111111111111,
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,4111111111111111,
+25/09/02,08:00,25/09/02,09:00,横浜,名古屋,3000,-500,2500,2,1234,4111111111111112,`,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.Stats.SavedRecords != 1 || resp.Stats.SkippedRecords != 1 || resp.Stats.ErrorRecords != 1 {
+		t.Errorf("Unexpected stats: %+v", resp.Stats)
+	}
+
+	for _, e := range resp.Errors {
+		if strings.Contains(e, "41111111") {
+			t.Errorf("Card number leaked into error: %s", e)
+		}
+	}
+
+	var invalid *pb.RecordError
+	for _, e := range resp.RecordErrors {
+		if e.Code == pb.ErrorCode_ERROR_CODE_VALIDATION {
+			invalid = e
+		}
+	}
+	if invalid == nil || invalid.Field != "card_number" || !strings.Contains(invalid.Message, "************1112") {
+		t.Errorf("Expected masked card validation error, got %v", invalid)
+	}
+	if !strings.Contains(resp.Errors[0], "card: ************1111") {
+		t.Errorf("Expected masked card in duplicate message, got %s", resp.Errors[0])
+	}
+}
+
+func TestValidateCSVData_MasksRecordData(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+
+	resp, err := service.ValidateCSVData(context.Background(), &pb.ValidateCSVDataRequest{
+		CsvData: `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+bad,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,4111111111111111,
+25/09/02,08:00,25/09/02,09:00,横浜,名古屋,3000,-500,2500,2,1234,12345,`,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(resp.Errors) != 2 {
+		t.Fatalf("Expected 2 validation errors, got %v", resp.Errors)
+	}
+	if strings.Contains(resp.Errors[0].RecordData, "4111111111111111") || !strings.Contains(resp.Errors[0].RecordData, "************1111") {
+		t.Errorf("Expected masked record data, got %s", resp.Errors[0].RecordData)
+	}
+	if resp.Errors[1].Field != "card_number" {
+		t.Errorf("Expected card_number field error, got %v", resp.Errors[1])
+	}
+
+	service.SetCardMaskPolicy(card.MaskNone)
+	resp, _ = service.ValidateCSVData(context.Background(), &pb.ValidateCSVDataRequest{
+		CsvData: `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+bad,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,4111111111111111,`,
+	})
+	if !strings.Contains(resp.Errors[0].RecordData, "4111111111111111") {
+		t.Errorf("Expected unmasked record data with MaskNone, got %s", resp.Errors[0].RecordData)
+	}
+}
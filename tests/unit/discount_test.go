@@ -0,0 +1,161 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/discount"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+)
+
+func at(value string) time.Time {
+	t, _ := time.Parse("2006-01-02 15:04", value)
+	return t
+}
+
+func TestDiscountEngine_Verify(t *testing.T) {
+	engine := discount.NewEngine(append(discount.DefaultRules(), discount.Rule{
+		Name:  "大口・多頻度割引",
+		Kind:  discount.KindContract,
+		Rate:  0.1,
+		Cards: []string{"********12345678"},
+		Stack: true,
+	}), nil)
+
+	tests := []struct {
+		name     string
+		trip     discount.Trip
+		expected int
+		rules    []string
+		mismatch bool
+	}{
+		{
+			name:     "weekday daytime",
+			trip:     discount.Trip{Entry: at("2025-09-01 08:00"), Exit: at("2025-09-01 09:00"), VehicleClass: parser.VehicleClassStandard, NormalAmount: 1000},
+			expected: 0,
+		},
+		{
+			name:     "holiday discount applied",
+			trip:     discount.Trip{Entry: at("2025-09-06 08:00"), Exit: at("2025-09-06 09:00"), VehicleClass: parser.VehicleClassStandard, NormalAmount: 1000, Discount: 300},
+			expected: 300,
+			rules:    []string{"休日割引"},
+		},
+		{
+			name:     "holiday discount missing",
+			trip:     discount.Trip{Entry: at("2025-09-05 23:30"), Exit: at("2025-09-06 00:10"), VehicleClass: parser.VehicleClassLight, NormalAmount: 1000},
+			expected: 300,
+			rules:    []string{"休日割引"},
+			mismatch: true,
+		},
+		{
+			name:     "no holiday discount for large vehicles",
+			trip:     discount.Trip{Entry: at("2025-09-06 08:00"), Exit: at("2025-09-06 09:00"), VehicleClass: parser.VehicleClassLarge, NormalAmount: 1000},
+			expected: 0,
+		},
+		{
+			name:     "late night across midnight",
+			trip:     discount.Trip{Entry: at("2025-09-01 23:00"), Exit: at("2025-09-02 00:30"), VehicleClass: parser.VehicleClassLarge, NormalAmount: 1000, Discount: 300},
+			expected: 300,
+			rules:    []string{"深夜割引"},
+		},
+		{
+			name:     "contract discount stacks on the remaining amount",
+			trip:     discount.Trip{Entry: at("2025-09-02 02:00"), Exit: at("2025-09-02 03:00"), CardNumber: "4111111112345678", NormalAmount: 1000, Discount: 300},
+			expected: 370,
+			rules:    []string{"深夜割引", "大口・多頻度割引"},
+			mismatch: true,
+		},
+		{
+			name:     "within rounding tolerance",
+			trip:     discount.Trip{Entry: at("2025-09-06 08:00"), Exit: at("2025-09-06 09:00"), VehicleClass: parser.VehicleClassStandard, NormalAmount: 1010, Discount: 300},
+			expected: 303,
+			rules:    []string{"休日割引"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := engine.Verify(tt.trip)
+			if result.Expected != tt.expected || result.Mismatch != tt.mismatch || !reflect.DeepEqual(result.Rules, tt.rules) {
+				t.Errorf("Verify() = %+v, want expected %d, rules %v, mismatch %v", result, tt.expected, tt.rules, tt.mismatch)
+			}
+			if result.Difference != result.Expected-tt.trip.Discount {
+				t.Errorf("Expected difference %d, got %d", result.Expected-tt.trip.Discount, result.Difference)
+			}
+		})
+	}
+}
+
+func TestDiscountEngine_LoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "discounts.yaml")
+	data := `tolerance: 1
+holidays: ["2025-09-17"]
+rules:
+  - name: 休日割引
+    kind: holiday
+    rate: 0.3
+    vehicle_classes: [1, 5]
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	engine, err := discount.LoadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result := engine.Verify(discount.Trip{Entry: at("2025-09-17 10:00"), Exit: at("2025-09-17 11:00"), VehicleClass: parser.VehicleClassStandard, NormalAmount: 1010, Discount: 300})
+	if result.Expected != 303 || !result.Mismatch {
+		t.Errorf("Expected configured holiday and tolerance to apply, got %+v", result)
+	}
+
+	for _, bad := range []string{
+		"rules:\n  - {name: x, kind: unknown, rate: 0.3}\n",
+		"rules:\n  - {name: x, kind: holiday, rate: 1.5}\n",
+		"rules:\n  - {name: x, kind: late_night, rate: 0.3, start_hour: 4, end_hour: 2}\n",
+		"holidays: [2025/09/15]\n",
+	} {
+		if err := os.WriteFile(path, []byte(bad), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := discount.LoadFile(path); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
+
+func TestProcessCSVData_DiscountVerification(t *testing.T) {
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorService(mockDB)
+	service.SetDiscountEngine(discount.NewEngine(discount.DefaultRules(), nil))
+
+	// 2025-09-06 is a Saturday: the first row got the holiday discount, the second did not
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData: `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/06,08:00,25/09/06,09:00,東京,横浜,1500,-450,1050,1,1234,********12345678,
+25/09/06,10:00,25/09/06,11:00,横浜,東京,1500,0,1500,1,1234,********12345678,`,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.Stats.SavedRecords != 2 || resp.Stats.DiscountMismatchRecords != 1 || resp.Stats.ClaimableDiscount != 450 {
+		t.Errorf("Expected one mismatch with 450 yen claimable, got %+v", resp.Stats)
+	}
+	if len(resp.RecordErrors) != 1 || resp.RecordErrors[0].Code != pb.ErrorCode_ERROR_CODE_DISCOUNT_MISMATCH ||
+		resp.RecordErrors[0].RecordIndex != 2 || !strings.Contains(resp.RecordErrors[0].Message, "休日割引") {
+		t.Errorf("Expected DISCOUNT_MISMATCH for record 2, got %v", resp.RecordErrors)
+	}
+
+	payload := mockDB.savedData[1].(map[string]interface{})
+	if payload["expected_discount"] != 450 || payload["discount_difference"] != 450 || payload["discount_mismatch"] != true {
+		t.Errorf("Expected discount check in payload, got %v", payload)
+	}
+}
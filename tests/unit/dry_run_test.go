@@ -0,0 +1,167 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+)
+
+const dryRunCSV = `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,テスト
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,テスト
+bad,08:00,bad,09:00,横浜,名古屋,3000,-500,2500,2,1234,********12345678,テスト`
+
+func TestProcessCSVData_DryRun(t *testing.T) {
+	os.Setenv("SKIP_DUPLICATES", "true")
+	defer os.Unsetenv("SKIP_DUPLICATES")
+
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorService(mockDB)
+
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData:   dryRunCSV,
+		AccountId: strPtr("test-account"),
+		DryRun:    boolPtr(true),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(mockDB.savedData) != 0 {
+		t.Errorf("Dry run must not save, got %d saves", len(mockDB.savedData))
+	}
+	if !resp.DryRun || !strings.HasPrefix(resp.Message, "[dry run]") {
+		t.Errorf("Expected dry run response, got %v / %q", resp.DryRun, resp.Message)
+	}
+	if resp.Stats.SavedRecords != 1 || resp.Stats.SkippedRecords != 1 || resp.Stats.ErrorRecords != 1 {
+		t.Errorf("Unexpected stats: %+v", resp.Stats)
+	}
+
+	want := []struct {
+		action pb.DryRunAction
+		reason pb.ErrorCode
+	}{
+		{pb.DryRunAction_DRY_RUN_ACTION_SAVE, pb.ErrorCode_ERROR_CODE_UNSPECIFIED},
+		{pb.DryRunAction_DRY_RUN_ACTION_SKIP, pb.ErrorCode_ERROR_CODE_DUPLICATE},
+		{pb.DryRunAction_DRY_RUN_ACTION_REJECT, pb.ErrorCode_ERROR_CODE_CONVERSION},
+	}
+	if len(resp.DryRunRecords) != len(want) {
+		t.Fatalf("Expected %d dry run records, got %d", len(want), len(resp.DryRunRecords))
+	}
+	for i, w := range want {
+		got := resp.DryRunRecords[i]
+		if got.Action != w.action || got.Reason != w.reason || got.RecordIndex != int32(i+1) {
+			t.Errorf("DryRunRecords[%d] = %v/%v/%d, want %v/%v/%d", i, got.Action, got.Reason, got.RecordIndex, w.action, w.reason, i+1)
+		}
+	}
+
+	payload := resp.DryRunRecords[0].Payload.AsMap()
+	if payload["account_id"] != "test-account" || payload["date"] != "2025-09-01" || payload["amount"] != float64(1200) {
+		t.Errorf("Unexpected payload: %v", payload)
+	}
+	if payload["normal_amount"] != float64(1500) || payload["discount_amount"] != float64(-300) ||
+		payload["etc_amount"] != float64(1200) || payload["post_payment_amount"] != float64(0) || payload["mileage"] != float64(0) ||
+		payload["redemption_base_amount"] != float64(0) {
+		t.Errorf("Expected amount breakdown in payload, got %v", payload)
+	}
+	if resp.DryRunRecords[1].Payload != nil {
+		t.Errorf("Skipped records should not carry a payload")
+	}
+}
+
+func TestProcessCSVData_DryRunSkipsImported(t *testing.T) {
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorService(mockDB)
+
+	if _, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData:   fileResultsCSV,
+		AccountId: strPtr("test-account"),
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Both trips of the statement were imported before; only the new row would be saved
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData: fileResultsCSV + `
+25/09/03,08:00,25/09/03,09:00,名古屋,京都,2000,-400,1600,2,1234,********12345678,テスト`,
+		AccountId: strPtr("test-account"),
+		DryRun:    boolPtr(true),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(mockDB.savedData) != 2 {
+		t.Errorf("Dry run must not save, got %d saves", len(mockDB.savedData))
+	}
+	if resp.Stats.SavedRecords != 1 || resp.Stats.SkippedRecords != 2 {
+		t.Errorf("Expected the imported records to be skipped, got %+v", resp.Stats)
+	}
+	for i, want := range []pb.DryRunAction{pb.DryRunAction_DRY_RUN_ACTION_SKIP, pb.DryRunAction_DRY_RUN_ACTION_SKIP, pb.DryRunAction_DRY_RUN_ACTION_SAVE} {
+		if got := resp.DryRunRecords[i]; got.Action != want {
+			t.Errorf("DryRunRecords[%d] = %v/%v, want %v", i, got.Action, got.Reason, want)
+		}
+	}
+
+	// Imports of another account do not count
+	other, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData:   fileResultsCSV,
+		AccountId: strPtr("other-account"),
+		DryRun:    boolPtr(true),
+	})
+	if err != nil || other.Stats.SavedRecords != 2 {
+		t.Errorf("Expected another account to see no duplicates, got %v / %v", other, err)
+	}
+}
+
+func TestProcessCSVData_NoDryRunRecordsByDefault(t *testing.T) {
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorService(mockDB)
+
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{CsvData: dryRunCSV})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.DryRun || len(resp.DryRunRecords) != 0 {
+		t.Errorf("Expected regular run, got dry run records %v", resp.DryRunRecords)
+	}
+	if len(mockDB.savedData) == 0 {
+		t.Error("Expected records to be saved")
+	}
+}
+
+func TestProcessCSVFile_DryRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "statement.csv")
+	if err := os.WriteFile(path, []byte(dryRunCSV), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorService(mockDB)
+
+	resp, err := service.ProcessCSVFile(context.Background(), &pb.ProcessCSVFileRequest{
+		CsvFilePath: strPtr(tmpDir),
+		DryRun:      boolPtr(true),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(mockDB.savedData) != 0 {
+		t.Errorf("Dry run must not save, got %d saves", len(mockDB.savedData))
+	}
+	if len(resp.DryRunRecords) != 3 || len(resp.FileResults[0].DryRunRecords) != 3 {
+		t.Fatalf("Expected 3 dry run records, got %d", len(resp.DryRunRecords))
+	}
+	for _, record := range resp.DryRunRecords {
+		if record.FilePath != path {
+			t.Errorf("Expected file path %s, got %s", path, record.FilePath)
+		}
+	}
+}
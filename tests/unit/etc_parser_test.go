@@ -228,45 +228,50 @@ func TestETCCSVParser_ValidateRecord_EdgeCases(t *testing.T) {
 		{
 			name: "empty entry date but valid exit date",
 			record: parser.ActualETCRecord{
-				EntryDate:  "",
-				ExitDate:   "25/09/01",
-				CardNumber: "1234567890",
+				EntryDate:    "",
+				ExitDate:     "25/09/01",
+				CardNumber:   "1234567890",
+				VehicleClass: parser.VehicleClassStandard,
 			},
 			wantErr: false,
 		},
 		{
 			name: "empty exit date but valid entry date",
 			record: parser.ActualETCRecord{
-				EntryDate:  "25/09/01",
-				ExitDate:   "",
-				CardNumber: "1234567890",
+				EntryDate:    "25/09/01",
+				ExitDate:     "",
+				CardNumber:   "1234567890",
+				VehicleClass: parser.VehicleClassStandard,
 			},
 			wantErr: false,
 		},
 		{
 			name: "both dates empty but card exists",
 			record: parser.ActualETCRecord{
-				EntryDate:  "",
-				ExitDate:   "",
-				CardNumber: "1234567890",
+				EntryDate:    "",
+				ExitDate:     "",
+				CardNumber:   "1234567890",
+				VehicleClass: parser.VehicleClassStandard,
 			},
 			wantErr: false,
 		},
 		{
 			name: "invalid entry date format",
 			record: parser.ActualETCRecord{
-				EntryDate:  "invalid",
-				ExitDate:   "25/09/01",
-				CardNumber: "1234567890",
+				EntryDate:    "invalid",
+				ExitDate:     "25/09/01",
+				CardNumber:   "1234567890",
+				VehicleClass: parser.VehicleClassStandard,
 			},
 			wantErr: true,
 		},
 		{
 			name: "invalid exit date format",
 			record: parser.ActualETCRecord{
-				EntryDate:  "25/09/01",
-				ExitDate:   "invalid",
-				CardNumber: "1234567890",
+				EntryDate:    "25/09/01",
+				ExitDate:     "invalid",
+				CardNumber:   "1234567890",
+				VehicleClass: parser.VehicleClassStandard,
 			},
 			wantErr: true,
 		},
@@ -307,7 +312,7 @@ func TestETCCSVParser_ConvertToSimpleRecord_EdgeCases(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name: "negative ETC amount becomes positive",
+			name: "negative ETC amount is kept as a reversal",
 			record: parser.ActualETCRecord{
 				EntryDate:     "25/09/01",
 				ExitDate:      "25/09/01",
@@ -355,8 +360,8 @@ func TestETCCSVParser_ConvertToSimpleRecord_EdgeCases(t *testing.T) {
 
 			if !tt.wantErr {
 				// Check specific conversions
-				if tt.record.ETCAmount < 0 && simple.Amount < 0 {
-					t.Errorf("Negative amount should be converted to positive")
+				if tt.record.ETCAmount < 0 && (simple.Amount != tt.record.ETCAmount || !simple.IsReversal()) {
+					t.Errorf("Negative amount should be kept as a reversal, got %d", simple.Amount)
 				}
 				if tt.record.ETCAmount == 0 && tt.record.NormalAmount > 0 && simple.Amount != tt.record.NormalAmount {
 					t.Errorf("Should use normal amount when ETC amount is zero")
@@ -364,4 +369,29 @@ func TestETCCSVParser_ConvertToSimpleRecord_EdgeCases(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestETCCSVParser_ConvertToSimpleRecord_AmountBreakdown(t *testing.T) {
+	p := parser.NewETCCSVParser()
+
+	simple, err := p.ConvertToSimpleRecord(parser.ActualETCRecord{
+		EntryDate:         "25/09/01",
+		ExitDate:          "25/09/01",
+		NormalAmount:      1500,
+		DiscountApplied:   -300,
+		ETCAmount:         1200,
+		PostPaymentAmount: 1100,
+		Mileage:           12,
+		VehicleClass:      2,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if simple.Amount != 1100 {
+		t.Errorf("Expected post-payment amount to be charged, got %d", simple.Amount)
+	}
+	if simple.NormalAmount != 1500 || simple.DiscountAmount != -300 || simple.ETCAmount != 1200 ||
+		simple.PostPaymentAmount != 1100 || simple.Mileage != 12 {
+		t.Errorf("Amount breakdown not preserved: %+v", simple)
+	}
+}
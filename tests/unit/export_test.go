@@ -0,0 +1,382 @@
+package unit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/export"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// exportRecords returns sample records for writer and store tests
+func exportRecords() []export.Record {
+	return []export.Record{
+		{ImportJobID: "job-1", AccountID: "acc-1", SourceFile: "a.csv", LineNumber: 2, Date: "2025-09-01",
+			EntryTime: "2025-09-01T08:00:00", ExitTime: "2025-09-01T09:00:00", EntryIC: "東京", ExitIC: "横浜",
+			CardNumber: "1234", NormalAmount: 1500, DiscountAmount: -300, ETCAmount: 1200, Amount: 1200},
+		{ImportJobID: "job-2", AccountID: "acc-2", SourceFile: "b.csv", LineNumber: 2, Date: "2025-10-01",
+			EntryTime: "2025-10-01T08:00:00", ExitTime: "2025-10-01T09:00:00", EntryIC: "横浜", ExitIC: "厚木",
+			CardNumber: "5678", Amount: -1000, Reversal: true, CorrectionReason: "refund"},
+	}
+}
+
+func TestExportParseFormat(t *testing.T) {
+	tests := map[string]export.Format{"csv": export.FormatCSV, "JSONL": export.FormatJSONL, "json": export.FormatJSONL, "parquet": export.FormatParquet}
+	for name, want := range tests {
+		if got, err := export.ParseFormat(name); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := export.ParseFormat("xlsx"); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
+
+func TestExportWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := export.Write(&buf, export.FormatCSV, exportRecords()); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("Expected header and 2 rows, got %d", len(rows))
+	}
+	if strings.Join(rows[0], ",") != strings.Join(export.Columns(), ",") {
+		t.Errorf("Unexpected header: %v", rows[0])
+	}
+	row := make(map[string]string)
+	for i, name := range rows[0] {
+		row[name] = rows[2][i]
+	}
+	if row["schema_version"] != export.SchemaVersion || row["amount"] != "-1000" || row["reversal"] != "true" || row["entry_ic"] != "横浜" {
+		t.Errorf("Unexpected row: %v", row)
+	}
+}
+
+func TestExportWriteJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	if err := export.Write(&buf, export.FormatJSONL, exportRecords()); err != nil {
+		t.Fatal(err)
+	}
+	scanner := bufio.NewScanner(&buf)
+	var records []export.Record
+	for scanner.Scan() {
+		var record export.Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 2 || records[0].SchemaVersion != export.SchemaVersion || records[0].DiscountAmount != -300 {
+		t.Errorf("Unexpected records: %+v", records)
+	}
+}
+
+func TestExportWriteParquet(t *testing.T) {
+	for _, records := range [][]export.Record{exportRecords(), nil} {
+		var buf bytes.Buffer
+		if err := export.Write(&buf, export.FormatParquet, records); err != nil {
+			t.Fatal(err)
+		}
+		data := buf.Bytes()
+		if len(data) < 12 || string(data[:4]) != "PAR1" || string(data[len(data)-4:]) != "PAR1" {
+			t.Fatalf("Not a Parquet file: % x", data)
+		}
+		footer := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+		if footer <= 0 || footer > len(data)-12 {
+			t.Fatalf("Invalid footer length %d", footer)
+		}
+		metadata := data[len(data)-8-footer : len(data)-8]
+		for _, want := range []string{"etc_record.schema_version", export.SchemaVersion, "card_number", "day_type"} {
+			if !bytes.Contains(metadata, []byte(want)) {
+				t.Errorf("Footer is missing %q", want)
+			}
+		}
+	}
+}
+
+// parquetBuffer is a read-only in-memory source for the Parquet reader
+type parquetBuffer struct {
+	*bytes.Reader
+	data []byte
+}
+
+func newParquetBuffer(data []byte) *parquetBuffer {
+	return &parquetBuffer{Reader: bytes.NewReader(data), data: data}
+}
+
+func (b *parquetBuffer) Open(string) (source.ParquetFile, error) {
+	return newParquetBuffer(b.data), nil
+}
+
+func (b *parquetBuffer) Create(string) (source.ParquetFile, error) {
+	return nil, errors.New("read-only")
+}
+
+func (b *parquetBuffer) Write([]byte) (int, error) {
+	return 0, errors.New("read-only")
+}
+
+func (b *parquetBuffer) Close() error {
+	return nil
+}
+
+func TestExportWriteParquet_RoundTrip(t *testing.T) {
+	records := exportRecords()
+	records[1].TripID, records[1].VehicleID = "trip-1", "truck-1"
+	var buf bytes.Buffer
+	if err := export.Write(&buf, export.FormatParquet, records); err != nil {
+		t.Fatal(err)
+	}
+
+	pr, err := reader.NewParquetColumnReader(newParquetBuffer(buf.Bytes()), 1)
+	if err != nil {
+		t.Fatalf("Parquet reader rejected the file: %v", err)
+	}
+	defer pr.ReadStop()
+
+	if pr.GetNumRows() != 2 {
+		t.Fatalf("Expected 2 rows, got %d", pr.GetNumRows())
+	}
+	metadata := make(map[string]string)
+	for _, kv := range pr.Footer.KeyValueMetadata {
+		if kv.Value != nil {
+			metadata[kv.Key] = *kv.Value
+		}
+	}
+	if metadata["etc_record.schema_version"] != export.SchemaVersion {
+		t.Errorf("Expected schema version metadata, got %v", metadata)
+	}
+
+	columns := export.Columns()
+	if len(pr.Footer.Schema) != len(columns)+1 {
+		t.Fatalf("Expected %d columns, got %d", len(columns), len(pr.Footer.Schema)-1)
+	}
+	// The reader renames schema elements in place; Infos keeps the names as written
+	for i, element := range pr.Footer.Schema[1:] {
+		if name := pr.SchemaHandler.Infos[i+1].ExName; name != columns[i] || element.GetRepetitionType() != parquet.FieldRepetitionType_REQUIRED {
+			t.Errorf("Unexpected schema element %d: %s %v", i, name, element.GetRepetitionType())
+		}
+	}
+
+	read := func(name string) []interface{} {
+		t.Helper()
+		values, _, dls, err := pr.ReadColumnByPath(pr.SchemaHandler.GetRootExName()+"\x01"+name, 2)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		for _, dl := range dls {
+			if dl != 0 {
+				t.Errorf("Expected %s to have no nulls, got definition levels %v", name, dls)
+			}
+		}
+		return values
+	}
+	checks := map[string][]interface{}{
+		"schema_version":    {export.SchemaVersion, export.SchemaVersion},
+		"line_number":       {int32(2), int32(2)},
+		"entry_ic":          {"東京", "横浜"},
+		"card_number":       {"1234", "5678"},
+		"discount_amount":   {int64(-300), int64(0)},
+		"amount":            {int64(1200), int64(-1000)},
+		"reversal":          {false, true},
+		// Values missing from a record are written as empty strings, not nulls
+		"correction_reason": {"", "refund"},
+		"trip_id":           {"", "trip-1"},
+		"vehicle_id":        {"", "truck-1"},
+		"driver_id":         {"", ""},
+		"entry_ic_code":     {"", ""},
+	}
+	for name, want := range checks {
+		if got := read(name); !reflect.DeepEqual(got, want) {
+			t.Errorf("Column %s = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestExportMemoryStore(t *testing.T) {
+	store := export.NewMemoryStore()
+	records := exportRecords()
+	if err := store.Add(records...); err != nil {
+		t.Fatal(err)
+	}
+	// Re-importing a row replaces it
+	records[0].ImportJobID = "job-3"
+	if err := store.Add(records[0]); err != nil {
+		t.Fatal(err)
+	}
+	if store.Len() != 2 {
+		t.Fatalf("Expected 2 records, got %d", store.Len())
+	}
+
+	tests := []struct {
+		name   string
+		filter export.Filter
+		want   int
+	}{
+		{"all", export.Filter{}, 2},
+		{"replaced job", export.Filter{ImportJobID: "job-1"}, 0},
+		{"job", export.Filter{ImportJobID: "job-3"}, 1},
+		{"file", export.Filter{SourceFile: "b.csv"}, 1},
+		{"account", export.Filter{AccountID: "acc-2"}, 1},
+		{"from", export.Filter{From: mustDate("2025-09-02")}, 1},
+		{"to", export.Filter{To: mustDate("2025-09-01")}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.List(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("Expected %d records, got %d", tt.want, len(got))
+			}
+		})
+	}
+}
+
+func TestExportFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "records.jsonl")
+	store, err := export.OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := exportRecords()
+	if err := store.Add(records...); err != nil {
+		t.Fatal(err)
+	}
+	records[1].ImportJobID = "job-3"
+	if err := store.Add(records[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := export.OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := reopened.List(export.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[1].ImportJobID != "job-3" {
+		t.Errorf("Unexpected records after reload: %+v", got)
+	}
+
+	// The replaced line is dropped when the file is loaded
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := bytes.Count(data, []byte("\n")); lines != 2 {
+		t.Errorf("Expected the file to be compacted to 2 lines, got %d", lines)
+	}
+}
+
+func TestExportRecords_FromStore(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+	service.SetRecordStore(export.NewMemoryStore())
+	account := "acc-1"
+	imported, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{CsvData: usageCSV, AccountId: &account})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported.ImportJobId == "" {
+		t.Fatal("Expected an import job ID")
+	}
+	// Dry runs are not kept
+	if _, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{CsvData: usageCSV, DryRun: boolPtr(true)}); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := service.ExportRecords(context.Background(), &pb.ExportRecordsRequest{
+		Format:      pb.ExportFormat_EXPORT_FORMAT_JSONL,
+		ImportJobId: imported.ImportJobId,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.RecordCount != 5 || resp.SchemaVersion != export.SchemaVersion || resp.ContentType != "application/x-ndjson" || resp.Filename != "etc_records.jsonl" {
+		t.Fatalf("Unexpected response: count=%d version=%s type=%s file=%s", resp.RecordCount, resp.SchemaVersion, resp.ContentType, resp.Filename)
+	}
+	var first export.Record
+	if err := json.Unmarshal(bytes.SplitN(resp.Content, []byte("\n"), 2)[0], &first); err != nil {
+		t.Fatal(err)
+	}
+	if first.AccountID != "acc-1" || first.Date != "2025-09-01" || first.EntryTime != "2025-09-01T08:00:00" ||
+		first.ImportJobID != imported.ImportJobId || first.TaxAmount+first.TaxExclusiveAmount != 1200 || first.DayType != "weekday" {
+		t.Errorf("Unexpected record: %+v", first)
+	}
+
+	september, err := service.ExportRecords(context.Background(), &pb.ExportRecordsRequest{FromDate: "2025-09-01", ToDate: "2025-09-30"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if september.RecordCount != 3 || september.ContentType != "text/csv; charset=UTF-8" {
+		t.Errorf("Expected 3 CSV records for September, got %d (%s)", september.RecordCount, september.ContentType)
+	}
+}
+
+func TestExportRecords_DirectCSV(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+
+	resp, err := service.ExportRecords(context.Background(), &pb.ExportRecordsRequest{
+		Format:  pb.ExportFormat_EXPORT_FORMAT_PARQUET,
+		CsvData: strPtr(usageCSV),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.RecordCount != 5 || !bytes.HasPrefix(resp.Content, []byte("PAR1")) {
+		t.Errorf("Unexpected response: count=%d", resp.RecordCount)
+	}
+}
+
+func TestExportRecords_Errors(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+	if _, err := service.ExportRecords(context.Background(), &pb.ExportRecordsRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected Unimplemented without a record store by default, got %v", err)
+	}
+	service.SetRecordStore(export.NewMemoryStore())
+
+	tests := []struct {
+		name string
+		req  *pb.ExportRecordsRequest
+	}{
+		{"bad format", &pb.ExportRecordsRequest{Format: pb.ExportFormat(99)}},
+		{"bad from date", &pb.ExportRecordsRequest{FromDate: "2025/09/01"}},
+		{"reversed range", &pb.ExportRecordsRequest{FromDate: "2025-09-30", ToDate: "2025-09-01"}},
+		{"two sources", &pb.ExportRecordsRequest{CsvData: strPtr(usageCSV), CsvFilePath: strPtr("a.csv")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ExportRecords(context.Background(), tt.req)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("Expected InvalidArgument, got %v", err)
+			}
+		})
+	}
+
+	service.SetRecordStore(nil)
+	if _, err := service.ExportRecords(context.Background(), &pb.ExportRecordsRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected Unimplemented without a record store, got %v", err)
+	}
+}
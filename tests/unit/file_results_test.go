@@ -0,0 +1,208 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+	"golang.org/x/text/encoding/japanese"
+)
+
+const fileResultsCSV = `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,テスト
+25/09/02,08:00,25/09/02,09:00,横浜,名古屋,3000,-500,2500,2,1234,********12345678,テスト`
+
+func TestDetectEncoding(t *testing.T) {
+	sjis, err := japanese.ShiftJIS.NewEncoder().Bytes([]byte(fileResultsCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{name: "utf-8", data: []byte(fileResultsCSV), want: parser.EncodingUTF8},
+		{name: "utf-8 with BOM", data: append([]byte{0xEF, 0xBB, 0xBF}, fileResultsCSV...), want: parser.EncodingUTF8},
+		{name: "shift-jis", data: sjis, want: parser.EncodingShiftJIS},
+		{name: "ascii", data: []byte("a,b,c"), want: parser.EncodingUTF8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parser.DetectEncoding(tt.data); got != tt.want {
+				t.Errorf("DetectEncoding() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestETCCSVParser_ParseFileWithInfo(t *testing.T) {
+	tmpDir := t.TempDir()
+	p := parser.NewETCCSVParser()
+
+	sjis, err := japanese.ShiftJIS.NewEncoder().Bytes([]byte(fileResultsCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	positional := "25/09/01,08:00,25/09/01,09:00,東京,横浜,,1200,1500,-300,0,2,1234,********12345678,"
+
+	tests := []struct {
+		name         string
+		data         []byte
+		wantFormat   string
+		wantEncoding string
+	}{
+		{name: "shift-jis header", data: sjis, wantFormat: parser.FormatHeader, wantEncoding: parser.EncodingShiftJIS},
+		{name: "utf-8 header", data: []byte(fileResultsCSV), wantFormat: parser.FormatHeader, wantEncoding: parser.EncodingUTF8},
+		{name: "utf-8 positional", data: []byte(positional), wantFormat: parser.FormatPositional, wantEncoding: parser.EncodingUTF8},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tmpDir, tt.name+".csv")
+			if err := os.WriteFile(path, tt.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			records, info, err := p.ParseFileWithInfo(path)
+			if err != nil {
+				t.Fatalf("case %d: unexpected error: %v", i, err)
+			}
+			if info.Format != tt.wantFormat {
+				t.Errorf("Format = %s, want %s", info.Format, tt.wantFormat)
+			}
+			if info.Encoding != tt.wantEncoding {
+				t.Errorf("Encoding = %s, want %s", info.Encoding, tt.wantEncoding)
+			}
+			if len(records) == 0 || records[0].EntryIC != "東京" {
+				t.Errorf("Expected decoded entry IC 東京, got %+v", records)
+			}
+		})
+	}
+}
+
+func TestETCCSVParser_ReadFile(t *testing.T) {
+	sjis, err := japanese.ShiftJIS.NewEncoder().Bytes([]byte(fileResultsCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "sjis.csv")
+	if err := os.WriteFile(path, sjis, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	data, info, err := parser.NewETCCSVParser().ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if data != fileResultsCSV || info.Encoding != parser.EncodingShiftJIS {
+		t.Errorf("Expected the decoded statement, got %q (%s)", data, info.Encoding)
+	}
+
+	if _, _, err := parser.NewETCCSVParser().ReadFile(filepath.Join(t.TempDir(), "missing.csv")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+func TestProcessCSVFile_FileResults(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	sjis, err := japanese.ShiftJIS.NewEncoder().Bytes([]byte(fileResultsCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{
+		"202509.csv": sjis,
+		"202510.csv": []byte(fileResultsCSV),
+		"broken.csv": []byte(""),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	os.Setenv("SKIP_DUPLICATES", "true")
+	defer os.Unsetenv("SKIP_DUPLICATES")
+
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorService(mockDB)
+
+	resp, err := service.ProcessCSVFile(context.Background(), &pb.ProcessCSVFileRequest{
+		CsvFilePath: strPtr(tmpDir),
+		AccountId:   strPtr("test-account"),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(resp.FileResults) != 3 {
+		t.Fatalf("Expected 3 file results, got %d", len(resp.FileResults))
+	}
+
+	byName := make(map[string]*pb.FileResult)
+	for _, result := range resp.FileResults {
+		byName[filepath.Base(result.FilePath)] = result
+	}
+
+	first := byName["202509.csv"]
+	if first.Encoding != parser.EncodingShiftJIS || first.Format != parser.FormatHeader {
+		t.Errorf("Unexpected detection for 202509.csv: %s/%s", first.Encoding, first.Format)
+	}
+	if first.Stats.TotalRecords != 2 || first.Stats.SavedRecords != 2 {
+		t.Errorf("Unexpected stats for 202509.csv: %+v", first.Stats)
+	}
+
+	// The second statement repeats the same trips, so they are skipped as duplicates
+	second := byName["202510.csv"]
+	if second.Encoding != parser.EncodingUTF8 {
+		t.Errorf("Expected UTF-8 for 202510.csv, got %s", second.Encoding)
+	}
+	if second.Stats.SkippedRecords != 2 || len(second.Errors) != 2 {
+		t.Errorf("Expected 2 skipped records for 202510.csv, got %+v (errors %v)", second.Stats, second.Errors)
+	}
+
+	broken := byName["broken.csv"]
+	if len(broken.Errors) != 1 || broken.Stats.TotalRecords != 0 {
+		t.Errorf("Expected parse error for broken.csv, got %+v", broken)
+	}
+
+	if resp.Stats.TotalRecords != 4 || resp.Stats.SavedRecords != 2 || resp.Stats.SkippedRecords != 2 {
+		t.Errorf("Unexpected aggregate stats: %+v", resp.Stats)
+	}
+	if len(mockDB.savedData) != 2 {
+		t.Errorf("Expected 2 saves, got %d", len(mockDB.savedData))
+	}
+}
+
+func TestProcessCSVFile_SingleFileParseErrorResult(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "broken.csv")
+	if err := os.WriteFile(path, []byte(""), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	service := handler.NewDataProcessorService(&mockDBClient{})
+	resp, err := service.ProcessCSVFile(context.Background(), &pb.ProcessCSVFileRequest{
+		CsvFilePath: strPtr(path),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.Success {
+		t.Error("Expected failure for unparseable file")
+	}
+	if len(resp.FileResults) != 1 || resp.FileResults[0].FilePath != path {
+		t.Fatalf("Expected one file result for %s, got %+v", path, resp.FileResults)
+	}
+	if len(resp.FileResults[0].Errors) != 1 {
+		t.Errorf("Expected parse error in file result, got %v", resp.FileResults[0].Errors)
+	}
+}
@@ -0,0 +1,150 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/discount"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/holiday"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+)
+
+func TestHolidayCalendar_National(t *testing.T) {
+	c := holiday.New()
+
+	tests := []struct {
+		date string
+		name string
+	}{
+		{"2025-01-01", "元日"},
+		{"2025-01-13", "成人の日"},
+		{"2025-02-24", "振替休日"}, // 天皇誕生日 on Sunday
+		{"2025-03-20", "春分の日"},
+		{"2025-05-06", "振替休日"}, // みどりの日 on Sunday, next free day after こどもの日
+		{"2025-07-21", "海の日"},
+		{"2025-09-23", "秋分の日"},
+		{"2025-10-13", "スポーツの日"},
+		{"2026-09-22", "国民の休日"}, // between 敬老の日 and 秋分の日
+		{"2019-05-01", "天皇の即位の日"},
+		{"2019-04-30", "国民の休日"},
+		{"2020-07-24", "スポーツの日"},
+		{"2018-12-23", "天皇誕生日"},
+		{"2019-12-23", ""},
+		{"2025-09-16", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			name, ok := c.HolidayName(mustDate(tt.date))
+			if name != tt.name || ok != (tt.name != "") {
+				t.Errorf("HolidayName(%s) = %q/%v, want %q", tt.date, name, ok, tt.name)
+			}
+		})
+	}
+
+	if got := len(c.Holidays(2025)); got != 19 {
+		t.Errorf("Expected 19 holidays in 2025, got %d", got)
+	}
+	if got := c.Holidays(2100); len(got) != 0 {
+		t.Errorf("Expected no built-in holidays outside the supported range, got %v", got)
+	}
+}
+
+func TestHolidayCalendar_DayType(t *testing.T) {
+	c := holiday.New()
+
+	tests := []struct {
+		date string
+		want holiday.DayType
+	}{
+		{"2025-09-16", holiday.Weekday},
+		{"2025-09-20", holiday.Weekend},
+		{"2025-09-15", holiday.Holiday},
+		{"2025-11-23", holiday.Holiday}, // holiday on Sunday
+	}
+	for _, tt := range tests {
+		if got := c.DayType(mustDate(tt.date)); got != tt.want {
+			t.Errorf("DayType(%s) = %s, want %s", tt.date, got, tt.want)
+		}
+	}
+
+	// Statement times are wall-clock values, so a late evening still belongs to its own day
+	if !c.IsDayOff(time.Date(2025, 9, 15, 23, 59, 0, 0, time.UTC)) {
+		t.Error("Expected 2025-09-15 23:59 to be a day off")
+	}
+}
+
+func TestHolidayCalendar_LoadFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	yamlPath := filepath.Join(tmpDir, "holidays.yaml")
+	if err := os.WriteFile(yamlPath, []byte("holidays:\n  - date: \"2025-12-30\"\n    name: 年末休業\n  - date: \"2025-12-31\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := holiday.LoadFile(yamlPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if name, _ := c.HolidayName(mustDate("2025-12-30")); name != "年末休業" {
+		t.Errorf("Expected extra holiday, got %q", name)
+	}
+	if !c.IsHoliday(mustDate("2025-12-31")) || !c.IsHoliday(mustDate("2025-01-01")) {
+		t.Error("Expected extra days on top of national holidays")
+	}
+
+	csvPath := filepath.Join(tmpDir, "holidays.csv")
+	if err := os.WriteFile(csvPath, []byte("date,name\n2025-08-13,夏季休業\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := holiday.LoadFile(csvPath); err != nil || !c.IsHoliday(mustDate("2025-08-13")) {
+		t.Errorf("Expected CSV holiday to load, got %v", err)
+	}
+
+	if err := os.WriteFile(csvPath, []byte("2025/08/13,夏季休業\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := holiday.LoadFile(csvPath); err == nil {
+		t.Error("Expected error for a malformed date")
+	}
+}
+
+func TestDiscountEngine_NationalHoliday(t *testing.T) {
+	engine := discount.NewEngine(discount.DefaultRules(), nil)
+
+	// 2025-09-15 (敬老の日) is a Monday
+	result := engine.Verify(discount.Trip{Entry: at("2025-09-15 10:00"), Exit: at("2025-09-15 11:00"), VehicleClass: parser.VehicleClassStandard, NormalAmount: 1000})
+	if result.Expected != 300 || !result.Mismatch {
+		t.Errorf("Expected holiday discount on a national holiday, got %+v", result)
+	}
+}
+
+func TestProcessCSVData_DayType(t *testing.T) {
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorService(mockDB)
+	calendar := holiday.New()
+	calendar.Add(mustDate("2025-09-02"), "創立記念日")
+	service.SetHolidayCalendar(calendar)
+
+	_, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData: `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,
+25/09/02,08:00,25/09/02,09:00,横浜,東京,1500,-300,1200,2,1234,********12345678,`,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	weekday := mockDB.savedData[0].(map[string]interface{})
+	extra := mockDB.savedData[1].(map[string]interface{})
+	if weekday["day_type"] != "weekday" || weekday["holiday_name"] != nil {
+		t.Errorf("Expected weekday, got %v", weekday)
+	}
+	if extra["day_type"] != "holiday" || extra["holiday_name"] != "創立記念日" {
+		t.Errorf("Expected configured holiday, got %v", extra)
+	}
+}
@@ -0,0 +1,250 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/idempotency"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestProcessCSVData_IdempotencyReplay(t *testing.T) {
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorService(mockDB)
+
+	req := &pb.ProcessCSVDataRequest{
+		CsvData:        dryRunCSV,
+		AccountId:      strPtr("test-account"),
+		IdempotencyKey: strPtr("retry-1"),
+	}
+
+	first, err := service.ProcessCSVData(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	saves := len(mockDB.savedData)
+	if saves == 0 || first.Replayed {
+		t.Fatalf("Expected first attempt to save records, got %d saves (replayed %v)", saves, first.Replayed)
+	}
+
+	second, err := service.ProcessCSVData(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error on replay: %v", err)
+	}
+	if len(mockDB.savedData) != saves {
+		t.Errorf("Replay must not save again, got %d saves", len(mockDB.savedData))
+	}
+	if !second.Replayed || second.Message != first.Message || second.Stats.SavedRecords != first.Stats.SavedRecords {
+		t.Errorf("Expected stored response, got %v", second)
+	}
+
+	// Same key for a different account is a separate request
+	other := &pb.ProcessCSVDataRequest{CsvData: dryRunCSV, AccountId: strPtr("other-account"), IdempotencyKey: strPtr("retry-1")}
+	resp, err := service.ProcessCSVData(context.Background(), other)
+	if err != nil || resp.Replayed {
+		t.Errorf("Expected a fresh run for another account, got %v / %v", resp, err)
+	}
+}
+
+func TestProcessCSVData_IdempotencyConflict(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+
+	_, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData:        dryRunCSV,
+		IdempotencyKey: strPtr("retry-1"),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, err = service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData:        dryRunCSV,
+		DryRun:         boolPtr(true),
+		IdempotencyKey: strPtr("retry-1"),
+	})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("Expected AlreadyExists for conflicting payload, got %v", err)
+	}
+	st, _ := status.FromError(err)
+	if !strings.Contains(st.Message(), "different request") {
+		t.Errorf("Unexpected conflict message: %s", st.Message())
+	}
+}
+
+func TestProcessCSVFile_IdempotencyKeyNotSharedWithData(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+
+	if _, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData:        dryRunCSV,
+		IdempotencyKey: strPtr("shared"),
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, err := service.ProcessCSVFile(context.Background(), &pb.ProcessCSVFileRequest{
+		CsvFilePath:    strPtr("/nonexistent/file.csv"),
+		IdempotencyKey: strPtr("shared"),
+	})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("Expected AlreadyExists when reusing a key across methods, got %v", err)
+	}
+}
+
+func TestProcessCSVData_IdempotencyErrorsNotStored(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+
+	req := &pb.ProcessCSVDataRequest{CsvData: "", IdempotencyKey: strPtr("retry-1")}
+	for i := 0; i < 2; i++ {
+		_, err := service.ProcessCSVData(context.Background(), req)
+		if status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("Attempt %d: expected InvalidArgument, got %v", i+1, err)
+		}
+	}
+}
+
+func TestProcessCSVData_IdempotencyCancelledResumes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The client goes away after the first record is saved
+	mockDB := &mockDBClient{saveFunc: func(data interface{}) error {
+		cancel()
+		return nil
+	}}
+	service := handler.NewDataProcessorService(mockDB)
+
+	req := &pb.ProcessCSVDataRequest{CsvData: fileResultsCSV, IdempotencyKey: strPtr("retry-1")}
+	first, err := service.ProcessCSVData(ctx, req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if first.Stats.SavedRecords != 1 || first.Stats.ErrorRecords != 1 {
+		t.Fatalf("Expected the import to stop after one record, got %+v", first.Stats)
+	}
+
+	mockDB.saveFunc = nil
+	retry, err := service.ProcessCSVData(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error on retry: %v", err)
+	}
+	if retry.Replayed || retry.Stats.SavedRecords != 1 || retry.Stats.SkippedRecords != 1 {
+		t.Errorf("Expected the retry to skip the saved record and save the rest, got replayed %v with %+v", retry.Replayed, retry.Stats)
+	}
+	if len(mockDB.savedData) != 2 {
+		t.Fatalf("Expected each record to be saved once, got %d saves", len(mockDB.savedData))
+	}
+	firstSave := mockDB.savedData[0].(map[string]interface{})
+	secondSave := mockDB.savedData[1].(map[string]interface{})
+	if firstSave["amount"] == secondSave["amount"] {
+		t.Errorf("Expected the retry to save the other record, got %v twice", firstSave)
+	}
+	if retry.RecordErrors[0].Code != pb.ErrorCode_ERROR_CODE_DUPLICATE || retry.RecordErrors[0].RecordIndex != 1 {
+		t.Errorf("Expected the first record to be reported as already saved, got %v", retry.RecordErrors)
+	}
+
+	// The completed retry is replayed as usual
+	again, err := service.ProcessCSVData(context.Background(), req)
+	if err != nil || !again.Replayed || len(mockDB.savedData) != 2 {
+		t.Errorf("Expected the completed retry to be replayed, got %v / %v", again, err)
+	}
+}
+
+func TestProcessCSVData_IdempotencyKeyTooLong(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+
+	_, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData:        dryRunCSV,
+		IdempotencyKey: strPtr(strings.Repeat("k", 256)),
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestProcessCSVData_IdempotencyExpiry(t *testing.T) {
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorService(mockDB)
+	service.SetIdempotencyStore(idempotency.NewMemoryStore(20 * time.Millisecond))
+
+	req := &pb.ProcessCSVDataRequest{CsvData: dryRunCSV, IdempotencyKey: strPtr("retry-1")}
+	if _, err := service.ProcessCSVData(context.Background(), req); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	saves := len(mockDB.savedData)
+
+	time.Sleep(50 * time.Millisecond)
+
+	resp, err := service.ProcessCSVData(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.Replayed || len(mockDB.savedData) != 2*saves {
+		t.Errorf("Expected expired key to run again, got replayed %v with %d saves", resp.Replayed, len(mockDB.savedData))
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := idempotency.NewMemoryStore(time.Hour)
+
+	if resp, err := store.Begin("key", "a"); resp != nil || err != nil {
+		t.Fatalf("Expected new reservation, got %v / %v", resp, err)
+	}
+	if _, err := store.Begin("key", "a"); !errors.Is(err, idempotency.ErrInProgress) {
+		t.Errorf("Expected ErrInProgress, got %v", err)
+	}
+	if _, err := store.Begin("key", "b"); !errors.Is(err, idempotency.ErrConflict) {
+		t.Errorf("Expected ErrConflict, got %v", err)
+	}
+
+	store.Abort("key")
+	if store.Len() != 0 {
+		t.Errorf("Expected aborted key to be released, got %d entries", store.Len())
+	}
+
+	// An interrupted attempt keeps its saved records for the retry
+	store.Begin("key", "a")
+	store.MarkSaved("key", "row-1")
+	store.Abort("key")
+	if !store.Saved("key", "row-1") || store.Saved("key", "row-2") {
+		t.Errorf("Expected only row-1 to be kept as saved")
+	}
+	if _, err := store.Begin("key", "b"); !errors.Is(err, idempotency.ErrConflict) {
+		t.Errorf("Expected ErrConflict for an interrupted key, got %v", err)
+	}
+	if resp, err := store.Begin("key", "a"); resp != nil || err != nil {
+		t.Fatalf("Expected the retry to reserve the interrupted key, got %v / %v", resp, err)
+	}
+	if _, err := store.Begin("key", "a"); !errors.Is(err, idempotency.ErrInProgress) {
+		t.Errorf("Expected ErrInProgress while the retry runs, got %v", err)
+	}
+
+	store.Complete("key", &pb.ProcessCSVDataResponse{Message: "done"})
+	store.Abort("key")
+
+	resp, err := store.Begin("key", "a")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stored, ok := resp.(*pb.ProcessCSVDataResponse); !ok || stored.Message != "done" {
+		t.Errorf("Expected stored response, got %v", resp)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a, err := idempotency.Fingerprint("ProcessCSVData", &pb.ProcessCSVDataRequest{CsvData: "x"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	b, _ := idempotency.Fingerprint("ProcessCSVData", &pb.ProcessCSVDataRequest{CsvData: "x"})
+	c, _ := idempotency.Fingerprint("ProcessCSVFile", &pb.ProcessCSVDataRequest{CsvData: "x"})
+
+	if a != b || a == c {
+		t.Errorf("Expected fingerprints to depend on method and payload only")
+	}
+}
@@ -0,0 +1,156 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/interchange"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+)
+
+func TestInterchangeNormalize(t *testing.T) {
+	tests := []struct {
+		input      string
+		normalized string
+		key        string
+	}{
+		{"東京", "東京", "東京"},
+		{"東京ＩＣ", "東京IC", "東京"},
+		{" 東京　IC ", "東京 IC", "東京"},
+		{"東京ic", "東京ic", "東京"},
+		{"ﾄｳｷｮｳ", "トウキョウ", "トウキョウ"},
+		{"川口ＪＣＴ", "川口JCT", "川口JCT"},
+		{"大井松田インター", "大井松田インター", "大井松田"},
+		{"IC", "IC", "IC"},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := interchange.Normalize(tt.input); got != tt.normalized {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.normalized)
+			}
+			if got := interchange.Key(tt.input); got != tt.key {
+				t.Errorf("Key(%q) = %q, want %q", tt.input, got, tt.key)
+			}
+		})
+	}
+}
+
+func TestInterchangeDictionary(t *testing.T) {
+	d := interchange.NewDictionary()
+	if err := d.Add(interchange.Entry{Code: "1010", Name: "東京", Aliases: []string{"東京料金所"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, name := range []string{"東京", "東京ＩＣ", "東京IC", "東京料金所"} {
+		entry, ok := d.Lookup(name)
+		if !ok || entry.Code != "1010" || entry.Name != "東京" {
+			t.Errorf("Lookup(%q) = %+v/%v, want 1010 東京", name, entry, ok)
+		}
+	}
+	if _, ok := d.Lookup("横浜"); ok {
+		t.Error("Expected no match for a name outside the dictionary")
+	}
+
+	if err := d.Add(interchange.Entry{Code: "2020", Name: "東京IC"}); !errors.Is(err, interchange.ErrConflict) {
+		t.Errorf("Expected ErrConflict for an alias of another code, got %v", err)
+	}
+	if err := d.Add(interchange.Entry{Name: "横浜"}); err == nil {
+		t.Error("Expected error for an entry without code")
+	}
+}
+
+func TestLoadInterchangeDictionary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "interchanges.yaml")
+	data := `interchanges:
+  - code: "1010"
+    name: 東京
+  - code: "1110"
+    name: 横浜町田
+    aliases: [横浜町田ＩＣ, ﾖｺﾊﾏﾏﾁﾀﾞ]
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := interchange.LoadDictionary(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if entry, ok := d.Lookup("ﾖｺﾊﾏﾏﾁﾀﾞIC"); !ok || entry.Code != "1110" {
+		t.Errorf("Expected half-width alias to match, got %+v/%v", entry, ok)
+	}
+
+	if _, err := interchange.LoadDictionary(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected error for missing dictionary file")
+	}
+}
+
+func TestInterchangeUnmatched(t *testing.T) {
+	u := interchange.NewUnmatched()
+	for _, name := range []string{"名古屋", "横浜", "横浜ＩＣ", "横浜", ""} {
+		u.Add(name)
+	}
+
+	list := u.List()
+	if len(list) != 2 {
+		t.Fatalf("Expected 2 unmatched names, got %+v", list)
+	}
+	if list[0].Name != "横浜" || list[0].Count != 3 || len(list[0].RawNames) != 2 {
+		t.Errorf("Expected 横浜 x3 with 2 spellings first, got %+v", list[0])
+	}
+}
+
+func TestParser_NormalizesICNames(t *testing.T) {
+	p := parser.NewETCCSVParser()
+	result, err := p.Preview(strings.NewReader(`利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京ＩＣ,ﾖｺﾊﾏ,1500,-300,1200,2,1234,********12345678,`), 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	record := result.Records[0]
+	if record.Record.EntryIC != "東京IC" || record.Record.ExitIC != "ヨコハマ" {
+		t.Errorf("Expected NFKC-normalized IC names, got %q / %q", record.Record.EntryIC, record.Record.ExitIC)
+	}
+
+	mapping := findMapping(record.Mappings, "ExitIC", "利用ＩＣ（至）")
+	if mapping == nil || !mapping.Coerced || mapping.RawValue != "ﾖｺﾊﾏ" {
+		t.Errorf("Expected coerced ExitIC mapping, got %+v", mapping)
+	}
+}
+
+func TestProcessCSVData_CanonicalICNames(t *testing.T) {
+	d := interchange.NewDictionary()
+	if err := d.Add(interchange.Entry{Code: "1010", Name: "東京"}); err != nil {
+		t.Fatal(err)
+	}
+
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorService(mockDB)
+	service.SetInterchangeDictionary(d)
+
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData: `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京ＩＣ,横浜,1500,-300,1200,2,1234,********12345678,
+25/09/02,08:00,25/09/02,09:00,横浜IC,東京,1500,-300,1200,2,1234,********12345678,`,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	first := mockDB.savedData[0].(map[string]interface{})
+	if first["entry_ic"] != "東京" || first["entry_ic_code"] != "1010" || first["exit_ic_code"] != "" {
+		t.Errorf("Expected canonical entry IC with code, got %v", first)
+	}
+
+	if len(resp.UnmatchedIcs) != 1 || resp.UnmatchedIcs[0].Name != "横浜" || resp.UnmatchedIcs[0].Count != 2 {
+		t.Errorf("Expected 横浜 reported as unmatched twice, got %v", resp.UnmatchedIcs)
+	}
+}
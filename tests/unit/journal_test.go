@@ -0,0 +1,241 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/journal"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+	"golang.org/x/text/encoding/japanese"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const journalCSV = `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,
+25/09/02,08:00,25/09/02,09:00,東京,横浜,-1500,300,-1200,2,1234,********12345678,取消`
+
+// readJournal decodes an export and returns its CSV rows
+func readJournal(t *testing.T, data []byte, shiftJIS bool) [][]string {
+	t.Helper()
+	if shiftJIS {
+		decoded, err := japanese.ShiftJIS.NewDecoder().Bytes(data)
+		if err != nil {
+			t.Fatalf("Expected Shift_JIS output: %v", err)
+		}
+		data = decoded
+	}
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected valid CSV: %v", err)
+	}
+	return rows
+}
+
+func TestJournalNewEntry(t *testing.T) {
+	settings := journal.DefaultSettings()
+	settings.CreditSubAccount = "ETCカード"
+	settings.DefaultDepartment = "本社"
+	settings.Departments = []journal.Department{
+		{Name: "営業部", Cards: []string{"1234567812345678"}},
+		{Name: "物流部", Vehicles: []string{"truck-1"}},
+	}
+
+	charge := settings.NewEntry(parser.ETCRecord{Date: mustDate("2025-09-01"), EntryIC: "東京", ExitIC: "横浜", Amount: 1200, CardNumber: "********12345678"},
+		journal.Vehicle{Number: "1234"})
+	if charge.DebitAccount != "旅費交通費" || charge.CreditAccount != "未払金" || charge.CreditSubAccount != "ETCカード" ||
+		charge.Amount != 1200 || charge.Department != "営業部" || charge.Description != "ETC 東京→横浜 1234" {
+		t.Errorf("Unexpected charge entry: %+v", charge)
+	}
+
+	refund := settings.NewEntry(parser.ETCRecord{Date: mustDate("2025-09-02"), Amount: -1200, Correction: parser.CorrectionNotes, CardNumber: "********99999999"},
+		journal.Vehicle{ID: "truck-1", Number: "1234"})
+	if refund.DebitAccount != "未払金" || refund.DebitSubAccount != "ETCカード" || refund.CreditAccount != "旅費交通費" ||
+		refund.Amount != 1200 || !refund.Reversal || refund.Department != "物流部" || !strings.HasSuffix(refund.Description, "取消") {
+		t.Errorf("Expected reversal booked the other way round, got %+v", refund)
+	}
+
+	if department := settings.DepartmentFor("********00000000", "", "9999"); department != "本社" {
+		t.Errorf("Expected default department, got %q", department)
+	}
+}
+
+func TestJournalWrite(t *testing.T) {
+	entries := []journal.Entry{
+		{Date: mustDate("2025-09-01"), DebitAccount: "旅費交通費", CreditAccount: "未払金", Amount: 1200, TaxAmount: 109, Department: "営業部", Description: "ETC 東京→横浜"},
+		{Date: mustDate("2025-09-02"), DebitAccount: "未払金", CreditAccount: "旅費交通費", Amount: 1200, TaxAmount: 109, Reversal: true, Description: "ETC 取消"},
+	}
+
+	tests := []struct {
+		format journal.Format
+		rows   int
+		check  func(rows [][]string) bool
+	}{
+		{journal.FormatFreee, 3, func(rows [][]string) bool {
+			return rows[1][0] == "支出" && rows[1][2] == "2025/09/01" && rows[1][5] == "旅費交通費" && rows[1][6] == "課対仕入10%" &&
+				rows[1][9] == "109" && rows[1][12] == "営業部" && rows[2][0] == "収入" && rows[2][5] == "旅費交通費"
+		}},
+		{journal.FormatMoneyForward, 3, func(rows [][]string) bool {
+			return rows[1][2] == "旅費交通費" && rows[1][5] == "課税仕入 10%" && rows[1][7] == "109" && rows[1][11] == "対象外" && rows[1][13] == "" &&
+				rows[2][5] == "対象外" && rows[2][11] == "課税仕入 10%" && rows[2][13] == "109" && rows[2][14] == "ETC 取消"
+		}},
+		{journal.FormatYayoi, 2, func(rows [][]string) bool {
+			return len(rows[0]) == 25 && rows[0][0] == "2000" && rows[0][3] == "2025/09/01" && rows[0][7] == "課対仕入込10%" && rows[0][8] == "1200" && rows[0][9] == "109"
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := journal.Write(&buf, tt.format, "", entries); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !bytes.Contains(buf.Bytes(), []byte("\r\n")) {
+				t.Error("Expected CRLF line endings")
+			}
+			rows := readJournal(t, buf.Bytes(), true)
+			if len(rows) != tt.rows || !tt.check(rows) {
+				t.Errorf("Unexpected %s rows: %v", tt.format, rows)
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := journal.Write(&buf, journal.FormatFreee, parser.EncodingUTF8, entries); err != nil || !strings.Contains(buf.String(), "旅費交通費") {
+		t.Errorf("Expected UTF-8 output, got %v", err)
+	}
+	if err := journal.Write(&buf, journal.FormatFreee, "EUC-JP", entries); !errors.Is(err, journal.ErrUnknownEncoding) {
+		t.Errorf("Expected ErrUnknownEncoding, got %v", err)
+	}
+	if _, err := journal.ParseFormat("quickbooks"); !errors.Is(err, journal.ErrUnknownFormat) {
+		t.Errorf("Expected ErrUnknownFormat, got %v", err)
+	}
+	if format, err := journal.ParseFormat("MF"); err != nil || format != journal.FormatMoneyForward {
+		t.Errorf("Expected MF alias, got %v/%v", format, err)
+	}
+}
+
+func TestJournalLoadSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.yaml")
+	data := `credit_account: 未払費用
+tax_category: 課税仕入10%
+departments:
+  - name: 営業部
+    vehicles: ["2302"]
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	settings, err := journal.LoadSettings(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if settings.DebitAccount != "旅費交通費" || settings.CreditAccount != "未払費用" || settings.TaxCategory != "課税仕入10%" {
+		t.Errorf("Expected defaults kept for omitted fields, got %+v", settings)
+	}
+
+	if err := os.WriteFile(path, []byte("departments:\n  - vehicles: [\"2302\"]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := journal.LoadSettings(path); err == nil {
+		t.Error("Expected error for a department without name")
+	}
+}
+
+func TestExportJournal(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+	settings := journal.DefaultSettings()
+	settings.Departments = []journal.Department{{Name: "営業部", Vehicles: []string{"1234"}}}
+	service.SetJournalSettings(settings)
+
+	resp, err := service.ExportJournal(context.Background(), &pb.ExportJournalRequest{
+		CsvData:  strPtr(journalCSV),
+		Format:   pb.JournalFormat_JOURNAL_FORMAT_MONEY_FORWARD,
+		Encoding: "UTF-8",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.EntryCount != 2 || resp.TaxAmount != 0 || resp.RecordTaxAmount != 0 || resp.Filename != "etc_journal_moneyforward.csv" || resp.ContentType != "text/csv; charset=UTF-8" {
+		t.Errorf("Unexpected response: %v", resp)
+	}
+	rows := readJournal(t, resp.Content, false)
+	if rows[1][2] != "旅費交通費" || rows[1][4] != "営業部" || rows[1][7] != "109" || rows[2][8] != "旅費交通費" || rows[2][12] != "1200" {
+		t.Errorf("Unexpected rows: %v", rows)
+	}
+
+	sjis, err := service.ExportJournal(context.Background(), &pb.ExportJournalRequest{
+		CsvData: strPtr(journalCSV),
+		Format:  pb.JournalFormat_JOURNAL_FORMAT_YAYOI,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sjis.ContentType != "text/csv; charset=Shift_JIS" || len(readJournal(t, sjis.Content, true)) != 2 {
+		t.Errorf("Expected Shift_JIS Yayoi export by default, got %v", sjis.ContentType)
+	}
+}
+
+func TestExportJournal_SkipsRejectedRows(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+
+	// The second row repeats the first and the third has an invalid card; an import would save neither
+	csvData := `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,
+25/09/03,08:00,25/09/03,09:00,東京,横浜,1500,-300,1200,2,1234,invalid,
+25/09/04,08:00,25/09/04,09:00,横浜,東京,2200,0,2200,2,1234,********12345678,`
+	resp, err := service.ExportJournal(context.Background(), &pb.ExportJournalRequest{
+		CsvData:  strPtr(csvData),
+		Format:   pb.JournalFormat_JOURNAL_FORMAT_FREEE,
+		Encoding: "UTF-8",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.EntryCount != 2 || len(readJournal(t, resp.Content, false)) != 3 {
+		t.Fatalf("Expected entries for the two rows an import would save, got %d", resp.EntryCount)
+	}
+	if len(resp.RecordErrors) != 2 || resp.RecordErrors[0].RecordIndex != 2 || resp.RecordErrors[1].RecordIndex != 3 {
+		t.Errorf("Expected the duplicate and the invalid card reported, got %v", resp.RecordErrors)
+	}
+
+	// The journal's tax agrees with the import of the same CSV
+	imported, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{CsvData: csvData})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.TaxAmount != imported.Stats.TaxAmount || resp.RecordTaxAmount != imported.Stats.RecordTaxAmount {
+		t.Errorf("Expected tax %d/%d as on import, got %d/%d",
+			imported.Stats.TaxAmount, imported.Stats.RecordTaxAmount, resp.TaxAmount, resp.RecordTaxAmount)
+	}
+}
+
+func TestExportJournal_Errors(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+
+	tests := []struct {
+		name string
+		req  *pb.ExportJournalRequest
+	}{
+		{"no source", &pb.ExportJournalRequest{Format: pb.JournalFormat_JOURNAL_FORMAT_FREEE}},
+		{"no format", &pb.ExportJournalRequest{CsvData: strPtr(journalCSV)}},
+		{"bad encoding", &pb.ExportJournalRequest{CsvData: strPtr(journalCSV), Format: pb.JournalFormat_JOURNAL_FORMAT_FREEE, Encoding: "EUC-JP"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ExportJournal(context.Background(), tt.req)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("Expected InvalidArgument, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package unit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// logLines decodes JSON log output
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("Invalid log line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestLoggingParseLevel(t *testing.T) {
+	tests := map[string]slog.Level{"": slog.LevelInfo, "debug": slog.LevelDebug, "INFO": slog.LevelInfo, "warning": slog.LevelWarn, "error": slog.LevelError}
+	for name, want := range tests {
+		if got, err := logging.ParseLevel(name); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := logging.ParseLevel("verbose"); err == nil {
+		t.Error("Expected error for unknown level")
+	}
+}
+
+func TestLoggingContextIDs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.New(&buf, slog.LevelInfo)
+
+	ctx := logging.WithAccountID(logging.WithRequestID(context.Background(), "req-1"), "acc-1")
+	logger.InfoContext(ctx, "hello", "key", "value")
+	logger.Debug("hidden below the level")
+	logger.With("component", "test").Info("outside a request")
+
+	lines := logLines(t, &buf)
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 log lines, got %d", len(lines))
+	}
+	if lines[0]["request_id"] != "req-1" || lines[0]["account_id"] != "acc-1" || lines[0]["key"] != "value" || lines[0]["level"] != "INFO" {
+		t.Errorf("Unexpected line: %v", lines[0])
+	}
+	// The keys are present on every line so log queries can rely on them
+	if _, ok := lines[1]["request_id"]; !ok || lines[1]["component"] != "test" {
+		t.Errorf("Unexpected line: %v", lines[1])
+	}
+}
+
+func TestLoggingUnaryServerInterceptor(t *testing.T) {
+	var buf bytes.Buffer
+	interceptor := logging.UnaryServerInterceptor(logging.New(&buf, slog.LevelInfo))
+	info := &grpc.UnaryServerInfo{FullMethod: "/etc_data_processor.DataProcessorService/ProcessCSVData"}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(logging.RequestIDHeader, "req-42"))
+	req := &pb.ProcessCSVDataRequest{AccountId: strPtr("acc-1")}
+	var handlerCtx context.Context
+	_, err := interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		handlerCtx = ctx
+		return &pb.ProcessCSVDataResponse{}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logging.RequestID(handlerCtx) != "req-42" || logging.AccountID(handlerCtx) != "acc-1" {
+		t.Errorf("Handler context has request %q, account %q", logging.RequestID(handlerCtx), logging.AccountID(handlerCtx))
+	}
+
+	// Without metadata a request ID is generated, and failures are logged with their code
+	_, err = interceptor(context.Background(), &pb.HealthCheckRequest{}, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		handlerCtx = ctx
+		return nil, status.Error(codes.InvalidArgument, "bad request")
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected the handler error, got %v", err)
+	}
+	if logging.RequestID(handlerCtx) == "" {
+		t.Error("Expected a generated request ID")
+	}
+
+	lines := logLines(t, &buf)
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 log lines, got %d", len(lines))
+	}
+	if lines[0]["msg"] != "request completed" || lines[0]["request_id"] != "req-42" || lines[0]["method"] != info.FullMethod || lines[0]["code"] != "OK" {
+		t.Errorf("Unexpected line: %v", lines[0])
+	}
+	if lines[1]["level"] != "WARN" || lines[1]["code"] != "InvalidArgument" || lines[1]["error"] == nil {
+		t.Errorf("Unexpected line: %v", lines[1])
+	}
+}
+
+func TestServiceLogging(t *testing.T) {
+	var buf bytes.Buffer
+	service := handler.NewDataProcessorService(&mockDBClient{})
+	service.SetLogger(logging.New(&buf, slog.LevelDebug))
+
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+	csvData := usageCSV + "\n25/10/03,08:00,25/10/03,09:00,東京,横浜,1500,-300,1200,2,1234,12,"
+	if _, err := service.ProcessCSVData(ctx, &pb.ProcessCSVDataRequest{CsvData: csvData, AccountId: strPtr("acc-1")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(buf.String(), "12345678") {
+		t.Error("Log output contains an unmasked card number")
+	}
+	messages := make(map[string]int)
+	for _, line := range logLines(t, &buf) {
+		messages[line["msg"].(string)]++
+		if line["request_id"] != "req-1" || line["account_id"] != "acc-1" {
+			t.Errorf("Line without request and account IDs: %v", line)
+		}
+		if line["msg"] == "record rejected" && (line["level"] != "WARN" || line["card_number"] != "**" || line["line"] != float64(7)) {
+			t.Errorf("Unexpected rejected record line: %v", line)
+		}
+		if line["msg"] == "record saved" && line["card_number"] != "************5678" && line["card_number"] != "************4321" {
+			t.Errorf("Unexpected card number: %v", line["card_number"])
+		}
+	}
+	if messages["parsed CSV data"] != 1 || messages["record saved"] != 5 || messages["record rejected"] != 1 || messages["processed records"] != 1 {
+		t.Errorf("Unexpected log messages: %v", messages)
+	}
+}
@@ -0,0 +1,264 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/masterdata"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func mustDate(value string) time.Time {
+	t, _ := time.Parse("2006-01-02", value)
+	return t
+}
+
+func TestRegistry_Lookup(t *testing.T) {
+	r := masterdata.NewRegistry()
+
+	mustCreate := func(a masterdata.Assignment) masterdata.Assignment {
+		created, err := r.Create(a)
+		if err != nil {
+			t.Fatalf("Create(%+v) failed: %v", a, err)
+		}
+		return created
+	}
+
+	august := mustCreate(masterdata.Assignment{CardNumber: "4111-1111-1111-1111", VehicleID: "V1", DriverID: "D1", ValidTo: mustDate("2025-08-31")})
+	september := mustCreate(masterdata.Assignment{CardNumber: "4111111111111111", VehicleID: "V1", DriverID: "D2", ValidFrom: mustDate("2025-09-01")})
+	byVehicle := mustCreate(masterdata.Assignment{VehicleNumber: "2302", VehicleID: "V9"})
+
+	if august.ID == "" || august.CardNumber != "4111111111111111" {
+		t.Errorf("Expected generated ID and normalized card, got %+v", august)
+	}
+
+	tests := []struct {
+		name          string
+		card          string
+		vehicleNumber string
+		date          string
+		wantID        string
+		wantOK        bool
+	}{
+		{name: "full card before change", card: "4111111111111111", date: "2025-08-31", wantID: august.ID, wantOK: true},
+		{name: "masked card after change", card: "********11111111", date: "2025-09-01", wantID: september.ID, wantOK: true},
+		{name: "vehicle number fallback", card: "********99999999", vehicleNumber: "2302", date: "2025-09-01", wantID: byVehicle.ID, wantOK: true},
+		{name: "unknown card", card: "********99999999", vehicleNumber: "1234", date: "2025-09-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := r.Lookup(tt.card, tt.vehicleNumber, mustDate(tt.date))
+			if ok != tt.wantOK || got.ID != tt.wantID {
+				t.Errorf("Lookup() = %q/%v, want %q/%v", got.ID, ok, tt.wantID, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRegistry_Validation(t *testing.T) {
+	r := masterdata.NewRegistry()
+
+	if _, err := r.Create(masterdata.Assignment{VehicleID: "V1"}); !errors.Is(err, masterdata.ErrInvalid) {
+		t.Errorf("Expected ErrInvalid without card or vehicle number, got %v", err)
+	}
+	if _, err := r.Create(masterdata.Assignment{CardNumber: "4111111111111111"}); !errors.Is(err, masterdata.ErrInvalid) {
+		t.Errorf("Expected ErrInvalid without vehicle or driver, got %v", err)
+	}
+	if _, err := r.Create(masterdata.Assignment{CardNumber: "1234", DriverID: "D1"}); !errors.Is(err, masterdata.ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for malformed card, got %v", err)
+	}
+	if _, err := r.Create(masterdata.Assignment{CardNumber: "4111111111111111", DriverID: "D1", ValidFrom: mustDate("2025-09-02"), ValidTo: mustDate("2025-09-01")}); !errors.Is(err, masterdata.ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for reversed period, got %v", err)
+	}
+
+	first, err := r.Create(masterdata.Assignment{CardNumber: "4111111111111111", DriverID: "D1", ValidFrom: mustDate("2025-09-01")})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := r.Create(masterdata.Assignment{CardNumber: "********11111111", DriverID: "D2", ValidTo: mustDate("2025-09-01")}); !errors.Is(err, masterdata.ErrOverlap) {
+		t.Errorf("Expected ErrOverlap, got %v", err)
+	}
+
+	first.ValidFrom = mustDate("2025-10-01")
+	if _, err := r.Update(first); err != nil {
+		t.Errorf("Updating an assignment must not overlap with itself: %v", err)
+	}
+	if err := r.Delete(first.ID); err != nil {
+		t.Errorf("Unexpected delete error: %v", err)
+	}
+	if _, err := r.Get(first.ID); !errors.Is(err, masterdata.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestRegistry_LoadFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	csvPath := filepath.Join(tmpDir, "cards.csv")
+	csvData := "id,card_number,vehicle_number,vehicle_id,driver_id,valid_from,valid_to\n" +
+		"a1,4111111111111111,2302,V1,D1,2025-09-01,\n"
+	if err := os.WriteFile(csvPath, []byte(csvData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := masterdata.LoadFile(csvPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := r.Lookup("********11111111", "", mustDate("2025-09-15")); !ok || r.Len() != 1 {
+		t.Fatalf("Expected loaded assignment, got %d entries", r.Len())
+	}
+
+	// Changes are written back to the file
+	if _, err := r.Create(masterdata.Assignment{VehicleNumber: "9063", VehicleID: "V2"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	reloaded, err := masterdata.LoadFile(csvPath)
+	if err != nil || reloaded.Len() != 2 {
+		t.Fatalf("Expected 2 persisted assignments, got %v / %v", reloaded, err)
+	}
+
+	yamlPath := filepath.Join(tmpDir, "cards.yaml")
+	yamlData := `assignments:
+  - id: y1
+    card_number: "********22223333"
+    driver_id: D5
+    vehicle_class: 中型車
+    valid_from: "2025-01-01"
+    valid_to: "2025-12-31"
+`
+	if err := os.WriteFile(yamlPath, []byte(yamlData), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err = masterdata.LoadFile(yamlPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if a, ok := r.Lookup("1234567822223333", "", mustDate("2025-06-01")); !ok || a.DriverID != "D5" {
+		t.Errorf("Expected masked registry card to match full statement card, got %+v", a)
+	} else if a.VehicleClass != parser.VehicleClassMedium {
+		t.Errorf("Expected vehicle class label to be parsed, got %v", a.VehicleClass)
+	}
+
+	if _, err := masterdata.LoadFile(filepath.Join(tmpDir, "missing.yaml")); err != nil {
+		t.Errorf("Missing file should yield an empty registry, got %v", err)
+	}
+
+	badPath := filepath.Join(tmpDir, "bad.csv")
+	os.WriteFile(badPath, []byte("id,card_number,driver_id,valid_from\nb1,4111111111111111,D1,2025/09/01\n"), 0644)
+	if _, err := masterdata.LoadFile(badPath); !errors.Is(err, masterdata.ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for bad date, got %v", err)
+	}
+}
+
+func TestCardAssignmentRPCs(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+	ctx := context.Background()
+
+	created, err := service.CreateCardAssignment(ctx, &pb.CreateCardAssignmentRequest{Assignment: &pb.CardAssignment{
+		CardNumber:   "4111 1111 1111 1111",
+		VehicleId:    "V1",
+		DriverId:     "D1",
+		ValidFrom:    "2025-09-01",
+		VehicleClass: pb.VehicleClass_VEHICLE_CLASS_MEDIUM,
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if created.Id == "" || created.CardNumber != "4111111111111111" || created.ValidFrom != "2025-09-01" ||
+		created.VehicleClass != pb.VehicleClass_VEHICLE_CLASS_MEDIUM {
+		t.Errorf("Unexpected created assignment: %v", created)
+	}
+
+	got, err := service.GetCardAssignment(ctx, &pb.GetCardAssignmentRequest{Id: created.Id})
+	if err != nil || got.DriverId != "D1" {
+		t.Errorf("Unexpected get result: %v / %v", got, err)
+	}
+
+	created.DriverId = "D2"
+	updated, err := service.UpdateCardAssignment(ctx, &pb.UpdateCardAssignmentRequest{Assignment: created})
+	if err != nil || updated.DriverId != "D2" {
+		t.Errorf("Unexpected update result: %v / %v", updated, err)
+	}
+
+	list, err := service.ListCardAssignments(ctx, &pb.ListCardAssignmentsRequest{CardNumber: "********11111111"})
+	if err != nil || len(list.Assignments) != 1 {
+		t.Errorf("Expected 1 listed assignment, got %v / %v", list, err)
+	}
+
+	if _, err := service.DeleteCardAssignment(ctx, &pb.DeleteCardAssignmentRequest{Id: created.Id}); err != nil {
+		t.Errorf("Unexpected delete error: %v", err)
+	}
+
+	errorCases := []struct {
+		name string
+		call func() error
+		want codes.Code
+	}{
+		{"create without assignment", func() error { _, err := service.CreateCardAssignment(ctx, &pb.CreateCardAssignmentRequest{}); return err }, codes.InvalidArgument},
+		{"create with bad date", func() error {
+			_, err := service.CreateCardAssignment(ctx, &pb.CreateCardAssignmentRequest{Assignment: &pb.CardAssignment{VehicleNumber: "1", VehicleId: "V", ValidFrom: "9/1"}})
+			return err
+		}, codes.InvalidArgument},
+		{"get missing", func() error { _, err := service.GetCardAssignment(ctx, &pb.GetCardAssignmentRequest{Id: created.Id}); return err }, codes.NotFound},
+		{"get without id", func() error { _, err := service.GetCardAssignment(ctx, &pb.GetCardAssignmentRequest{}); return err }, codes.InvalidArgument},
+		{"update without id", func() error { _, err := service.UpdateCardAssignment(ctx, &pb.UpdateCardAssignmentRequest{}); return err }, codes.InvalidArgument},
+		{"delete missing", func() error { _, err := service.DeleteCardAssignment(ctx, &pb.DeleteCardAssignmentRequest{Id: "nope"}); return err }, codes.NotFound},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if code := status.Code(tc.call()); code != tc.want {
+				t.Errorf("Expected %v, got %v", tc.want, code)
+			}
+		})
+	}
+}
+
+func TestProcessCSVData_MasterDataEnrichment(t *testing.T) {
+	registry := masterdata.NewRegistry()
+	assignment, err := registry.Create(masterdata.Assignment{CardNumber: "********12345678", VehicleID: "V1", DriverID: "D1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorService(mockDB)
+	service.SetMasterData(registry)
+
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData: `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,
+25/09/02,08:00,25/09/02,09:00,横浜,名古屋,3000,-500,2500,2,5678,********87654321,`,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.Stats.SavedRecords != 2 || resp.Stats.UnknownCardRecords != 1 {
+		t.Errorf("Expected both records saved with one unknown card, got %+v", resp.Stats)
+	}
+
+	known := mockDB.savedData[0].(map[string]interface{})
+	if known["assignment_id"] != assignment.ID || known["vehicle_id"] != "V1" || known["driver_id"] != "D1" {
+		t.Errorf("Expected enriched payload, got %v", known)
+	}
+	unknown := mockDB.savedData[1].(map[string]interface{})
+	if unknown["unknown_card"] != true || unknown["vehicle_id"] != nil {
+		t.Errorf("Expected unknown card flag, got %v", unknown)
+	}
+
+	if len(resp.RecordErrors) != 1 || resp.RecordErrors[0].Code != pb.ErrorCode_ERROR_CODE_UNKNOWN_CARD ||
+		!strings.Contains(resp.RecordErrors[0].Message, "************4321") {
+		t.Errorf("Expected masked UNKNOWN_CARD record error, got %v", resp.RecordErrors)
+	}
+}
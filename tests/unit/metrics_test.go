@@ -0,0 +1,107 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// scrape returns the text exposition of the metrics
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	m.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(recorder.Result().Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+// expectMetrics fails for each sample line missing from the exposition
+func expectMetrics(t *testing.T, exposition string, samples ...string) {
+	t.Helper()
+	for _, sample := range samples {
+		if !strings.Contains(exposition, sample+"\n") {
+			t.Errorf("Missing sample %q", sample)
+		}
+	}
+}
+
+func TestMetrics_NilIsNoop(t *testing.T) {
+	var m *metrics.Metrics
+	m.AddRecords(metrics.OutcomeSaved, "acc-1", "header", 1)
+	m.AddPending(1)
+	m.ObserveDedup(true)
+	m.ObserveDBCall("Create", time.Second, nil)
+
+	called := false
+	_, err := m.UnaryServerInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			called = true
+			return nil, nil
+		})
+	if err != nil || !called {
+		t.Errorf("Expected the handler to run, got called=%v err=%v", called, err)
+	}
+}
+
+func TestMetrics_ProcessRecords(t *testing.T) {
+	m := metrics.New()
+	service := handler.NewDataProcessorService(&mockDBClient{})
+	service.SetMetrics(m)
+
+	// The last row repeats the first one
+	csvData := usageCSV + "\n25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,"
+	if _, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{CsvData: csvData, AccountId: strPtr("acc-1")}); err != nil {
+		t.Fatal(err)
+	}
+	// Dry runs are not imports
+	if _, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{CsvData: csvData, AccountId: strPtr("acc-1"), DryRun: boolPtr(true)}); err != nil {
+		t.Fatal(err)
+	}
+
+	expectMetrics(t, scrape(t, m),
+		`etc_processor_records_total{account="acc-1",format="unknown",outcome="parsed"} 6`,
+		`etc_processor_records_total{account="acc-1",format="unknown",outcome="saved"} 5`,
+		`etc_processor_records_total{account="acc-1",format="unknown",outcome="skipped"} 1`,
+		`etc_processor_dedup_checks_total 6`,
+		`etc_processor_dedup_hits_total 1`,
+		`etc_processor_pending_records 0`,
+	)
+}
+
+func TestMetrics_UnaryServerInterceptor(t *testing.T) {
+	m := metrics.New()
+	interceptor := m.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/etc_data_processor.DataProcessorService/ProcessCSVData"}
+
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+	failed := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.InvalidArgument, "bad request")
+	}
+	for _, h := range []grpc.UnaryHandler{ok, ok, failed} {
+		interceptor(context.Background(), nil, info, h)
+	}
+	m.ObserveDBCall("Create", 10*time.Millisecond, nil)
+	m.ObserveDBCall("Create", 20*time.Millisecond, errors.New("unavailable"))
+
+	expectMetrics(t, scrape(t, m),
+		`etc_processor_rpc_duration_seconds_count{code="OK",method="/etc_data_processor.DataProcessorService/ProcessCSVData"} 2`,
+		`etc_processor_rpc_errors_total{code="InvalidArgument",method="/etc_data_processor.DataProcessorService/ProcessCSVData"} 1`,
+		`etc_processor_rpc_in_flight 0`,
+		`etc_processor_db_call_duration_seconds_count{operation="Create"} 2`,
+		`etc_processor_db_call_errors_total{operation="Create"} 1`,
+	)
+}
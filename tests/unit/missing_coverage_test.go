@@ -210,7 +210,7 @@ func TestETCCSVParser_RecordLengthWarning(t *testing.T) {
 	}
 }
 
-// Test parseWithHeaders with vehicle class non-numeric fallback (etc_csv_parser.go:184-186)
+// Test parseWithHeaders with a Japanese vehicle class label
 func TestETCCSVParser_VehicleClassFallback(t *testing.T) {
 	p := parser.NewETCCSVParser()
 
@@ -229,9 +229,9 @@ func TestETCCSVParser_VehicleClassFallback(t *testing.T) {
 		t.Errorf("Expected 1 record, got %d", len(records))
 	}
 
-	// Vehicle class should use fallback value (the actual fallback is 0 for non-numeric)
-	if records[0].VehicleClass != 0 {
-		t.Errorf("Expected vehicle class 0 (fallback for non-numeric), got %d", records[0].VehicleClass)
+	// Japanese labels are mapped to their vehicle class
+	if records[0].VehicleClass != parser.VehicleClassStandard {
+		t.Errorf("Expected vehicle class 1 for '普通車', got %d", records[0].VehicleClass)
 	}
 }
 
@@ -114,9 +114,10 @@ func TestETCCSVParser_ParseDate_Validation(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create a test record with the date to test
 			record := parser.ActualETCRecord{
-				EntryDate:   tt.entryDate,
-				ExitDate:    tt.exitDate,
-				CardNumber:  "1234567890", // Required field
+				EntryDate:    tt.entryDate,
+				ExitDate:     tt.exitDate,
+				CardNumber:   "1234567890", // Required field
+				VehicleClass: parser.VehicleClassStandard,
 			}
 
 			err := p.ValidateRecord(record)
@@ -1,8 +1,12 @@
 package unit
 
 import (
+	"context"
+	"errors"
 	"testing"
 
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
 	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
 )
 
@@ -14,7 +18,7 @@ func TestETCCSVParser_ParseVehicleClass(t *testing.T) {
 		name        string
 		record      []string
 		fieldIndex  int
-		expected    int
+		expected    parser.VehicleClass
 		description string
 	}{
 		{
@@ -28,8 +32,8 @@ func TestETCCSVParser_ParseVehicleClass(t *testing.T) {
 			name:        "non-numeric class",
 			record:      []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "普通車", "m"},
 			fieldIndex:  11,
-			expected:    0,
-			description: "Should return 0 for non-numeric value '普通車'",
+			expected:    parser.VehicleClassStandard,
+			description: "Should parse the label '普通車' as the standard class",
 		},
 		{
 			name:        "empty field",
@@ -77,8 +81,8 @@ func TestETCCSVParser_ParseVehicleClass(t *testing.T) {
 			name:        "spaces around number",
 			record:      []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", " 3 ", "m"},
 			fieldIndex:  11,
-			expected:    0,
-			description: "Should return 0 for spaces around number (strconv.Atoi doesn't trim)",
+			expected:    parser.VehicleClassLarge,
+			description: "Should trim spaces around number",
 		},
 	}
 
@@ -108,15 +112,15 @@ func TestETCCSVParser_ParseVehicleClass_RealScenarios(t *testing.T) {
 		t.Errorf("Expected vehicle class 2, got %d", result)
 	}
 
-	// Scenario 2: ETC record with non-numeric vehicle class
-	etcRecordInvalid := []string{
+	// Scenario 2: ETC record with a Japanese label instead of a code
+	etcRecordLabel := []string{
 		"25/09/01", "08:00", "25/09/01", "09:00", "東京", "横浜",
-		"1500", "-300", "1200", "普通車", "1234", "********12345678", "テスト",
+		"1500", "-300", "1200", "中型車", "1234", "********12345678", "テスト",
 	}
 
-	result = p.ParseVehicleClass(etcRecordInvalid, 9)
-	if result != 0 {
-		t.Errorf("Expected vehicle class 0 for non-numeric value, got %d", result)
+	result = p.ParseVehicleClass(etcRecordLabel, 9)
+	if result != parser.VehicleClassMedium {
+		t.Errorf("Expected vehicle class 2 for '中型車', got %d", result)
 	}
 
 	// Scenario 3: Short record (missing vehicle class field)
@@ -138,13 +142,13 @@ func TestETCCSVParser_ParseVehicleClass_StrconvErrors(t *testing.T) {
 	errorCases := []struct {
 		name     string
 		value    string
-		expected int
+		expected parser.VehicleClass
 	}{
 		{"non-numeric string", "abc", 0},
 		{"mixed alphanumeric", "123abc", 0},
 		{"decimal number", "2.5", 0},
 		{"special characters", "2@#$", 0},
-		{"unicode characters", "２", 0}, // Full-width number
+		{"unknown label", "トラック", 0},
 		{"hex-like string", "0x1A", 0},
 		{"scientific notation", "1e5", 0},
 		{"very large number", "999999999999999999999", 0}, // Overflow
@@ -161,4 +165,85 @@ func TestETCCSVParser_ParseVehicleClass_StrconvErrors(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestVehicleClassFromString(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    parser.VehicleClass
+		wantErr bool
+	}{
+		{"1", parser.VehicleClassStandard, false},
+		{"２", parser.VehicleClassMedium, false},
+		{"大型車", parser.VehicleClassLarge, false},
+		{"特大", parser.VehicleClassExtraLarge, false},
+		{"軽自動車等", parser.VehicleClassLight, false},
+		{"", parser.VehicleClassUnknown, false},
+		{"9", parser.VehicleClass(9), true},
+		{"0", parser.VehicleClassUnknown, true},
+		{"バス", parser.VehicleClassUnknown, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parser.VehicleClassFromString(tt.value)
+			if got != tt.want {
+				t.Errorf("VehicleClassFromString(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+			if tt.wantErr != errors.Is(err, parser.ErrUnknownVehicleClass) {
+				t.Errorf("VehicleClassFromString(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVehicleClass_String(t *testing.T) {
+	if parser.VehicleClassMedium.String() != "中型車" || parser.VehicleClassLight.String() != "軽自動車等" {
+		t.Errorf("Unexpected labels: %s, %s", parser.VehicleClassMedium, parser.VehicleClassLight)
+	}
+	if parser.VehicleClass(9).IsValid() || !parser.VehicleClassExtraLarge.IsValid() {
+		t.Error("Unexpected IsValid result")
+	}
+}
+
+func TestETCCSVParser_ValidateRecord_UnknownVehicleClass(t *testing.T) {
+	p := parser.NewETCCSVParser()
+
+	record := parser.ActualETCRecord{EntryDate: "25/09/01", ExitDate: "25/09/01", CardNumber: "1234", VehicleClass: 9}
+	if err := p.ValidateRecord(record); !errors.Is(err, parser.ErrUnknownVehicleClass) {
+		t.Errorf("Expected unknown vehicle class error, got %v", err)
+	}
+
+	// Missing and unrecognised labels are rejected like CSVParser does
+	record.VehicleClass = parser.VehicleClassUnknown
+	if err := p.ValidateRecord(record); !errors.Is(err, parser.ErrUnknownVehicleClass) {
+		t.Errorf("Expected missing vehicle class to be rejected, got %v", err)
+	}
+
+	record.VehicleClass = parser.VehicleClassLight
+	if err := p.ValidateRecord(record); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestProcessCSVData_UnknownVehicleClass(t *testing.T) {
+	csvData := `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,バス,1234,********12345678,
+25/09/02,08:00,25/09/02,09:00,横浜,名古屋,3000,-500,2500,中型車,1234,********12345678,`
+
+	dbClient := &mockDBClient{}
+	service := handler.NewDataProcessorService(dbClient)
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{CsvData: csvData})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.Stats.SavedRecords != 1 || resp.Stats.ErrorRecords != 1 || len(dbClient.savedData) != 1 {
+		t.Fatalf("Expected the unknown label to be rejected, got %v", resp.Stats)
+	}
+	if len(resp.RecordErrors) != 1 {
+		t.Fatalf("Expected 1 record error, got %v", resp.RecordErrors)
+	}
+	if recordErr := resp.RecordErrors[0]; recordErr.Code != pb.ErrorCode_ERROR_CODE_VALIDATION || recordErr.Field != "vehicle_class" || recordErr.LineNumber != 2 {
+		t.Errorf("Unexpected record error: %v", recordErr)
+	}
+}
@@ -0,0 +1,261 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+	"golang.org/x/text/encoding/japanese"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const previewCSV = `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,後納料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,横浜,"1,500",-300,1200,1100,2,1234,********12345678,テスト
+25/09/02,08:00,25/09/02,09:00,横浜,名古屋,3000,-500,abc,0,x,1234,********12345678,
+bad,08:00,bad,09:00,横浜,名古屋,3000,-500,2500,0,2,1234,********12345678,`
+
+func findMapping(mappings []parser.FieldMapping, field, header string) *parser.FieldMapping {
+	for i := range mappings {
+		if mappings[i].Field == field && mappings[i].Header == header {
+			return &mappings[i]
+		}
+	}
+	return nil
+}
+
+func TestETCCSVParser_Preview(t *testing.T) {
+	p := parser.NewETCCSVParser()
+
+	result, err := p.Preview(strings.NewReader(previewCSV), 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.TotalRecords != 3 || len(result.Records) != 2 {
+		t.Fatalf("Expected 2 of 3 records, got %d of %d", len(result.Records), result.TotalRecords)
+	}
+	if result.Info.Format != parser.FormatHeader {
+		t.Errorf("Expected header format, got %s", result.Info.Format)
+	}
+
+	first := result.Records[0]
+	if first.Record.LineNumber != 2 || first.Raw[4] != "東京" {
+		t.Errorf("Unexpected first record: %+v", first)
+	}
+
+	normal := findMapping(first.Mappings, "NormalAmount", "割引前料金")
+	if normal == nil || normal.Column != 6 || normal.RawValue != "1,500" || normal.Value != "1500" || !normal.Coerced {
+		t.Errorf("Unexpected NormalAmount mapping: %+v", normal)
+	}
+
+	postPayment := findMapping(first.Mappings, "PostPaymentAmount", "後納料金")
+	if postPayment == nil || postPayment.Value != "1100" || first.Record.PostPaymentAmount != 1100 || first.Record.ETCAmount != 1200 {
+		t.Errorf("Expected separate post-payment amount, got %+v (ETCAmount %d)", postPayment, first.Record.ETCAmount)
+	}
+
+	entryIC := findMapping(first.Mappings, "EntryIC", "利用ＩＣ（自）")
+	if entryIC == nil || entryIC.Coerced || entryIC.Value != "東京" {
+		t.Errorf("Unexpected EntryIC mapping: %+v", entryIC)
+	}
+
+	second := result.Records[1]
+	charged := findMapping(second.Mappings, "ETCAmount", "通行料金")
+	if charged == nil || !charged.Coerced || charged.Value != "0" || charged.Note == "" {
+		t.Errorf("Expected coerced ETCAmount for invalid value, got %+v", charged)
+	}
+	class := findMapping(second.Mappings, "VehicleClass", "車種")
+	if class == nil || !class.Coerced || class.Value != "0" {
+		t.Errorf("Expected coerced VehicleClass, got %+v", class)
+	}
+	zeroPostPayment := findMapping(second.Mappings, "PostPaymentAmount", "後納料金")
+	if zeroPostPayment == nil || zeroPostPayment.Value != "0" || second.Record.PostPaymentAmount != 0 {
+		t.Errorf("Expected zero post-payment mapping, got %+v", zeroPostPayment)
+	}
+}
+
+func TestETCCSVParser_PreviewPositional(t *testing.T) {
+	p := parser.NewETCCSVParser()
+
+	data := "25/09/01,08:00,25/09/01,09:00,東京,横浜,首都高,1200,1500,-300,5,2,1234,********12345678,メモ"
+	result, err := p.Preview(strings.NewReader(data), 10)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Info.Format != parser.FormatPositional || len(result.Records) != 1 {
+		t.Fatalf("Unexpected preview: %+v", result)
+	}
+
+	mileage := findMapping(result.Records[0].Mappings, "Mileage", "")
+	if mileage == nil || mileage.Column != 10 || mileage.Value != "5" {
+		t.Errorf("Unexpected Mileage mapping: %+v", mileage)
+	}
+	if len(result.Records[0].Mappings) != 15 {
+		t.Errorf("Expected 15 field mappings, got %d", len(result.Records[0].Mappings))
+	}
+}
+
+func TestPreviewCSV(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+
+	resp, err := service.PreviewCSV(context.Background(), &pb.PreviewCSVRequest{CsvData: strPtr(previewCSV)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.TotalRecords != 3 || len(resp.Records) != 3 {
+		t.Fatalf("Expected 3 records, got %d of %d", len(resp.Records), resp.TotalRecords)
+	}
+	if resp.Format != parser.FormatHeader || resp.Encoding != "" {
+		t.Errorf("Unexpected format/encoding: %s/%s", resp.Format, resp.Encoding)
+	}
+
+	first := resp.Records[0]
+	if first.Parsed.EntryIc != "東京" || first.Parsed.NormalAmount != 1500 || first.Parsed.EtcAmount != 1200 || first.Parsed.PostPaymentAmount != 1100 {
+		t.Errorf("Unexpected parsed record: %v", first.Parsed)
+	}
+	if first.Converted == nil || first.Converted.Date != "2025-09-01" || first.Converted.Amount != 1100 {
+		t.Errorf("Unexpected converted record: %v", first.Converted)
+	}
+	if first.Converted.NormalAmount != 1500 || first.Converted.DiscountAmount != -300 ||
+		first.Converted.EtcAmount != 1200 || first.Converted.PostPaymentAmount != 1100 {
+		t.Errorf("Expected amount breakdown on converted record, got %v", first.Converted)
+	}
+	if len(first.Mappings) == 0 || len(first.RawColumns) != 14 {
+		t.Errorf("Expected mappings and raw columns, got %d/%d", len(first.Mappings), len(first.RawColumns))
+	}
+
+	last := resp.Records[2]
+	if last.Converted != nil || last.ConversionError == "" {
+		t.Errorf("Expected conversion error for invalid dates, got %v", last)
+	}
+}
+
+func TestPreviewCSV_File(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "statement.csv")
+	sjis, err := japanese.ShiftJIS.NewEncoder().Bytes([]byte(fileResultsCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, sjis, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	service := handler.NewDataProcessorService(&mockDBClient{})
+	resp, err := service.PreviewCSV(context.Background(), &pb.PreviewCSVRequest{
+		CsvFilePath: strPtr(path),
+		Limit:       int32Ptr(1),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.Encoding != parser.EncodingShiftJIS || resp.TotalRecords != 2 || len(resp.Records) != 1 {
+		t.Errorf("Unexpected file preview: %s, %d of %d", resp.Encoding, len(resp.Records), resp.TotalRecords)
+	}
+}
+
+func TestPreviewCSV_Errors(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+
+	tests := []struct {
+		name     string
+		service  *handler.DataProcessorService
+		req      *pb.PreviewCSVRequest
+		wantCode codes.Code
+	}{
+		{name: "nil request", service: service, req: nil, wantCode: codes.InvalidArgument},
+		{name: "no input", service: service, req: &pb.PreviewCSVRequest{}, wantCode: codes.InvalidArgument},
+		{
+			name:     "both inputs",
+			service:  service,
+			req:      &pb.PreviewCSVRequest{CsvData: strPtr(previewCSV), CsvFilePath: strPtr("/tmp/x.csv")},
+			wantCode: codes.InvalidArgument,
+		},
+		{name: "negative limit", service: service, req: &pb.PreviewCSVRequest{CsvData: strPtr(previewCSV), Limit: int32Ptr(-1)}, wantCode: codes.InvalidArgument},
+		{name: "missing file", service: service, req: &pb.PreviewCSVRequest{CsvFilePath: strPtr("/nonexistent.csv")}, wantCode: codes.NotFound},
+		{name: "unparseable data", service: service, req: &pb.PreviewCSVRequest{CsvData: strPtr("a,\"b\n\"c,d")}, wantCode: codes.InvalidArgument},
+		{
+			name:     "parser without preview",
+			service:  handler.NewDataProcessorServiceWithDependencies(nil, &MockParserWithValidation{}, handler.NewDefaultValidator()),
+			req:      &pb.PreviewCSVRequest{CsvData: strPtr(previewCSV)},
+			wantCode: codes.Unimplemented,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.req == nil {
+				_, err = tt.service.PreviewCSV(context.Background(), nil)
+			} else {
+				_, err = tt.service.PreviewCSV(context.Background(), tt.req)
+			}
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("Expected %v, got %v", tt.wantCode, err)
+			}
+		})
+	}
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func TestSingleFileRPCs_CSVBasePath(t *testing.T) {
+	basePath := t.TempDir()
+	for _, dir := range []string{"20250901", "20251001"} {
+		if err := os.Mkdir(filepath.Join(basePath, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	latest := filepath.Join(basePath, "20251001")
+	if err := os.WriteFile(filepath.Join(latest, "statement.csv"), []byte(usageCSV), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CSV_BASE_PATH", basePath)
+
+	service := handler.NewDataProcessorService(&mockDBClient{})
+	path := strPtr("ignored.csv")
+	expected := []*pb.ExpectedTotal{{CardNumber: "12345678", Month: "2025-09", Amount: 2200}}
+
+	preview, err := service.PreviewCSV(context.Background(), &pb.PreviewCSVRequest{CsvFilePath: path})
+	if err != nil || preview.TotalRecords != 5 {
+		t.Errorf("Expected the CSV in the latest folder to be previewed, got %v, %v", preview, err)
+	}
+	journal, err := service.ExportJournal(context.Background(), &pb.ExportJournalRequest{CsvFilePath: path, Format: pb.JournalFormat_JOURNAL_FORMAT_FREEE})
+	if err != nil || journal.EntryCount == 0 {
+		t.Errorf("Expected the CSV in the latest folder to be journalized, got %v, %v", journal, err)
+	}
+	export, err := service.ExportRecords(context.Background(), &pb.ExportRecordsRequest{CsvFilePath: path, Format: pb.ExportFormat_EXPORT_FORMAT_CSV})
+	if err != nil || export.RecordCount != 5 {
+		t.Errorf("Expected the CSV in the latest folder to be exported, got %v, %v", export, err)
+	}
+	if _, err := service.ReconcileStatement(context.Background(), &pb.ReconcileStatementRequest{Expected: expected, CsvFilePath: path}); err != nil {
+		t.Errorf("Expected the CSV in the latest folder to be reconciled, got %v", err)
+	}
+
+	// A folder with more than one CSV is ambiguous for a single-file RPC
+	if err := os.WriteFile(filepath.Join(latest, "other.csv"), []byte(usageCSV), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.PreviewCSV(context.Background(), &pb.PreviewCSVRequest{CsvFilePath: path}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got %v", err)
+	}
+	if _, err := service.ExportJournal(context.Background(), &pb.ExportJournalRequest{CsvFilePath: path, Format: pb.JournalFormat_JOURNAL_FORMAT_FREEE}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got %v", err)
+	}
+	if _, err := service.ExportRecords(context.Background(), &pb.ExportRecordsRequest{CsvFilePath: path, Format: pb.ExportFormat_EXPORT_FORMAT_CSV}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got %v", err)
+	}
+	if _, err := service.ReconcileStatement(context.Background(), &pb.ReconcileStatementRequest{Expected: expected, CsvFilePath: path}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/reconcile"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestReconcile_Duplicates(t *testing.T) {
+	imported := []reconcile.Row{
+		{Key: "a", Date: mustDate("2025-09-01"), Amount: 1200},
+		{Key: "a", Date: mustDate("2025-09-01"), Amount: 1200},
+		{Key: "b", Date: mustDate("2025-09-02"), Amount: 1000},
+	}
+	result := reconcile.Reconcile(reconcile.Expected{CardNumber: "1", Month: "2025-09", Amount: 2200}, imported, nil)
+
+	if result.Matched || result.Difference != 1200 || result.ImportedRecords != 3 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Kind != reconcile.IssueDuplicate {
+		t.Errorf("Expected the second copy reported as a duplicate, got %+v", result.Issues)
+	}
+}
+
+func TestReconcile_WithoutStatement(t *testing.T) {
+	imported := []reconcile.Row{
+		{Key: "a", Date: mustDate("2025-09-01"), Amount: 1200},
+		{Key: "b", Date: mustDate("2025-09-02"), Amount: 800},
+	}
+
+	surplus := reconcile.Reconcile(reconcile.Expected{Amount: 1200}, imported, nil)
+	if len(surplus.Issues) != 1 || surplus.Issues[0].Kind != reconcile.IssueExtra || surplus.Issues[0].Row.Key != "b" {
+		t.Errorf("Expected the row matching the surplus as a candidate, got %+v", surplus.Issues)
+	}
+
+	shortfall := reconcile.Reconcile(reconcile.Expected{Amount: 2500}, imported, nil)
+	if len(shortfall.Issues) != 1 || shortfall.Issues[0].Kind != reconcile.IssueMissing || shortfall.Issues[0].Row.Amount != 500 {
+		t.Errorf("Expected a single missing amount, got %+v", shortfall.Issues)
+	}
+
+	matched := reconcile.Reconcile(reconcile.Expected{Amount: 2000, Records: 2}, imported, nil)
+	if !matched.Matched || len(matched.Issues) != 0 {
+		t.Errorf("Expected a match, got %+v", matched)
+	}
+	if recordCount := reconcile.Reconcile(reconcile.Expected{Amount: 2000, Records: 3}, imported, nil); recordCount.Matched {
+		t.Error("Expected a record count difference to fail the match")
+	}
+}
+
+func TestReconcile_WithStatement(t *testing.T) {
+	imported := []reconcile.Row{
+		{Key: "a", Date: mustDate("2025-09-01"), Amount: 1200},
+		{Key: "c", Date: mustDate("2025-09-03"), Amount: 500},
+	}
+	statement := []reconcile.Row{
+		{Key: "a", Date: mustDate("2025-09-01"), Amount: 1200, LineNumber: 2},
+		{Key: "b", Date: mustDate("2025-09-02"), Amount: 1000, LineNumber: 3},
+	}
+	result := reconcile.Reconcile(reconcile.Expected{Amount: 2200}, imported, statement)
+
+	if len(result.Issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %+v", result.Issues)
+	}
+	if missing := result.Issues[0]; missing.Kind != reconcile.IssueMissing || missing.Row.LineNumber != 3 {
+		t.Errorf("Expected statement line 3 missing, got %+v", missing)
+	}
+	if extra := result.Issues[1]; extra.Kind != reconcile.IssueExtra || extra.Row.Key != "c" {
+		t.Errorf("Expected row c extra, got %+v", extra)
+	}
+}
+
+func TestReconcileStatement(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+	if _, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData:   usageCSV,
+		AccountId: strPtr("acc-1"),
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resp, err := service.ReconcileStatement(context.Background(), &pb.ReconcileStatementRequest{
+		AccountId: "acc-1",
+		Expected: []*pb.ExpectedTotal{
+			{CardNumber: "12345678", Month: "2025-09", Amount: 2200, RecordCount: 2},
+			{CardNumber: "********87654321", Month: "2025-09", Amount: 2400},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.Matched || len(resp.Results) != 2 {
+		t.Fatalf("Expected one card to differ, got %v", resp)
+	}
+	if first := resp.Results[0]; !first.Matched || first.ImportedAmount != 2200 || first.ImportedRecordCount != 2 {
+		t.Errorf("Expected the first card to match, got %v", first)
+	}
+	second := resp.Results[1]
+	if second.Matched || second.Difference != -1200 || len(second.Issues) != 1 ||
+		second.Issues[0].Kind != pb.ReconciliationIssueKind_RECONCILIATION_ISSUE_KIND_MISSING {
+		t.Errorf("Expected a 1200 yen shortfall, got %v", second)
+	}
+
+	// With the statement itself the missing rows are pointed out
+	statement := usageCSV + "\n25/09/20,08:00,25/09/20,09:00,東京,横浜,1500,-300,1200,2,5678,********87654321,"
+	withStatement, err := service.ReconcileStatement(context.Background(), &pb.ReconcileStatementRequest{
+		AccountId: "acc-1",
+		Expected:  []*pb.ExpectedTotal{{CardNumber: "87654321", Month: "2025-09", Amount: 2400}},
+		CsvData:   strPtr(statement),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	issues := withStatement.Results[0].Issues
+	if len(issues) != 1 || issues[0].Date != "2025-09-20" || issues[0].LineNumber != 7 || issues[0].Amount != 1200 {
+		t.Errorf("Expected the 2025-09-20 row missing, got %v", issues)
+	}
+}
+
+func TestReconcileStatement_ImportedTwice(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+	for i := 0; i < 2; i++ {
+		if _, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+			CsvData:   usageCSV,
+			AccountId: strPtr("acc-1"),
+		}); err != nil {
+			t.Fatalf("Import %d: unexpected error: %v", i+1, err)
+		}
+	}
+
+	resp, err := service.ReconcileStatement(context.Background(), &pb.ReconcileStatementRequest{
+		AccountId: "acc-1",
+		Expected:  []*pb.ExpectedTotal{{CardNumber: "87654321", Month: "2025-09", Amount: 1200}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result := resp.Results[0]
+	if resp.Matched || result.ImportedAmount != 2400 || result.ImportedRecordCount != 2 || result.Difference != 1200 {
+		t.Fatalf("Expected the row to be counted once per import, got %v", result)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Kind != pb.ReconciliationIssueKind_RECONCILIATION_ISSUE_KIND_DUPLICATE {
+		t.Errorf("Expected a duplicate issue, got %v", result.Issues)
+	}
+}
+
+func TestReconcileStatement_Errors(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+
+	tests := []struct {
+		name string
+		req  *pb.ReconcileStatementRequest
+	}{
+		{"no totals", &pb.ReconcileStatementRequest{}},
+		{"bad month", &pb.ReconcileStatementRequest{Expected: []*pb.ExpectedTotal{{CardNumber: "1234", Month: "2025/09"}}}},
+		{"no card", &pb.ReconcileStatementRequest{Expected: []*pb.ExpectedTotal{{Month: "2025-09"}}}},
+		{"both sources", &pb.ReconcileStatementRequest{
+			Expected:    []*pb.ExpectedTotal{{CardNumber: "1234", Month: "2025-09"}},
+			CsvData:     strPtr(usageCSV),
+			CsvFilePath: strPtr("statement.csv"),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ReconcileStatement(context.Background(), tt.req)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("Expected InvalidArgument, got %v", err)
+			}
+		})
+	}
+
+	service.SetUsageStore(nil)
+	if _, err := service.ReconcileStatement(context.Background(), &pb.ReconcileStatementRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected Unimplemented when usage reporting is disabled, got %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestProcessCSVData_RecordErrorCodes(t *testing.T) {
+	os.Setenv("SKIP_DUPLICATES", "true")
+	defer os.Unsetenv("SKIP_DUPLICATES")
+
+	saves := 0
+	mockDB := &mockDBClient{
+		saveFunc: func(data interface{}) error {
+			saves++
+			if saves == 2 {
+				return errors.New("db unavailable")
+			}
+			return nil
+		},
+	}
+	service := handler.NewDataProcessorService(mockDB)
+
+	req := &pb.ProcessCSVDataRequest{
+		CsvData: `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,テスト
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,テスト
+25/09/02,08:00,25/09/02,09:00,横浜,名古屋,3000,-500,2500,2,1234,********12345678,テスト
+bad,08:00,bad,09:00,横浜,名古屋,3000,-500,2500,2,1234,********12345678,テスト`,
+	}
+
+	resp, err := service.ProcessCSVData(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []struct {
+		code        pb.ErrorCode
+		recordIndex int32
+		lineNumber  int32
+	}{
+		{pb.ErrorCode_ERROR_CODE_DUPLICATE, 2, 3},
+		{pb.ErrorCode_ERROR_CODE_PERSISTENCE, 3, 4},
+		{pb.ErrorCode_ERROR_CODE_CONVERSION, 4, 5},
+	}
+
+	if len(resp.RecordErrors) != len(want) {
+		t.Fatalf("Expected %d record errors, got %d: %v", len(want), len(resp.RecordErrors), resp.RecordErrors)
+	}
+	for i, w := range want {
+		got := resp.RecordErrors[i]
+		if got.Code != w.code || got.RecordIndex != w.recordIndex || got.LineNumber != w.lineNumber {
+			t.Errorf("RecordErrors[%d] = %v/%d/%d, want %v/%d/%d",
+				i, got.Code, got.RecordIndex, got.LineNumber, w.code, w.recordIndex, w.lineNumber)
+		}
+		if resp.Errors[i] != got.Message {
+			t.Errorf("Legacy error %q does not match structured message %q", resp.Errors[i], got.Message)
+		}
+	}
+}
+
+func TestProcessCSVData_CancelledRecordError(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	resp, err := service.ProcessCSVData(ctx, &pb.ProcessCSVDataRequest{
+		CsvData: `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,テスト`,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(resp.RecordErrors) != 1 || resp.RecordErrors[0].Code != pb.ErrorCode_ERROR_CODE_CANCELLED {
+		t.Errorf("Expected a single CANCELLED error, got %v", resp.RecordErrors)
+	}
+}
+
+func TestProcessCSVFile_RecordErrorFilePath(t *testing.T) {
+	tmpDir := t.TempDir()
+	good := filepath.Join(tmpDir, "a.csv")
+	empty := filepath.Join(tmpDir, "b.csv")
+	if err := os.WriteFile(good, []byte(fileResultsCSV), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(empty, []byte(""), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mockDB := &mockDBClient{saveFunc: func(data interface{}) error { return errors.New("down") }}
+	service := handler.NewDataProcessorService(mockDB)
+
+	resp, err := service.ProcessCSVFile(context.Background(), &pb.ProcessCSVFileRequest{CsvFilePath: strPtr(tmpDir)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	counts := make(map[pb.ErrorCode]int)
+	for _, e := range resp.RecordErrors {
+		counts[e.Code]++
+		switch e.Code {
+		case pb.ErrorCode_ERROR_CODE_PERSISTENCE:
+			if e.FilePath != good {
+				t.Errorf("Expected file path %s, got %s", good, e.FilePath)
+			}
+		case pb.ErrorCode_ERROR_CODE_PARSE:
+			if e.FilePath != empty || e.RecordIndex != 0 {
+				t.Errorf("Expected file-level parse error for %s, got %v", empty, e)
+			}
+		}
+	}
+
+	if counts[pb.ErrorCode_ERROR_CODE_PERSISTENCE] != 2 || counts[pb.ErrorCode_ERROR_CODE_PARSE] != 1 {
+		t.Errorf("Unexpected error code counts: %v", counts)
+	}
+}
+
+func TestStatusErrorDetails(t *testing.T) {
+	service := handler.NewDataProcessorServiceWithDependencies(&mockDBClient{},
+		&MockParserWithValidation{
+			ParseFunc: func(reader io.Reader) ([]parser.ActualETCRecord, error) {
+				return nil, errors.New("bad quote")
+			},
+		},
+		handler.NewDefaultValidator())
+
+	tests := []struct {
+		name       string
+		call       func() error
+		wantCode   codes.Code
+		wantReason string
+		wantField  string
+	}{
+		{
+			name: "parse error",
+			call: func() error {
+				_, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{CsvData: "some,csv,data"})
+				return err
+			},
+			wantCode:   codes.InvalidArgument,
+			wantReason: "PARSE",
+			wantField:  "csv_data",
+		},
+		{
+			name: "short account id",
+			call: func() error {
+				_, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{CsvData: "some,csv,data", AccountId: strPtr("ab")})
+				return err
+			},
+			wantCode:   codes.InvalidArgument,
+			wantReason: "VALIDATION",
+			wantField:  "account_id",
+		},
+		{
+			name: "missing file",
+			call: func() error {
+				_, err := service.ProcessCSVFile(context.Background(), &pb.ProcessCSVFileRequest{CsvFilePath: strPtr("/nonexistent/file.csv")})
+				return err
+			},
+			wantCode:   codes.NotFound,
+			wantReason: "VALIDATION",
+			wantField:  "csv_file_path",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, ok := status.FromError(tt.call())
+			if !ok || st.Code() != tt.wantCode {
+				t.Fatalf("Expected %v status, got %v", tt.wantCode, st)
+			}
+
+			var info *errdetails.ErrorInfo
+			var badRequest *errdetails.BadRequest
+			for _, d := range st.Details() {
+				switch v := d.(type) {
+				case *errdetails.ErrorInfo:
+					info = v
+				case *errdetails.BadRequest:
+					badRequest = v
+				}
+			}
+
+			if info == nil || info.Reason != tt.wantReason || info.Domain != "etcdataprocessor.v1" {
+				t.Errorf("Unexpected ErrorInfo: %v", info)
+			}
+			if badRequest == nil || len(badRequest.FieldViolations) != 1 || badRequest.FieldViolations[0].Field != tt.wantField {
+				t.Errorf("Unexpected BadRequest: %v", badRequest)
+			}
+		})
+	}
+}
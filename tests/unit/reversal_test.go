@@ -0,0 +1,167 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+)
+
+const reversalCSV = `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,確定;深夜割引
+25/09/01,08:00,25/09/01,09:00,東京,横浜,-1500,300,-1200,2,1234,********12345678,
+25/09/02,08:00,25/09/02,09:00,横浜,名古屋,3000,-500,2500,2,1234,********12345678,
+25/09/02,08:00,25/09/02,09:00,横浜,名古屋,3000,-500,2500,2,1234,********12345678,取消
+25/09/03,08:00,25/09/03,09:00,名古屋,京都,2000,-400,1600,2,1234,********12345678,
+25/09/03,08:00,25/09/03,09:00,名古屋,京都,2000,400,1600,2,1234,********12345678,
+25/09/04,08:00,25/09/04,09:00,京都,大阪,900,0,900,2,1234,********12345678,`
+
+func TestDetectCorrection(t *testing.T) {
+	tests := []struct {
+		name   string
+		record parser.ActualETCRecord
+		want   parser.CorrectionReason
+	}{
+		{name: "regular charge", record: parser.ActualETCRecord{NormalAmount: 1500, DiscountApplied: -300, ETCAmount: 1200}, want: parser.CorrectionNone},
+		{name: "negative charged amount", record: parser.ActualETCRecord{ETCAmount: -1200}, want: parser.CorrectionNegative},
+		{name: "negative post-payment amount", record: parser.ActualETCRecord{ETCAmount: 1200, PostPaymentAmount: -1200}, want: parser.CorrectionNegative},
+		{name: "refund note", record: parser.ActualETCRecord{ETCAmount: 1200, Notes: "返金"}, want: parser.CorrectionNotes},
+		{name: "discount note is not a correction", record: parser.ActualETCRecord{ETCAmount: 1200, Notes: "確定;深夜割引"}, want: parser.CorrectionNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parser.DetectCorrection(tt.record); got != tt.want {
+				t.Errorf("DetectCorrection() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessCSVData_Reversals(t *testing.T) {
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorService(mockDB)
+
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{CsvData: reversalCSV})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.Stats.SavedRecords != 7 || resp.Stats.ReversalRecords != 3 {
+		t.Fatalf("Expected 7 saved records with 3 reversals, got %+v (errors %v)", resp.Stats, resp.Errors)
+	}
+	if resp.Stats.NetAmount != 900 {
+		t.Errorf("Expected net amount 900, got %d", resp.Stats.NetAmount)
+	}
+
+	want := []struct {
+		amount     int
+		reason     parser.CorrectionReason
+		originLine int
+	}{
+		{1200, parser.CorrectionNone, 0},
+		{-1200, parser.CorrectionNegative, 2},
+		{2500, parser.CorrectionNone, 0},
+		{-2500, parser.CorrectionNotes, 4},
+		{1600, parser.CorrectionNone, 0},
+		{-1600, parser.CorrectionEarlierTrip, 6},
+		{900, parser.CorrectionNone, 0},
+	}
+	for i, w := range want {
+		payload := mockDB.savedData[i].(map[string]interface{})
+		if payload["amount"] != w.amount || payload["correction_reason"] != string(w.reason) || payload["reversal"] != (w.reason != parser.CorrectionNone) {
+			t.Errorf("Record %d: got amount %v reason %v, want %d %q", i+1, payload["amount"], payload["correction_reason"], w.amount, w.reason)
+		}
+
+		original, linked := payload["reversal_of"].(map[string]interface{})
+		if w.originLine == 0 {
+			if linked {
+				t.Errorf("Record %d: unexpected reversal link %v", i+1, original)
+			}
+			continue
+		}
+		if !linked || original["line_number"] != w.originLine || original["amount"] != -w.amount {
+			t.Errorf("Record %d: expected link to line %d, got %v", i+1, w.originLine, original)
+		}
+	}
+}
+
+func TestProcessCSVData_DiscountedRowNotReversed(t *testing.T) {
+	os.Setenv("SKIP_DUPLICATES", "false")
+	defer os.Unsetenv("SKIP_DUPLICATES")
+
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorService(mockDB)
+
+	// A positive ＥＴＣ割引額 that does not return the earlier charge's discount is a printed discount
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData: `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/03,08:00,25/09/03,09:00,名古屋,京都,2000,-400,1600,2,1234,********12345678,
+25/09/03,08:00,25/09/03,09:00,名古屋,京都,2000,200,1600,2,1234,********12345678,
+25/09/03,10:00,25/09/03,11:00,京都,大阪,1000,100,900,2,1234,********12345678,`,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.Stats.SavedRecords != 3 || resp.Stats.ReversalRecords != 0 || resp.Stats.NetAmount != 4100 {
+		t.Fatalf("Expected three charges and no reversal, got %+v", resp.Stats)
+	}
+	for i := 1; i < 3; i++ {
+		payload := mockDB.savedData[i].(map[string]interface{})
+		if payload["reversal"] != false || payload["correction_reason"] != "" {
+			t.Errorf("Record %d: expected a regular charge, got %v", i+1, payload)
+		}
+		if _, linked := payload["reversal_of"]; linked {
+			t.Errorf("Record %d: must not be linked to an earlier charge", i+1)
+		}
+	}
+}
+
+func TestProcessCSVData_EarlierTripCorrectionNotDuplicate(t *testing.T) {
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorService(mockDB)
+
+	// With duplicate skipping on, the row returning the discount still reverses the charge
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData: `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/03,08:00,25/09/03,09:00,名古屋,京都,2000,-400,1600,2,1234,********12345678,
+25/09/03,08:00,25/09/03,09:00,名古屋,京都,2000,400,1600,2,1234,********12345678,
+25/09/03,08:00,25/09/03,09:00,名古屋,京都,2000,400,1600,2,1234,********12345678,`,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.Stats.SavedRecords != 2 || resp.Stats.ReversalRecords != 1 || resp.Stats.SkippedRecords != 1 || resp.Stats.NetAmount != 0 {
+		t.Fatalf("Expected the charge and one reversal, got %+v (errors %v)", resp.Stats, resp.Errors)
+	}
+	payload := mockDB.savedData[1].(map[string]interface{})
+	if payload["correction_reason"] != string(parser.CorrectionEarlierTrip) || payload["amount"] != -1600 {
+		t.Errorf("Expected an earlier-trip reversal, got %v", payload)
+	}
+}
+
+func TestProcessCSVData_ReversalWithoutOriginal(t *testing.T) {
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorService(mockDB)
+
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData: `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,横浜,-1500,300,-1200,2,1234,********12345678,
+25/09/03,08:00,25/09/03,09:00,名古屋,京都,2000,400,1600,2,1234,********12345678,`,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.Stats.ReversalRecords != 1 || resp.Stats.NetAmount != 400 {
+		t.Errorf("Expected only the negative row as reversal, got %+v", resp.Stats)
+	}
+	if _, linked := mockDB.savedData[0].(map[string]interface{})["reversal_of"]; linked {
+		t.Error("Reversal without an earlier charge must not be linked")
+	}
+}
@@ -0,0 +1,116 @@
+package unit
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+)
+
+func TestParseRoute(t *testing.T) {
+	tests := []struct {
+		name string
+		info string
+		want []parser.RouteSegment
+	}{
+		{name: "empty", info: "", want: nil},
+		{name: "single road", info: "東名高速", want: []parser.RouteSegment{{Road: "東名高速"}}},
+		{
+			name: "roads with sections",
+			info: "首都高速（霞が関～大橋ＪＣＴ）→東名高速（東京～厚木）",
+			want: []parser.RouteSegment{
+				{Road: "首都高速", From: "霞が関", To: "大橋JCT"},
+				{Road: "東名高速", From: "東京", To: "厚木"},
+			},
+		},
+		{
+			name: "junction between roads",
+			info: "東名高速 → 海老名JCT → 圏央道(海老名~相模原)",
+			want: []parser.RouteSegment{
+				{Road: "東名高速", Via: []string{"海老名JCT"}},
+				{Road: "圏央道", From: "海老名", To: "相模原"},
+			},
+		},
+		{
+			name: "via list and arrows inside section",
+			info: "中央道(高井戸->八王子JCT->相模湖)/経由:談合坂スマートIC・大月JCT",
+			want: []parser.RouteSegment{
+				{Road: "中央道", From: "高井戸", To: "相模湖", Via: []string{"八王子JCT", "談合坂スマートIC", "大月JCT"}},
+			},
+		},
+		{
+			name: "via-point only",
+			info: "浦和料金所",
+			want: []parser.RouteSegment{{Via: []string{"浦和料金所"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parser.ParseRoute(tt.info); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRoute(%q) = %+v, want %+v", tt.info, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpendByRoad(t *testing.T) {
+	records := []parser.ETCRecord{
+		{Amount: 1001, Segments: parser.ParseRoute("東名高速→圏央道")},
+		{Amount: 500, Segments: parser.ParseRoute("東名高速(東京~厚木)")},
+		{Amount: -500, Segments: parser.ParseRoute("東名高速(東京~厚木)")},
+		{Amount: 300},
+	}
+
+	want := []parser.RoadSpend{
+		{Road: "東名高速", Amount: 501, Trips: 3},
+		{Road: "圏央道", Amount: 500, Trips: 1},
+		{Road: "", Amount: 300, Trips: 1},
+	}
+	if got := parser.SpendByRoad(records); !reflect.DeepEqual(got, want) {
+		t.Errorf("SpendByRoad() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPreviewCSV_RouteSegments(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+
+	resp, err := service.PreviewCSV(context.Background(), &pb.PreviewCSVRequest{
+		CsvData: strPtr(`利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,経路情報,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,相模原,東名高速(東京~海老名JCT)→圏央道,1500,-300,1200,2,1234,********12345678,`),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	segments := resp.Records[0].Converted.RouteSegments
+	if len(segments) != 2 || segments[0].Road != "東名高速" || segments[0].To != "海老名JCT" || segments[1].Road != "圏央道" {
+		t.Errorf("Expected 2 route segments on the converted record, got %v", segments)
+	}
+}
+
+func TestProcessCSVData_RouteSegmentsPayload(t *testing.T) {
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorService(mockDB)
+
+	_, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData: `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,経路情報,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,相模原,東名高速→海老名JCT→圏央道,1500,-300,1200,2,1234,********12345678,`,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	payload := mockDB.savedData[0].(map[string]interface{})
+	segments, ok := payload["route_segments"].([]interface{})
+	if !ok || len(segments) != 2 {
+		t.Fatalf("Expected 2 route segments in payload, got %v", payload["route_segments"])
+	}
+	first := segments[0].(map[string]interface{})
+	if first["road"] != "東名高速" || !reflect.DeepEqual(first["via"], []interface{}{"海老名JCT"}) {
+		t.Errorf("Unexpected first segment: %v", first)
+	}
+}
@@ -0,0 +1,116 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/tax"
+)
+
+func TestTaxSplit(t *testing.T) {
+	tests := []struct {
+		rounding  tax.Rounding
+		inclusive int
+		tax       int
+	}{
+		{tax.RoundDown, 1200, 109}, // 109.09
+		{tax.RoundHalfUp, 1200, 109},
+		{tax.RoundUp, 1200, 110},
+		{tax.RoundUp, 1650, 150},    // exactly 150
+		{tax.RoundHalfUp, 1006, 91}, // 91.45
+		{tax.RoundHalfUp, 1051, 96}, // 95.55
+		{tax.RoundDown, -1200, -109},
+		{tax.RoundUp, -1200, -110},
+		{tax.RoundDown, 0, 0},
+	}
+
+	for _, tt := range tests {
+		breakdown := tax.NewCalculator(tt.rounding).Split(tt.inclusive)
+		if breakdown.Tax != tt.tax || breakdown.Exclusive != tt.inclusive-tt.tax || breakdown.Inclusive != tt.inclusive {
+			t.Errorf("Split(%d) with %s = %+v, want tax %d", tt.inclusive, tt.rounding, breakdown, tt.tax)
+		}
+	}
+
+	// A zero calculator uses the default rate and rounding
+	if breakdown := (tax.Calculator{}).Split(1200); breakdown.Tax != 109 {
+		t.Errorf("Expected defaults for a zero calculator, got %+v", breakdown)
+	}
+	if breakdown := (tax.Calculator{RatePercent: 8}).Split(1080); breakdown.Tax != 80 {
+		t.Errorf("Expected 8%% rate, got %+v", breakdown)
+	}
+}
+
+func TestTaxStatement(t *testing.T) {
+	statement := tax.NewCalculator(tax.RoundDown).NewStatement()
+	for _, amount := range []int{1200, 1200, 1200, 1010} {
+		statement.Add(amount)
+	}
+
+	// Per record: 3 x 109 + 91 = 418; on the total: floor(419.09) = 419
+	total := statement.Total()
+	if total.Inclusive != 4610 || total.Tax != 419 || total.Exclusive != 4191 {
+		t.Errorf("Unexpected statement total: %+v", total)
+	}
+	if statement.RecordTax() != 418 {
+		t.Errorf("Expected per-record tax to differ by rounding, got %d", statement.RecordTax())
+	}
+}
+
+func TestParseTaxRounding(t *testing.T) {
+	for name, want := range map[string]tax.Rounding{"": tax.RoundDown, "floor": tax.RoundDown, "ROUND": tax.RoundHalfUp, " ceil ": tax.RoundUp} {
+		if got, err := tax.ParseRounding(name); err != nil || got != want {
+			t.Errorf("ParseRounding(%q) = %v/%v, want %v", name, got, err, want)
+		}
+	}
+	if _, err := tax.ParseRounding("bankers"); !errors.Is(err, tax.ErrUnknownRounding) {
+		t.Errorf("Expected ErrUnknownRounding, got %v", err)
+	}
+}
+
+func TestProcessCSVData_TaxBreakdown(t *testing.T) {
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorService(mockDB)
+
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData: `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,
+25/09/02,08:00,25/09/02,09:00,横浜,東京,1500,-300,1200,2,1234,********12345678,
+25/09/03,08:00,25/09/03,09:00,横浜,厚木,1010,0,1010,2,1234,********12345678,`,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	first := mockDB.savedData[0].(map[string]interface{})
+	if first["tax_amount"] != 109 || first["tax_exclusive_amount"] != 1091 {
+		t.Errorf("Expected per-record tax in payload, got %v", first)
+	}
+
+	stats := resp.Stats
+	if stats.NetAmount != 3410 || stats.TaxAmount != 310 || stats.TaxExclusiveAmount != 3100 || stats.RecordTaxAmount != 309 {
+		t.Errorf("Expected tax computed on the statement total, got %v", stats)
+	}
+}
+
+func TestPreviewCSV_TaxBreakdown(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+	service.SetTaxCalculator(tax.NewCalculator(tax.RoundUp))
+
+	resp, err := service.PreviewCSV(context.Background(), &pb.PreviewCSVRequest{
+		CsvData: strPtr(strings.Join([]string{
+			"利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考",
+			"25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,",
+		}, "\n")),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	converted := resp.Records[0].Converted
+	if converted.TaxAmount != 110 || converted.TaxExclusiveAmount != 1090 {
+		t.Errorf("Expected rounded-up tax, got %v", converted)
+	}
+}
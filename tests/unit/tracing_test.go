@@ -0,0 +1,195 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// contextDBClient records the span context each save was called with
+type contextDBClient struct {
+	err   error
+	saves []trace.SpanContext
+}
+
+func (m *contextDBClient) SaveETCData(data interface{}) error {
+	return m.SaveETCDataContext(context.Background(), data)
+}
+
+func (m *contextDBClient) SaveETCDataContext(ctx context.Context, data interface{}) error {
+	m.saves = append(m.saves, trace.SpanContextFromContext(ctx))
+	return m.err
+}
+
+// recordSpans installs a global tracer provider recording ended spans for the duration of the test
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+// spansByName groups ended spans by name
+func spansByName(recorder *tracetest.SpanRecorder) map[string][]sdktrace.ReadOnlySpan {
+	byName := make(map[string][]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		byName[span.Name()] = append(byName[span.Name()], span)
+	}
+	return byName
+}
+
+func TestTracing_ProcessCSVFile(t *testing.T) {
+	recorder := recordSpans(t)
+
+	tmpDir := t.TempDir()
+	for _, name := range []string{"202509.csv", "202510.csv"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(fileResultsCSV), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dbClient := &contextDBClient{}
+	service := handler.NewDataProcessorService(dbClient)
+
+	ctx, root := otel.Tracer("test").Start(context.Background(), "ProcessCSVFile")
+	if _, err := service.ProcessCSVFile(ctx, &pb.ProcessCSVFileRequest{CsvFilePath: strPtr(tmpDir)}); err != nil {
+		t.Fatal(err)
+	}
+	root.End()
+
+	byName := spansByName(recorder)
+	// The second statement repeats the first, so its trips are skipped before conversion
+	want := map[string]int{"resolveCSVFilePath": 1, "processFile": 2, "parseFile": 2, "convertRecord": 2, "SaveETCData": 2}
+	for name, count := range want {
+		if len(byName[name]) != count {
+			t.Errorf("Expected %d %s spans, got %d", count, name, len(byName[name]))
+		}
+	}
+
+	traceID := root.SpanContext().TraceID()
+	for _, span := range recorder.Ended() {
+		if span.SpanContext().TraceID() != traceID {
+			t.Errorf("Span %s is not part of the request's trace", span.Name())
+		}
+	}
+
+	// Every file is parsed before any is processed, so parsing is not part of processFile
+	files := make(map[trace.SpanID]bool)
+	for _, name := range []string{"parseFile", "processFile"} {
+		for _, span := range byName[name] {
+			if span.Parent().SpanID() != root.SpanContext().SpanID() {
+				t.Errorf("Expected %s to be a child of the request span", name)
+			}
+		}
+	}
+	for _, span := range byName["processFile"] {
+		files[span.SpanContext().SpanID()] = true
+	}
+	for _, name := range []string{"convertRecord", "SaveETCData"} {
+		for _, span := range byName[name] {
+			if !files[span.Parent().SpanID()] {
+				t.Errorf("Expected %s to be a child of processFile", name)
+			}
+		}
+	}
+
+	// db_service is called with the SaveETCData span so the trace continues there
+	if len(dbClient.saves) != 2 {
+		t.Fatalf("Expected 2 saves, got %d", len(dbClient.saves))
+	}
+	for i, span := range byName["SaveETCData"] {
+		if dbClient.saves[i].SpanID() != span.SpanContext().SpanID() {
+			t.Errorf("Save %d was not called with its SaveETCData span", i)
+		}
+	}
+}
+
+func TestTracing_SaveError(t *testing.T) {
+	recorder := recordSpans(t)
+
+	service := handler.NewDataProcessorService(&contextDBClient{err: errors.New("db_service unavailable")})
+	if _, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{CsvData: fileResultsCSV}); err != nil {
+		t.Fatal(err)
+	}
+
+	byName := spansByName(recorder)
+	if len(byName["parseCSVData"]) != 1 {
+		t.Errorf("Expected a parseCSVData span, got %d", len(byName["parseCSVData"]))
+	}
+	saves := byName["SaveETCData"]
+	if len(saves) != 2 {
+		t.Fatalf("Expected 2 SaveETCData spans, got %d", len(saves))
+	}
+	for _, span := range saves {
+		if span.Status().Code != codes.Error || span.Status().Description != "db_service unavailable" {
+			t.Errorf("Expected error status, got %+v", span.Status())
+		}
+	}
+}
+
+func TestParseExporter(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    tracing.Exporter
+		wantErr bool
+	}{
+		{name: "", want: tracing.ExporterNone},
+		{name: "none", want: tracing.ExporterNone},
+		{name: "Stdout", want: tracing.ExporterStdout},
+		{name: "file", want: tracing.ExporterFile},
+		{name: "otlp", want: tracing.ExporterOTLP},
+		{name: "jaeger", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := tracing.ParseExporter(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseExporter(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("ParseExporter(%q) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSetup_FileExporter(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+
+	if _, err := tracing.Setup(context.Background(), tracing.Options{Exporter: tracing.ExporterFile}); err == nil {
+		t.Error("Expected error without a trace file")
+	}
+
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	shutdown, err := tracing.Setup(context.Background(), tracing.Options{Exporter: tracing.ExporterFile, File: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, span := tracing.Start(context.Background(), "parseFile")
+	tracing.End(span, nil)
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"Name":"parseFile"`) || !strings.Contains(string(data), tracing.ServiceName) {
+		t.Errorf("Expected the span in the trace file, got %s", data)
+	}
+}
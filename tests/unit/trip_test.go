@@ -0,0 +1,215 @@
+package unit
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/parser"
+)
+
+// tripCSV has a two-leg trip (NEXCO then 首都高, chained at 東京), a separate trip two hours later,
+// another card's trip in between, and a refund row for the first leg
+const tripCSV = `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,厚木,東京ＩＣ,1500,-300,1200,2,1234,********12345678,
+25/09/01,09:10,25/09/01,09:40,東京,霞が関,1300,0,1300,2,1234,********12345678,
+25/09/01,09:00,25/09/01,09:30,横浜,東京,800,0,800,2,5678,********87654321,
+25/09/01,11:40,25/09/01,12:00,霞が関,大井,700,0,700,2,1234,********12345678,
+25/09/01,08:00,25/09/01,09:00,厚木,東京,-1500,300,-1200,2,1234,********12345678,取消`
+
+func TestStitchTrips(t *testing.T) {
+	p := parser.NewETCCSVParser()
+	records, err := p.Parse(strings.NewReader(tripCSV))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	trips := p.StitchTrips(records, parser.StitchOptions{})
+
+	var groups [][]int
+	for _, trip := range trips {
+		groups = append(groups, trip.Records)
+	}
+	if want := [][]int{{0, 1}, {2}, {3}, {4}}; !reflect.DeepEqual(groups, want) {
+		t.Fatalf("Expected trips %v, got %v", want, groups)
+	}
+
+	first := trips[0]
+	if first.EntryIC != "厚木" || first.ExitIC != "霞が関" || first.Amount != 2500 {
+		t.Errorf("Unexpected first trip: %+v", first)
+	}
+	if !first.Start.Equal(time.Date(2025, 9, 1, 8, 0, 0, 0, time.UTC)) || !first.End.Equal(time.Date(2025, 9, 1, 9, 40, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected trip period: %v - %v", first.Start, first.End)
+	}
+	if trips[3].Amount != -1200 {
+		t.Errorf("Expected the refund row to be its own trip with a negative amount, got %+v", trips[3])
+	}
+
+	// IDs are stable across runs
+	if again := p.StitchTrips(records, parser.StitchOptions{}); again[0].ID != first.ID || first.ID == trips[1].ID {
+		t.Errorf("Expected stable, distinct trip IDs, got %q / %q", again[0].ID, first.ID)
+	}
+}
+
+func TestStitchTrips_UniqueIDs(t *testing.T) {
+	p := parser.NewETCCSVParser()
+	records, err := p.Parse(strings.NewReader(`利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,厚木,東京,1500,-300,1200,2,1234,********12345678,
+25/09/01,08:00,25/09/01,09:00,厚木,東京,-1500,300,-1200,2,1234,********12345678,取消
+25/09/01,08:00,25/09/01,09:00,厚木,東京,1500,-300,1200,2,1234,********12345678,`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The correction and the repeated charge share the trip key of the first row
+	trips := p.StitchTrips(records, parser.StitchOptions{})
+	if len(trips) != 3 {
+		t.Fatalf("Expected 3 trips, got %+v", trips)
+	}
+	seen := make(map[string]bool)
+	for _, trip := range trips {
+		if seen[trip.ID] {
+			t.Errorf("Trip ID %q is used twice: %+v", trip.ID, trips)
+		}
+		seen[trip.ID] = true
+	}
+
+	// IDs are still stable across runs
+	again := p.StitchTrips(records, parser.StitchOptions{})
+	for i := range trips {
+		if again[i].ID != trips[i].ID {
+			t.Errorf("Trip %d: expected stable ID %q, got %q", i, trips[i].ID, again[i].ID)
+		}
+	}
+}
+
+func TestStitchTrips_Options(t *testing.T) {
+	p := parser.NewETCCSVParser()
+	records, err := p.Parse(strings.NewReader(`利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,厚木,東京,1500,-300,1200,2,1234,********12345678,
+25/09/01,09:20,25/09/01,09:40,用賀,霞が関,1300,0,1300,2,1234,********12345678,`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		opts  parser.StitchOptions
+		trips int
+	}{
+		{name: "ICs must chain by default", opts: parser.StitchOptions{}, trips: 2},
+		{name: "border without chained ICs", opts: parser.StitchOptions{IgnoreICChain: true}, trips: 1},
+		{name: "gap longer than max", opts: parser.StitchOptions{IgnoreICChain: true, MaxGap: 10 * time.Minute}, trips: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(p.StitchTrips(records, tt.opts)); got != tt.trips {
+				t.Errorf("Expected %d trips, got %d", tt.trips, got)
+			}
+		})
+	}
+}
+
+func TestProcessCSVData_StitchTrips(t *testing.T) {
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorService(mockDB)
+
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData:     tripCSV,
+		StitchTrips: boolPtr(true),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(resp.Trips) != 4 {
+		t.Fatalf("Expected 4 trips, got %v", resp.Trips)
+	}
+	trip := resp.Trips[0]
+	if !reflect.DeepEqual(trip.RecordIndexes, []int32{1, 2}) || !reflect.DeepEqual(trip.LineNumbers, []int32{2, 3}) ||
+		trip.TotalAmount != 2500 || trip.StartTime != "2025-09-01T08:00:00" || trip.EndTime != "2025-09-01T09:40:00" {
+		t.Errorf("Unexpected first trip: %v", trip)
+	}
+
+	first := mockDB.savedData[0].(map[string]interface{})
+	second := mockDB.savedData[1].(map[string]interface{})
+	if first["trip_id"] != trip.Id || second["trip_id"] != trip.Id {
+		t.Errorf("Expected both legs tagged with trip %q, got %v / %v", trip.Id, first["trip_id"], second["trip_id"])
+	}
+
+	// Stitching is optional
+	mockDB = &mockDBClient{}
+	resp, err = handler.NewDataProcessorService(mockDB).ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{CsvData: tripCSV})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resp.Trips) != 0 || mockDB.savedData[0].(map[string]interface{})["trip_id"] != nil {
+		t.Errorf("Expected no trips without stitch_trips, got %v", resp.Trips)
+	}
+}
+
+func TestProcessCSVData_StitchTripsAcceptedOnly(t *testing.T) {
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorService(mockDB)
+
+	// The repeated first leg is skipped as a duplicate and must not break the chain to the second leg
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData: `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,厚木,東京,1500,-300,1200,2,1234,********12345678,
+25/09/01,08:00,25/09/01,09:00,厚木,東京,1500,-300,1200,2,1234,********12345678,
+25/09/01,09:05,25/09/01,09:08,東京,東京,100,0,100,2,1234,invalid,
+25/09/01,09:10,25/09/01,09:40,東京,霞が関,1300,0,1300,2,1234,********12345678,`,
+		StitchTrips: boolPtr(true),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.Stats.SavedRecords != 2 || resp.Stats.SkippedRecords != 1 || resp.Stats.ErrorRecords != 1 {
+		t.Fatalf("Unexpected stats: %+v", resp.Stats)
+	}
+	if len(resp.Trips) != 1 {
+		t.Fatalf("Expected one trip over the accepted rows, got %v", resp.Trips)
+	}
+	trip := resp.Trips[0]
+	if !reflect.DeepEqual(trip.RecordIndexes, []int32{1, 4}) || !reflect.DeepEqual(trip.LineNumbers, []int32{2, 5}) || trip.TotalAmount != 2500 {
+		t.Errorf("Unexpected trip: %v", trip)
+	}
+	for i, saved := range mockDB.savedData {
+		if saved.(map[string]interface{})["trip_id"] != trip.Id {
+			t.Errorf("Saved record %d: expected trip %q, got %v", i+1, trip.Id, saved)
+		}
+	}
+
+	// Errors are still reported in record order
+	for i, want := range []int32{2, 3} {
+		if got := resp.RecordErrors[i].RecordIndex; got != want {
+			t.Errorf("RecordErrors[%d].RecordIndex = %d, want %d", i, got, want)
+		}
+	}
+}
+
+func TestPreviewCSV_StitchTrips(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+
+	resp, err := service.PreviewCSV(context.Background(), &pb.PreviewCSVRequest{
+		CsvData:     strPtr(tripCSV),
+		Limit:       int32Ptr(3),
+		StitchTrips: boolPtr(true),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(resp.Trips) != 2 {
+		t.Fatalf("Expected trips over the 3 previewed records, got %v", resp.Trips)
+	}
+	if resp.Records[0].TripId == "" || resp.Records[0].TripId != resp.Records[1].TripId || resp.Records[2].TripId == resp.Records[0].TripId {
+		t.Errorf("Unexpected trip IDs: %q %q %q", resp.Records[0].TripId, resp.Records[1].TripId, resp.Records[2].TripId)
+	}
+}
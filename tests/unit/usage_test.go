@@ -0,0 +1,287 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/masterdata"
+	"github.com/yhonda-ohishi-pub-dev/etc_data_processor/src/pkg/usage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const usageCSV = `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,
+25/09/01,09:10,25/09/01,10:00,横浜,厚木,1000,0,1000,2,1234,********12345678,
+25/09/15,08:00,25/09/15,09:00,東京,横浜,1500,-300,1200,2,5678,********87654321,
+25/10/01,08:00,25/10/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,
+25/10/02,08:00,25/10/02,09:00,東京,横浜,-1500,300,-1200,2,1234,********12345678,取消`
+
+func TestUsageSummarize(t *testing.T) {
+	entries := []usage.Entry{
+		{Key: "a", CardNumber: "1", TripID: "trip-1", Amount: 1200, NormalAmount: 1500, Discount: 300, Date: mustDate("2025-09-01")},
+		{Key: "b", CardNumber: "1", TripID: "trip-1", Amount: 1000, NormalAmount: 1000, Date: mustDate("2025-09-01")},
+		{Key: "c", CardNumber: "1", Amount: -1200, Reversal: true, Date: mustDate("2025-09-02")},
+		{Key: "d", CardNumber: "2", VehicleNumber: "5678", Amount: 800, NormalAmount: 800, Date: mustDate("2025-10-01")},
+	}
+
+	total := usage.Summarize(entries, nil)
+	if len(total) != 1 {
+		t.Fatalf("Expected a single summary without dimensions, got %+v", total)
+	}
+	if total[0].Amount != 1800 || total[0].NormalAmount != 3300 || total[0].Discount != 300 ||
+		total[0].Trips != 2 || total[0].Records != 4 || total[0].Reversals != 1 {
+		t.Errorf("Unexpected total: %+v", total[0])
+	}
+
+	byCardMonth := usage.Summarize(entries, []usage.Dimension{usage.DimensionCard, usage.DimensionMonth})
+	if len(byCardMonth) != 2 {
+		t.Fatalf("Expected 2 groups, got %+v", byCardMonth)
+	}
+	if group := byCardMonth[1].Group; group[usage.DimensionCard] != "2" || group[usage.DimensionMonth] != "2025-10" {
+		t.Errorf("Expected groups ordered by value, got %v", group)
+	}
+
+	// A stitched trip is counted once in every route it has rows in
+	byRoute := usage.Summarize(entries[:2], []usage.Dimension{usage.DimensionRoute})
+	if len(byRoute) != 1 || byRoute[0].Trips != 1 {
+		t.Errorf("Expected one trip for rows without IC names, got %+v", byRoute)
+	}
+
+	if value := entries[3].Value(usage.DimensionVehicle); value != "5678" {
+		t.Errorf("Expected vehicle number without a vehicle ID, got %q", value)
+	}
+}
+
+func TestUsageMemoryStore(t *testing.T) {
+	store := usage.NewMemoryStore()
+	store.Add(usage.Entry{Key: "a", AccountID: "acc-1", Amount: 1000, Date: mustDate("2025-09-01")})
+	store.Add(usage.Entry{Key: "a", AccountID: "acc-1", Amount: 1000, Date: mustDate("2025-09-01")})
+	store.Add(usage.Entry{Key: "b", AccountID: "acc-2", Amount: 500, Date: mustDate("2025-09-30")})
+	store.Add(usage.Entry{Key: "c", AccountID: "acc-1", Amount: 700, Date: mustDate("2025-10-01")})
+
+	if store.Len() != 3 {
+		t.Errorf("Expected entries with the same key to be replaced, got %d entries", store.Len())
+	}
+	if entries := store.Entries(usage.Filter{AccountID: "acc-1", To: mustDate("2025-09-30")}); len(entries) != 1 || entries[0].Saves != 2 {
+		t.Errorf("Expected both saves of entry a to be counted, got %+v", entries)
+	}
+
+	september := store.Summarize(usage.Filter{From: mustDate("2025-09-01"), To: mustDate("2025-09-30")}, nil)
+	if len(september) != 1 || september[0].Amount != 1500 {
+		t.Errorf("Expected inclusive date range, got %+v", september)
+	}
+	account := store.Summarize(usage.Filter{AccountID: "acc-1"}, nil)
+	if len(account) != 1 || account[0].Amount != 1700 {
+		t.Errorf("Expected account filter, got %+v", account)
+	}
+	if empty := store.Summarize(usage.Filter{AccountID: "none"}, nil); len(empty) != 0 {
+		t.Errorf("Expected no summary without matching entries, got %+v", empty)
+	}
+}
+
+func TestUsageMemoryStore_Retention(t *testing.T) {
+	store := usage.NewMemoryStore()
+	store.Add(usage.Entry{Key: "old", Amount: 1000, Date: mustDate("2025-09-01")})
+	store.Add(usage.Entry{Key: "recent", Amount: 500, Date: mustDate("2025-10-10")})
+	store.Add(usage.Entry{Key: "ancient", Amount: 300, Date: mustDate("2023-09-01")})
+	if store.Len() != 2 {
+		t.Errorf("Expected entries beyond the default retention not to be added, got %d entries", store.Len())
+	}
+
+	// Retention counts back from the newest usage date, not from today
+	store.SetRetention(30)
+	if entries := store.Entries(usage.Filter{}); len(entries) != 1 || entries[0].Key != "recent" {
+		t.Errorf("Expected entries older than 30 days to be dropped, got %+v", entries)
+	}
+	store.Add(usage.Entry{Key: "late", Amount: 700, Date: mustDate("2025-09-05")})
+	if store.Len() != 1 {
+		t.Errorf("Expected expired entries not to be added, got %d entries", store.Len())
+	}
+	store.Add(usage.Entry{Key: "newer", Amount: 700, Date: mustDate("2025-11-20")})
+	if entries := store.Entries(usage.Filter{}); len(entries) != 1 || entries[0].Key != "newer" {
+		t.Errorf("Expected newer usage to push out older entries, got %+v", entries)
+	}
+}
+
+func TestUsageFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.jsonl")
+
+	store, err := usage.OpenFileStore(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := []usage.Entry{
+		{Key: "a", AccountID: "acc-1", CardNumber: "12345678", Amount: 1000, Date: mustDate("2025-09-01")},
+		{Key: "a", AccountID: "acc-1", CardNumber: "12345678", Amount: 1000, Date: mustDate("2025-09-01")},
+		{Key: "b", AccountID: "acc-1", CardNumber: "12345678", Amount: 500, Date: mustDate("2025-10-10")},
+	}
+	for _, entry := range entries {
+		if err := store.Add(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reopened, err := usage.OpenFileStore(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := reopened.Entries(usage.Filter{})
+	if len(got) != 2 || got[0].Key != "a" || got[0].Saves != 2 || got[0].CardNumber != "12345678" || got[1].Saves != 1 {
+		t.Fatalf("Expected entries and saves to survive a reload, got %+v", got)
+	}
+
+	// Reopening with a retention period drops old entries and rewrites the file without them
+	retained, err := usage.OpenFileStore(path, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := retained.Entries(usage.Filter{}); len(got) != 1 || got[0].Key != "b" {
+		t.Errorf("Expected only the recent entry, got %+v", got)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := bytes.Count(data, []byte("\n")); lines != 1 {
+		t.Errorf("Expected the file to be compacted to 1 line, got %d", lines)
+	}
+}
+
+func TestReconcileStatement_FileStoreImportedTwice(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.jsonl")
+	store, err := usage.OpenFileStore(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := handler.NewDataProcessorService(&mockDBClient{})
+	service.SetUsageStore(store)
+	for i := 0; i < 2; i++ {
+		if _, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{CsvData: usageCSV}); err != nil {
+			t.Fatalf("Import %d: unexpected error: %v", i+1, err)
+		}
+	}
+
+	// A restarted server still sees that the statement was imported twice
+	reopened, err := usage.OpenFileStore(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restarted := handler.NewDataProcessorService(&mockDBClient{})
+	restarted.SetUsageStore(reopened)
+	resp, err := restarted.ReconcileStatement(context.Background(), &pb.ReconcileStatementRequest{
+		Expected: []*pb.ExpectedTotal{{CardNumber: "87654321", Month: "2025-09", Amount: 1200}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result := resp.Results[0]
+	if resp.Matched || result.ImportedAmount != 2400 || result.ImportedRecordCount != 2 {
+		t.Fatalf("Expected the row to be counted once per import after a reload, got %v", result)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Kind != pb.ReconciliationIssueKind_RECONCILIATION_ISSUE_KIND_DUPLICATE {
+		t.Errorf("Expected a duplicate issue, got %v", result.Issues)
+	}
+}
+
+func TestGetUsageSummary(t *testing.T) {
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorService(mockDB)
+	registry := masterdata.NewRegistry()
+	if _, err := registry.Create(masterdata.Assignment{CardNumber: "********12345678", VehicleID: "truck-1"}); err != nil {
+		t.Fatal(err)
+	}
+	service.SetMasterData(registry)
+
+	for i := 0; i < 2; i++ {
+		// Importing the same statement twice must not double the totals
+		if _, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+			CsvData:     usageCSV,
+			AccountId:   strPtr("acc-1"),
+			StitchTrips: boolPtr(true),
+		}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	resp, err := service.GetUsageSummary(context.Background(), &pb.GetUsageSummaryRequest{
+		FromDate: "2025-09-01",
+		ToDate:   "2025-09-30",
+		GroupBy:  []pb.UsageDimension{pb.UsageDimension_USAGE_DIMENSION_VEHICLE},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resp.Groups) != 2 {
+		t.Fatalf("Expected 2 vehicles, got %v", resp.Groups)
+	}
+	truck := resp.Groups[1]
+	if truck.Vehicle != "truck-1" || truck.Amount != 2200 || truck.DiscountAmount != 300 || truck.TripCount != 1 || truck.RecordCount != 2 {
+		t.Errorf("Expected stitched trip for truck-1, got %v", truck)
+	}
+	if resp.Groups[0].Vehicle != "5678" {
+		t.Errorf("Expected unassigned card grouped by vehicle number, got %v", resp.Groups[0])
+	}
+	if resp.Total.Amount != 3400 || resp.Total.TripCount != 2 {
+		t.Errorf("Unexpected total: %v", resp.Total)
+	}
+
+	monthly, err := service.GetUsageSummary(context.Background(), &pb.GetUsageSummaryRequest{
+		AccountId: "acc-1",
+		GroupBy:   []pb.UsageDimension{pb.UsageDimension_USAGE_DIMENSION_MONTH, pb.UsageDimension_USAGE_DIMENSION_ROUTE},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	october := monthly.Groups[len(monthly.Groups)-1]
+	if october.Month != "2025-10" || october.Route != "東京 → 横浜" || october.Amount != 0 || october.ReversalCount != 1 || october.TripCount != 1 {
+		t.Errorf("Expected the October charge and its cancellation to net out, got %v", october)
+	}
+}
+
+func TestGetUsageSummary_Errors(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+
+	tests := []struct {
+		name string
+		req  *pb.GetUsageSummaryRequest
+	}{
+		{"bad from date", &pb.GetUsageSummaryRequest{FromDate: "2025/09/01"}},
+		{"reversed range", &pb.GetUsageSummaryRequest{FromDate: "2025-09-30", ToDate: "2025-09-01"}},
+		{"unspecified dimension", &pb.GetUsageSummaryRequest{GroupBy: []pb.UsageDimension{pb.UsageDimension_USAGE_DIMENSION_UNSPECIFIED}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.GetUsageSummary(context.Background(), tt.req)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("Expected InvalidArgument, got %v", err)
+			}
+		})
+	}
+
+	service.SetUsageStore(nil)
+	if _, err := service.GetUsageSummary(context.Background(), &pb.GetUsageSummaryRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected Unimplemented when usage reporting is disabled, got %v", err)
+	}
+}
+
+func TestGetUsageSummary_DryRunNotRecorded(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+	if _, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{CsvData: usageCSV, DryRun: boolPtr(true)}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resp, err := service.GetUsageSummary(context.Background(), &pb.GetUsageSummaryRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.Total.RecordCount != 0 {
+		t.Errorf("Expected dry runs to leave usage empty, got %v", resp.Total)
+	}
+}